@@ -43,6 +43,7 @@ OCSERV_PRE_LOGIN_BANNER="Welcome"                             # Default Ocserv p
 LANGUAGES="en:English,it:Italiano,zh-cn:中文(简体),zh-tw:中文(繁體),ru:Русский,fa:فارسی,ar:العربية"  # Supported languages
 SECRET_KEY=$(openssl rand -hex 32)                            # Secret key for app encryption (32 hex chars)
 JWT_SECRET=$(openssl rand -hex 32)                            # JWT signing secret (32 hex chars)
+WEBHOOK_SECRET=$(openssl rand -hex 32)                        # Webhook signing secret (32 hex chars)
 SSL_C=US                                                      # SSL Country iso2
 SSL_ST=CA                                                     # SSL State name
 SSL_L=SanFrancisco                                            # SSl City name
@@ -468,6 +469,7 @@ set_environment() {
 HOST="${HOST}"
 SECRET_KEY="${SECRET_KEY}"
 JWT_SECRET="${JWT_SECRET}"
+WEBHOOK_SECRET="${WEBHOOK_SECRET}"
 LANGUAGES="${LANGUAGES}"
 ALLOW_ORIGINS="https://${HOST}:3443"
 SSL_CN="${SSL_CN}"
@@ -169,6 +169,15 @@ server {
         proxy_set_header X-Forwarded-Proto $scheme;
     }
 
+    # session events pushed by the node agents, the signed path is kept as is
+    location /agent/ {
+        proxy_pass http://log_stream_backend;
+        proxy_set_header Host $host;
+        proxy_set_header X-Real-IP $remote_addr;
+        proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+        proxy_set_header X-Forwarded-Proto $scheme;
+    }
+
     location /ws/ {
         proxy_pass http://log_stream_backend/;
         proxy_http_version 1.1;
@@ -0,0 +1,21 @@
+module github.com/mmtaee/ocserv-dashboard/agent
+
+go 1.25.0
+
+require (
+	github.com/joho/godotenv v1.5.1
+	github.com/mmtaee/ocserv-dashboard/common v0.0.0-00010101000000-000000000000
+	github.com/oklog/ulid/v2 v2.1.1
+)
+
+require (
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.28.0 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+	gorm.io/gorm v1.30.1 // indirect
+)
+
+replace github.com/mmtaee/ocserv-dashboard/common => ./../common
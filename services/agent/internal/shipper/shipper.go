@@ -0,0 +1,111 @@
+package shipper
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/agent/internal/spool"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/oklog/ulid/v2"
+	"time"
+)
+
+const (
+	retryDelay    = time.Second
+	maxRetryDelay = time.Minute
+	// flushInterval pushes events spooled by a previous run without waiting for new ones.
+	flushInterval = 30 * time.Second
+)
+
+// Shipper spools the occtl events of the node and pushes them to the dashboard in
+// batches, retrying with a growing delay while it is unreachable.
+type Shipper struct {
+	spool  *spool.Spool
+	opts   occtlDocker.ClientOptions
+	server string
+	notify chan struct{}
+}
+
+// New returns a shipper pushing the events of server to the log_stream service at
+// opts.URL.
+func New(s *spool.Spool, opts occtlDocker.ClientOptions, server string) *Shipper {
+	return &Shipper{
+		spool:  s,
+		opts:   opts,
+		server: server,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Collect spools the events of in until ctx is done.
+func (s *Shipper) Collect(ctx context.Context, in <-chan models.OcctlEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-in:
+			if !ok {
+				return
+			}
+			err := s.spool.Push(occtlDocker.AgentEvent{ID: ulid.Make().String(), Event: event})
+			if err != nil {
+				logger.Error("Failed to spool %s event of %s: %v", event.Type, event.Username, err)
+			}
+
+			select {
+			case s.notify <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Run pushes the spooled events until ctx is done.
+func (s *Shipper) Run(ctx context.Context) {
+	delay := retryDelay
+	for {
+		pushed, err := s.Flush(ctx)
+		if err != nil {
+			logger.Warn("Failed to push events to the dashboard, %d spooled, retrying in %s: %v", s.spool.Len(), delay, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxRetryDelay)
+			continue
+		}
+		delay = retryDelay
+
+		if pushed > 0 {
+			logger.Info("Pushed %d events to the dashboard", pushed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.notify:
+		case <-time.After(flushInterval):
+		}
+	}
+}
+
+// Flush pushes the spooled events batch by batch and returns how many were pushed.
+// It stops at the first failed batch, which stays spooled.
+func (s *Shipper) Flush(ctx context.Context) (int, error) {
+	pushed := 0
+	for {
+		batch := s.spool.Peek(occtlDocker.MaxEventsBatch)
+		if len(batch) == 0 || ctx.Err() != nil {
+			return pushed, nil
+		}
+
+		if err := occtlDocker.PushEvents(ctx, s.opts, s.server, batch); err != nil {
+			return pushed, err
+		}
+		if err := s.spool.Ack(batch[len(batch)-1].ID); err != nil {
+			logger.Error("Failed to remove pushed events from the spool: %v", err)
+		}
+		pushed += len(batch)
+	}
+}
@@ -0,0 +1,77 @@
+package shipper
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mmtaee/ocserv-dashboard/agent/internal/spool"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFlushKeepsEventsUntilPushed(t *testing.T) {
+	secret := []byte("node-secret")
+	verifier := occtlDocker.NewVerifier(secret)
+
+	var (
+		down     = true
+		received []occtlDocker.AgentEvent
+		paths    []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, requestID, log, ok := occtlDocker.ReadRequest(w, r, verifier)
+		if !ok {
+			return
+		}
+		if down {
+			occtlDocker.WriteError(w, log, requestID, http.StatusServiceUnavailable, occtlDocker.ErrCodeActionFailed, "database down")
+			return
+		}
+
+		var payload occtlDocker.AgentEventsPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		received = append(received, payload.Events...)
+		paths = append(paths, r.URL.Path)
+		occtlDocker.WriteResponse(w, http.StatusOK, occtlDocker.WebhookResponse{OK: true, RequestID: requestID})
+	}))
+	defer server.Close()
+
+	s, err := spool.Open("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shipper := New(s, occtlDocker.ClientOptions{URL: server.URL, Secret: secret}, "node 1")
+
+	in := make(chan models.OcctlEvent, occtlDocker.MaxEventsBatch+1)
+	for i := 0; i <= occtlDocker.MaxEventsBatch; i++ {
+		in <- models.OcctlEvent{Type: models.OcctlEventConnect, ID: i, Username: "john"}
+	}
+	close(in)
+	shipper.Collect(context.Background(), in)
+
+	if _, err = shipper.Flush(context.Background()); err == nil {
+		t.Fatal("flush succeeded while the dashboard is down")
+	}
+	if s.Len() != occtlDocker.MaxEventsBatch+1 {
+		t.Fatalf("spooled %d events after a failed push, want %d", s.Len(), occtlDocker.MaxEventsBatch+1)
+	}
+
+	down = false
+	pushed, err := shipper.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pushed != occtlDocker.MaxEventsBatch+1 || len(received) != pushed || s.Len() != 0 {
+		t.Fatalf("pushed %d, received %d, spooled %d", pushed, len(received), s.Len())
+	}
+	if len(paths) != 2 || paths[0] != occtlDocker.EventsPath+"node 1" {
+		t.Fatalf("paths = %v, want two batches to %snode 1", paths, occtlDocker.EventsPath)
+	}
+	if received[0].ID == "" || received[0].ID == received[1].ID {
+		t.Fatal("events are not given unique IDs")
+	}
+}
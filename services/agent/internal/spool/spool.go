@@ -0,0 +1,196 @@
+package spool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Spool is the FIFO of the events waiting to be pushed to the dashboard. Pushed
+// events are appended to a JSON lines file, so they survive restarts of the agent
+// while the dashboard is unreachable. When it is full the oldest events are
+// dropped. The file is compacted to the spooled events when a batch is
+// acknowledged, or when the dropped events make up half of it.
+type Spool struct {
+	mu     sync.Mutex
+	path   string
+	max    int
+	events []occtlDocker.AgentEvent
+	file   *os.File // the spool file, opened for appending
+	lines  int      // events in the file, the dropped ones included
+}
+
+// Open loads the events left in path by a previous run. An empty path keeps the
+// events in memory only.
+func Open(path string, max int) (*Spool, error) {
+	s := &Spool{path: path, max: max}
+	if path == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	s.trim()
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads the events of the spool file, a missing file holds none.
+func (s *Spool) load() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var event occtlDocker.AgentEvent
+		if err = json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			logger.Warn("Skipping corrupt spooled event: %v", err)
+			continue
+		}
+		s.events = append(s.events, event)
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("read spool %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Push appends events to the spool.
+func (s *Spool) Push(events ...occtlDocker.AgentEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, events...)
+	if err := s.append(events); err != nil {
+		return err
+	}
+
+	if s.trim() && s.lines >= 2*s.max {
+		return s.compact()
+	}
+	return nil
+}
+
+// Peek returns the n oldest events without removing them.
+func (s *Spool) Peek(n int) []occtlDocker.AgentEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n = min(n, len(s.events))
+	return append([]occtlDocker.AgentEvent(nil), s.events[:n]...)
+}
+
+// Ack removes the events up to the one with lastID, the last of a pushed batch.
+// Events dropped meanwhile are already gone, so a missing lastID removes nothing.
+func (s *Spool) Ack(lastID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, event := range s.events {
+		if event.ID == lastID {
+			s.events = append([]occtlDocker.AgentEvent(nil), s.events[i+1:]...)
+			return s.compact()
+		}
+	}
+	return nil
+}
+
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+// trim drops the oldest events beyond max and reports whether it dropped any.
+func (s *Spool) trim() bool {
+	if s.max <= 0 || len(s.events) <= s.max {
+		return false
+	}
+	dropped := len(s.events) - s.max
+	s.events = append([]occtlDocker.AgentEvent(nil), s.events[dropped:]...)
+	logger.Warn("Event spool full, dropped the %d oldest events", dropped)
+	return true
+}
+
+// append writes events at the end of the spool file.
+func (s *Spool) append(events []occtlDocker.AgentEvent) error {
+	if s.file == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	s.lines += len(events)
+	return nil
+}
+
+// compact replaces the spool file with the current events and reopens it for
+// appending.
+func (s *Spool) compact() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tmp := s.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, event := range s.events {
+		if err = enc.Encode(event); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	if s.file != nil {
+		_ = s.file.Close()
+		s.file = nil
+	}
+	if s.file, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600); err != nil {
+		return err
+	}
+	s.lines = len(s.events)
+	return nil
+}
@@ -0,0 +1,149 @@
+package spool
+
+import (
+	"bytes"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func events(ids ...string) []occtlDocker.AgentEvent {
+	var list []occtlDocker.AgentEvent
+	for _, id := range ids {
+		list = append(list, occtlDocker.AgentEvent{ID: id})
+	}
+	return list
+}
+
+func ids(list []occtlDocker.AgentEvent) []string {
+	var out []string
+	for _, event := range list {
+		out = append(out, event.ID)
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSpoolSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool", "events.jsonl")
+
+	s, err := Open(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Push(events("a", "b", "c")...); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Ack("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(reopened.Peek(10)); !equal(got, []string{"b", "c"}) {
+		t.Fatalf("reopened spool = %v, want [b c]", got)
+	}
+}
+
+func TestSpoolDropsOldest(t *testing.T) {
+	s, err := Open("", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Push(events("a", "b", "c", "d", "e")...); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(s.Peek(10)); !equal(got, []string{"c", "d", "e"}) {
+		t.Fatalf("spool = %v, want [c d e]", got)
+	}
+}
+
+func TestSpoolAckAfterDrop(t *testing.T) {
+	s, err := Open("", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Push(events("a", "b", "c")...)
+	batch := s.Peek(2)
+
+	// a and b are dropped while the batch is pushed
+	_ = s.Push(events("d", "e")...)
+	if err = s.Ack(batch[len(batch)-1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(s.Peek(10)); !equal(got, []string{"c", "d", "e"}) {
+		t.Fatalf("spool = %v, want [c d e]", got)
+	}
+
+	_ = s.Ack("c")
+	if s.Len() != 2 {
+		t.Fatalf("len = %d, want 2", s.Len())
+	}
+}
+
+func lineCount(t *testing.T, path string) int {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestSpoolAppendsAndCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	s, err := Open(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		if err = s.Push(events(id)...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the dropped a and b stay in the file until it is compacted
+	if n := lineCount(t, path); n != 5 {
+		t.Fatalf("spool file has %d events, want 5", n)
+	}
+
+	if err = s.Push(events("f")...); err != nil {
+		t.Fatal(err)
+	}
+	if n := lineCount(t, path); n != 3 {
+		t.Fatalf("compacted spool file has %d events, want 3", n)
+	}
+
+	if err = s.Ack("d"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Push(events("g")...); err != nil {
+		t.Fatal(err)
+	}
+	if n := lineCount(t, path); n != 3 {
+		t.Fatalf("spool file has %d events, want 3", n)
+	}
+
+	reopened, err := Open(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(reopened.Peek(10)); !equal(got, []string{"e", "f", "g"}) {
+		t.Fatalf("reopened spool = %v, want [e f g]", got)
+	}
+}
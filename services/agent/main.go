@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"github.com/joho/godotenv"
+	"github.com/mmtaee/ocserv-dashboard/agent/internal/shipper"
+	"github.com/mmtaee/ocserv-dashboard/agent/internal/spool"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var insecure bool
+
+func main() {
+	flag.BoolVar(&insecure, "insecure", false, "Serve plain HTTP, for agents behind a TLS terminating proxy")
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		<-logger.Done()
+	}()
+	logger.Init(ctx, "agent", 100)
+
+	if err := godotenv.Load(); err != nil {
+		logger.Warn("Error loading .env file, using system environment")
+	}
+
+	cfg := config.Agent()
+	if cfg.Secret == "" {
+		logger.Fatal("AGENT_SECRET is required, it is the secret of this server in the dashboard")
+	}
+	if !insecure && (cfg.TLSCert == "" || cfg.TLSKey == "") {
+		logger.Fatal("AGENT_TLS_CERT and AGENT_TLS_KEY are required, run with -insecure to serve plain HTTP")
+	}
+
+	// the agent runs next to ocserv, other drivers are only useful for development
+	driverCfg := config.Driver()
+	if driverCfg.Name == "" {
+		driverCfg.Name = driver.Local
+	}
+	ocservDriver, err := driver.New(driverCfg.Name, driverCfg)
+	if err != nil {
+		logger.Fatal("Failed to create ocserv driver: %v", err)
+	}
+
+	if cfg.PanelURL == "" || cfg.ServerName == "" {
+		logger.Warn("AGENT_PANEL_URL or AGENT_SERVER_NAME not set, session events are not pushed to the dashboard")
+	} else if !ocservDriver.SupportsEvents() {
+		logger.Warn("The %s driver has no occtl events, session events are not pushed to the dashboard", ocservDriver.Name())
+	} else {
+		eventSpool, err := spool.Open(cfg.BufferFile, cfg.BufferSize)
+		if err != nil {
+			logger.Fatal("Failed to open event spool %s: %v", cfg.BufferFile, err)
+		}
+		if n := eventSpool.Len(); n > 0 {
+			logger.Info("%d events left in the spool by the previous run", n)
+		}
+
+		httpClient, err := panelClient(cfg.PanelCA)
+		if err != nil {
+			logger.Fatal("Failed to load AGENT_PANEL_CA %s: %v", cfg.PanelCA, err)
+		}
+
+		eventShipper := shipper.New(eventSpool, occtlDocker.ClientOptions{
+			URL:        strings.TrimRight(cfg.PanelURL, "/"),
+			Secret:     []byte(cfg.Secret),
+			HTTPClient: httpClient,
+		}, cfg.ServerName)
+
+		events := make(chan models.OcctlEvent, 1000)
+		go occtl.WatchEvents(ctx, ocservDriver.Occtl(), events)
+		go eventShipper.Collect(ctx, events)
+		go eventShipper.Run(ctx)
+		logger.Info("Pushing session events of server %s to %s", cfg.ServerName, cfg.PanelURL)
+	}
+
+	verifier := occtlDocker.NewVerifier([]byte(cfg.Secret))
+
+	mux := http.NewServeMux()
+	mux.Handle(occtlDocker.RPCPrefix, occtlDocker.NewRPCServer(verifier, ocservDriver.Users(), ocservDriver.Groups(), ocservDriver.Occtl()))
+	mux.Handle(occtlDocker.LogsPath, occtlDocker.NewLogsHandler(verifier, ocservDriver.Logs))
+
+	// log streams are closed on shutdown instead of holding it up
+	streamsCtx, closeStreams := context.WithCancel(ctx)
+	server := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		BaseContext:       func(net.Listener) context.Context { return streamsCtx },
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    8 << 10,
+	}
+	server.RegisterOnShutdown(closeStreams)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		var err error
+		if insecure {
+			logger.Warn("Agent serving plain HTTP on: %s", server.Addr)
+			err = server.ListenAndServe()
+		} else {
+			logger.Info("Agent listening on: %s", server.Addr)
+			err = server.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Failed to start agent server: %v", err)
+		}
+	}()
+
+	<-stop
+	logger.Warn("Shutting down agent...")
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to shutdown agent server: %v", err)
+	}
+
+	logger.Info("Agent shutdown successfully")
+}
+
+// panelClient returns the client events are pushed with, trusting the certificates
+// of caFile when it is set and the system ones otherwise.
+func panelClient(caFile string) (*http.Client, error) {
+	if caFile == "" {
+		return nil, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no PEM certificate found")
+	}
+
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+	}, nil
+}
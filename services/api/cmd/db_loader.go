@@ -31,7 +31,7 @@ func loader() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	logger.Init(ctx, 100)
+	logger.Init(ctx, "api", 100)
 	defer func() {
 		if r := recover(); r != nil {
 			logger.Error("panic recovered: %v", r)
@@ -264,9 +264,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/customers/summary": {
+        "/customers/login": {
             "post": {
-                "description": "Customer summary account",
+                "description": "Customer login with ocserv account username and password. Returns a short-lived customer token",
                 "consumes": [
                     "application/json"
                 ],
@@ -276,7 +276,7 @@ const docTemplate = `{
                 "tags": [
                     "Customers"
                 ],
-                "summary": "Customer summary account",
+                "summary": "Customer login",
                 "parameters": [
                     {
                         "description": "customer username and password (same ocserv account).",
@@ -284,7 +284,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/customer.SummaryData"
+                            "$ref": "#/definitions/customer.LoginData"
                         }
                     }
                 ],
@@ -292,7 +292,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/customer.SummaryResponse"
+                            "$ref": "#/definitions/customer.LoginResponse"
                         }
                     },
                     "400": {
@@ -310,9 +310,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/home": {
-            "get": {
-                "description": "Content of home",
+        "/customers/me/disconnect_sessions": {
+            "post": {
+                "description": "Disconnect all online sessions of the customer",
                 "consumes": [
                     "application/json"
                 ],
@@ -320,9 +320,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Home"
+                    "Customers(Portal)"
                 ],
-                "summary": "Content of home",
+                "summary": "Disconnect all online sessions of the customer",
                 "parameters": [
                     {
                         "type": "string",
@@ -333,11 +333,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/home.GetHomeResponse"
-                        }
+                    "202": {
+                        "description": "Accepted"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -354,9 +351,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/home/container-stats": {
-            "get": {
-                "description": "Content of docker system usage stats (cpu, ram, swap)",
+        "/customers/me/password": {
+            "post": {
+                "description": "Change the customer ocserv password. The ocpasswd file is updated as well and the customer tokens, this one included, are revoked",
                 "consumes": [
                     "application/json"
                 ],
@@ -364,9 +361,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Home"
+                    "Customers(Portal)"
                 ],
-                "summary": "Content of docker system usage stats",
+                "summary": "Customer change password",
                 "parameters": [
                     {
                         "type": "string",
@@ -374,17 +371,20 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "customer old and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/customer.ChangePasswordData"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/home.DockerService"
-                            }
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -401,19 +401,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/home/ocserv-stats": {
+        "/customers/me/profile": {
             "get": {
-                "description": "Content of ocserv server stats",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Client connection profile of the customer as AnyConnect XML, OpenConnect command line or deep link QR code",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Home"
+                    "Customers(Portal)"
                 ],
-                "summary": "Content of ocserv server stats",
+                "summary": "Customer connection profile",
                 "parameters": [
                     {
                         "type": "string",
@@ -421,13 +418,25 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "anyconnect",
+                            "openconnect",
+                            "qr"
+                        ],
+                        "type": "string",
+                        "description": "Profile format",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/home.OcservStatusResponse"
+                            "$ref": "#/definitions/profile.Bundle"
                         }
                     },
                     "400": {
@@ -445,9 +454,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/home/system-stats": {
+        "/customers/me/session_logs": {
             "get": {
-                "description": "Content of os system usage stats (cpu, ram, swap)",
+                "description": "Customer session history",
                 "consumes": [
                     "application/json"
                 ],
@@ -455,9 +464,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Home"
+                    "Customers(Portal)"
                 ],
-                "summary": "Content of os system usage stats",
+                "summary": "Customer session history",
                 "parameters": [
                     {
                         "type": "string",
@@ -465,13 +474,40 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/home.ServerStatusResponse"
+                            "$ref": "#/definitions/customer.SessionLogsResponse"
                         }
                     },
                     "400": {
@@ -489,9 +525,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/occtl/commands": {
+        "/customers/me/sessions": {
             "get": {
-                "description": "Occtl Commands",
+                "description": "Customer active (online) sessions",
                 "consumes": [
                     "application/json"
                 ],
@@ -499,9 +535,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "OCCTL"
+                    "Customers(Portal)"
                 ],
-                "summary": "Occtl Commands",
+                "summary": "Customer active sessions",
                 "parameters": [
                     {
                         "type": "string",
@@ -509,26 +545,16 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Command Action ID (1 to 15)",
-                        "name": "action",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Optional parameter depending on command",
-                        "name": "value",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OnlineUserSession"
+                            }
                         }
                     },
                     "400": {
@@ -546,9 +572,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/occtl/server_info": {
+        "/customers/me/statistics": {
             "get": {
-                "description": "Server information",
+                "description": "Customer daily usage chart data",
                 "consumes": [
                     "application/json"
                 ],
@@ -556,14 +582,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "OCCTL"
+                    "Customers(Portal)"
+                ],
+                "summary": "Customer daily usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Server information",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.OcservInfo"
+                            "$ref": "#/definitions/customer.StatisticsResponse"
                         }
                     },
                     "400": {
@@ -571,13 +618,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/request.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
                     }
                 }
             }
         },
-        "/ocserv/groups": {
+        "/customers/me/summary": {
             "get": {
-                "description": "List of Ocserv groups",
+                "description": "Customer account summary with the usage of the current and previous month",
                 "consumes": [
                     "application/json"
                 ],
@@ -585,41 +638,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Groups)"
+                    "Customers(Portal)"
                 ],
-                "summary": "List of Ocserv groups",
+                "summary": "Customer account summary",
                 "parameters": [
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Page number, starting from 1",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 100,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to order by",
-                        "name": "order",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "ASC",
-                            "DESC"
-                        ],
-                        "type": "string",
-                        "description": "Sort order, either ASC or DESC",
-                        "name": "sort",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -632,7 +654,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/ocserv_group.OcservGroupsResponse"
+                            "$ref": "#/definitions/customer.SummaryResponse"
                         }
                     },
                     "400": {
@@ -648,9 +670,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/customers/summary": {
             "post": {
-                "description": "Ocserv Group creation",
+                "description": "Customer summary account",
                 "consumes": [
                     "application/json"
                 ],
@@ -658,32 +682,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Groups)"
+                    "Customers"
                 ],
-                "summary": "Ocserv Group creation",
+                "summary": "Customer summary account",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bearer TOKEN",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "description": "ocserv group create data",
+                        "description": "customer username and password (same ocserv account).",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ocserv_group.CreateOcservGroupData"
+                            "$ref": "#/definitions/customer.SummaryData"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.OcservGroup"
+                            "$ref": "#/definitions/customer.SummaryResponse"
                         }
                     },
                     "400": {
@@ -692,18 +709,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/request.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
-                            "$ref": "#/definitions/middlewares.Unauthorized"
+                            "$ref": "#/definitions/middlewares.TooManyRequests"
                         }
                     }
                 }
             }
         },
-        "/ocserv/groups/defaults": {
+        "/home": {
             "get": {
-                "description": "Ocserv Defaults Group config",
+                "description": "Content of home",
                 "consumes": [
                     "application/json"
                 ],
@@ -711,9 +728,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Groups)"
+                    "Home"
                 ],
-                "summary": "Ocserv Defaults Group config",
+                "summary": "Content of home",
                 "parameters": [
                     {
                         "type": "string",
@@ -727,8 +744,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/home.GetHomeResponse"
                         }
                     },
                     "400": {
@@ -744,9 +760,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update Ocserv Defaults Group",
+            }
+        },
+        "/home/bandwidth": {
+            "get": {
+                "description": "Current throughput in bits per second of every interface and ocserv session (tun device)",
                 "consumes": [
                     "application/json"
                 ],
@@ -754,9 +772,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Groups)"
+                    "Home"
                 ],
-                "summary": "Update Ocserv Defaults Group",
+                "summary": "Live server and session bandwidth",
                 "parameters": [
                     {
                         "type": "string",
@@ -764,20 +782,14 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "description": "ocserv group default data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ocserv_group.UpdateOcservGroupData"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/monitor.BandwidthSnapshot"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -794,19 +806,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/ocserv/groups/lookup": {
+        "/home/bandwidth/stream": {
             "get": {
-                "description": "List of Ocserv group names",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Server-Sent Events stream of bandwidth snapshots, one \"bandwidth\" event per sample",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Ocserv(Groups)"
+                    "Home"
                 ],
-                "summary": "List of Ocserv group names",
+                "summary": "Live server and session bandwidth stream",
                 "parameters": [
                     {
                         "type": "string",
@@ -820,16 +829,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/request.ErrorResponse"
+                            "$ref": "#/definitions/monitor.BandwidthSnapshot"
                         }
                     },
                     "401": {
@@ -841,9 +841,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ocserv/groups/sync": {
-            "post": {
-                "description": "Ocserv Groups from file",
+        "/home/container-stats": {
+            "get": {
+                "description": "Content of docker system usage stats (cpu, ram, swap)",
                 "consumes": [
                     "application/json"
                 ],
@@ -851,9 +851,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(UnsyncedGroup)"
+                    "Home"
                 ],
-                "summary": "Ocserv Groups from file",
+                "summary": "Content of docker system usage stats",
                 "parameters": [
                     {
                         "type": "string",
@@ -861,15 +861,6 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "description": "list of groups with config to sync in db",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ocserv_group.SyncGroupRequest"
-                        }
                     }
                 ],
                 "responses": {
@@ -878,7 +869,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/home.DockerService"
                             }
                         }
                     },
@@ -897,9 +888,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ocserv/groups/unsynced": {
+        "/home/metrics": {
             "get": {
-                "description": "list of Unsynced Groups",
+                "description": "Stored samples of cpu, memory, disk, network throughput and online sessions for charts",
                 "consumes": [
                     "application/json"
                 ],
@@ -907,9 +898,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(UnsyncedGroup)"
+                    "Home"
                 ],
-                "summary": "list of Unsynced Groups",
+                "summary": "Historical server resource metrics",
                 "parameters": [
                     {
                         "type": "string",
@@ -917,16 +908,25 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "hour",
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "default": "hour",
+                        "description": "range of samples",
+                        "name": "range",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/group.UnsyncedGroup"
-                            }
+                            "$ref": "#/definitions/home.MetricsResponse"
                         }
                     },
                     "400": {
@@ -944,9 +944,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ocserv/groups/{id}": {
+        "/home/ocserv-stats": {
             "get": {
-                "description": "Ocserv group detail",
+                "description": "Content of ocserv server stats",
                 "consumes": [
                     "application/json"
                 ],
@@ -954,17 +954,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Groups)"
+                    "Home"
                 ],
-                "summary": "Ocserv group detail",
+                "summary": "Content of ocserv server stats",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Ocserv Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -977,7 +970,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.OcservGroup"
+                            "$ref": "#/definitions/home.OcservStatusResponse"
                         }
                     },
                     "400": {
@@ -993,9 +986,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Ocserv Group delete",
+            }
+        },
+        "/home/servers": {
+            "get": {
+                "description": "Status, latency, online sessions and traffic of the ocserv of the dashboard and of every server,\nwith the sessions and traffic summed over the healthy ones",
                 "consumes": [
                     "application/json"
                 ],
@@ -1003,9 +998,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Groups)"
+                    "Home"
                 ],
-                "summary": "Ocserv Group delete",
+                "summary": "Health of the ocserv servers",
                 "parameters": [
                     {
                         "type": "string",
@@ -1013,18 +1008,14 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Ocserv Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/home.ServersHealthResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1039,9 +1030,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Ocserv Group update",
+            }
+        },
+        "/home/system-stats": {
+            "get": {
+                "description": "Content of os system usage stats (cpu, ram, swap)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1049,9 +1042,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Groups)"
+                    "Home"
                 ],
-                "summary": "Ocserv Group update",
+                "summary": "Content of os system usage stats",
                 "parameters": [
                     {
                         "type": "string",
@@ -1059,29 +1052,13 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Ocserv Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "ocserv group create data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ocserv_group.UpdateOcservGroupData"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.OcservGroup"
+                            "$ref": "#/definitions/home.ServerStatusResponse"
                         }
                     },
                     "400": {
@@ -1099,9 +1076,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ocserv/users": {
+        "/ip_bans": {
             "get": {
-                "description": "List of Ocserv Users",
+                "description": "List of IP bans added by operators, expired bans are removed by the ban poller",
                 "consumes": [
                     "application/json"
                 ],
@@ -1109,9 +1086,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "IP Bans"
                 ],
-                "summary": "List of Ocserv Users",
+                "summary": "List of manual IP bans",
                 "parameters": [
                     {
                         "minimum": 1,
@@ -1144,25 +1121,6 @@ const docTemplate = `{
                         "name": "sort",
                         "in": "query"
                     },
-                    {
-                        "minLength": 2,
-                        "type": "string",
-                        "description": "ocserv username q search",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "online",
-                            "active",
-                            "deactivated",
-                            "locked"
-                        ],
-                        "type": "string",
-                        "description": "filter ocserv user by statues",
-                        "name": "filter",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -1175,7 +1133,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/ocserv_user.OcservUsersResponse"
+                            "$ref": "#/definitions/ip_ban.IPBansResponse"
                         }
                     },
                     "400": {
@@ -1193,7 +1151,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Ocserv User creation",
+                "description": "Ban an IP address for a duration or until removed. Sessions from the address are disconnected\nimmediately and whenever they connect again. Allowlisted addresses cannot be banned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1201,9 +1159,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "IP Bans"
                 ],
-                "summary": "Ocserv User creation",
+                "summary": "Manual IP ban",
                 "parameters": [
                     {
                         "type": "string",
@@ -1213,12 +1171,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "ocserv user create data",
+                        "description": "ip ban data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ocserv_user.CreateOcservUserData"
+                            "$ref": "#/definitions/ip_ban.CreateIPBanData"
                         }
                     }
                 ],
@@ -1226,7 +1184,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.OcservUser"
+                            "$ref": "#/definitions/github_com_mmtaee_ocserv-dashboard_api_internal_models.IPBan"
                         }
                     },
                     "400": {
@@ -1240,13 +1198,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
                     }
                 }
             }
         },
-        "/ocserv/users/ocpasswd": {
+        "/ip_bans/allowlist": {
             "get": {
-                "description": "Ocserv Users from ocpasswd file",
+                "description": "Addresses and networks that are never kept banned",
                 "consumes": [
                     "application/json"
                 ],
@@ -1254,41 +1218,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Ocpasswd)"
+                    "IP Bans"
                 ],
-                "summary": "Ocserv Users from ocpasswd file",
+                "summary": "IP allowlist",
                 "parameters": [
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Page number, starting from 1",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 100,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to order by",
-                        "name": "order",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "ASC",
-                            "DESC"
-                        ],
-                        "type": "string",
-                        "description": "Sort order, either ASC or DESC",
-                        "name": "sort",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -1301,7 +1234,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/ocserv_user.OcservUsersSyncResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IPAllowlist"
+                            }
                         }
                     },
                     "400": {
@@ -1317,11 +1253,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/ocserv/users/ocpasswd/sync": {
+            },
             "post": {
-                "description": "Ocserv Users from ocpasswd file to db",
+                "description": "Allowlist an IP address or CIDR network. ocserv bans of matching addresses are lifted by the ban poller.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1329,9 +1263,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Ocpasswd)"
+                    "IP Bans"
                 ],
-                "summary": "Ocserv Users from ocpasswd file to db",
+                "summary": "Add to IP allowlist",
                 "parameters": [
                     {
                         "type": "string",
@@ -1341,23 +1275,20 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "list of users with config to sync in db",
+                        "description": "allowlist data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ocserv_user.SyncOcpasswdRequest"
+                            "$ref": "#/definitions/ip_ban.CreateAllowlistData"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.IPAllowlist"
                         }
                     },
                     "400": {
@@ -1371,13 +1302,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
                     }
                 }
             }
         },
-        "/ocserv/users/{uid}": {
-            "get": {
-                "description": "Ocserv user detail",
+        "/ip_bans/allowlist/{id}": {
+            "delete": {
+                "description": "Remove from IP allowlist",
                 "consumes": [
                     "application/json"
                 ],
@@ -1385,31 +1322,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "IP Bans"
                 ],
-                "summary": "Ocserv user detail",
+                "summary": "Remove from IP allowlist",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Ocserv User UID",
-                        "name": "uid",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Allowlist entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.OcservUser"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1422,11 +1356,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Ocserv User delete",
+            }
+        },
+        "/ip_bans/history": {
+            "get": {
+                "description": "Addresses seen in the ocserv ban list with their first and last sight, last and highest score",
                 "consumes": [
                     "application/json"
                 ],
@@ -1434,28 +1382,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "IP Bans"
                 ],
-                "summary": "Ocserv User delete",
+                "summary": "IP ban history",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bearer TOKEN",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Ocserv User UID",
-                        "name": "uid",
-                        "in": "path",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ip_ban.IPBanHistoryResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1470,9 +1445,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Ocserv User update",
+            }
+        },
+        "/ip_bans/{id}": {
+            "delete": {
+                "description": "Remove manual IP ban",
                 "consumes": [
                     "application/json"
                 ],
@@ -1480,9 +1457,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "IP Bans"
                 ],
-                "summary": "Ocserv User update",
+                "summary": "Remove manual IP ban",
                 "parameters": [
                     {
                         "type": "string",
@@ -1492,27 +1469,71 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Ocserv User UID",
-                        "name": "uid",
+                        "type": "integer",
+                        "description": "IP ban ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     },
-                    {
-                        "description": "ocserv user update data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/ocserv_user.UpdateOcservUserData"
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "description": "List of the jobs of the user expiry service with their schedules, next and latest runs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "List of scheduled jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.OcservUser"
+                            "$ref": "#/definitions/job.JobsResponse"
                         }
                     },
                     "400": {
@@ -1530,9 +1551,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ocserv/users/{uid}/activate": {
-            "post": {
-                "description": "Restore and activate expired Ocserv User accounts",
+        "/jobs/{name}": {
+            "get": {
+                "description": "Scheduled job detail",
                 "consumes": [
                     "application/json"
                 ],
@@ -1540,9 +1561,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "Jobs"
                 ],
-                "summary": "Restore and activate expired Ocserv User accounts",
+                "summary": "Scheduled job detail",
                 "parameters": [
                     {
                         "type": "string",
@@ -1553,24 +1574,18 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Ocserv User UID",
-                        "name": "uid",
+                        "description": "Job name",
+                        "name": "name",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "list of ocserv users and expire time",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ocserv_user.ActivateUserData"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/repository.JobStatus"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1583,13 +1598,17 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/ocserv/users/{uid}/lock": {
-            "post": {
-                "description": "Ocserv User locking",
+            },
+            "patch": {
+                "description": "Change the schedule of a job or pause it, paused jobs only run when triggered",
                 "consumes": [
                     "application/json"
                 ],
@@ -1597,9 +1616,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "Jobs"
                 ],
-                "summary": "Ocserv User locking",
+                "summary": "Scheduled job update",
                 "parameters": [
                     {
                         "type": "string",
@@ -1610,15 +1629,27 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Ocserv User UID",
-                        "name": "uid",
+                        "description": "Job name",
+                        "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "job data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/job.UpdateJobData"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/repository.JobStatus"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1631,13 +1662,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/ocserv/users/{uid}/session_logs": {
+        "/jobs/{name}/runs": {
             "get": {
-                "description": "Ocserv User session logs",
+                "description": "Runs of a job with their trigger, duration, affected users and errors, latest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -1645,9 +1688,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "Jobs"
                 ],
-                "summary": "Ocserv User session logs",
+                "summary": "Run history of a job",
                 "parameters": [
                     {
                         "type": "string",
@@ -1656,6 +1699,13 @@ const docTemplate = `{
                         "in": "header",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Job name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "minimum": 1,
                         "type": "integer",
@@ -1686,32 +1736,13 @@ const docTemplate = `{
                         "description": "Sort order, either ASC or DESC",
                         "name": "sort",
                         "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Ocserv User UID",
-                        "name": "uid",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_start",
-                        "name": "date_start",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_end",
-                        "name": "date_end",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/ocserv_user.SessionLogsResponse"
+                            "$ref": "#/definitions/job.JobRunsResponse"
                         }
                     },
                     "400": {
@@ -1725,13 +1756,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/ocserv/users/{uid}/statistics": {
-            "get": {
-                "description": "Ocserv User Statistics",
+        "/jobs/{name}/trigger": {
+            "post": {
+                "description": "Ask the user expiry service to run a job now, paused jobs included. The run shows up in the job history within seconds.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1739,9 +1776,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "Jobs"
                 ],
-                "summary": "Ocserv User Statistics",
+                "summary": "Run a job now",
                 "parameters": [
                     {
                         "type": "string",
@@ -1752,29 +1789,17 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Ocserv User UID",
-                        "name": "uid",
+                        "description": "Job name",
+                        "name": "name",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_start",
-                        "name": "date_start",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_end",
-                        "name": "date_end",
-                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/ocserv_user.StatisticsResponse"
+                            "$ref": "#/definitions/repository.JobStatus"
                         }
                     },
                     "400": {
@@ -1788,13 +1813,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/ocserv/users/{uid}/unlock": {
-            "post": {
-                "description": "Ocserv User unlocking",
+        "/occtl/bans": {
+            "get": {
+                "description": "List banned IPs with their scores (occtl show ip bans points)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1802,9 +1839,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "OCCTL"
                 ],
-                "summary": "Ocserv User unlocking",
+                "summary": "List banned IPs",
                 "parameters": [
                     {
                         "type": "string",
@@ -1812,18 +1849,17 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Ocserv User UID",
-                        "name": "uid",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IPBanPoints"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1840,9 +1876,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ocserv/users/{username}/disconnect": {
-            "post": {
-                "description": "Disconnect Ocserv User",
+        "/occtl/bans/{ip}": {
+            "delete": {
+                "description": "Remove the ban of an IP (occtl unban ip)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1850,9 +1886,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ocserv(Users)"
+                    "OCCTL"
                 ],
-                "summary": "Disconnect Ocserv User",
+                "summary": "Unban IP",
                 "parameters": [
                     {
                         "type": "string",
@@ -1863,15 +1899,18 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Ocserv User username",
-                        "name": "username",
+                        "description": "IP address",
+                        "name": "ip",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/occtl.CommandResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1884,13 +1923,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
                     }
                 }
             }
         },
-        "/reports/session_logs": {
+        "/occtl/commands": {
             "get": {
-                "description": "Ocserv session logs",
+                "description": "Occtl Commands by numeric action ID. Kept for compatibility, use the typed occtl endpoints instead.\nActions changing the server state (4, 9, 13) require admin permission",
                 "consumes": [
                     "application/json"
                 ],
@@ -1898,9 +1943,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Report"
+                    "OCCTL"
                 ],
-                "summary": "Ocserv session logs",
+                "summary": "Occtl Commands",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -1910,46 +1956,16 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Page number, starting from 1",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 100,
-                        "minimum": 1,
                         "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to order by",
-                        "name": "order",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "ASC",
-                            "DESC"
-                        ],
-                        "type": "string",
-                        "description": "Sort order, either ASC or DESC",
-                        "name": "sort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_start",
-                        "name": "date_start",
-                        "in": "query"
+                        "description": "Command Action ID (1 to 15)",
+                        "name": "action",
+                        "in": "query",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "date_end",
-                        "name": "date_end",
+                        "description": "Optional parameter depending on command",
+                        "name": "value",
                         "in": "query"
                     }
                 ],
@@ -1957,7 +1973,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/report.SessionLogsResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -1971,13 +1987,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
                     }
                 }
             }
         },
-        "/reports/statistics": {
+        "/occtl/events": {
             "get": {
-                "description": "Ocserv Users Statistics",
+                "description": "Snapshot of the ocserv events (occtl show events)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1985,9 +2007,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Report"
+                    "OCCTL"
                 ],
-                "summary": "Ocserv Users Statistics",
+                "summary": "Occtl events",
                 "parameters": [
                     {
                         "type": "string",
@@ -1995,36 +2017,13 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_start",
-                        "name": "date_start",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_end",
-                        "name": "date_end",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.DailyTraffic"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/request.ErrorResponse"
+                            "$ref": "#/definitions/occtl.CommandResponse"
                         }
                     },
                     "401": {
@@ -2036,19 +2035,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports/total-bandwidth": {
+        "/occtl/events/stream": {
             "get": {
-                "description": "Ocserv Users TotalBandwidth calculating",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Server-Sent Events stream of the ocserv session events (occtl show events), one \"connect\" or \"disconnect\" event per session change",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Report"
+                    "OCCTL"
                 ],
-                "summary": "Ocserv Users TotalBandwidth calculating",
+                "summary": "Occtl events stream",
                 "parameters": [
                     {
                         "type": "string",
@@ -2056,33 +2052,13 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_start",
-                        "name": "date_start",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "date_end",
-                        "name": "date_end",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/repository.TotalBandwidths"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/request.ErrorResponse"
+                            "$ref": "#/definitions/models.OcctlEvent"
                         }
                     },
                     "401": {
@@ -2094,9 +2070,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports/users": {
+        "/occtl/iroutes": {
             "get": {
-                "description": "Result of all user reports",
+                "description": "List the routes announced by connected users (occtl show iroutes)",
                 "consumes": [
                     "application/json"
                 ],
@@ -2104,9 +2080,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Report"
+                    "OCCTL"
                 ],
-                "summary": "Result of all user reports",
+                "summary": "List iroutes",
                 "parameters": [
                     {
                         "type": "string",
@@ -2120,7 +2096,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/report.OcservUserReportResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IRoute"
+                            }
                         }
                     },
                     "400": {
@@ -2138,9 +2117,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/system": {
-            "get": {
-                "description": "Get panel System Config",
+        "/occtl/reload": {
+            "post": {
+                "description": "Reload the ocserv configuration (occtl reload)",
                 "consumes": [
                     "application/json"
                 ],
@@ -2148,9 +2127,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System"
+                    "OCCTL"
                 ],
-                "summary": "Get panel System Config",
+                "summary": "Reload ocserv",
                 "parameters": [
                     {
                         "type": "string",
@@ -2164,7 +2143,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/system.GetSystemResponse"
+                            "$ref": "#/definitions/occtl.CommandResponse"
                         }
                     },
                     "400": {
@@ -2178,11 +2157,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update panel System Config",
+            }
+        },
+        "/occtl/server_info": {
+            "get": {
+                "description": "Server information",
                 "consumes": [
                     "application/json"
                 ],
@@ -2190,32 +2177,66 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System"
+                    "OCCTL"
                 ],
-                "summary": "Update panel System Config",
-                "parameters": [
-                    {
-                        "description": "update system config data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Server information",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/system.PatchSystemUpdateData"
+                            "$ref": "#/definitions/models.OcservInfo"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/occtl/sessions": {
+            "get": {
+                "description": "List ocserv sessions (occtl show sessions)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OCCTL"
+                ],
+                "summary": "List ocserv sessions",
+                "parameters": [
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "valid"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "sessions state",
+                        "name": "state",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/system.GetSystemResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OcctlSession"
+                            }
                         }
                     },
                     "400": {
@@ -2233,9 +2254,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/system/init": {
+        "/occtl/sessions/{sid}": {
             "get": {
-                "description": "Get panel System init Config",
+                "description": "Get ocserv session by session ID (occtl show session)",
                 "consumes": [
                     "application/json"
                 ],
@@ -2243,14 +2264,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System"
+                    "OCCTL"
+                ],
+                "summary": "Get ocserv session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get panel System init Config",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/system.GetSystemInitResponse"
+                            "$ref": "#/definitions/models.OcctlSession"
                         }
                     },
                     "400": {
@@ -2258,13 +2295,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/request.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/system/setup": {
-            "post": {
-                "description": "Setup user and system config",
+        "/ocserv/groups": {
+            "get": {
+                "description": "List of Ocserv groups",
                 "consumes": [
                     "application/json"
                 ],
@@ -2272,49 +2321,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System"
+                    "Ocserv(Groups)"
                 ],
-                "summary": "Setup user and system config",
-                "parameters": [
-                    {
-                        "description": "system setup data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/system.SetupSystem"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/system.SetupSystemResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/request.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/system/users": {
-            "get": {
-                "description": "List of Admin or simple users",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "System(Users)"
-                ],
-                "summary": "List of Admin or simple users",
+                "summary": "List of Ocserv groups",
                 "parameters": [
                     {
                         "minimum": 1,
@@ -2359,7 +2368,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/system.UsersResponse"
+                            "$ref": "#/definitions/ocserv_group.OcservGroupsResponse"
                         }
                     },
                     "400": {
@@ -2373,17 +2382,11 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/middlewares.PermissionDenied"
-                        }
                     }
                 }
             },
             "post": {
-                "description": "Create user Admin or simple",
+                "description": "Ocserv Group creation",
                 "consumes": [
                     "application/json"
                 ],
@@ -2391,9 +2394,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System(Users)"
+                    "Ocserv(Groups)"
                 ],
-                "summary": "Create user",
+                "summary": "Ocserv Group creation",
                 "parameters": [
                     {
                         "type": "string",
@@ -2403,12 +2406,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "create user data",
+                        "description": "ocserv group create data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/system.CreateUserData"
+                            "$ref": "#/definitions/ocserv_group.CreateOcservGroupData"
                         }
                     }
                 ],
@@ -2416,7 +2419,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.OcservGroup"
                         }
                     },
                     "400": {
@@ -2430,19 +2433,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/middlewares.PermissionDenied"
-                        }
                     }
                 }
             }
         },
-        "/system/users/login": {
-            "post": {
-                "description": "Admin users login with Google captcha(captcha site key required in get config api)",
+        "/ocserv/groups/defaults": {
+            "get": {
+                "description": "Ocserv Defaults Group config",
                 "consumes": [
                     "application/json"
                 ],
@@ -2450,25 +2447,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System(Users)"
+                    "Ocserv(Groups)"
                 ],
-                "summary": "Admin users login",
+                "summary": "Ocserv Defaults Group config",
                 "parameters": [
                     {
-                        "description": "login data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/system.LoginData"
-                        }
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/system.UserLoginResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -2476,13 +2472,17 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/request.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
                     }
                 }
-            }
-        },
-        "/system/users/lookup": {
-            "get": {
-                "description": "List of Users Lookup",
+            },
+            "patch": {
+                "description": "Update Ocserv Defaults Group",
                 "consumes": [
                     "application/json"
                 ],
@@ -2490,9 +2490,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System(Users)"
+                    "Ocserv(Groups)"
                 ],
-                "summary": "List of Users Lookup",
+                "summary": "Update Ocserv Defaults Group",
                 "parameters": [
                     {
                         "type": "string",
@@ -2500,17 +2500,20 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "ocserv group default data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ocserv_group.UpdateOcservGroupData"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.UsersLookup"
-                            }
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -2523,19 +2526,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/middlewares.PermissionDenied"
-                        }
                     }
                 }
             }
         },
-        "/system/users/password": {
-            "post": {
-                "description": "Change user password by self",
+        "/ocserv/groups/lookup": {
+            "get": {
+                "description": "List of Ocserv group names",
                 "consumes": [
                     "application/json"
                 ],
@@ -2543,19 +2540,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System(Users)"
+                    "Ocserv(Groups)"
                 ],
-                "summary": "Change user password by self",
+                "summary": "List of Ocserv group names",
                 "parameters": [
-                    {
-                        "description": "user new password",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/system.ChangeUserPasswordBySelf"
-                        }
-                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -2568,7 +2556,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/system.UsersResponse"
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
@@ -2586,9 +2577,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/system/users/profile": {
-            "get": {
-                "description": "Get User Profile",
+        "/ocserv/groups/sync": {
+            "post": {
+                "description": "Ocserv Groups from file",
                 "consumes": [
                     "application/json"
                 ],
@@ -2596,9 +2587,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System(Users)"
+                    "Ocserv(UnsyncedGroup)"
                 ],
-                "summary": "Get User Profile",
+                "summary": "Ocserv Groups from file",
                 "parameters": [
                     {
                         "type": "string",
@@ -2606,13 +2597,25 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "list of groups with config to sync in db",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ocserv_group.SyncGroupRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
@@ -2630,9 +2633,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/system/users/{uid}": {
-            "delete": {
-                "description": "Delete simple user",
+        "/ocserv/groups/unsynced": {
+            "get": {
+                "description": "list of Unsynced Groups",
                 "consumes": [
                     "application/json"
                 ],
@@ -2640,17 +2643,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System(Users)"
+                    "Ocserv(UnsyncedGroup)"
                 ],
-                "summary": "Delete simple user",
+                "summary": "list of Unsynced Groups",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User UID",
-                        "name": "uid",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -2660,8 +2656,14 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.UnsyncedGroup"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -2674,19 +2676,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/middlewares.PermissionDenied"
-                        }
                     }
                 }
             }
         },
-        "/system/users/{uid}/password": {
-            "post": {
-                "description": "Change user password by admin",
+        "/ocserv/groups/{id}": {
+            "get": {
+                "description": "Ocserv group detail",
                 "consumes": [
                     "application/json"
                 ],
@@ -2694,26 +2690,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System(Users)"
+                    "Ocserv(Groups)"
                 ],
-                "summary": "Change user password by admin",
+                "summary": "Ocserv group detail",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User UID",
-                        "name": "uid",
+                        "type": "integer",
+                        "description": "Ocserv Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "description": "user new password",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/system.ChangeUserPassword"
-                        }
-                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -2726,7 +2713,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/system.UsersResponse"
+                            "$ref": "#/definitions/models.OcservGroup"
                         }
                     },
                     "400": {
@@ -2740,19 +2727,11 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/middlewares.PermissionDenied"
-                        }
                     }
                 }
-            }
-        },
-        "/systemd/disable": {
-            "post": {
-                "description": "Disable ocserv systemd service (remove from auto start)",
+            },
+            "delete": {
+                "description": "Ocserv Group delete",
                 "consumes": [
                     "application/json"
                 ],
@@ -2760,9 +2739,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Systemd"
+                    "Ocserv(Groups)"
                 ],
-                "summary": "Disable ocserv service",
+                "summary": "Ocserv Group delete",
                 "parameters": [
                     {
                         "type": "string",
@@ -2770,14 +2749,18 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/systemd.ActionResponse"
-                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Ocserv Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -2790,19 +2773,71 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Ocserv Group update",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Groups)"
+                ],
+                "summary": "Ocserv Group update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     },
-                    "429": {
-                        "description": "Too Many Requests",
+                    {
+                        "type": "integer",
+                        "description": "Ocserv Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ocserv group create data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/middlewares.TooManyRequests"
+                            "$ref": "#/definitions/ocserv_group.UpdateOcservGroupData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.OcservGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
                         }
                     }
                 }
             }
         },
-        "/systemd/enable": {
-            "post": {
-                "description": "Enable ocserv systemd service (auto start on boot)",
+        "/ocserv/users": {
+            "get": {
+                "description": "List of Ocserv Users",
                 "consumes": [
                     "application/json"
                 ],
@@ -2810,10 +2845,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Systemd"
+                    "Ocserv(Users)"
                 ],
-                "summary": "Enable ocserv service",
+                "summary": "List of Ocserv Users",
                 "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "minLength": 2,
+                        "type": "string",
+                        "description": "ocserv username q search",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "online",
+                            "active",
+                            "deactivated",
+                            "locked"
+                        ],
+                        "type": "string",
+                        "description": "filter ocserv user by statues",
+                        "name": "filter",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -2826,7 +2911,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/systemd.ActionResponse"
+                            "$ref": "#/definitions/ocserv_user.OcservUsersResponse"
                         }
                     },
                     "400": {
@@ -2840,19 +2925,11 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/middlewares.TooManyRequests"
-                        }
                     }
                 }
-            }
-        },
-        "/systemd/restart": {
+            },
             "post": {
-                "description": "Restart ocserv systemd service",
+                "description": "Ocserv User creation",
                 "consumes": [
                     "application/json"
                 ],
@@ -2860,9 +2937,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Systemd"
+                    "Ocserv(Users)"
                 ],
-                "summary": "Restart ocserv service",
+                "summary": "Ocserv User creation",
                 "parameters": [
                     {
                         "type": "string",
@@ -2870,13 +2947,22 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "ocserv user create data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ocserv_user.CreateOcservUserData"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/systemd.ActionResponse"
+                            "$ref": "#/definitions/models.OcservUser"
                         }
                     },
                     "400": {
@@ -2890,19 +2976,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/middlewares.TooManyRequests"
-                        }
                     }
                 }
             }
         },
-        "/systemd/status": {
+        "/ocserv/users/ocpasswd": {
             "get": {
-                "description": "Ocserv systemctl status",
+                "description": "Ocserv Users from ocpasswd file",
                 "consumes": [
                     "application/json"
                 ],
@@ -2910,10 +2990,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Systemd"
+                    "Ocserv(Ocpasswd)"
                 ],
-                "summary": "Ocserv systemctl status",
+                "summary": "Ocserv Users from ocpasswd file",
                 "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Bearer TOKEN",
@@ -2926,7 +3037,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/systemd.OcservSystemdStatus"
+                            "$ref": "#/definitions/ocserv_user.OcservUsersSyncResponse"
                         }
                     },
                     "400": {
@@ -2940,624 +3051,4797 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middlewares.Unauthorized"
                         }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/middlewares.TooManyRequests"
-                        }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "backup.RestoreResponse": {
-            "type": "object",
-            "properties": {
-                "existing": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "inserted": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
                     }
                 }
             }
         },
-        "customer.ModelCustomer": {
-            "type": "object",
-            "required": [
-                "deactivated_at",
-                "expire_at",
-                "is_locked",
-                "owner",
-                "rx",
-                "traffic_size",
-                "traffic_type",
-                "tx",
-                "username"
-            ],
-            "properties": {
-                "deactivated_at": {
-                    "type": "string"
+        "/ocserv/users/ocpasswd/sync": {
+            "post": {
+                "description": "Ocserv Users from ocpasswd file to db",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Ocpasswd)"
+                ],
+                "summary": "Ocserv Users from ocpasswd file to db",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "list of users with config to sync in db",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ocserv_user.SyncOcpasswdRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}": {
+            "get": {
+                "description": "Ocserv user detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv user detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OcservUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Ocserv User delete",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv User delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Ocserv User update",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv User update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ocserv user update data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ocserv_user.UpdateOcservUserData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.OcservUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/activate": {
+            "post": {
+                "description": "Restore and activate expired Ocserv User accounts, the renewal starts a new billing cycle from today",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Restore and activate expired Ocserv User accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "list of ocserv users and expire time",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ocserv_user.ActivateUserData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/devices": {
+            "get": {
+                "description": "Clients the user connected with, identified by user agent, most recently seen first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv User devices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OcservUserDevice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/devices/{id}": {
+            "delete": {
+                "description": "Forget the device, freeing its slot of the user device limit. It is tracked again as a new device on its next connection.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Delete Ocserv User device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/devices/{id}/revoke": {
+            "post": {
+                "description": "Block the device and disconnect its sessions, it is disconnected again whenever it connects",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Revoke Ocserv User device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OcservUserDevice"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/devices/{id}/unblock": {
+            "post": {
+                "description": "Let a revoked device, or one blocked beyond the device limit, connect again. Refused while the user has max devices active ones.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Unblock Ocserv User device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OcservUserDevice"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/lock": {
+            "post": {
+                "description": "Ocserv User locking",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv User locking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/profile": {
+            "get": {
+                "description": "Client connection profile as AnyConnect XML, OpenConnect command line or deep link QR code",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv User connection profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "anyconnect",
+                            "openconnect",
+                            "qr"
+                        ],
+                        "type": "string",
+                        "description": "Profile format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.Bundle"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/session_logs": {
+            "get": {
+                "description": "Ocserv User session logs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv User session logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ocserv_user.SessionLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/statistics": {
+            "get": {
+                "description": "Ocserv User Statistics",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv User Statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ocserv_user.StatisticsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{uid}/unlock": {
+            "post": {
+                "description": "Ocserv User unlocking",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Ocserv User unlocking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/ocserv/users/{username}/disconnect": {
+            "post": {
+                "description": "Disconnect Ocserv User",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ocserv(Users)"
+                ],
+                "summary": "Disconnect Ocserv User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ocserv User username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/alerts": {
+            "get": {
+                "description": "Concurrent sessions, impossible travels and unusual user agents detected by the stats service",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Account sharing alerts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ocserv username",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "concurrent_sessions",
+                            "impossible_travel",
+                            "user_agents"
+                        ],
+                        "type": "string",
+                        "description": "Filter by alert kind",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/report.AlertsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/alerts/{id}/acknowledge": {
+            "post": {
+                "description": "Mark an alert as reviewed. Users locked by the alert stay locked until unlocked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Acknowledge account sharing alert",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Alert ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OcservUserAlert"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/session_logs": {
+            "get": {
+                "description": "Ocserv session logs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Ocserv session logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/report.SessionLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/sessions/asns": {
+            "get": {
+                "description": "Number of sessions and distinct users per client autonomous system, from the GeoIP enriched session logs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Sessions by ASN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/repository.ASNSessions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/sessions/countries": {
+            "get": {
+                "description": "Number of sessions and distinct users per client country, from the GeoIP enriched session logs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Sessions by country",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/repository.CountrySessions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/sessions/new_countries": {
+            "get": {
+                "description": "Sessions started from a country the user was not seen in before, a hint of shared or stolen accounts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "New country sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/report.NewCountrySessionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/statistics": {
+            "get": {
+                "description": "Ocserv Users Statistics",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Ocserv Users Statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DailyTraffic"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/total-bandwidth": {
+            "get": {
+                "description": "Ocserv Users TotalBandwidth calculating",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Ocserv Users TotalBandwidth calculating",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_start",
+                        "name": "date_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "date_end",
+                        "name": "date_end",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/repository.TotalBandwidths"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/users": {
+            "get": {
+                "description": "Result of all user reports",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Result of all user reports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/report.OcservUserReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/servers": {
+            "get": {
+                "description": "List of the ocserv nodes managed by the dashboard through their webhook service or node agent",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Servers"
+                ],
+                "summary": "List of ocserv servers",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.ServersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add an ocserv node running the webhook service or the node agent. Users and groups assigned to it are deployed to it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Servers"
+                ],
+                "summary": "Ocserv server creation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "server data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CreateServerData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Server"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            }
+        },
+        "/servers/{id}": {
+            "get": {
+                "description": "Ocserv server detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Servers"
+                ],
+                "summary": "Ocserv server detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Server ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Server"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove an ocserv server. Servers with users or groups cannot be removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Servers"
+                ],
+                "summary": "Ocserv server delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Server ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Ocserv server update, an empty secret keeps the current one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Servers"
+                ],
+                "summary": "Ocserv server update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Server ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "server data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateServerData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Server"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/system": {
+            "get": {
+                "description": "Get panel System Config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Get panel System Config",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.GetSystemResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update panel System Config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Update panel System Config",
+                "parameters": [
+                    {
+                        "description": "update system config data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.PatchSystemUpdateData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.GetSystemResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/init": {
+            "get": {
+                "description": "Get panel System init Config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Get panel System init Config",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.GetSystemInitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/logging": {
+            "get": {
+                "description": "Format, minimum level and counters of the api logger",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Logging settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.LoggingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change the minimum level of the api logger until the next restart, LOG_LEVEL sets it at startup",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Update logging level",
+                "parameters": [
+                    {
+                        "description": "logging level data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.PatchLoggingData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.LoggingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/setup": {
+            "post": {
+                "description": "Setup user and system config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Setup user and system config",
+                "parameters": [
+                    {
+                        "description": "system setup data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.SetupSystem"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/system.SetupSystemResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users": {
+            "get": {
+                "description": "List of Admin or simple users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "List of Admin or simple users",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ASC",
+                            "DESC"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, either ASC or DESC",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.UsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create user Admin or simple",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "create user data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.CreateUserData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/2fa/disable": {
+            "post": {
+                "description": "Disable 2FA of the current user, or cancel a pending enrollment. The code is required only when 2FA is enabled. Not allowed when the policy requires 2FA for the user role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Disable 2FA",
+                "parameters": [
+                    {
+                        "description": "password and totp code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.TwoFactorDisableData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/2fa/enroll": {
+            "post": {
+                "description": "Generate a new TOTP secret and QR code. 2FA is enabled after verifying a code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Start 2FA enrollment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.TwoFactorEnrollResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/2fa/recovery_codes": {
+            "post": {
+                "description": "Replace all recovery codes of the current user. Previous codes stop working",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Regenerate 2FA recovery codes",
+                "parameters": [
+                    {
+                        "description": "totp code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.TwoFactorCodeData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/2fa/verify": {
+            "post": {
+                "description": "Enable 2FA with a code of the enrolled secret. Recovery codes are returned once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Verify 2FA enrollment",
+                "parameters": [
+                    {
+                        "description": "totp code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.TwoFactorCodeData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/login": {
+            "post": {
+                "description": "Admin users login with Google captcha(captcha site key required in get config api)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Admin users login",
+                "parameters": [
+                    {
+                        "description": "login data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.LoginData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/login/2fa": {
+            "post": {
+                "description": "Complete the login with the challenge token and a TOTP code or a recovery code.\nFor users who must enroll, the first valid code enables 2FA and recovery codes are returned once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Admin users login second step",
+                "parameters": [
+                    {
+                        "description": "2fa login data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.TwoFactorLoginData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.TooManyRequests"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/login/2fa/enroll": {
+            "post": {
+                "description": "Start 2FA enrollment with the challenge token of a user required to use 2FA by policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Admin users login 2fa enrollment",
+                "parameters": [
+                    {
+                        "description": "2fa challenge token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.TwoFactorChallengeData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.TwoFactorEnrollResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.TooManyRequests"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/logout": {
+            "post": {
+                "description": "Revoke the current login session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/lookup": {
+            "get": {
+                "description": "List of Users Lookup",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "List of Users Lookup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UsersLookup"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/password": {
+            "post": {
+                "description": "Change user password by self",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Change user password by self",
+                "parameters": [
+                    {
+                        "description": "user new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.ChangeUserPasswordBySelf"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.UsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/profile": {
+            "get": {
+                "description": "Get User Profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Get User Profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.ProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/sessions": {
+            "get": {
+                "description": "List of active login sessions (tokens) of the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "List of active sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/system.UserSession"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revoke all login sessions of the current user except the current one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Revoke other sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/sessions/{uid}": {
+            "delete": {
+                "description": "Revoke one login session of the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Revoke session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/{uid}": {
+            "delete": {
+                "description": "Delete simple user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Delete simple user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/{uid}/2fa/reset": {
+            "post": {
+                "description": "Disable 2FA of a user who lost the authenticator and recovery codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Reset user 2FA by admin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/{uid}/password": {
+            "post": {
+                "description": "Change user password by admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Change user password by admin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "user new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.ChangeUserPassword"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.UsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/users/{uid}/quota": {
+            "get": {
+                "description": "Get simple user quota and current usage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Get simple user quota",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.UserQuotaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update simple user quota. Zero values mean unlimited and empty allowed groups allow all groups",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System(Users)"
+                ],
+                "summary": "Update simple user quota",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "user quota data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/system.PatchUserQuotaData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/system.UserQuotaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.PermissionDenied"
+                        }
+                    }
+                }
+            }
+        },
+        "/systemd/disable": {
+            "post": {
+                "description": "Disable ocserv systemd service (remove from auto start)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Systemd"
+                ],
+                "summary": "Disable ocserv service",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/systemd.ActionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.TooManyRequests"
+                        }
+                    }
+                }
+            }
+        },
+        "/systemd/enable": {
+            "post": {
+                "description": "Enable ocserv systemd service (auto start on boot)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Systemd"
+                ],
+                "summary": "Enable ocserv service",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/systemd.ActionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.TooManyRequests"
+                        }
+                    }
+                }
+            }
+        },
+        "/systemd/restart": {
+            "post": {
+                "description": "Restart ocserv systemd service",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Systemd"
+                ],
+                "summary": "Restart ocserv service",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/systemd.ActionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.TooManyRequests"
+                        }
+                    }
+                }
+            }
+        },
+        "/systemd/status": {
+            "get": {
+                "description": "Ocserv systemctl status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Systemd"
+                ],
+                "summary": "Ocserv systemctl status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer TOKEN",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/systemd.OcservSystemdStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/request.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.Unauthorized"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/middlewares.TooManyRequests"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "backup.RestoreResponse": {
+            "type": "object",
+            "properties": {
+                "existing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "inserted": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "customer.ChangePasswordData": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                },
+                "old_password": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                }
+            }
+        },
+        "customer.LoginData": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                }
+            }
+        },
+        "customer.LoginResponse": {
+            "type": "object",
+            "required": [
+                "expire_at",
+                "ocserv_user",
+                "token"
+            ],
+            "properties": {
+                "expire_at": {
+                    "type": "string"
+                },
+                "ocserv_user": {
+                    "$ref": "#/definitions/customer.ModelCustomer"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "customer.ModelCustomer": {
+            "type": "object",
+            "required": [
+                "cycle_anchor",
+                "deactivated_at",
+                "expire_at",
+                "is_locked",
+                "owner",
+                "rx",
+                "traffic_size",
+                "traffic_type",
+                "tx",
+                "username"
+            ],
+            "properties": {
+                "cycle_anchor": {
+                    "description": "monthly quotas reset on its day of month",
+                    "type": "string"
+                },
+                "deactivated_at": {
+                    "type": "string"
+                },
+                "expire_at": {
+                    "type": "string"
+                },
+                "is_locked": {
+                    "type": "boolean"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "rx": {
+                    "description": "Receive in bytes",
+                    "type": "integer"
+                },
+                "traffic_size": {
+                    "description": "in GiB  \u003e\u003e x * 1024 ** 3",
+                    "type": "integer"
+                },
+                "traffic_type": {
+                    "type": "string",
+                    "enum": [
+                        "Free",
+                        "MonthlyTransmit",
+                        "MonthlyReceive",
+                        "TotallyTransmit",
+                        "TotallyReceive"
+                    ]
+                },
+                "tx": {
+                    "description": "Transmit in bytes",
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "customer.SessionLogsResponse": {
+            "type": "object",
+            "required": [
+                "meta"
+            ],
+            "properties": {
+                "meta": {
+                    "$ref": "#/definitions/request.Meta"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OcservUserSessionLog"
+                    }
+                }
+            }
+        },
+        "customer.StatisticsResponse": {
+            "type": "object",
+            "required": [
+                "statistics"
+            ],
+            "properties": {
+                "statistics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DailyTraffic"
+                    }
+                }
+            }
+        },
+        "customer.SummaryData": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                }
+            }
+        },
+        "customer.SummaryResponse": {
+            "type": "object",
+            "required": [
+                "ocserv_user",
+                "usage"
+            ],
+            "properties": {
+                "ocserv_user": {
+                    "$ref": "#/definitions/customer.ModelCustomer"
+                },
+                "usage": {
+                    "$ref": "#/definitions/customer.UsageResponse"
+                }
+            }
+        },
+        "customer.UsageResponse": {
+            "type": "object",
+            "required": [
+                "bandwidths",
+                "date_end",
+                "date_start"
+            ],
+            "properties": {
+                "bandwidths": {
+                    "$ref": "#/definitions/repository.TotalBandwidths"
+                },
+                "date_end": {
+                    "type": "string"
+                },
+                "date_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_mmtaee_ocserv-dashboard_api_internal_models.IPBan": {
+            "type": "object",
+            "required": [
+                "country",
+                "created_at",
+                "created_by",
+                "id",
+                "ip",
+                "reason"
+            ],
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.UnsyncedGroup": {
+            "type": "object",
+            "required": [
+                "config",
+                "name"
+            ],
+            "properties": {
+                "config": {
+                    "$ref": "#/definitions/models.OcservGroupConfig"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "home.CPU": {
+            "type": "object",
+            "properties": {
+                "avg_percent": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "used_units": {
+                    "type": "number"
+                }
+            }
+        },
+        "home.CurrentStats": {
+            "type": "object",
+            "properties": {
+                "Authentication failures": {
+                    "type": "integer"
+                },
+                "Average auth time": {
+                    "type": "string"
+                },
+                "Average session time": {
+                    "type": "string"
+                },
+                "Closed due to error sessions": {
+                    "type": "integer"
+                },
+                "Last stats reset": {
+                    "type": "string"
+                },
+                "Max auth time": {
+                    "type": "string"
+                },
+                "Max session time": {
+                    "type": "string"
+                },
+                "RX": {
+                    "type": "string"
+                },
+                "Sessions handled": {
+                    "type": "integer"
+                },
+                "TX": {
+                    "type": "string"
+                },
+                "Timed out (idle) sessions": {
+                    "type": "integer"
+                },
+                "Timed out sessions": {
+                    "type": "integer"
+                },
+                "_Last stats reset": {
+                    "type": "string"
+                },
+                "raw_avg_auth_time": {
+                    "type": "integer"
+                },
+                "raw_avg_session_time": {
+                    "type": "integer"
+                },
+                "raw_last_stats_reset": {
+                    "type": "integer"
+                },
+                "raw_max_auth_time": {
+                    "type": "integer"
+                },
+                "raw_max_session_time": {
+                    "type": "integer"
+                },
+                "raw_rx": {
+                    "description": "raw fields if you want",
+                    "type": "integer"
+                },
+                "raw_tx": {
+                    "type": "integer"
+                }
+            }
+        },
+        "home.Disk": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "number"
+                },
+                "used": {
+                    "type": "number"
+                },
+                "used_percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "home.DockerService": {
+            "type": "object",
+            "required": [
+                "log_stream",
+                "ocserv",
+                "postgres",
+                "user_expiry",
+                "web"
+            ],
+            "properties": {
+                "log_stream": {
+                    "$ref": "#/definitions/home.DockerStats"
+                },
+                "ocserv": {
+                    "$ref": "#/definitions/home.DockerStats"
+                },
+                "postgres": {
+                    "$ref": "#/definitions/home.DockerStats"
+                },
+                "user_expiry": {
+                    "$ref": "#/definitions/home.DockerStats"
+                },
+                "web": {
+                    "$ref": "#/definitions/home.DockerStats"
+                }
+            }
+        },
+        "home.DockerStats": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "cpu": {
+                    "$ref": "#/definitions/home.CPU"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ram": {
+                    "$ref": "#/definitions/home.RAM"
+                }
+            }
+        },
+        "home.GeneralInfo": {
+            "type": "object",
+            "properties": {
+                "Active sessions": {
+                    "type": "integer"
+                },
+                "IPs in ban list": {
+                    "type": "integer"
+                },
+                "Median latency": {
+                    "type": "string"
+                },
+                "STDEV latency": {
+                    "type": "string"
+                },
+                "Sec-mod PID": {
+                    "type": "integer"
+                },
+                "Sec-mod instance count": {
+                    "type": "integer"
+                },
+                "Server PID": {
+                    "type": "integer"
+                },
+                "Status": {
+                    "type": "string"
+                },
+                "Total authentication failures": {
+                    "type": "integer"
+                },
+                "Total sessions": {
+                    "type": "integer"
+                },
+                "Up since": {
+                    "type": "string"
+                },
+                "_Up since": {
+                    "type": "string"
+                },
+                "raw_median_latency": {
+                    "description": "raw fields if you want",
+                    "type": "integer"
+                },
+                "raw_stdev_latency": {
+                    "type": "integer"
+                },
+                "raw_up_since": {
+                    "type": "integer"
+                },
+                "uptime": {
+                    "type": "integer"
+                }
+            }
+        },
+        "home.GetHomeResponse": {
+            "type": "object",
+            "properties": {
+                "ip_bans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.IPBanPoints"
+                    }
+                },
+                "statistics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DailyTraffic"
+                    }
+                },
+                "top_bandwidth_user": {
+                    "$ref": "#/definitions/repository.TopBandwidthUsers"
                 },
-                "expire_at": {
-                    "type": "string"
+                "total_bandwidth": {
+                    "$ref": "#/definitions/repository.TotalBandwidths"
                 },
-                "is_locked": {
-                    "type": "boolean"
+                "users": {
+                    "$ref": "#/definitions/home.GetHomeUser"
+                }
+            }
+        },
+        "home.GetHomeUser": {
+            "type": "object",
+            "properties": {
+                "online_users_session": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OnlineUserSession"
+                    }
                 },
-                "owner": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "home.MetricsResponse": {
+            "type": "object",
+            "required": [
+                "interval",
+                "range"
+            ],
+            "properties": {
+                "interval": {
+                    "type": "integer"
                 },
-                "rx": {
-                    "description": "Receive in bytes",
+                "range": {
+                    "type": "string",
+                    "example": "hour"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ServerMetric"
+                    }
+                }
+            }
+        },
+        "home.OcservStatusResponse": {
+            "type": "object",
+            "properties": {
+                "current_stats": {
+                    "$ref": "#/definitions/home.CurrentStats"
+                },
+                "general_info": {
+                    "$ref": "#/definitions/home.GeneralInfo"
+                }
+            }
+        },
+        "home.RAM": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "number"
+                },
+                "used": {
+                    "type": "number"
+                },
+                "used_percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "home.ServerStatusResponse": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "$ref": "#/definitions/home.CPU"
+                },
+                "disk": {
+                    "$ref": "#/definitions/home.Disk"
+                },
+                "ram": {
+                    "$ref": "#/definitions/home.RAM"
+                },
+                "swap": {
+                    "$ref": "#/definitions/home.Swap"
+                }
+            }
+        },
+        "home.ServersHealthResponse": {
+            "type": "object",
+            "required": [
+                "active_sessions",
+                "healthy",
+                "rx",
+                "servers",
+                "tx"
+            ],
+            "properties": {
+                "active_sessions": {
                     "type": "integer"
                 },
-                "traffic_size": {
-                    "description": "in GiB  \u003e\u003e x * 1024 ** 3",
+                "healthy": {
                     "type": "integer"
                 },
-                "traffic_type": {
-                    "type": "string",
-                    "enum": [
-                        "Free",
-                        "MonthlyTransmit",
-                        "MonthlyReceive",
-                        "TotallyTransmit",
-                        "TotallyReceive"
-                    ]
+                "rx": {
+                    "description": "bytes",
+                    "type": "integer"
+                },
+                "servers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.ServerHealth"
+                    }
                 },
                 "tx": {
-                    "description": "Transmit in bytes",
+                    "description": "bytes",
                     "type": "integer"
+                }
+            }
+        },
+        "home.Swap": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "number"
                 },
-                "username": {
+                "used": {
+                    "type": "number"
+                },
+                "used_percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "ip_ban.CreateAllowlistData": {
+            "type": "object",
+            "required": [
+                "cidr"
+            ],
+            "properties": {
+                "cidr": {
                     "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 512
                 }
             }
         },
-        "customer.SummaryData": {
+        "ip_ban.CreateIPBanData": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "ip"
             ],
             "properties": {
-                "password": {
+                "duration": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "reason": {
                     "type": "string",
-                    "maxLength": 32,
-                    "minLength": 2
+                    "maxLength": 512
+                }
+            }
+        },
+        "ip_ban.IPBanHistoryResponse": {
+            "type": "object",
+            "required": [
+                "meta"
+            ],
+            "properties": {
+                "meta": {
+                    "$ref": "#/definitions/request.Meta"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.IPBanHistory"
+                    }
+                }
+            }
+        },
+        "ip_ban.IPBansResponse": {
+            "type": "object",
+            "required": [
+                "meta"
+            ],
+            "properties": {
+                "meta": {
+                    "$ref": "#/definitions/request.Meta"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_mmtaee_ocserv-dashboard_api_internal_models.IPBan"
+                    }
+                }
+            }
+        },
+        "job.JobRunsResponse": {
+            "type": "object",
+            "required": [
+                "meta"
+            ],
+            "properties": {
+                "meta": {
+                    "$ref": "#/definitions/request.Meta"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JobRun"
+                    }
+                }
+            }
+        },
+        "job.JobsResponse": {
+            "type": "object",
+            "properties": {
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.JobStatus"
+                    }
+                }
+            }
+        },
+        "job.UpdateJobData": {
+            "type": "object",
+            "properties": {
+                "paused": {
+                    "type": "boolean"
+                },
+                "schedule": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "0 1 0 * * *"
+                }
+            }
+        },
+        "logger.Stats": {
+            "type": "object",
+            "properties": {
+                "dropped": {
+                    "type": "integer"
+                },
+                "written": {
+                    "type": "integer"
+                }
+            }
+        },
+        "middlewares.PermissionDenied": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "middlewares.TooManyRequests": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "middlewares.Unauthorized": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AlertEvidence": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "models.DailyTraffic": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string"
+                },
+                "rx": {
+                    "description": "in GiB",
+                    "type": "number"
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 32,
-                    "minLength": 2
+                "tx": {
+                    "description": "in GiB",
+                    "type": "number"
                 }
             }
         },
-        "customer.SummaryResponse": {
+        "models.IPAllowlist": {
             "type": "object",
             "required": [
-                "ocserv_user",
-                "usage"
+                "cidr",
+                "created_at",
+                "id",
+                "note"
             ],
             "properties": {
-                "ocserv_user": {
-                    "$ref": "#/definitions/customer.ModelCustomer"
+                "cidr": {
+                    "type": "string"
                 },
-                "usage": {
-                    "$ref": "#/definitions/customer.UsageResponse"
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
                 }
             }
         },
-        "customer.UsageResponse": {
+        "models.IPBanHistory": {
             "type": "object",
             "required": [
-                "bandwidths",
-                "date_end",
-                "date_start"
+                "country",
+                "first_seen_at",
+                "id",
+                "ip",
+                "last_seen_at",
+                "max_score",
+                "score",
+                "unbanned"
             ],
             "properties": {
-                "bandwidths": {
-                    "$ref": "#/definitions/repository.TotalBandwidths"
+                "country": {
+                    "type": "string"
                 },
-                "date_end": {
+                "first_seen_at": {
                     "type": "string"
                 },
-                "date_start": {
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
                     "type": "string"
+                },
+                "max_score": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "unbanned": {
+                    "type": "boolean"
                 }
             }
         },
-        "group.UnsyncedGroup": {
+        "models.IPBanPoints": {
             "type": "object",
-            "required": [
-                "config",
-                "name"
-            ],
             "properties": {
-                "config": {
-                    "$ref": "#/definitions/models.OcservGroupConfig"
+                "IP": {
+                    "type": "string"
                 },
-                "name": {
+                "Score": {
+                    "type": "integer"
+                },
+                "Since": {
                     "type": "string"
                 },
-                "path": {
+                "_Since": {
                     "type": "string"
                 }
             }
         },
-        "home.CPU": {
+        "models.IRoute": {
             "type": "object",
             "properties": {
-                "avg_percent": {
-                    "type": "number"
+                "Device": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "ID": {
+                    "type": "string"
                 },
-                "used_units": {
-                    "type": "number"
+                "IP": {
+                    "type": "string"
+                },
+                "Username": {
+                    "type": "string"
+                },
+                "iRoutes": {
+                    "type": "string"
+                },
+                "vhost": {
+                    "type": "string"
                 }
             }
         },
-        "home.CurrentStats": {
+        "models.JobRun": {
             "type": "object",
+            "required": [
+                "affected",
+                "id",
+                "job_name",
+                "started_at",
+                "status",
+                "trigger"
+            ],
             "properties": {
-                "Authentication failures": {
+                "affected": {
                     "type": "integer"
                 },
-                "Average auth time": {
+                "error": {
                     "type": "string"
                 },
-                "Average session time": {
+                "finished_at": {
                     "type": "string"
                 },
-                "Closed due to error sessions": {
+                "id": {
                     "type": "integer"
                 },
-                "Last stats reset": {
+                "job_name": {
                     "type": "string"
                 },
-                "Max auth time": {
+                "request_id": {
                     "type": "string"
                 },
-                "Max session time": {
+                "started_at": {
                     "type": "string"
                 },
-                "RX": {
-                    "type": "string"
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "succeeded",
+                        "failed"
+                    ]
                 },
-                "Sessions handled": {
-                    "type": "integer"
+                "trigger": {
+                    "type": "string",
+                    "enum": [
+                        "schedule",
+                        "missed",
+                        "manual"
+                    ]
+                }
+            }
+        },
+        "models.OcctlEvent": {
+            "type": "object",
+            "required": [
+                "id",
+                "time",
+                "type",
+                "username"
+            ],
+            "properties": {
+                "device": {
+                    "type": "string"
                 },
-                "TX": {
+                "group": {
                     "type": "string"
                 },
-                "Timed out (idle) sessions": {
+                "id": {
                     "type": "integer"
                 },
-                "Timed out sessions": {
-                    "type": "integer"
+                "ipv4": {
+                    "type": "string"
                 },
-                "_Last stats reset": {
+                "reason": {
                     "type": "string"
                 },
-                "raw_avg_auth_time": {
-                    "type": "integer"
+                "remote_ip": {
+                    "type": "string"
                 },
-                "raw_avg_session_time": {
+                "rx": {
                     "type": "integer"
                 },
-                "raw_last_stats_reset": {
-                    "type": "integer"
+                "time": {
+                    "type": "string"
                 },
-                "raw_max_auth_time": {
+                "tx": {
                     "type": "integer"
                 },
-                "raw_max_session_time": {
-                    "type": "integer"
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "connect",
+                        "disconnect"
+                    ]
                 },
-                "raw_rx": {
-                    "description": "raw fields if you want",
-                    "type": "integer"
+                "user_agent": {
+                    "type": "string"
                 },
-                "raw_tx": {
-                    "type": "integer"
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "home.Disk": {
+        "models.OcctlSession": {
             "type": "object",
             "properties": {
-                "total": {
-                    "type": "number"
+                "Full session": {
+                    "type": "string"
                 },
-                "used": {
-                    "type": "number"
+                "Groupname": {
+                    "type": "string"
                 },
-                "used_percent": {
-                    "type": "number"
-                }
-            }
-        },
-        "home.DockerService": {
-            "type": "object",
-            "required": [
-                "log_stream",
-                "ocserv",
-                "postgres",
-                "user_expiry",
-                "web"
-            ],
-            "properties": {
-                "log_stream": {
-                    "$ref": "#/definitions/home.DockerStats"
+                "Location": {
+                    "type": "string"
                 },
-                "ocserv": {
-                    "$ref": "#/definitions/home.DockerStats"
+                "Remote IP": {
+                    "type": "string"
                 },
-                "postgres": {
-                    "$ref": "#/definitions/home.DockerStats"
+                "Session": {
+                    "type": "string"
                 },
-                "user_expiry": {
-                    "$ref": "#/definitions/home.DockerStats"
+                "State": {
+                    "type": "string"
                 },
-                "web": {
-                    "$ref": "#/definitions/home.DockerStats"
+                "User-Agent": {
+                    "type": "string"
+                },
+                "Username": {
+                    "type": "string"
+                },
+                "_Created": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "in_use": {
+                    "type": "boolean"
+                },
+                "raw_created": {
+                    "type": "integer"
+                },
+                "session_is_open": {
+                    "type": "boolean"
+                },
+                "tls_auth_ok": {
+                    "type": "boolean"
+                },
+                "vhost": {
+                    "type": "string"
                 }
             }
         },
-        "home.DockerStats": {
+        "models.OcservGroup": {
             "type": "object",
             "required": [
-                "name"
+                "name",
+                "owner"
             ],
             "properties": {
-                "cpu": {
-                    "$ref": "#/definitions/home.CPU"
+                "config": {
+                    "$ref": "#/definitions/models.OcservGroupConfig"
+                },
+                "id": {
+                    "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "ram": {
-                    "$ref": "#/definitions/home.RAM"
+                "owner": {
+                    "type": "string"
+                },
+                "servers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Server"
+                    }
                 }
             }
         },
-        "home.GeneralInfo": {
+        "models.OcservGroupConfig": {
             "type": "object",
             "properties": {
-                "Active sessions": {
-                    "type": "integer"
-                },
-                "IPs in ban list": {
-                    "type": "integer"
-                },
-                "Median latency": {
+                "cgroup": {
+                    "description": "Linux control group to assign the VPN worker process to. Format: 'controller,subsystem:name'. Example: 'cpuset,cpu:test'",
                     "type": "string"
                 },
-                "STDEV latency": {
-                    "type": "string"
+                "deny-roaming": {
+                    "description": "Disconnect client if its IP changes (e.g., due to network switch). Example: true",
+                    "type": "boolean"
                 },
-                "Sec-mod PID": {
+                "dns": {
+                    "description": "Comma-separated list of DNS servers to assign to the client. Example: '8.8.8.8,1.1.1.1'",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "dpd": {
+                    "description": "Dead Peer Detection timeout in seconds. Example: 90",
                     "type": "integer"
                 },
-                "Sec-mod instance count": {
+                "explicit-ipv4": {
+                    "description": "Static IPv4 address to assign to client. Example: '192.168.100.10'",
+                    "type": "string"
+                },
+                "idle-timeout": {
+                    "description": "Time in seconds before disconnecting idle clients. Example: 600",
                     "type": "integer"
                 },
-                "Server PID": {
-                    "type": "integer"
+                "ipv4-network": {
+                    "description": "The pool of addresses that leases will be given from. Example: '192.168.1.0/24'",
+                    "type": "string"
                 },
-                "Status": {
+                "iroute": {
+                    "description": "Internal route available only via VPN. Format: 'IP/prefix'. Example: '10.0.0.0/8'",
                     "type": "string"
                 },
-                "Total authentication failures": {
+                "keepalive": {
+                    "description": "Interval in seconds to send keep-alive pings. Example: 60",
                     "type": "integer"
                 },
-                "Total sessions": {
+                "max-same-clients": {
+                    "description": "Maximum simultaneous logins per user. Example: 2",
                     "type": "integer"
                 },
-                "Up since": {
-                    "type": "string"
-                },
-                "_Up since": {
-                    "type": "string"
-                },
-                "raw_median_latency": {
-                    "description": "raw fields if you want",
+                "mobile-dpd": {
+                    "description": "DPD timeout specifically for mobile clients. Example: 300",
                     "type": "integer"
                 },
-                "raw_stdev_latency": {
+                "mobile-idle-timeout": {
+                    "description": "Idle timeout for mobile clients. Example: 900",
                     "type": "integer"
                 },
-                "raw_up_since": {
+                "mtu": {
+                    "description": "Tunnel interface MTU to avoid fragmentation. Example: 1400",
                     "type": "integer"
                 },
-                "uptime": {
+                "nbns": {
+                    "description": "NetBIOS Name Servers (WINS) for Windows clients. Example: '192.168.1.1'",
+                    "type": "string"
+                },
+                "net-priority": {
+                    "description": "Priority for routes; lower is higher priority. Example: 1",
                     "type": "integer"
-                }
-            }
-        },
-        "home.GetHomeResponse": {
-            "type": "object",
-            "properties": {
-                "ip_bans": {
+                },
+                "no-route": {
+                    "description": "List of networks to exclude from VPN routing. Each entry should be in 'IP/prefix' format. Example: ['192.168.0.0/16', '10.0.0.0/8']",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.IPBanPoints"
+                        "type": "string"
                     }
                 },
-                "statistics": {
+                "no-udp": {
+                    "description": "Disables UDP, enforcing TCP-only VPN connection. Example: true",
+                    "type": "boolean"
+                },
+                "restrict-user-to-ports": {
+                    "description": "Comma-separated list of allowed (or blocked, if negated) protocols and ports. Supports 'tcp(port)', 'udp(port)', 'icmp()', 'icmpv6()', and negation with '!()'. Example: 'tcp(443), tcp(80), udp(53)', or '!(tcp(22), udp(1194))'",
+                    "type": "string"
+                },
+                "restrict-user-to-routes": {
+                    "description": "Allow client access only to defined routes. Example: true",
+                    "type": "boolean"
+                },
+                "route": {
+                    "description": "Routes pushed to the client for routing traffic. Example: ['0.0.0.0/0', '10.10.0.0/16']",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.DailyTraffic"
+                        "type": "string"
                     }
                 },
-                "top_bandwidth_user": {
-                    "$ref": "#/definitions/repository.TopBandwidthUsers"
+                "rx-data-per-sec": {
+                    "description": "Maximum receive bandwidth in bytes per second. Example: '100000' for 100 KB/s",
+                    "type": "integer"
                 },
-                "total_bandwidth": {
-                    "$ref": "#/definitions/repository.TotalBandwidths"
+                "session-timeout": {
+                    "description": "Max session time in seconds before forced disconnect. Example: 3600",
+                    "type": "integer"
                 },
-                "users": {
-                    "$ref": "#/definitions/home.GetHomeUser"
-                }
-            }
-        },
-        "home.GetHomeUser": {
-            "type": "object",
-            "properties": {
-                "online_users_session": {
+                "split-dns": {
+                    "description": "List of domains over which the provided DNS servers should be used. Example: ['example.com', 'internal.company.com']",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.OnlineUserSession"
+                        "type": "string"
                     }
                 },
-                "total": {
+                "stats-report-time": {
+                    "description": "Interval in seconds for stats reporting. Example: 300",
                     "type": "integer"
-                }
-            }
-        },
-        "home.OcservStatusResponse": {
-            "type": "object",
-            "properties": {
-                "current_stats": {
-                    "$ref": "#/definitions/home.CurrentStats"
                 },
-                "general_info": {
-                    "$ref": "#/definitions/home.GeneralInfo"
+                "tunnel-all-dns": {
+                    "description": "Force all DNS traffic through the VPN tunnel. Example: true",
+                    "type": "boolean"
+                },
+                "tx-data-per-sec": {
+                    "description": "Maximum transmit bandwidth in bytes per second. Example: '200000' for 200 KB/s",
+                    "type": "integer"
                 }
             }
         },
-        "home.RAM": {
+        "models.OcservInfo": {
             "type": "object",
+            "required": [
+                "status",
+                "version"
+            ],
             "properties": {
-                "total": {
-                    "type": "number"
-                },
-                "used": {
-                    "type": "number"
+                "status": {
+                    "type": "string"
                 },
-                "used_percent": {
-                    "type": "number"
+                "version": {
+                    "$ref": "#/definitions/models.ServerVersion"
                 }
             }
         },
-        "home.ServerStatusResponse": {
+        "models.OcservUser": {
             "type": "object",
+            "required": [
+                "created_at",
+                "cycle_anchor",
+                "group",
+                "is_locked",
+                "is_online",
+                "owner",
+                "password",
+                "rx",
+                "traffic_size",
+                "traffic_type",
+                "tx",
+                "uid",
+                "username"
+            ],
             "properties": {
-                "cpu": {
-                    "$ref": "#/definitions/home.CPU"
+                "config": {
+                    "$ref": "#/definitions/models.OcservUserConfig"
                 },
-                "disk": {
-                    "$ref": "#/definitions/home.Disk"
+                "created_at": {
+                    "type": "string"
                 },
-                "ram": {
-                    "$ref": "#/definitions/home.RAM"
+                "cycle_anchor": {
+                    "description": "creation or renewal date, monthly quotas reset on its day of month",
+                    "type": "string"
                 },
-                "swap": {
-                    "$ref": "#/definitions/home.Swap"
-                }
-            }
-        },
-        "home.Swap": {
-            "type": "object",
-            "properties": {
-                "total": {
-                    "type": "number"
+                "deactivated_at": {
+                    "type": "string"
                 },
-                "used": {
-                    "type": "number"
+                "description": {
+                    "type": "string"
                 },
-                "used_percent": {
-                    "type": "number"
-                }
-            }
-        },
-        "middlewares.PermissionDenied": {
-            "type": "object",
-            "properties": {
-                "error": {
+                "expire_at": {
                     "type": "string"
-                }
-            }
-        },
-        "middlewares.TooManyRequests": {
-            "type": "object",
-            "properties": {
-                "error": {
+                },
+                "group": {
                     "type": "string"
-                }
-            }
-        },
-        "middlewares.Unauthorized": {
-            "type": "object",
-            "properties": {
-                "error": {
+                },
+                "is_locked": {
+                    "type": "boolean"
+                },
+                "is_online": {
+                    "type": "boolean"
+                },
+                "lock_reason": {
+                    "description": "why the user was locked, empty when it is not",
+                    "type": "string",
+                    "enum": [
+                        "quota",
+                        "anomaly",
+                        "expired",
+                        "manual"
+                    ]
+                },
+                "max_devices": {
+                    "description": "0 is unlimited",
+                    "type": "integer"
+                },
+                "owner": {
                     "type": "string"
-                }
-            }
-        },
-        "models.DailyTraffic": {
-            "type": "object",
-            "properties": {
-                "date": {
-                    "description": "Format: YYYY-MM-DD",
+                },
+                "password": {
                     "type": "string"
                 },
                 "rx": {
-                    "description": "in GiB",
-                    "type": "number"
+                    "description": "Receive in bytes",
+                    "type": "integer"
                 },
-                "tx": {
-                    "description": "in GiB",
-                    "type": "number"
-                }
-            }
-        },
-        "models.IPBanPoints": {
-            "type": "object",
-            "properties": {
-                "IP": {
-                    "type": "string"
+                "servers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Server"
+                    }
                 },
-                "Score": {
+                "traffic_size": {
+                    "description": "in GiB  \u003e\u003e x * 1024 ** 3",
                     "type": "integer"
                 },
-                "Since": {
+                "traffic_type": {
+                    "type": "string",
+                    "enum": [
+                        "Free",
+                        "MonthlyTransmit",
+                        "MonthlyReceive",
+                        "TotallyTransmit",
+                        "TotallyReceive"
+                    ]
+                },
+                "tx": {
+                    "description": "Transmit in bytes",
+                    "type": "integer"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 },
-                "_Since": {
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "models.OcservGroup": {
+        "models.OcservUserAlert": {
             "type": "object",
             "required": [
-                "name",
-                "owner"
+                "acknowledged",
+                "created_at",
+                "evidence",
+                "id",
+                "kind",
+                "locked",
+                "username"
             ],
             "properties": {
-                "config": {
-                    "$ref": "#/definitions/models.OcservGroupConfig"
+                "acknowledged": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "evidence": {
+                    "$ref": "#/definitions/models.AlertEvidence"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "concurrent_sessions",
+                        "impossible_travel",
+                        "user_agents"
+                    ]
                 },
-                "owner": {
+                "locked": {
+                    "type": "boolean"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "models.OcservGroupConfig": {
+        "models.OcservUserConfig": {
             "type": "object",
             "properties": {
-                "cgroup": {
-                    "description": "Linux control group to assign the VPN worker process to. Format: 'controller,subsystem:name'. Example: 'cpuset,cpu:test'",
-                    "type": "string"
-                },
-                "deny-roaming": {
-                    "description": "Disconnect client if its IP changes (e.g., due to network switch). Example: true",
-                    "type": "boolean"
-                },
                 "dns": {
-                    "description": "Comma-separated list of DNS servers to assign to the client. Example: '8.8.8.8,1.1.1.1'",
+                    "description": "Comma-separated list of DNS servers to assign to the user. Example: '8.8.8.8,1.1.1.1'",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "dpd": {
-                    "description": "Dead Peer Detection timeout in seconds. Example: 90",
-                    "type": "integer"
-                },
                 "explicit-ipv4": {
-                    "description": "Static IPv4 address to assign to client. Example: '192.168.100.10'",
+                    "description": "Static IPv4 address to assign to the user. Example: '192.168.100.10'",
                     "type": "string"
                 },
                 "idle-timeout": {
-                    "description": "Time in seconds before disconnecting idle clients. Example: 600",
+                    "description": "Time in seconds before disconnecting idle users. Example: 600",
                     "type": "integer"
                 },
                 "ipv4-network": {
-                    "description": "The pool of addresses that leases will be given from. Example: '192.168.1.0/24'",
+                    "description": "The pool of addresses from which to assign to the user. Example: '192.168.1.0/24'",
                     "type": "string"
                 },
                 "iroute": {
-                    "description": "Internal route available only via VPN. Format: 'IP/prefix'. Example: '10.0.0.0/8'",
+                    "description": "Internal route available only via VPN. Example: '10.0.0.0/8'",
                     "type": "string"
                 },
-                "keepalive": {
-                    "description": "Interval in seconds to send keep-alive pings. Example: 60",
-                    "type": "integer"
-                },
-                "max-same-clients": {
-                    "description": "Maximum simultaneous logins per user. Example: 2",
-                    "type": "integer"
-                },
-                "mobile-dpd": {
-                    "description": "DPD timeout specifically for mobile clients. Example: 300",
-                    "type": "integer"
-                },
                 "mobile-idle-timeout": {
-                    "description": "Idle timeout for mobile clients. Example: 900",
-                    "type": "integer"
-                },
-                "mtu": {
-                    "description": "Tunnel interface MTU to avoid fragmentation. Example: 1400",
+                    "description": "Idle timeout in seconds for mobile users. Example: 900",
                     "type": "integer"
                 },
                 "nbns": {
                     "description": "NetBIOS Name Servers (WINS) for Windows clients. Example: '192.168.1.1'",
                     "type": "string"
                 },
-                "net-priority": {
-                    "description": "Priority for routes; lower is higher priority. Example: 1",
-                    "type": "integer"
-                },
                 "no-route": {
-                    "description": "List of networks to exclude from VPN routing. Each entry should be in 'IP/prefix' format. Example: ['192.168.0.0/16', '10.0.0.0/8']",
+                    "description": "List of networks to exclude from VPN routing. Example: ['192.168.0.0/16', '10.0.0.0/8']",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "no-udp": {
-                    "description": "Disables UDP, enforcing TCP-only VPN connection. Example: true",
-                    "type": "boolean"
+                "rekey-time": {
+                    "description": "Rekey time in seconds; triggers key renegotiation. Example: 86400 for 24 hours",
+                    "type": "integer"
                 },
-                "restrict-user-to-ports": {
-                    "description": "Comma-separated list of allowed (or blocked, if negated) protocols and ports. Supports 'tcp(port)', 'udp(port)', 'icmp()', 'icmpv6()', and negation with '!()'. Example: 'tcp(443), tcp(80), udp(53)', or '!(tcp(22), udp(1194))'",
+                "restrict-to-ports": {
+                    "description": "Comma-separated list of allowed or blocked ports/protocols. Supports 'tcp(port)', 'udp(port)', 'icmp()', 'icmpv6()', and negation with '!()'. Example: 'tcp(443), udp(53)' or '!(tcp(22), udp(1194))'",
                     "type": "string"
                 },
-                "restrict-user-to-routes": {
-                    "description": "Allow client access only to defined routes. Example: true",
+                "restrict-to-routes": {
+                    "description": "Allow user access only to defined routes. Example: true",
                     "type": "boolean"
                 },
                 "route": {
-                    "description": "Routes pushed to the client for routing traffic. Example: ['0.0.0.0/0', '10.10.0.0/16']",
+                    "description": "Routes pushed to the user for routing traffic. Example: ['0.0.0.0/0', '10.10.0.0/16']",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "rx-data-per-sec": {
-                    "description": "Maximum receive bandwidth in bytes per second. Example: '100000' for 100 KB/s",
-                    "type": "integer"
-                },
                 "session-timeout": {
-                    "description": "Max session time in seconds before forced disconnect. Example: 3600",
+                    "description": "Maximum session time in seconds before forced disconnect. Example: 3600",
                     "type": "integer"
                 },
                 "split-dns": {
@@ -3566,214 +7850,423 @@ const docTemplate = `{
                     "items": {
                         "type": "string"
                     }
-                },
-                "stats-report-time": {
-                    "description": "Interval in seconds for stats reporting. Example: 300",
-                    "type": "integer"
-                },
-                "tunnel-all-dns": {
-                    "description": "Force all DNS traffic through the VPN tunnel. Example: true",
-                    "type": "boolean"
-                },
-                "tx-data-per-sec": {
-                    "description": "Maximum transmit bandwidth in bytes per second. Example: '200000' for 200 KB/s",
-                    "type": "integer"
                 }
             }
         },
-        "models.OcservInfo": {
+        "models.OcservUserDevice": {
             "type": "object",
             "required": [
-                "status",
-                "version"
+                "blocked",
+                "first_seen_at",
+                "id",
+                "last_seen_at",
+                "platform",
+                "user_agent"
             ],
             "properties": {
-                "status": {
+                "block_reason": {
+                    "type": "string",
+                    "enum": [
+                        "revoked",
+                        "device_limit"
+                    ]
+                },
+                "blocked": {
+                    "type": "boolean"
+                },
+                "first_seen_at": {
                     "type": "string"
                 },
-                "version": {
-                    "$ref": "#/definitions/models.ServerVersion"
+                "id": {
+                    "type": "integer"
+                },
+                "last_ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string",
+                    "enum": [
+                        "windows",
+                        "macos",
+                        "linux",
+                        "android",
+                        "ios",
+                        "unknown"
+                    ]
+                },
+                "user_agent": {
+                    "type": "string"
                 }
             }
         },
-        "models.OcservUser": {
+        "models.OcservUserSessionLog": {
             "type": "object",
             "required": [
                 "created_at",
-                "group",
-                "is_locked",
-                "is_online",
-                "owner",
-                "password",
-                "rx",
-                "traffic_size",
-                "traffic_type",
-                "tx",
-                "uid",
+                "event",
+                "message",
                 "username"
             ],
             "properties": {
-                "config": {
-                    "$ref": "#/definitions/models.OcservUserConfig"
+                "asn": {
+                    "type": "integer"
+                },
+                "asn_organization": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "description": "GeoIP enrichment of IP, empty when GeoIP is disabled or the address is unknown.\nNewCountry flags a session started from a country the user was not seen in before.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string",
+                    "enum": [
+                        "connect",
+                        "user-agent",
+                        "handshake",
+                        "periodic-stats",
+                        "disconnect"
+                    ]
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "new_country": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OnlineUserSession": {
+            "type": "object",
+            "properties": {
+                "Average RX": {
+                    "type": "string"
+                },
+                "Average TX": {
+                    "type": "string"
+                },
+                "CSTP compression": {
+                    "type": "string"
+                },
+                "DNS": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "DPD": {
+                    "type": "integer"
+                },
+                "DTLS cipher": {
+                    "type": "string"
+                },
+                "DTLS compression": {
+                    "type": "string"
+                },
+                "Device": {
+                    "type": "string"
+                },
+                "Full session": {
+                    "type": "string"
+                },
+                "Groupname": {
+                    "type": "string"
+                },
+                "Hostname": {
+                    "type": "string"
+                },
+                "ID": {
+                    "type": "string"
+                },
+                "IPv4": {
+                    "type": "string"
+                },
+                "IPv6": {
+                    "type": "string"
+                },
+                "KeepAlive": {
+                    "type": "integer"
+                },
+                "Local Device IP": {
+                    "type": "string"
+                },
+                "Location": {
+                    "type": "string"
+                },
+                "MTU": {
+                    "type": "integer"
+                },
+                "NBNS": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "No-routes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "P-t-P IPv4": {
+                    "type": "string"
+                },
+                "P-t-P IPv6": {
+                    "type": "string"
+                },
+                "RX": {
+                    "description": "bytes",
+                    "type": "integer"
+                },
+                "Remote IP": {
+                    "type": "string"
+                },
+                "Restricted to ports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "Restricted to routes": {
+                    "type": "boolean"
+                },
+                "Routes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "created_at": {
+                "Session": {
                     "type": "string"
                 },
-                "deactivated_at": {
+                "Split-DNS-Domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "State": {
                     "type": "string"
                 },
-                "description": {
+                "TLS ciphersuite": {
                     "type": "string"
                 },
-                "expire_at": {
+                "TX": {
+                    "description": "bytes",
+                    "type": "integer"
+                },
+                "User-Agent": {
                     "type": "string"
                 },
-                "group": {
+                "Username": {
                     "type": "string"
                 },
-                "is_locked": {
-                    "type": "boolean"
+                "_Connected at": {
+                    "description": "e.g. \"2h:15m\"",
+                    "type": "string"
                 },
-                "is_online": {
-                    "type": "boolean"
+                "_RX": {
+                    "type": "string"
                 },
-                "owner": {
+                "_TX": {
                     "type": "string"
                 },
-                "password": {
+                "connected_at": {
                     "type": "string"
                 },
-                "rx": {
-                    "description": "Receive in bytes",
-                    "type": "integer"
+                "iRoutes": {
+                    "type": "string"
                 },
-                "traffic_size": {
-                    "description": "in GiB  \u003e\u003e x * 1024 ** 3",
+                "raw_connected_at": {
                     "type": "integer"
                 },
-                "traffic_type": {
+                "server": {
+                    "description": "Server is the name of the node of the session when sessions of several nodes are listed.",
+                    "type": "string"
+                },
+                "vhost": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Server": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "endpoint",
+                "id",
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "endpoint": {
                     "type": "string",
-                    "enum": [
-                        "Free",
-                        "MonthlyTransmit",
-                        "MonthlyReceive",
-                        "TotallyTransmit",
-                        "TotallyReceive"
-                    ]
+                    "example": "http://10.0.0.2:8888"
                 },
-                "tx": {
-                    "description": "Transmit in bytes",
+                "id": {
                     "type": "integer"
                 },
-                "uid": {
+                "name": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
-                },
-                "username": {
-                    "type": "string"
                 }
             }
         },
-        "models.OcservUserConfig": {
+        "models.ServerMetric": {
             "type": "object",
+            "required": [
+                "cpu_percent",
+                "created_at",
+                "disk_percent",
+                "memory_percent",
+                "memory_used",
+                "net_rx_bps",
+                "net_tx_bps",
+                "online_sessions",
+                "swap_percent",
+                "vpn_rx_bps",
+                "vpn_tx_bps"
+            ],
             "properties": {
-                "dns": {
-                    "description": "Comma-separated list of DNS servers to assign to the user. Example: '8.8.8.8,1.1.1.1'",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "cpu_percent": {
+                    "type": "number"
                 },
-                "explicit-ipv4": {
-                    "description": "Static IPv4 address to assign to the user. Example: '192.168.100.10'",
+                "created_at": {
                     "type": "string"
                 },
-                "idle-timeout": {
-                    "description": "Time in seconds before disconnecting idle users. Example: 600",
+                "disk_percent": {
+                    "type": "number"
+                },
+                "memory_percent": {
+                    "type": "number"
+                },
+                "memory_used": {
+                    "description": "bytes",
                     "type": "integer"
                 },
-                "ipv4-network": {
-                    "description": "The pool of addresses from which to assign to the user. Example: '192.168.1.0/24'",
-                    "type": "string"
+                "net_rx_bps": {
+                    "description": "all interfaces except loopback, bits/s",
+                    "type": "number"
                 },
-                "iroute": {
-                    "description": "Internal route available only via VPN. Example: '10.0.0.0/8'",
+                "net_tx_bps": {
+                    "type": "number"
+                },
+                "online_sessions": {
+                    "type": "number"
+                },
+                "swap_percent": {
+                    "type": "number"
+                },
+                "vpn_rx_bps": {
+                    "description": "vpns* tun devices, bits/s",
+                    "type": "number"
+                },
+                "vpn_tx_bps": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ServerVersion": {
+            "type": "object",
+            "properties": {
+                "occtl_version": {
                     "type": "string"
                 },
-                "mobile-idle-timeout": {
-                    "description": "Idle timeout in seconds for mobile users. Example: 900",
+                "ocserv_version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.System": {
+            "type": "object",
+            "properties": {
+                "_": {
                     "type": "integer"
                 },
-                "nbns": {
-                    "description": "NetBIOS Name Servers (WINS) for Windows clients. Example: '192.168.1.1'",
-                    "type": "string"
+                "anomaly_auto_lock": {
+                    "type": "boolean"
                 },
-                "no-route": {
-                    "description": "List of networks to exclude from VPN routing. Example: ['192.168.0.0/16', '10.0.0.0/8']",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "anomaly_detection": {
+                    "description": "Account sharing detection policy of the stats service. Alerts are raised for\nmore concurrent session IPs than AnomalyMaxConcurrentIPs, travel between\nhandshakes faster than AnomalyMaxTravelKmh and more distinct user agents a day\nthan AnomalyMaxUserAgents. AnomalyAutoLock locks the user on every alert.",
+                    "type": "boolean"
                 },
-                "rekey-time": {
-                    "description": "Rekey time in seconds; triggers key renegotiation. Example: 86400 for 24 hours",
+                "anomaly_max_concurrent_ips": {
                     "type": "integer"
                 },
-                "restrict-to-ports": {
-                    "description": "Comma-separated list of allowed or blocked ports/protocols. Supports 'tcp(port)', 'udp(port)', 'icmp()', 'icmpv6()', and negation with '!()'. Example: 'tcp(443), udp(53)' or '!(tcp(22), udp(1194))'",
-                    "type": "string"
+                "anomaly_max_travel_kmh": {
+                    "type": "integer"
                 },
-                "restrict-to-routes": {
-                    "description": "Allow user access only to defined routes. Example: true",
+                "anomaly_max_user_agents": {
+                    "type": "integer"
+                },
+                "auto_delete_inactive_users": {
                     "type": "boolean"
                 },
-                "route": {
-                    "description": "Routes pushed to the user for routing traffic. Example: ['0.0.0.0/0', '10.10.0.0/16']",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "google_captcha_secret": {
+                    "type": "string"
                 },
-                "session-timeout": {
-                    "description": "Maximum session time in seconds before forced disconnect. Example: 3600",
+                "google_captcha_site_key": {
+                    "type": "string"
+                },
+                "keep_inactive_user_days": {
                     "type": "integer"
                 },
-                "split-dns": {
-                    "description": "List of domains over which the provided DNS servers should be used. Example: ['example.com', 'internal.company.com']",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "require_2fa_admins": {
+                    "type": "boolean"
+                },
+                "require_2fa_staffs": {
+                    "type": "boolean"
                 }
             }
         },
-        "models.OcservUserSessionLog": {
+        "models.User": {
             "type": "object",
             "required": [
-                "created_at",
-                "event",
-                "message",
+                "is_admin",
+                "last_login",
+                "totp_enabled",
+                "uid",
                 "username"
             ],
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "event": {
-                    "type": "string",
-                    "enum": [
-                        "user-agent",
-                        "handshake",
-                        "periodic-stats",
-                        "disconnect"
-                    ]
+                "is_admin": {
+                    "type": "boolean"
                 },
-                "ip": {
+                "last_login": {
                     "type": "string"
                 },
-                "message": {
+                "totp_enabled": {
+                    "type": "boolean"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 },
                 "username": {
@@ -3781,97 +8274,174 @@ const docTemplate = `{
                 }
             }
         },
-        "models.OnlineUserSession": {
+        "models.UserQuota": {
             "type": "object",
+            "required": [
+                "allowed_groups",
+                "max_expire_days",
+                "max_traffic_gib",
+                "max_users"
+            ],
             "properties": {
-                "Average RX": {
-                    "type": "string"
+                "allowed_groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "Average TX": {
+                "created_at": {
                     "type": "string"
                 },
-                "Groupname": {
-                    "type": "string"
+                "max_expire_days": {
+                    "type": "integer"
                 },
-                "Username": {
-                    "type": "string"
+                "max_traffic_gib": {
+                    "type": "integer"
                 },
-                "_Connected at": {
+                "max_users": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.ServerVersion": {
+        "models.UserQuotaUsage": {
             "type": "object",
+            "required": [
+                "traffic_gib",
+                "users"
+            ],
             "properties": {
-                "occtl_version": {
+                "traffic_gib": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UsersLookup": {
+            "type": "object",
+            "required": [
+                "uid",
+                "username"
+            ],
+            "properties": {
+                "uid": {
                     "type": "string"
                 },
-                "ocserv_version": {
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "models.System": {
+        "monitor.BandwidthSnapshot": {
             "type": "object",
+            "required": [
+                "interfaces",
+                "rx_bps",
+                "sessions",
+                "time",
+                "tx_bps",
+                "vpn_rx_bps",
+                "vpn_tx_bps"
+            ],
             "properties": {
-                "_": {
-                    "type": "integer"
+                "interfaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/monitor.InterfaceBandwidth"
+                    }
                 },
-                "auto_delete_inactive_users": {
-                    "type": "boolean"
+                "rx_bps": {
+                    "type": "number"
                 },
-                "google_captcha_secret": {
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/monitor.SessionBandwidth"
+                    }
+                },
+                "time": {
+                    "type": "string"
+                },
+                "tx_bps": {
+                    "type": "number"
+                },
+                "vpn_rx_bps": {
+                    "type": "number"
+                },
+                "vpn_tx_bps": {
+                    "type": "number"
+                }
+            }
+        },
+        "monitor.InterfaceBandwidth": {
+            "type": "object",
+            "required": [
+                "interface",
+                "rx_bps",
+                "tx_bps"
+            ],
+            "properties": {
+                "interface": {
                     "type": "string"
                 },
-                "google_captcha_site_key": {
-                    "type": "string"
+                "rx_bps": {
+                    "type": "number"
                 },
-                "keep_inactive_user_days": {
-                    "type": "integer"
+                "tx_bps": {
+                    "type": "number"
                 }
             }
         },
-        "models.User": {
+        "monitor.SessionBandwidth": {
             "type": "object",
             "required": [
-                "is_admin",
-                "last_login",
-                "uid",
+                "device",
+                "group",
+                "id",
+                "rx_bps",
+                "rx_bytes",
+                "tx_bps",
+                "tx_bytes",
                 "username"
             ],
             "properties": {
-                "created_at": {
+                "device": {
                     "type": "string"
                 },
-                "is_admin": {
-                    "type": "boolean"
-                },
-                "last_login": {
+                "group": {
                     "type": "string"
                 },
-                "uid": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "rx_bps": {
+                    "type": "number"
+                },
+                "rx_bytes": {
+                    "type": "integer"
+                },
+                "tx_bps": {
+                    "type": "number"
+                },
+                "tx_bytes": {
+                    "type": "integer"
                 },
                 "username": {
                     "type": "string"
                 }
             }
         },
-        "models.UsersLookup": {
+        "occtl.CommandResponse": {
             "type": "object",
             "required": [
-                "uid",
-                "username"
+                "output"
             ],
             "properties": {
-                "uid": {
-                    "type": "string"
-                },
-                "username": {
+                "output": {
                     "type": "string"
                 }
             }
@@ -3888,6 +8458,12 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "server_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
@@ -3930,6 +8506,12 @@ const docTemplate = `{
             "properties": {
                 "config": {
                     "$ref": "#/definitions/models.OcservGroupConfig"
+                },
+                "server_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
@@ -3967,11 +8549,23 @@ const docTemplate = `{
                 "group": {
                     "type": "string"
                 },
+                "max_devices": {
+                    "description": "0 is unlimited",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 2
+                },
                 "password": {
                     "type": "string",
                     "maxLength": 32,
                     "minLength": 2
                 },
+                "server_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "traffic_size": {
                     "description": "10 GiB",
                     "type": "integer",
@@ -4132,11 +8726,23 @@ const docTemplate = `{
                     "type": "string",
                     "example": "default"
                 },
+                "max_devices": {
+                    "description": "0 is unlimited",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 2
+                },
                 "password": {
                     "type": "string",
                     "maxLength": 32,
                     "minLength": 2
                 },
+                "server_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "traffic_size": {
                     "description": "10 GiB",
                     "type": "integer",
@@ -4161,6 +8767,63 @@ const docTemplate = `{
                 }
             }
         },
+        "profile.Bundle": {
+            "type": "object",
+            "required": [
+                "anyconnect",
+                "deep_link",
+                "openconnect",
+                "qr_code"
+            ],
+            "properties": {
+                "anyconnect": {
+                    "type": "string"
+                },
+                "deep_link": {
+                    "type": "string"
+                },
+                "openconnect": {
+                    "type": "string"
+                },
+                "qr_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.AlertsResponse": {
+            "type": "object",
+            "required": [
+                "meta"
+            ],
+            "properties": {
+                "meta": {
+                    "$ref": "#/definitions/request.Meta"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OcservUserAlert"
+                    }
+                }
+            }
+        },
+        "report.NewCountrySessionsResponse": {
+            "type": "object",
+            "required": [
+                "meta"
+            ],
+            "properties": {
+                "meta": {
+                    "$ref": "#/definitions/request.Meta"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OcservUserSessionLog"
+                    }
+                }
+            }
+        },
         "report.OcservUserReportResponse": {
             "type": "object",
             "properties": {
@@ -4195,6 +8858,133 @@ const docTemplate = `{
                 }
             }
         },
+        "repository.ASNSessions": {
+            "type": "object",
+            "required": [
+                "asn",
+                "organization",
+                "sessions",
+                "users"
+            ],
+            "properties": {
+                "asn": {
+                    "type": "integer"
+                },
+                "organization": {
+                    "type": "string"
+                },
+                "sessions": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.CountrySessions": {
+            "type": "object",
+            "required": [
+                "country",
+                "sessions",
+                "users"
+            ],
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "sessions": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.JobStatus": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "name",
+                "paused",
+                "schedule"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "latest_run": {
+                    "$ref": "#/definitions/models.JobRun"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "0 1 0 * * *"
+                },
+                "trigger_requested_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.ServerHealth": {
+            "type": "object",
+            "required": [
+                "active_sessions",
+                "healthy",
+                "latency",
+                "name",
+                "rx",
+                "tx"
+            ],
+            "properties": {
+                "active_sessions": {
+                    "type": "integer"
+                },
+                "endpoint": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latency": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rx": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tx": {
+                    "type": "integer"
+                },
+                "up_since": {
+                    "type": "string"
+                }
+            }
+        },
         "repository.TopBandwidthUsers": {
             "type": "object",
             "properties": {
@@ -4240,30 +9030,103 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
-                "message": {
+                "message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.Meta": {
+            "type": "object",
+            "required": [
+                "page",
+                "size",
+                "total_records"
+            ],
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "total_records": {
+                    "type": "integer"
+                }
+            }
+        },
+        "server.CreateServerData": {
+            "type": "object",
+            "required": [
+                "endpoint",
+                "name",
+                "secret"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1024
+                },
+                "endpoint": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "http://10.0.0.2:8888"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "edge-1"
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16
+                }
+            }
+        },
+        "server.ServersResponse": {
+            "type": "object",
+            "required": [
+                "meta"
+            ],
+            "properties": {
+                "meta": {
+                    "$ref": "#/definitions/request.Meta"
+                },
+                "result": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.Server"
                     }
                 }
             }
         },
-        "request.Meta": {
+        "server.UpdateServerData": {
             "type": "object",
-            "required": [
-                "page",
-                "size",
-                "total_records"
-            ],
             "properties": {
-                "page": {
-                    "type": "integer"
+                "description": {
+                    "type": "string",
+                    "maxLength": 1024
                 },
-                "size": {
-                    "type": "integer"
+                "endpoint": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "http://10.0.0.2:8888"
                 },
-                "total_records": {
-                    "type": "integer"
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "edge-1"
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16
                 }
             }
         },
@@ -4327,6 +9190,21 @@ const docTemplate = `{
         "system.GetSystemResponse": {
             "type": "object",
             "properties": {
+                "anomaly_auto_lock": {
+                    "type": "boolean"
+                },
+                "anomaly_detection": {
+                    "type": "boolean"
+                },
+                "anomaly_max_concurrent_ips": {
+                    "type": "integer"
+                },
+                "anomaly_max_travel_kmh": {
+                    "type": "integer"
+                },
+                "anomaly_max_user_agents": {
+                    "type": "integer"
+                },
                 "auto_delete_inactive_users": {
                     "type": "boolean"
                 },
@@ -4338,6 +9216,42 @@ const docTemplate = `{
                 },
                 "keep_inactive_user_days": {
                     "type": "integer"
+                },
+                "require_2fa_admins": {
+                    "type": "boolean"
+                },
+                "require_2fa_staffs": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "system.LoggingResponse": {
+            "type": "object",
+            "required": [
+                "format",
+                "level",
+                "stats"
+            ],
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "text",
+                        "json",
+                        "logfmt"
+                    ]
+                },
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "DEBUG",
+                        "INFO",
+                        "WARNING",
+                        "ERROR"
+                    ]
+                },
+                "stats": {
+                    "$ref": "#/definitions/logger.Stats"
                 }
             }
         },
@@ -4368,6 +9282,30 @@ const docTemplate = `{
                 }
             }
         },
+        "system.PatchLoggingData": {
+            "type": "object",
+            "required": [
+                "level"
+            ],
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "debug",
+                        "info",
+                        "warn",
+                        "warning",
+                        "error",
+                        "DEBUG",
+                        "INFO",
+                        "WARN",
+                        "WARNING",
+                        "ERROR"
+                    ],
+                    "example": "debug"
+                }
+            }
+        },
         "system.PatchSystemUpdateData": {
             "type": "object",
             "required": [
@@ -4377,6 +9315,27 @@ const docTemplate = `{
                 "keep_inactive_user_days"
             ],
             "properties": {
+                "anomaly_auto_lock": {
+                    "type": "boolean"
+                },
+                "anomaly_detection": {
+                    "type": "boolean"
+                },
+                "anomaly_max_concurrent_ips": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "anomaly_max_travel_kmh": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 900
+                },
+                "anomaly_max_user_agents": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 3
+                },
                 "auto_delete_inactive_users": {
                     "type": "boolean"
                 },
@@ -4388,6 +9347,94 @@ const docTemplate = `{
                 },
                 "keep_inactive_user_days": {
                     "type": "integer"
+                },
+                "require_2fa_admins": {
+                    "type": "boolean"
+                },
+                "require_2fa_staffs": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "system.PatchUserQuotaData": {
+            "type": "object",
+            "properties": {
+                "allowed_groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "defaults"
+                    ]
+                },
+                "max_expire_days": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 90
+                },
+                "max_traffic_gib": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 500
+                },
+                "max_users": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 50
+                }
+            }
+        },
+        "system.ProfileResponse": {
+            "type": "object",
+            "required": [
+                "is_admin",
+                "last_login",
+                "totp_enabled",
+                "uid",
+                "username"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "is_admin": {
+                    "type": "boolean"
+                },
+                "last_login": {
+                    "type": "string"
+                },
+                "quota": {
+                    "$ref": "#/definitions/models.UserQuota"
+                },
+                "totp_enabled": {
+                    "type": "boolean"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "usage": {
+                    "$ref": "#/definitions/models.UserQuotaUsage"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "system.RecoveryCodesResponse": {
+            "type": "object",
+            "required": [
+                "recovery_codes"
+            ],
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -4441,21 +9488,175 @@ const docTemplate = `{
                 }
             }
         },
-        "system.UserLoginResponse": {
+        "system.TwoFactorChallenge": {
             "type": "object",
             "required": [
-                "token",
-                "user"
+                "challenge_token",
+                "enrollment_required",
+                "expire_at"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "enrollment_required": {
+                    "type": "boolean"
+                },
+                "expire_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "system.TwoFactorChallengeData": {
+            "type": "object",
+            "required": [
+                "challenge_token"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "system.TwoFactorCodeData": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "system.TwoFactorDisableData": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 16,
+                    "minLength": 4
+                }
+            }
+        },
+        "system.TwoFactorEnrollResponse": {
+            "type": "object",
+            "required": [
+                "qr_code",
+                "secret",
+                "uri"
+            ],
+            "properties": {
+                "qr_code": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "system.TwoFactorLoginData": {
+            "type": "object",
+            "required": [
+                "challenge_token"
             ],
             "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "recovery_code": {
+                    "type": "string",
+                    "example": "a1b2c-3d4e5"
+                },
+                "remember_me": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "system.UserLoginResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "token": {
                     "type": "string"
                 },
+                "two_factor": {
+                    "$ref": "#/definitions/system.TwoFactorChallenge"
+                },
                 "user": {
                     "$ref": "#/definitions/models.User"
                 }
             }
         },
+        "system.UserQuotaResponse": {
+            "type": "object",
+            "required": [
+                "quota",
+                "usage"
+            ],
+            "properties": {
+                "quota": {
+                    "$ref": "#/definitions/models.UserQuota"
+                },
+                "usage": {
+                    "$ref": "#/definitions/models.UserQuotaUsage"
+                }
+            }
+        },
+        "system.UserSession": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "current",
+                "expire_at",
+                "ip",
+                "uid",
+                "user_agent"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expire_at": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
         "system.UsersResponse": {
             "type": "object",
             "required": [
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration003 = &gormigrate.Migration{
+	ID: "003_create_user_quotas",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// USER QUOTAS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS user_quotas (
+				id BIGSERIAL PRIMARY KEY,
+				user_id BIGINT NOT NULL UNIQUE,
+				max_users INTEGER NOT NULL DEFAULT 0,
+				max_traffic_gib INTEGER NOT NULL DEFAULT 0,
+				allowed_groups TEXT DEFAULT '',
+				max_expire_days INTEGER NOT NULL DEFAULT 0,
+				created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				CONSTRAINT fk_user_quotas_user
+					FOREIGN KEY(user_id)
+					REFERENCES users(id)
+					ON DELETE CASCADE
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// INDEXES
+		// =========================
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ocserv_users_owner
+			ON ocserv_users(owner);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 003 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP INDEX IF EXISTS idx_ocserv_users_owner;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			DROP TABLE IF EXISTS user_quotas;
+		`).Error
+	},
+}
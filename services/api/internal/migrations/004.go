@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration004 = &gormigrate.Migration{
+	ID: "004_add_two_factor_authentication",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// USERS TOTP COLUMNS
+		// =========================
+		if err := tx.Exec(`
+			ALTER TABLE users
+				ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64) DEFAULT '',
+				ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN DEFAULT FALSE;
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// RECOVERY CODES TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS user_recovery_codes (
+				id BIGSERIAL PRIMARY KEY,
+				user_id BIGINT NOT NULL,
+				code_hash VARCHAR(64) NOT NULL,
+				used_at TIMESTAMP NULL,
+				created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				CONSTRAINT fk_user_recovery_codes_user
+					FOREIGN KEY(user_id)
+					REFERENCES users(id)
+					ON DELETE CASCADE
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id
+			ON user_recovery_codes(user_id);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// SYSTEM 2FA POLICY
+		// =========================
+		if err := tx.Exec(`
+			ALTER TABLE systems
+				ADD COLUMN IF NOT EXISTS require_2fa_admins BOOLEAN DEFAULT FALSE,
+				ADD COLUMN IF NOT EXISTS require_2fa_staffs BOOLEAN DEFAULT FALSE;
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 004 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			ALTER TABLE systems
+				DROP COLUMN IF EXISTS require_2fa_admins,
+				DROP COLUMN IF EXISTS require_2fa_staffs;
+		`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`DROP TABLE IF EXISTS user_recovery_codes;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			ALTER TABLE users
+				DROP COLUMN IF EXISTS totp_secret,
+				DROP COLUMN IF EXISTS totp_enabled;
+		`).Error
+	},
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration005 = &gormigrate.Migration{
+	ID: "005_add_user_token_sessions",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// USER TOKENS SESSION COLUMNS
+		// =========================
+		if err := tx.Exec(`
+			ALTER TABLE user_tokens
+				ADD COLUMN IF NOT EXISTS ip VARCHAR(45) DEFAULT '',
+				ADD COLUMN IF NOT EXISTS user_agent TEXT DEFAULT '',
+				ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP NULL,
+				ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP NULL;
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// INDEXES
+		// =========================
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_user_tokens_expire_at
+			ON user_tokens(expire_at);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 005 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP INDEX IF EXISTS idx_user_tokens_expire_at;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			ALTER TABLE user_tokens
+				DROP COLUMN IF EXISTS ip,
+				DROP COLUMN IF EXISTS user_agent,
+				DROP COLUMN IF EXISTS last_seen_at,
+				DROP COLUMN IF EXISTS revoked_at;
+		`).Error
+	},
+}
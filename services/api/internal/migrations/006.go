@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration006 = &gormigrate.Migration{
+	ID: "006_create_server_metrics",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// SERVER METRICS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS server_metrics (
+				id BIGSERIAL PRIMARY KEY,
+				resolution VARCHAR(8) NOT NULL DEFAULT 'raw',
+				created_at TIMESTAMP NOT NULL,
+				cpu_percent DOUBLE PRECISION DEFAULT 0,
+				memory_percent DOUBLE PRECISION DEFAULT 0,
+				memory_used BIGINT DEFAULT 0,
+				swap_percent DOUBLE PRECISION DEFAULT 0,
+				disk_percent DOUBLE PRECISION DEFAULT 0,
+				net_rx_bps DOUBLE PRECISION DEFAULT 0,
+				net_tx_bps DOUBLE PRECISION DEFAULT 0,
+				vpn_rx_bps DOUBLE PRECISION DEFAULT 0,
+				vpn_tx_bps DOUBLE PRECISION DEFAULT 0,
+				online_sessions DOUBLE PRECISION DEFAULT 0
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// INDEXES
+		// =========================
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_server_metrics_resolution_created_at
+			ON server_metrics(resolution, created_at);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 006 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Exec(`
+			DROP TABLE IF EXISTS server_metrics;
+		`).Error
+	},
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration007 = &gormigrate.Migration{
+	ID: "007_create_ip_bans",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// MANUAL IP BANS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS ip_bans (
+				id BIGSERIAL PRIMARY KEY,
+				ip VARCHAR(45) NOT NULL UNIQUE,
+				reason TEXT,
+				country VARCHAR(2),
+				created_by VARCHAR(64),
+				expires_at TIMESTAMP NULL,
+				created_at TIMESTAMP NOT NULL DEFAULT NOW()
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// IP ALLOWLIST TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS ip_allowlists (
+				id BIGSERIAL PRIMARY KEY,
+				cidr VARCHAR(64) NOT NULL UNIQUE,
+				note TEXT,
+				created_at TIMESTAMP NOT NULL DEFAULT NOW()
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// IP BAN HISTORY TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS ip_ban_histories (
+				id BIGSERIAL PRIMARY KEY,
+				ip VARCHAR(45) NOT NULL UNIQUE,
+				country VARCHAR(2),
+				score INT NOT NULL DEFAULT 0,
+				max_score INT NOT NULL DEFAULT 0,
+				unbanned BOOLEAN NOT NULL DEFAULT FALSE,
+				first_seen_at TIMESTAMP NOT NULL,
+				last_seen_at TIMESTAMP NOT NULL
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// INDEXES
+		// =========================
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ip_bans_expires_at
+			ON ip_bans(expires_at);
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ip_ban_histories_last_seen_at
+			ON ip_ban_histories(last_seen_at);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 007 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP TABLE IF EXISTS ip_ban_histories;`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`DROP TABLE IF EXISTS ip_allowlists;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			DROP TABLE IF EXISTS ip_bans;
+		`).Error
+	},
+}
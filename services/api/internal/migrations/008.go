@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration008 = &gormigrate.Migration{
+	ID: "008_add_session_logs_geoip",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// SESSION LOGS GEOIP COLUMNS
+		// =========================
+		if err := tx.Exec(`
+			ALTER TABLE ocserv_user_session_logs
+				ADD COLUMN IF NOT EXISTS country VARCHAR(2) DEFAULT '',
+				ADD COLUMN IF NOT EXISTS city VARCHAR(128) DEFAULT '',
+				ADD COLUMN IF NOT EXISTS asn BIGINT DEFAULT 0,
+				ADD COLUMN IF NOT EXISTS asn_organization VARCHAR(255) DEFAULT '',
+				ADD COLUMN IF NOT EXISTS new_country BOOLEAN NOT NULL DEFAULT FALSE;
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// INDEXES
+		// =========================
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ocserv_logs_username_country
+			ON ocserv_user_session_logs(username, country);
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ocserv_logs_new_country
+			ON ocserv_user_session_logs(created_at)
+			WHERE new_country;
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 008 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP INDEX IF EXISTS idx_ocserv_logs_new_country;`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`DROP INDEX IF EXISTS idx_ocserv_logs_username_country;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			ALTER TABLE ocserv_user_session_logs
+				DROP COLUMN IF EXISTS country,
+				DROP COLUMN IF EXISTS city,
+				DROP COLUMN IF EXISTS asn,
+				DROP COLUMN IF EXISTS asn_organization,
+				DROP COLUMN IF EXISTS new_country;
+		`).Error
+	},
+}
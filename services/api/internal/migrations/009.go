@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration009 = &gormigrate.Migration{
+	ID: "009_add_anomaly_detection",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// SYSTEMS ANOMALY POLICY COLUMNS
+		// =========================
+		if err := tx.Exec(`
+			ALTER TABLE systems
+				ADD COLUMN IF NOT EXISTS anomaly_detection BOOLEAN DEFAULT TRUE,
+				ADD COLUMN IF NOT EXISTS anomaly_auto_lock BOOLEAN DEFAULT FALSE,
+				ADD COLUMN IF NOT EXISTS anomaly_max_concurrent_ips INT DEFAULT 1,
+				ADD COLUMN IF NOT EXISTS anomaly_max_travel_kmh INT DEFAULT 900,
+				ADD COLUMN IF NOT EXISTS anomaly_max_user_agents INT DEFAULT 3;
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// USER ALERTS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS ocserv_user_alerts (
+				id BIGSERIAL PRIMARY KEY,
+				username VARCHAR(64) NOT NULL,
+				kind VARCHAR(32) NOT NULL,
+				evidence JSONB,
+				locked BOOLEAN NOT NULL DEFAULT FALSE,
+				acknowledged BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMP NOT NULL DEFAULT NOW()
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// INDEXES
+		// =========================
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ocserv_user_alerts_username_kind
+			ON ocserv_user_alerts(username, kind, created_at);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 009 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP TABLE IF EXISTS ocserv_user_alerts;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			ALTER TABLE systems
+				DROP COLUMN IF EXISTS anomaly_detection,
+				DROP COLUMN IF EXISTS anomaly_auto_lock,
+				DROP COLUMN IF EXISTS anomaly_max_concurrent_ips,
+				DROP COLUMN IF EXISTS anomaly_max_travel_kmh,
+				DROP COLUMN IF EXISTS anomaly_max_user_agents;
+		`).Error
+	},
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration010 = &gormigrate.Migration{
+	ID: "010_add_ocserv_user_devices",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// OCSERV USERS DEVICE LIMIT
+		// =========================
+		if err := tx.Exec(`
+			ALTER TABLE ocserv_users
+				ADD COLUMN IF NOT EXISTS max_devices INT NOT NULL DEFAULT 0;
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// OCSERV USER DEVICES TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS ocserv_user_devices (
+				id BIGSERIAL PRIMARY KEY,
+				oc_user_id BIGINT NOT NULL,
+				user_agent VARCHAR(255) NOT NULL,
+				platform VARCHAR(32) NOT NULL DEFAULT 'unknown',
+				last_ip VARCHAR(45) DEFAULT '',
+				blocked BOOLEAN NOT NULL DEFAULT FALSE,
+				block_reason VARCHAR(32) DEFAULT '',
+				first_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+				last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+				CONSTRAINT fk_devices_ocserv_user
+					FOREIGN KEY(oc_user_id)
+					REFERENCES ocserv_users(id)
+					ON DELETE CASCADE
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// INDEXES
+		// =========================
+		if err := tx.Exec(`
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_ocserv_user_devices_user_agent
+			ON ocserv_user_devices(oc_user_id, user_agent);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 010 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP TABLE IF EXISTS ocserv_user_devices;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`ALTER TABLE ocserv_users DROP COLUMN IF EXISTS max_devices;`).Error
+	},
+}
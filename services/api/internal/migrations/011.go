@@ -0,0 +1,102 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration011 = &gormigrate.Migration{
+	ID: "011_add_servers",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// SERVERS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS servers (
+				id BIGSERIAL PRIMARY KEY,
+				name VARCHAR(64) NOT NULL UNIQUE,
+				endpoint VARCHAR(255) NOT NULL,
+				secret VARCHAR(255) NOT NULL,
+				description TEXT,
+				created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// OCSERV USER SERVERS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS ocserv_user_servers (
+				oc_user_id BIGINT NOT NULL,
+				server_id BIGINT NOT NULL,
+				PRIMARY KEY (oc_user_id, server_id),
+				CONSTRAINT fk_user_servers_ocserv_user
+					FOREIGN KEY(oc_user_id)
+					REFERENCES ocserv_users(id)
+					ON DELETE CASCADE,
+				CONSTRAINT fk_user_servers_server
+					FOREIGN KEY(server_id)
+					REFERENCES servers(id)
+					ON DELETE RESTRICT
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// OCSERV GROUP SERVERS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS ocserv_group_servers (
+				group_id BIGINT NOT NULL,
+				server_id BIGINT NOT NULL,
+				PRIMARY KEY (group_id, server_id),
+				CONSTRAINT fk_group_servers_ocserv_group
+					FOREIGN KEY(group_id)
+					REFERENCES ocserv_groups(id)
+					ON DELETE CASCADE,
+				CONSTRAINT fk_group_servers_server
+					FOREIGN KEY(server_id)
+					REFERENCES servers(id)
+					ON DELETE RESTRICT
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// INDEXES
+		// =========================
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ocserv_user_servers_server
+			ON ocserv_user_servers(server_id);
+		`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ocserv_group_servers_server
+			ON ocserv_group_servers(server_id);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 011 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP TABLE IF EXISTS ocserv_group_servers;`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`DROP TABLE IF EXISTS ocserv_user_servers;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`DROP TABLE IF EXISTS servers;`).Error
+	},
+}
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration012 = &gormigrate.Migration{
+	ID: "012_add_leader_leases_and_cron_states",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// LEADER LEASES TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS leader_leases (
+				name VARCHAR(64) PRIMARY KEY,
+				holder VARCHAR(128) NOT NULL,
+				expires_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// CRON STATES TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS cron_states (
+				job VARCHAR(64) PRIMARY KEY,
+				last_run TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 012 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP TABLE IF EXISTS cron_states;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`DROP TABLE IF EXISTS leader_leases;`).Error
+	},
+}
@@ -0,0 +1,116 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration013 = &gormigrate.Migration{
+	ID: "013_add_jobs",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// JOBS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS jobs (
+				name VARCHAR(64) PRIMARY KEY,
+				schedule VARCHAR(64) NOT NULL,
+				paused BOOLEAN NOT NULL DEFAULT FALSE,
+				last_run_at TIMESTAMP,
+				trigger_requested_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// JOB RUNS TABLE
+		// =========================
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS job_runs (
+				id BIGSERIAL PRIMARY KEY,
+				job_name VARCHAR(64) NOT NULL,
+				trigger VARCHAR(16) NOT NULL,
+				status VARCHAR(16) NOT NULL,
+				started_at TIMESTAMP NOT NULL,
+				finished_at TIMESTAMP,
+				affected INTEGER NOT NULL DEFAULT 0,
+				error TEXT,
+				request_id VARCHAR(32),
+				CONSTRAINT fk_job_runs_job
+					FOREIGN KEY(job_name)
+					REFERENCES jobs(name)
+					ON DELETE CASCADE
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_job_runs_job_started
+			ON job_runs(job_name, started_at DESC);
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// JOBS OF USER EXPIRY
+		// =========================
+		// the last runs kept in cron_states carry over to the jobs replacing them
+		if err := tx.Exec(`
+			INSERT INTO jobs (name, schedule, last_run_at)
+			SELECT v.name, v.schedule, c.last_run
+			FROM (VALUES
+				('expire_users', '0 1 0 * * *', 'daily'),
+				('active_monthly_users', '0 1 0 1,2 * *', 'monthly'),
+				('delete_expired_users', '0 2 0 * * *', 'delete_inactive_user')
+			) AS v(name, schedule, state_job)
+			LEFT JOIN cron_states c ON c.job = v.state_job
+			ON CONFLICT (name) DO NOTHING;
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`DROP TABLE IF EXISTS cron_states;`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 013 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS cron_states (
+				job VARCHAR(64) PRIMARY KEY,
+				last_run TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+			);
+		`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			INSERT INTO cron_states (job, last_run)
+			SELECT v.state_job, j.last_run_at
+			FROM jobs j
+			JOIN (VALUES
+				('expire_users', 'daily'),
+				('active_monthly_users', 'monthly'),
+				('delete_expired_users', 'delete_inactive_user')
+			) AS v(name, state_job) ON v.name = j.name
+			WHERE j.last_run_at IS NOT NULL
+			ON CONFLICT (job) DO NOTHING;
+		`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`DROP TABLE IF EXISTS job_runs;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`DROP TABLE IF EXISTS jobs;`).Error
+	},
+}
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration014 = &gormigrate.Migration{
+	ID: "014_add_ocserv_user_cycle_anchor",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// OCSERV USERS CYCLE ANCHOR
+		// =========================
+		// existing users are anchored at their creation date
+		if err := tx.Exec(`
+			ALTER TABLE ocserv_users
+				ADD COLUMN IF NOT EXISTS cycle_anchor DATE;
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			UPDATE ocserv_users
+			SET cycle_anchor = created_at::date
+			WHERE cycle_anchor IS NULL;
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			ALTER TABLE ocserv_users
+				ALTER COLUMN cycle_anchor SET DEFAULT CURRENT_DATE,
+				ALTER COLUMN cycle_anchor SET NOT NULL;
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// MONTHLY RESET JOB
+		// =========================
+		// cycles roll over on any day, the reset runs daily unless rescheduled
+		if err := tx.Exec(`
+			UPDATE jobs
+			SET schedule = '0 1 0 * * *', updated_at = NOW()
+			WHERE name = 'active_monthly_users' AND schedule = '0 1 0 1,2 * *';
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 014 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			UPDATE jobs
+			SET schedule = '0 1 0 1,2 * *', updated_at = NOW()
+			WHERE name = 'active_monthly_users' AND schedule = '0 1 0 * * *';
+		`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			ALTER TABLE ocserv_users
+				DROP COLUMN IF EXISTS cycle_anchor;
+		`).Error
+	},
+}
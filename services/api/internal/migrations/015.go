@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration015 = &gormigrate.Migration{
+	ID: "015_add_ocserv_user_lock_reason",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// OCSERV USERS LOCK REASON
+		// =========================
+		// only users locked for their quota are unlocked when their cycle rolls over
+		if err := tx.Exec(`
+			ALTER TABLE ocserv_users
+				ADD COLUMN IF NOT EXISTS lock_reason VARCHAR(16) NOT NULL DEFAULT '';
+		`).Error; err != nil {
+			return err
+		}
+
+		// =========================
+		// EXISTING LOCKS
+		// =========================
+		// the reason of existing locks was never stored, it is inferred:
+		// deactivated_at is only set by the expiry job, the quota check and the
+		// anomaly auto lock, so locked users without it were locked by an admin.
+		// Deactivated users that are not expired are taken as quota locks, the only
+		// ones the monthly reset unlocks as it did before. Users with a locking
+		// anomaly alert go last so they stay locked.
+		if err := tx.Exec(`
+			UPDATE ocserv_users
+			SET lock_reason = CASE
+				WHEN deactivated_at IS NULL THEN 'manual'
+				WHEN expire_at IS NOT NULL AND expire_at < deactivated_at THEN 'expired'
+				ELSE 'quota'
+			END
+			WHERE is_locked AND lock_reason = '';
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			UPDATE ocserv_users
+			SET lock_reason = 'anomaly'
+			WHERE is_locked AND username IN (
+				SELECT username FROM ocserv_user_alerts WHERE locked
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 015 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Exec(`
+			ALTER TABLE ocserv_users
+				DROP COLUMN IF EXISTS lock_reason;
+		`).Error
+	},
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration016 = &gormigrate.Migration{
+	ID: "016_add_ingested_events",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// INGESTED EVENTS TABLE
+		// =========================
+		// the IDs of the events pushed by the node agents, shared by the log_stream replicas
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS ingested_events (
+				id VARCHAR(26) PRIMARY KEY,
+				server_id BIGINT NOT NULL,
+				created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+				CONSTRAINT fk_ingested_events_server
+					FOREIGN KEY(server_id)
+					REFERENCES servers(id)
+					ON DELETE CASCADE
+			);
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_ingested_events_created_at
+			ON ingested_events(created_at);
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 016 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Exec(`DROP TABLE IF EXISTS ingested_events;`).Error
+	},
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration017 = &gormigrate.Migration{
+	ID: "017_add_ocserv_user_token_version",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// OCSERV USERS TOKEN VERSION
+		// =========================
+		// bumped on a password change, the customer portal tokens of older versions are revoked
+		if err := tx.Exec(`
+			ALTER TABLE ocserv_users
+				ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 017 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Exec(`
+			ALTER TABLE ocserv_users
+				DROP COLUMN IF EXISTS token_version;
+		`).Error
+	},
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var Migration018 = &gormigrate.Migration{
+	ID: "018_add_user_totp_counter",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// USERS TOTP COUNTER
+		// =========================
+		// time step of the last accepted TOTP code, codes of that step or an earlier one are rejected
+		if err := tx.Exec(`
+			ALTER TABLE users
+				ADD COLUMN IF NOT EXISTS totp_counter BIGINT NOT NULL DEFAULT 0;
+		`).Error; err != nil {
+			return err
+		}
+
+		logger.Info("migration 018 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		return tx.Exec(`
+			ALTER TABLE users
+				DROP COLUMN IF EXISTS totp_counter;
+		`).Error
+	},
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/secret"
+	"gorm.io/gorm"
+)
+
+var Migration019 = &gormigrate.Migration{
+	ID: "019_encrypt_server_secrets",
+
+	Migrate: func(tx *gorm.DB) error {
+
+		// =========================
+		// SERVERS SECRET
+		// =========================
+		// encrypted secrets are longer than the plaintext ones
+		if err := tx.Exec(`
+			ALTER TABLE servers
+				ALTER COLUMN secret TYPE TEXT;
+		`).Error; err != nil {
+			return err
+		}
+
+		// existing secrets are encrypted with the key of SECRET_KEY
+		var servers []struct {
+			ID     uint
+			Secret string
+		}
+		if err := tx.Table("servers").Select("id", "secret").Find(&servers).Error; err != nil {
+			return err
+		}
+		for _, server := range servers {
+			if secret.IsEncrypted(server.Secret) {
+				continue
+			}
+			encrypted, err := secret.Encrypt(server.Secret)
+			if err != nil {
+				return err
+			}
+			if err = tx.Table("servers").Where("id = ?", server.ID).Update("secret", encrypted).Error; err != nil {
+				return err
+			}
+		}
+
+		logger.Info("migration 019 (Postgres) complete successfully")
+		return nil
+	},
+
+	Rollback: func(tx *gorm.DB) error {
+		var servers []struct {
+			ID     uint
+			Secret string
+		}
+		if err := tx.Table("servers").Select("id", "secret").Find(&servers).Error; err != nil {
+			return err
+		}
+		for _, server := range servers {
+			if !secret.IsEncrypted(server.Secret) {
+				continue
+			}
+			decrypted, err := secret.Decrypt(server.Secret)
+			if err != nil {
+				return err
+			}
+			if err = tx.Table("servers").Where("id = ?", server.ID).Update("secret", decrypted).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`
+			ALTER TABLE servers
+				ALTER COLUMN secret TYPE VARCHAR(255);
+		`).Error
+	},
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"net"
+	"time"
+)
+
+// IPBan is a ban added by an operator. ocserv has no command to ban an address,
+// so sessions from a banned IP are disconnected as soon as they show up. A nil
+// ExpiresAt bans the address until it is removed.
+type IPBan struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement" validate:"required"`
+	IP        string     `json:"ip" gorm:"type:varchar(45);not null;uniqueIndex" validate:"required"`
+	Reason    string     `json:"reason" gorm:"type:text" validate:"required"`
+	Country   string     `json:"country" gorm:"type:varchar(2)" validate:"required"`
+	CreatedBy string     `json:"created_by" gorm:"type:varchar(64)" validate:"required"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime" validate:"required"`
+}
+
+// IPAllowlist is an IP or CIDR that must never stay banned. Matching ocserv bans
+// are lifted by the ban poller and manual bans are refused.
+type IPAllowlist struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement" validate:"required"`
+	CIDR      string    `json:"cidr" gorm:"column:cidr;type:varchar(64);not null;uniqueIndex" validate:"required"`
+	Note      string    `json:"note" gorm:"type:text" validate:"required"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" validate:"required"`
+}
+
+// IPBanHistory is an address seen in the ocserv ban list by the ban poller.
+// Score is the last seen ban score and MaxScore the highest one.
+type IPBanHistory struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement" validate:"required"`
+	IP          string    `json:"ip" gorm:"type:varchar(45);not null;uniqueIndex" validate:"required"`
+	Country     string    `json:"country" gorm:"type:varchar(2)" validate:"required"`
+	Score       int       `json:"score" gorm:"not null;default:0" validate:"required"`
+	MaxScore    int       `json:"max_score" gorm:"not null;default:0" validate:"required"`
+	Unbanned    bool      `json:"unbanned" gorm:"not null;default:false" validate:"required" desc:"lifted because of the allowlist"`
+	FirstSeenAt time.Time `json:"first_seen_at" gorm:"not null" validate:"required"`
+	LastSeenAt  time.Time `json:"last_seen_at" gorm:"not null" validate:"required"`
+}
+
+// Contains reports whether ip is the allowlisted address or inside the allowlisted network.
+func (a *IPAllowlist) Contains(ip net.IP) bool {
+	_, network, err := net.ParseCIDR(a.CIDR)
+	if err != nil {
+		allowed := net.ParseIP(a.CIDR)
+		return allowed != nil && allowed.Equal(ip)
+	}
+	return network.Contains(ip)
+}
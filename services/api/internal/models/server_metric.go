@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	MetricResolutionRaw  = "raw"
+	MetricResolutionHour = "hour"
+)
+
+// ServerMetric is a sample of server resources. Hourly rows are averages of the raw samples.
+type ServerMetric struct {
+	ID             uint      `json:"-" gorm:"primaryKey;autoIncrement"`
+	Resolution     string    `json:"-" gorm:"type:varchar(8);not null;default:'raw'"`
+	CreatedAt      time.Time `json:"created_at" gorm:"not null" validate:"required"`
+	CPUPercent     float64   `json:"cpu_percent" gorm:"column:cpu_percent" validate:"required"`
+	MemoryPercent  float64   `json:"memory_percent" validate:"required"`
+	MemoryUsed     int64     `json:"memory_used" validate:"required"` // bytes
+	SwapPercent    float64   `json:"swap_percent" validate:"required"`
+	DiskPercent    float64   `json:"disk_percent" validate:"required"`
+	NetRxBps       float64   `json:"net_rx_bps" validate:"required"` // all interfaces except loopback, bits/s
+	NetTxBps       float64   `json:"net_tx_bps" validate:"required"`
+	VpnRxBps       float64   `json:"vpn_rx_bps" validate:"required"` // vpns* tun devices, bits/s
+	VpnTxBps       float64   `json:"vpn_tx_bps" validate:"required"`
+	OnlineSessions float64   `json:"online_sessions" validate:"required"`
+}
@@ -11,6 +11,26 @@ type System struct {
 	GoogleCaptchaSiteKey    string `json:"google_captcha_site_key" gorm:"type:text"`
 	AutoDeleteInactiveUsers bool   `json:"auto_delete_inactive_users" gorm:"type:boolean;default:false"`
 	KeepInactiveUserDays    int    `json:"keep_inactive_user_days" gorm:"default:30"`
+	Require2FAAdmins        bool   `json:"require_2fa_admins" gorm:"column:require_2fa_admins;type:boolean;default:false"`
+	Require2FAStaffs        bool   `json:"require_2fa_staffs" gorm:"column:require_2fa_staffs;type:boolean;default:false"`
+
+	// Account sharing detection policy of the stats service. Alerts are raised for
+	// more concurrent session IPs than AnomalyMaxConcurrentIPs, travel between
+	// handshakes faster than AnomalyMaxTravelKmh and more distinct user agents a day
+	// than AnomalyMaxUserAgents. AnomalyAutoLock locks the user on every alert.
+	AnomalyDetection        bool `json:"anomaly_detection" gorm:"type:boolean;default:true"`
+	AnomalyAutoLock         bool `json:"anomaly_auto_lock" gorm:"type:boolean;default:false"`
+	AnomalyMaxConcurrentIPs int  `json:"anomaly_max_concurrent_ips" gorm:"column:anomaly_max_concurrent_ips;default:1"`
+	AnomalyMaxTravelKmh     int  `json:"anomaly_max_travel_kmh" gorm:"default:900"`
+	AnomalyMaxUserAgents    int  `json:"anomaly_max_user_agents" gorm:"default:3"`
+}
+
+// Requires2FA reports whether the policy enforces two-factor authentication for the user role.
+func (s *System) Requires2FA(isAdmin bool) bool {
+	if isAdmin {
+		return s.Require2FAAdmins
+	}
+	return s.Require2FAStaffs
 }
 
 func (s *System) BeforeCreate(tx *gorm.DB) error {
@@ -7,26 +7,43 @@ import (
 )
 
 type User struct {
-	ID        uint        `json:"-" gorm:"primaryKey;autoIncrement" validate:"required"`
-	UID       string      `json:"uid" gorm:"type:varchar(26);not null;uniqueIndex" validate:"required"`
-	Username  string      `json:"username" gorm:"type:varchar(16);not null;uniqueIndex"  validate:"required"`
-	Password  string      `json:"-" gorm:"type:varchar(64); not null"`
-	IsAdmin   bool        `json:"is_admin" gorm:"type:bool;default(false)"  validate:"required"`
-	Salt      string      `json:"-" gorm:"type:varchar(8);not null"`
-	LastLogin *time.Time  `json:"last_login"  validate:"required"`
-	CreatedAt time.Time   `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
-	Token     []UserToken `json:"-"`
+	ID          uint        `json:"-" gorm:"primaryKey;autoIncrement" validate:"required"`
+	UID         string      `json:"uid" gorm:"type:varchar(26);not null;uniqueIndex" validate:"required"`
+	Username    string      `json:"username" gorm:"type:varchar(16);not null;uniqueIndex"  validate:"required"`
+	Password    string      `json:"-" gorm:"type:varchar(64); not null"`
+	IsAdmin     bool        `json:"is_admin" gorm:"type:bool;default(false)"  validate:"required"`
+	Salt        string      `json:"-" gorm:"type:varchar(8);not null"`
+	LastLogin   *time.Time  `json:"last_login"  validate:"required"`
+	TOTPSecret  string      `json:"-" gorm:"column:totp_secret;type:varchar(64);default:''"`
+	TOTPEnabled bool        `json:"totp_enabled" gorm:"column:totp_enabled;type:bool;default:false" validate:"required"`
+	TOTPCounter int64       `json:"-" gorm:"column:totp_counter;not null;default:0"`
+	CreatedAt   time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+	Token       []UserToken `json:"-"`
 }
 
+// UserRecoveryCode is a one-time 2FA recovery code. Only the hash of the code is stored.
+type UserRecoveryCode struct {
+	ID        uint       `json:"-" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"-" gorm:"index"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// UserToken is a dashboard login session. UID is the jti claim of the issued JWT.
 type UserToken struct {
-	ID        uint      `json:"-" gorm:"primaryKey;autoIncrement"`
-	UserID    uint      `json:"-" gorm:"index"`
-	UID       string    `json:"uid" gorm:"type:varchar(26);not null;uniqueIndex"`
-	Token     string    `json:"token" gorm:"type:text"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	ExpireAt  time.Time `json:"expire_at"`
-	User      User      `json:"user"`
+	ID         uint       `json:"-" gorm:"primaryKey;autoIncrement"`
+	UserID     uint       `json:"-" gorm:"index"`
+	UID        string     `json:"uid" gorm:"type:varchar(26);not null;uniqueIndex"`
+	Token      string     `json:"token" gorm:"type:text"`
+	IP         string     `json:"ip" gorm:"type:varchar(45);default:''"`
+	UserAgent  string     `json:"user_agent" gorm:"type:text;default:''"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	ExpireAt   time.Time  `json:"expire_at"`
+	User       User       `json:"user"`
 }
 
 type UsersLookup struct {
@@ -0,0 +1,25 @@
+package models
+
+import (
+	commonModels "github.com/mmtaee/ocserv-dashboard/common/models"
+	"time"
+)
+
+// UserQuota limits what a non-admin operator can create. A zero value on
+// any numeric field means unlimited and an empty AllowedGroups allows all groups.
+type UserQuota struct {
+	ID            uint                        `json:"-" gorm:"primaryKey;autoIncrement"`
+	UserID        uint                        `json:"-" gorm:"not null;uniqueIndex"`
+	MaxUsers      int                         `json:"max_users" gorm:"not null;default:0" validate:"required"`
+	MaxTrafficGiB int                         `json:"max_traffic_gib" gorm:"column:max_traffic_gib;not null;default:0" validate:"required"`
+	AllowedGroups *commonModels.CSVStringList `json:"allowed_groups" gorm:"type:text" validate:"required"`
+	MaxExpireDays int                         `json:"max_expire_days" gorm:"not null;default:0" validate:"required"`
+	CreatedAt     time.Time                   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time                   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// UserQuotaUsage is the current consumption of an operator against its quota.
+type UserQuotaUsage struct {
+	Users      int64 `json:"users" validate:"required"`
+	TrafficGiB int64 `json:"traffic_gib" validate:"required"`
+}
@@ -0,0 +1,240 @@
+package monitor
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/netdev"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	bandwidthInterval = 2 * time.Second
+	// sessionsRefresh bounds how often occtl is asked for the device to session mapping
+	// when no unknown vpns device shows up in between.
+	sessionsRefresh = 15 * time.Second
+)
+
+// InterfaceBandwidth is the current throughput of a network interface in bits per second.
+type InterfaceBandwidth struct {
+	Interface string  `json:"interface" validate:"required"`
+	RxBps     float64 `json:"rx_bps" validate:"required"`
+	TxBps     float64 `json:"tx_bps" validate:"required"`
+}
+
+// SessionBandwidth is the current throughput of an ocserv session. Rx is traffic
+// received from the client (upload), Tx is traffic sent to the client (download).
+type SessionBandwidth struct {
+	ID       int     `json:"id" validate:"required"`
+	Username string  `json:"username" validate:"required"`
+	Group    string  `json:"group" validate:"required"`
+	Device   string  `json:"device" validate:"required"`
+	RxBps    float64 `json:"rx_bps" validate:"required"`
+	TxBps    float64 `json:"tx_bps" validate:"required"`
+	RxBytes  uint64  `json:"rx_bytes" validate:"required"`
+	TxBytes  uint64  `json:"tx_bytes" validate:"required"`
+}
+
+type BandwidthSnapshot struct {
+	Time       time.Time            `json:"time" validate:"required"`
+	RxBps      float64              `json:"rx_bps" validate:"required" desc:"all interfaces except loopback"`
+	TxBps      float64              `json:"tx_bps" validate:"required" desc:"all interfaces except loopback"`
+	VpnRxBps   float64              `json:"vpn_rx_bps" validate:"required" desc:"sum of the vpns* devices"`
+	VpnTxBps   float64              `json:"vpn_tx_bps" validate:"required" desc:"sum of the vpns* devices"`
+	Interfaces []InterfaceBandwidth `json:"interfaces" validate:"required"`
+	Sessions   []SessionBandwidth   `json:"sessions" validate:"required"`
+}
+
+// BandwidthMonitor samples the interface counters every bandwidthInterval and maps
+// the ocserv tun devices to sessions. The latest snapshot is kept for the API and
+// broadcast to subscribers.
+type BandwidthMonitor struct {
+	occtlRepo repository.OcctlRepositoryInterface
+
+	mu          sync.RWMutex
+	snapshot    *BandwidthSnapshot
+	subscribers *broadcaster[*BandwidthSnapshot]
+
+	lastCounters  map[string]netdev.Counters
+	lastSampledAt time.Time
+	devices       map[string]models.OnlineUserSession
+	unmapped      map[string]struct{}
+	devicesAt     time.Time
+}
+
+var (
+	bandwidthOnce    sync.Once
+	bandwidthMonitor *BandwidthMonitor
+)
+
+// Bandwidth returns the process wide bandwidth monitor.
+func Bandwidth() *BandwidthMonitor {
+	bandwidthOnce.Do(func() {
+		bandwidthMonitor = &BandwidthMonitor{
+			occtlRepo:   repository.NewOcctlRepository(),
+			subscribers: newBroadcaster[*BandwidthSnapshot](),
+		}
+	})
+	return bandwidthMonitor
+}
+
+// Start samples until ctx is canceled.
+func (b *BandwidthMonitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(bandwidthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.subscribers.close()
+			return
+		case <-ticker.C:
+			snapshot, err := b.sample()
+			if err != nil {
+				logger.Warn("failed to sample bandwidth: %v", err)
+				continue
+			}
+			if snapshot != nil {
+				b.publish(snapshot)
+			}
+		}
+	}
+}
+
+// Snapshot returns the latest sample, or nil before the second tick.
+func (b *BandwidthMonitor) Snapshot() *BandwidthSnapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.snapshot
+}
+
+// Subscribe returns a channel receiving every new snapshot. Slow subscribers miss
+// samples instead of blocking the monitor. Call Unsubscribe when done.
+func (b *BandwidthMonitor) Subscribe() chan *BandwidthSnapshot {
+	return b.subscribers.subscribe(1)
+}
+
+func (b *BandwidthMonitor) Unsubscribe(ch chan *BandwidthSnapshot) {
+	b.subscribers.unsubscribe(ch)
+}
+
+func (b *BandwidthMonitor) publish(snapshot *BandwidthSnapshot) {
+	b.mu.Lock()
+	b.snapshot = snapshot
+	b.mu.Unlock()
+
+	b.subscribers.publish(snapshot)
+}
+
+func (b *BandwidthMonitor) sample() (*BandwidthSnapshot, error) {
+	counters, err := netdev.Read()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	prev, elapsed := b.lastCounters, now.Sub(b.lastSampledAt)
+	b.lastCounters, b.lastSampledAt = counters, now
+	if prev == nil {
+		return nil, nil
+	}
+
+	b.refreshDevices(counters, now)
+
+	snapshot := &BandwidthSnapshot{
+		Time:       now,
+		Interfaces: make([]InterfaceBandwidth, 0, len(counters)),
+		Sessions:   make([]SessionBandwidth, 0, len(b.devices)),
+	}
+
+	for name, current := range counters {
+		if name == "lo" {
+			continue
+		}
+		old, ok := prev[name]
+		if !ok {
+			continue
+		}
+
+		rx, tx := netdev.Rate(old, current, elapsed)
+		snapshot.Interfaces = append(snapshot.Interfaces, InterfaceBandwidth{Interface: name, RxBps: rx, TxBps: tx})
+		snapshot.RxBps += rx
+		snapshot.TxBps += tx
+
+		if !isVpnDevice(name) {
+			continue
+		}
+		snapshot.VpnRxBps += rx
+		snapshot.VpnTxBps += tx
+
+		if session, ok := b.devices[name]; ok {
+			snapshot.Sessions = append(snapshot.Sessions, SessionBandwidth{
+				ID:       int(session.ID),
+				Username: session.Username,
+				Group:    session.Group,
+				Device:   name,
+				RxBps:    rx,
+				TxBps:    tx,
+				RxBytes:  current.RxBytes,
+				TxBytes:  current.TxBytes,
+			})
+		}
+	}
+
+	sort.Slice(snapshot.Interfaces, func(i, j int) bool {
+		return snapshot.Interfaces[i].Interface < snapshot.Interfaces[j].Interface
+	})
+	sort.Slice(snapshot.Sessions, func(i, j int) bool {
+		return snapshot.Sessions[i].RxBps+snapshot.Sessions[i].TxBps > snapshot.Sessions[j].RxBps+snapshot.Sessions[j].TxBps
+	})
+	return snapshot, nil
+}
+
+// refreshDevices reloads the device to session mapping from occtl when it is stale
+// or a vpns device without a known session appeared.
+func (b *BandwidthMonitor) refreshDevices(counters map[string]netdev.Counters, now time.Time) {
+	stale := b.devicesAt.IsZero() || now.Sub(b.devicesAt) >= sessionsRefresh
+	if !stale {
+		for name := range counters {
+			_, mapped := b.devices[name]
+			_, unmapped := b.unmapped[name]
+			if isVpnDevice(name) && !mapped && !unmapped {
+				stale = true
+				break
+			}
+		}
+	}
+	if !stale {
+		return
+	}
+
+	b.devicesAt = now
+	if sessions, err := b.occtlRepo.OnlineUsersInfo(); err != nil {
+		logger.Warn("failed to load online sessions: %v", err)
+	} else {
+		b.devices = make(map[string]models.OnlineUserSession, len(*sessions))
+		for _, session := range *sessions {
+			// the sampled tun devices are the ones of this host, other servers reuse their names
+			if session.Device != "" && session.Server == driver.DefaultNode {
+				b.devices[session.Device] = session
+			}
+		}
+	}
+
+	// devices without a session (e.g. still authenticating) wait for the next regular refresh
+	b.unmapped = make(map[string]struct{})
+	for name := range counters {
+		if _, ok := b.devices[name]; isVpnDevice(name) && !ok {
+			b.unmapped[name] = struct{}{}
+		}
+	}
+}
+
+func isVpnDevice(name string) bool {
+	return strings.HasPrefix(name, "vpns")
+}
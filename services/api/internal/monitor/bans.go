@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	commonModels "github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/geoip"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"net"
+	"time"
+)
+
+const banPollInterval = 30 * time.Second
+
+// BanPoller records the ocserv ban list into the ban history, lifts bans of
+// allowlisted addresses and enforces the manual bans by disconnecting sessions
+// from banned addresses, on every poll and as soon as they connect. Only the
+// api replica holding the api_bans leader lease runs it.
+type BanPoller struct {
+	banRepo   repository.IPBanRepositoryInterface
+	occtlRepo repository.OcctlRepositoryInterface
+}
+
+func NewBanPoller() *BanPoller {
+	return &BanPoller{
+		banRepo:   repository.NewIPBanRepository(),
+		occtlRepo: repository.NewOcctlRepository(),
+	}
+}
+
+// Start polls until ctx, the leader term, is canceled.
+func (p *BanPoller) Start(ctx context.Context) {
+	ticker := time.NewTicker(banPollInterval)
+	defer ticker.Stop()
+
+	events := Events().Subscribe()
+	defer Events().Unsubscribe(events)
+
+	p.poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.poll(ctx)
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if event.Type == commonModels.OcctlEventConnect && event.RemoteIP != "" {
+				p.enforce(ctx, event.RemoteIP)
+			}
+		}
+	}
+}
+
+func (p *BanPoller) poll(ctx context.Context) {
+	allowlist, err := p.banRepo.Allowlist(ctx)
+	if err != nil {
+		logger.Error("failed to load IP allowlist: %v", err)
+		return
+	}
+
+	if bans, err := p.occtlRepo.IPBans(); err != nil {
+		logger.Warn("failed to list ocserv IP bans: %v", err)
+	} else {
+		now := time.Now()
+		for _, ban := range *bans {
+			history := &models.IPBanHistory{
+				IP:         ban.IP,
+				Country:    geoip.Country(ban.IP),
+				Score:      int(ban.Score),
+				LastSeenAt: now,
+			}
+			if allowlisted(allowlist, ban.IP) {
+				if _, err = p.occtlRepo.UnbanIP(ban.IP); err != nil {
+					logger.Error("failed to unban allowlisted IP %s: %v", ban.IP, err)
+				} else {
+					history.Unbanned = true
+				}
+			}
+			if err = p.banRepo.SaveBanHistory(ctx, history); err != nil {
+				logger.Error("failed to save IP ban history of %s: %v", ban.IP, err)
+			}
+		}
+	}
+
+	if err = p.banRepo.PurgeExpiredBans(ctx); err != nil {
+		logger.Error("failed to purge expired IP bans: %v", err)
+	}
+
+	active, err := p.banRepo.ActiveBans(ctx)
+	if err != nil {
+		logger.Error("failed to load IP bans: %v", err)
+		return
+	}
+	if len(active) == 0 {
+		return
+	}
+
+	sessions, err := p.occtlRepo.OnlineUsersInfo()
+	if err != nil {
+		logger.Warn("failed to list online sessions: %v", err)
+		return
+	}
+	banned := make(map[string]struct{}, len(active))
+	for _, ban := range active {
+		if !allowlisted(allowlist, ban.IP) {
+			banned[ban.IP] = struct{}{}
+		}
+	}
+	for _, session := range *sessions {
+		if _, ok := banned[session.RemoteIP]; ok {
+			p.disconnect(session.RemoteIP)
+			delete(banned, session.RemoteIP)
+		}
+	}
+}
+
+// enforce disconnects a new session when its address is banned and not allowlisted.
+func (p *BanPoller) enforce(ctx context.Context, ip string) {
+	banned, err := p.banRepo.IsBanned(ctx, ip)
+	if err != nil || !banned {
+		return
+	}
+	allowlist, err := p.banRepo.Allowlist(ctx)
+	if err != nil || allowlisted(allowlist, ip) {
+		return
+	}
+	p.disconnect(ip)
+}
+
+func (p *BanPoller) disconnect(ip string) {
+	count, err := p.occtlRepo.DisconnectIP(ip)
+	if err != nil {
+		logger.Error("failed to disconnect banned IP %s: %v", ip, err)
+		return
+	}
+	if count > 0 {
+		logger.Info("disconnected %d session(s) from banned IP %s", count, ip)
+	}
+}
+
+func allowlisted(allowlist []models.IPAllowlist, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, entry := range allowlist {
+		if entry.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package monitor
+
+import "sync"
+
+// broadcaster fans values out to subscribers. Slow subscribers miss values instead
+// of blocking the publisher.
+type broadcaster[T any] struct {
+	mu          sync.Mutex
+	subscribers map[chan T]struct{}
+}
+
+func newBroadcaster[T any]() *broadcaster[T] {
+	return &broadcaster[T]{subscribers: make(map[chan T]struct{})}
+}
+
+func (b *broadcaster[T]) subscribe(size int) chan T {
+	ch := make(chan T, size)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *broadcaster[T]) unsubscribe(ch chan T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (b *broadcaster[T]) publish(v T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- v:
+		default:
+		}
+	}
+}
+
+// close closes every subscriber channel, ending their streams.
+func (b *broadcaster[T]) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
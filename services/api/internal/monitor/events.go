@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"sync"
+)
+
+// EventsMonitor keeps a single `occtl show events` consumer running and broadcasts
+// the connect and disconnect events to subscribers.
+type EventsMonitor struct {
+	occtlRepo   repository.OcctlRepositoryInterface
+	subscribers *broadcaster[models.OcctlEvent]
+}
+
+var (
+	eventsOnce    sync.Once
+	eventsMonitor *EventsMonitor
+)
+
+// Events returns the process wide occtl events monitor.
+func Events() *EventsMonitor {
+	eventsOnce.Do(func() {
+		eventsMonitor = &EventsMonitor{
+			occtlRepo:   repository.NewOcctlRepository(),
+			subscribers: newBroadcaster[models.OcctlEvent](),
+		}
+	})
+	return eventsMonitor
+}
+
+// Start consumes events until ctx is canceled, restarting occtl when it exits.
+func (e *EventsMonitor) Start(ctx context.Context) {
+	events := make(chan models.OcctlEvent, 64)
+	go occtl.WatchEvents(ctx, e.occtlRepo, events)
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.subscribers.close()
+			return
+		case event := <-events:
+			e.subscribers.publish(event)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every new event. Slow subscribers miss
+// events instead of blocking the monitor. Call Unsubscribe when done.
+func (e *EventsMonitor) Subscribe() chan models.OcctlEvent {
+	return e.subscribers.subscribe(16)
+}
+
+func (e *EventsMonitor) Unsubscribe(ch chan models.OcctlEvent) {
+	e.subscribers.unsubscribe(ch)
+}
@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/netdev"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"time"
+)
+
+// Sampler periodically stores server resource metrics and maintains the hourly
+// rollups and retention of the server_metrics table.
+type Sampler struct {
+	cfg         config.MetricsConfig
+	metricsRepo repository.MetricsRepositoryInterface
+	occtlRepo   repository.OcctlRepositoryInterface
+	lastNet     map[string]netdev.Counters
+	lastNetAt   time.Time
+	lastRollup  time.Time
+}
+
+func NewSampler() *Sampler {
+	return &Sampler{
+		cfg:         config.Get().Metrics,
+		metricsRepo: repository.NewMetricsRepository(),
+		occtlRepo:   repository.NewOcctlRepository(),
+	}
+}
+
+// Start samples until ctx is canceled.
+func (s *Sampler) Start(ctx context.Context) {
+	logger.Info("Metrics sampler started (interval %s)", s.cfg.Interval)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	// prime the network counters so the first stored sample has throughput
+	s.lastNet, s.lastNetAt = netCounters(), time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Metrics sampler stopped")
+			return
+		case <-ticker.C:
+			s.tick(ctx)
+		}
+	}
+}
+
+func (s *Sampler) tick(ctx context.Context) {
+	metric := s.sample(ctx)
+	if err := s.metricsRepo.SaveMetric(ctx, metric); err != nil {
+		logger.Error("failed to save server metric: %v", err)
+	}
+
+	now := time.Now()
+	if now.Truncate(time.Hour).After(s.lastRollup) {
+		if err := s.metricsRepo.RollupHourly(ctx); err != nil {
+			logger.Error("failed to roll up server metrics: %v", err)
+			return
+		}
+		if err := s.metricsRepo.PurgeMetrics(
+			ctx,
+			now.Add(-s.cfg.RawRetention),
+			now.Add(-s.cfg.HourlyRetention),
+		); err != nil {
+			logger.Error("failed to purge server metrics: %v", err)
+			return
+		}
+		s.lastRollup = now.Truncate(time.Hour)
+	}
+}
+
+func (s *Sampler) sample(ctx context.Context) *models.ServerMetric {
+	metric := &models.ServerMetric{
+		Resolution: models.MetricResolutionRaw,
+		CreatedAt:  time.Now(),
+	}
+
+	if percents, err := cpu.PercentWithContext(ctx, time.Second, false); err == nil && len(percents) > 0 {
+		metric.CPUPercent = percents[0]
+	}
+	if vm, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+		metric.MemoryPercent = vm.UsedPercent
+		metric.MemoryUsed = int64(vm.Used)
+	}
+	if sw, err := mem.SwapMemoryWithContext(ctx); err == nil {
+		metric.SwapPercent = sw.UsedPercent
+	}
+	if usage, err := disk.UsageWithContext(ctx, "/"); err == nil {
+		metric.DiskPercent = usage.UsedPercent
+	}
+
+	current, now := netCounters(), time.Now()
+	if elapsed := now.Sub(s.lastNetAt); elapsed > 0 && s.lastNet != nil {
+		t := throughput(s.lastNet, current, elapsed)
+		metric.NetRxBps, metric.NetTxBps = t.netRx, t.netTx
+		metric.VpnRxBps, metric.VpnTxBps = t.vpnRx, t.vpnTx
+	}
+	s.lastNet, s.lastNetAt = current, now
+
+	if users, err := s.occtlRepo.OnlineUsers(); err == nil {
+		metric.OnlineSessions = float64(len(users))
+	}
+	return metric
+}
+
+// netCounters reads the interface counters the bandwidth monitor reads too, so
+// stored and live throughput are both bits per second of the same source.
+func netCounters() map[string]netdev.Counters {
+	counters, err := netdev.Read()
+	if err != nil {
+		logger.Warn("failed to read network counters: %v", err)
+		return nil
+	}
+	return counters
+}
+
+type rates struct {
+	netRx, netTx, vpnRx, vpnTx float64
+}
+
+// throughput returns bits per second between two counter snapshots. Interfaces
+// that appeared or disappeared are skipped, reset counters (e.g. vpns* devices of
+// reconnecting users) count as zero.
+func throughput(prev, current map[string]netdev.Counters, elapsed time.Duration) rates {
+	var r rates
+	for name, cur := range current {
+		if name == "lo" {
+			continue
+		}
+		old, ok := prev[name]
+		if !ok {
+			continue
+		}
+
+		rx, tx := netdev.Rate(old, cur, elapsed)
+		r.netRx += rx
+		r.netTx += tx
+		if isVpnDevice(name) {
+			r.vpnRx += rx
+			r.vpnTx += tx
+		}
+	}
+	return r
+}
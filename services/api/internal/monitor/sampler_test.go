@@ -0,0 +1,27 @@
+package monitor
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/netdev"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestThroughput(t *testing.T) {
+	prev := map[string]netdev.Counters{
+		"lo":    {RxBytes: 0, TxBytes: 0},
+		"eth0":  {RxBytes: 1000, TxBytes: 1000},
+		"vpns0": {RxBytes: 100, TxBytes: 100},
+		"vpns1": {RxBytes: 5000, TxBytes: 5000},
+	}
+	current := map[string]netdev.Counters{
+		"lo":    {RxBytes: 9000, TxBytes: 9000},
+		"eth0":  {RxBytes: 3000, TxBytes: 2000},
+		"vpns0": {RxBytes: 500, TxBytes: 300},
+		"vpns1": {RxBytes: 10, TxBytes: 10},
+		"vpns2": {RxBytes: 800, TxBytes: 800},
+	}
+
+	r := throughput(prev, current, 2*time.Second)
+	assert.Equal(t, rates{netRx: 9600, netTx: 4800, vpnRx: 1600, vpnTx: 800}, r)
+}
@@ -5,10 +5,13 @@ import (
 	backupRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/backup"
 	customerRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/customer"
 	homeRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/home"
+	ipBanRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/ip_ban"
+	jobRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/job"
 	occtlRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/occtl"
 	ocservGroupRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/ocserv_group"
 	ocservUserRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/ocserv_user"
 	reportRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/report"
+	serverRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/server"
 	systemRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/system"
 	systemdRoutes "github.com/mmtaee/ocserv-dashboard/api/internal/services/systemd"
 )
@@ -22,6 +25,12 @@ func Register(e *echo.Echo) {
 	occtlRoutes.Routes(group)
 	homeRoutes.Routes(group)
 
+	// ocserv servers
+	serverRoutes.Routes(group)
+
+	// ip bans
+	ipBanRoutes.Routes(group)
+
 	// backup
 	backupRoutes.Routes(group)
 
@@ -33,4 +42,7 @@ func Register(e *echo.Echo) {
 
 	// systemd
 	systemdRoutes.Routes(group)
+
+	// scheduled jobs
+	jobRoutes.Routes(group)
 }
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
@@ -30,8 +32,8 @@ type BackupRepositoryInterface interface {
 func NewBackupRepository() *BackupRepository {
 	return &BackupRepository{
 		db:                    database.GetConnection(),
-		commonOcservGroupRepo: group.NewOcservGroup(),
-		commonOcservUserRepo:  user.NewOcservUser(),
+		commonOcservGroupRepo: driver.Get().Groups(),
+		commonOcservUserRepo:  driver.Get().Users(),
 	}
 }
 
@@ -156,7 +158,8 @@ func (b *BackupRepository) OcservGroupRestore(ctx context.Context, owner string,
 			}
 
 			txErr := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-				res := tx.Create(&g)
+				// restored groups go to the ocserv of the configured driver
+				res := tx.Omit("Servers").Create(&g)
 				if res.Error != nil {
 					return res.Error
 				}
@@ -186,7 +189,7 @@ func (b *BackupRepository) OcservGroupRestore(ctx context.Context, owner string,
 	}
 
 	if len(errs) > 0 {
-		return &insertedNames, &dbExisting, fmt.Errorf(strings.Join(errs, "; "))
+		return &insertedNames, &dbExisting, errors.New(strings.Join(errs, "; "))
 	}
 
 	return &insertedNames, &dbExisting, nil
@@ -294,7 +297,8 @@ func (b *BackupRepository) OcservUserRestore(ctx context.Context, owner string,
 			}
 
 			txErr := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-				res := tx.Create(&u)
+				// restored users go to the ocserv of the configured driver
+				res := tx.Omit("Servers").Create(&u)
 				if res.Error != nil {
 					return res.Error
 				}
@@ -324,7 +328,7 @@ func (b *BackupRepository) OcservUserRestore(ctx context.Context, owner string,
 	}
 
 	if len(errs) > 0 {
-		return &insertedNames, &dbExisting, fmt.Errorf(strings.Join(errs, "; "))
+		return &insertedNames, &dbExisting, errors.New(strings.Join(errs, "; "))
 	}
 
 	return &insertedNames, &dbExisting, nil
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IPBanRepository struct {
+	db *gorm.DB
+}
+
+type IPBanManager interface {
+	Bans(ctx context.Context, pagination *request.Pagination) ([]models.IPBan, int64, error)
+	ActiveBans(ctx context.Context) ([]models.IPBan, error)
+	IsBanned(ctx context.Context, ip string) (bool, error)
+	CreateBan(ctx context.Context, ban *models.IPBan) (*models.IPBan, error)
+	DeleteBan(ctx context.Context, id string) error
+	PurgeExpiredBans(ctx context.Context) error
+}
+
+type IPAllowlistManager interface {
+	Allowlist(ctx context.Context) ([]models.IPAllowlist, error)
+	CreateAllowlist(ctx context.Context, entry *models.IPAllowlist) (*models.IPAllowlist, error)
+	DeleteAllowlist(ctx context.Context, id string) error
+}
+
+type IPBanHistoryManager interface {
+	BanHistory(ctx context.Context, pagination *request.Pagination) ([]models.IPBanHistory, int64, error)
+	SaveBanHistory(ctx context.Context, history *models.IPBanHistory) error
+}
+
+type IPBanRepositoryInterface interface {
+	IPBanManager
+	IPAllowlistManager
+	IPBanHistoryManager
+}
+
+func NewIPBanRepository() *IPBanRepository {
+	return &IPBanRepository{
+		db: database.GetConnection(),
+	}
+}
+
+func (r *IPBanRepository) Bans(ctx context.Context, pagination *request.Pagination) ([]models.IPBan, int64, error) {
+	var totalRecords int64
+	if err := r.db.WithContext(ctx).Model(&models.IPBan{}).Count(&totalRecords).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var bans []models.IPBan
+	if err := request.Paginator(ctx, r.db, pagination).Find(&bans).Error; err != nil {
+		return nil, 0, err
+	}
+	return bans, totalRecords, nil
+}
+
+// ActiveBans returns the bans that are permanent or not expired yet.
+func (r *IPBanRepository) ActiveBans(ctx context.Context) ([]models.IPBan, error) {
+	var bans []models.IPBan
+	err := r.db.WithContext(ctx).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&bans).Error
+	return bans, err
+}
+
+func (r *IPBanRepository) IsBanned(ctx context.Context, ip string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.IPBan{}).
+		Where("ip = ? AND (expires_at IS NULL OR expires_at > ?)", ip, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *IPBanRepository) CreateBan(ctx context.Context, ban *models.IPBan) (*models.IPBan, error) {
+	if err := r.db.WithContext(ctx).Create(ban).Error; err != nil {
+		return nil, err
+	}
+	return ban, nil
+}
+
+func (r *IPBanRepository) DeleteBan(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.IPBan{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *IPBanRepository) PurgeExpiredBans(ctx context.Context) error {
+	return r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now()).
+		Delete(&models.IPBan{}).Error
+}
+
+func (r *IPBanRepository) Allowlist(ctx context.Context) ([]models.IPAllowlist, error) {
+	var entries []models.IPAllowlist
+	err := r.db.WithContext(ctx).Order("id ASC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *IPBanRepository) CreateAllowlist(ctx context.Context, entry *models.IPAllowlist) (*models.IPAllowlist, error) {
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *IPBanRepository) DeleteAllowlist(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.IPAllowlist{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *IPBanRepository) BanHistory(ctx context.Context, pagination *request.Pagination) (
+	[]models.IPBanHistory, int64, error,
+) {
+	var totalRecords int64
+	if err := r.db.WithContext(ctx).Model(&models.IPBanHistory{}).Count(&totalRecords).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var history []models.IPBanHistory
+	if err := request.Paginator(ctx, r.db, pagination).Find(&history).Error; err != nil {
+		return nil, 0, err
+	}
+	return history, totalRecords, nil
+}
+
+// SaveBanHistory inserts the address on first sight and otherwise updates its
+// score and last seen time, keeping the first seen time and the highest score.
+func (r *IPBanRepository) SaveBanHistory(ctx context.Context, history *models.IPBanHistory) error {
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO ip_ban_histories (ip, country, score, max_score, unbanned, first_seen_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (ip) DO UPDATE SET
+			country = COALESCE(NULLIF(EXCLUDED.country, ''), ip_ban_histories.country),
+			score = EXCLUDED.score,
+			max_score = GREATEST(ip_ban_histories.max_score, EXCLUDED.score),
+			unbanned = EXCLUDED.unbanned,
+			last_seen_at = EXCLUDED.last_seen_at
+	`, history.IP, history.Country, history.Score, history.Score, history.Unbanned,
+		history.LastSeenAt, history.LastSeenAt).Error
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/schedule"
+	"gorm.io/gorm"
+	"time"
+)
+
+// JobStatus is a job with its next scheduled run, nil when paused, and its
+// latest run.
+type JobStatus struct {
+	models.Job
+	NextRunAt *time.Time     `json:"next_run_at" validate:"omitempty"`
+	LatestRun *models.JobRun `json:"latest_run" validate:"omitempty"`
+}
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+type JobRepositoryInterface interface {
+	Jobs(ctx context.Context) ([]JobStatus, error)
+	GetByName(ctx context.Context, name string) (*JobStatus, error)
+	Runs(ctx context.Context, name string, pagination *request.Pagination) ([]models.JobRun, int64, error)
+	Update(ctx context.Context, job *models.Job) (*JobStatus, error)
+	Trigger(ctx context.Context, name string) (*JobStatus, error)
+}
+
+func NewJobRepository() *JobRepository {
+	return &JobRepository{
+		db: database.GetConnection(),
+	}
+}
+
+func (r *JobRepository) Jobs(ctx context.Context) ([]JobStatus, error) {
+	var jobs []models.Job
+	if err := r.db.WithContext(ctx).Order("name").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+
+	statuses := make([]JobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		status, err := r.status(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+func (r *JobRepository) GetByName(ctx context.Context, name string) (*JobStatus, error) {
+	var job models.Job
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return r.status(ctx, job)
+}
+
+// Runs returns the runs of the job name, latest first unless ordered otherwise.
+func (r *JobRepository) Runs(ctx context.Context, name string, pagination *request.Pagination) ([]models.JobRun, int64, error) {
+	var job models.Job
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&job).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var totalRecords int64
+	if err := r.db.WithContext(ctx).Model(&models.JobRun{}).Where("job_name = ?", name).Count(&totalRecords).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []models.JobRun
+	err := request.Paginator(ctx, r.db, pagination).Where("job_name = ?", name).Find(&runs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return runs, totalRecords, nil
+}
+
+// Update saves the schedule and pause state of job, the leader of user_expiry
+// applies them within seconds.
+func (r *JobRepository) Update(ctx context.Context, job *models.Job) (*JobStatus, error) {
+	err := r.db.WithContext(ctx).Model(job).Select("schedule", "paused", "updated_at").Updates(job).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByName(ctx, job.Name)
+}
+
+// Trigger asks the leader of user_expiry to run the job name now, paused jobs
+// included.
+func (r *JobRepository) Trigger(ctx context.Context, name string) (*JobStatus, error) {
+	result := r.db.WithContext(ctx).Model(&models.Job{}).Where("name = ?", name).
+		Update("trigger_requested_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetByName(ctx, name)
+}
+
+// status adds the next and latest runs to job.
+func (r *JobRepository) status(ctx context.Context, job models.Job) (*JobStatus, error) {
+	status := &JobStatus{Job: job}
+
+	if spec, err := schedule.Parse(job.Schedule); err == nil && !job.Paused {
+		next := spec.Next(time.Now())
+		status.NextRunAt = &next
+	}
+
+	var runs []models.JobRun
+	err := r.db.WithContext(ctx).Where("job_name = ?", job.Name).Order("started_at DESC").Limit(1).Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 {
+		status.LatestRun = &runs[0]
+	}
+	return status, nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+type MetricsRepository struct {
+	db *gorm.DB
+}
+
+type MetricsWriter interface {
+	SaveMetric(ctx context.Context, metric *models.ServerMetric) error
+	RollupHourly(ctx context.Context) error
+	PurgeMetrics(ctx context.Context, rawBefore, hourlyBefore time.Time) error
+}
+
+type MetricsReader interface {
+	Metrics(ctx context.Context, resolution string, since time.Time, bucket time.Duration) ([]models.ServerMetric, error)
+}
+
+type MetricsRepositoryInterface interface {
+	MetricsWriter
+	MetricsReader
+}
+
+func NewMetricsRepository() *MetricsRepository {
+	return &MetricsRepository{
+		db: database.GetConnection(),
+	}
+}
+
+func (m *MetricsRepository) SaveMetric(ctx context.Context, metric *models.ServerMetric) error {
+	if metric.Resolution == "" {
+		metric.Resolution = models.MetricResolutionRaw
+	}
+	return m.db.WithContext(ctx).Create(metric).Error
+}
+
+// RollupHourly averages the raw samples of every complete hour that has no hourly row yet.
+func (m *MetricsRepository) RollupHourly(ctx context.Context) error {
+	return m.db.WithContext(ctx).Exec(`
+		INSERT INTO server_metrics (
+			resolution, created_at, cpu_percent, memory_percent, memory_used, swap_percent,
+			disk_percent, net_rx_bps, net_tx_bps, vpn_rx_bps, vpn_tx_bps, online_sessions
+		)
+		SELECT
+			?, date_trunc('hour', r.created_at) AS hour,
+			AVG(r.cpu_percent), AVG(r.memory_percent), AVG(r.memory_used)::BIGINT, AVG(r.swap_percent),
+			AVG(r.disk_percent), AVG(r.net_rx_bps), AVG(r.net_tx_bps), AVG(r.vpn_rx_bps), AVG(r.vpn_tx_bps),
+			AVG(r.online_sessions)
+		FROM server_metrics r
+		WHERE r.resolution = ?
+			AND r.created_at < date_trunc('hour', NOW())
+			AND NOT EXISTS (
+				SELECT 1 FROM server_metrics h
+				WHERE h.resolution = ? AND h.created_at = date_trunc('hour', r.created_at)
+			)
+		GROUP BY hour
+	`, models.MetricResolutionHour, models.MetricResolutionRaw, models.MetricResolutionHour).Error
+}
+
+func (m *MetricsRepository) PurgeMetrics(ctx context.Context, rawBefore, hourlyBefore time.Time) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("resolution = ? AND created_at < ?", models.MetricResolutionRaw, rawBefore).
+			Delete(&models.ServerMetric{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("resolution = ? AND created_at < ?", models.MetricResolutionHour, hourlyBefore).
+			Delete(&models.ServerMetric{}).Error
+	})
+}
+
+// Metrics returns the samples of a resolution since the given time averaged into buckets.
+func (m *MetricsRepository) Metrics(ctx context.Context, resolution string, since time.Time, bucket time.Duration) (
+	[]models.ServerMetric, error,
+) {
+	seconds := int64(bucket.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	var metrics []models.ServerMetric
+	err := m.db.WithContext(ctx).
+		Model(&models.ServerMetric{}).
+		Select(`
+			to_timestamp(floor(extract(epoch FROM created_at) / ?) * ?) AT TIME ZONE 'UTC' AS created_at,
+			AVG(cpu_percent) AS cpu_percent,
+			AVG(memory_percent) AS memory_percent,
+			AVG(memory_used)::BIGINT AS memory_used,
+			AVG(swap_percent) AS swap_percent,
+			AVG(disk_percent) AS disk_percent,
+			AVG(net_rx_bps) AS net_rx_bps,
+			AVG(net_tx_bps) AS net_tx_bps,
+			AVG(vpn_rx_bps) AS vpn_rx_bps,
+			AVG(vpn_tx_bps) AS vpn_tx_bps,
+			AVG(online_sessions) AS online_sessions
+		`, seconds, seconds).
+		Where("resolution = ? AND created_at >= ?", resolution, since).
+		Group("1").
+		Order("1").
+		Scan(&metrics).Error
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
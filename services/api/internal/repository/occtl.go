@@ -1,29 +1,46 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+	"strconv"
+	"sync"
+	"sync/atomic"
 )
 
+// OcctlRepository runs occtl on the ocserv of the configured driver. Online users,
+// disconnects and reloads go to every server, the other commands only to the
+// ocserv of the configured driver.
 type OcctlRepository struct {
+	db                    *gorm.DB
 	commonOcservOcctlRepo occtl.OcservOcctlInterface
 }
 
 type OcctlServerInfo interface {
 	Version() *models.ServerVersion
-	Status() (interface{}, error)
+	Status() (*models.ServerStatus, error)
 	ShowEvent() string
+	Events(ctx context.Context, out chan<- models.OcctlEvent) error
 }
 
 type OcctlUserManager interface {
 	OnlineUsers() ([]string, error)
 	OnlineUsersInfo() (*[]models.OnlineUserSession, error)
-	ShowUserByUsername(username string) (models.OnlineUserSession, error)
+	ShowUserByUsername(username string) ([]models.OnlineUserSession, error)
 	ShowUserByID(uid string) (models.OnlineUserSession, error)
-	ShowSessionsAll() (*[]interface{}, error)
-	ShowSessionsValid() (*[]interface{}, error)
-	ShowSessionBySID(sid string) (map[string]interface{}, error)
+	ShowSessionsAll() (*[]models.OcctlSession, error)
+	ShowSessionsValid() (*[]models.OcctlSession, error)
+	ShowSessionBySID(sid string) (*models.OcctlSession, error)
 	Disconnect(username string) (string, error)
+	DisconnectIP(ip string) (int, error)
+	DisconnectUserAgent(username, userAgent string) (int, error)
 }
 
 type OcctlSecurityManager interface {
@@ -40,36 +57,119 @@ type OcctlRepositoryInterface interface {
 }
 
 func NewOcctlRepository() *OcctlRepository {
-	return &OcctlRepository{commonOcservOcctlRepo: occtl.NewOcservOcctl()}
+	return &OcctlRepository{
+		db:                    database.GetConnection(),
+		commonOcservOcctlRepo: driver.Get().Occtl(),
+	}
+}
+
+// nodes returns the node of the configured driver and the nodes of the servers.
+func (o *OcctlRepository) nodes() []driver.Node {
+	if o.db == nil {
+		return driver.Nodes(nil)
+	}
+	nodes, err := allNodes(context.Background(), o.db)
+	if err != nil {
+		logger.Warn("failed to load ocserv servers: %v", err)
+		return driver.Nodes(nil)
+	}
+	return nodes
+}
+
+// eachNode calls fn on every node. It fails only when all of them failed, the
+// errors of the others are logged so one unreachable server does not hide the
+// results of the rest.
+func (o *OcctlRepository) eachNode(fn func(node driver.Node, d driver.Driver) error) error {
+	nodes := o.nodes()
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	_ = driver.FanOut(context.Background(), nodes, func(node driver.Node, d driver.Driver) error {
+		if err := fn(node, d); err != nil {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("server %s: %w", node.Name, err))
+			mu.Unlock()
+		}
+		return nil
+	})
+
+	if len(errs) == len(nodes) {
+		if len(errs) == 1 {
+			return errors.Unwrap(errs[0])
+		}
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		logger.Warn("occtl: %v", err)
+	}
+	return nil
 }
 
 func (o *OcctlRepository) Version() *models.ServerVersion {
 	return o.commonOcservOcctlRepo.Version()
 }
 
-func (o *OcctlRepository) Status() (interface{}, error) {
-	status, err := o.commonOcservOcctlRepo.ShowStatus(false)
+func (o *OcctlRepository) Status() (*models.ServerStatus, error) {
+	status, err := o.commonOcservOcctlRepo.ShowStatus()
 	if err != nil {
 		return nil, err
 	}
 	return status, nil
 }
 
+// OnlineUsers returns the usernames online on any server.
 func (o *OcctlRepository) OnlineUsers() ([]string, error) {
-	users, err := o.commonOcservOcctlRepo.OnlineUsers()
+	var (
+		mu    sync.Mutex
+		seen  = make(map[string]bool)
+		users = make([]string, 0)
+	)
+	err := o.eachNode(func(_ driver.Node, d driver.Driver) error {
+		online, err := d.Occtl().OnlineUsers()
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, username := range online {
+			if !seen[username] {
+				seen[username] = true
+				users = append(users, username)
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
+// OnlineUsersInfo returns the sessions of every server tagged with the server name.
 func (o *OcctlRepository) OnlineUsersInfo() (*[]models.OnlineUserSession, error) {
-	sessions, err := o.commonOcservOcctlRepo.OnlineSessions()
+	var (
+		mu       sync.Mutex
+		sessions = make([]models.OnlineUserSession, 0)
+	)
+	err := o.eachNode(func(node driver.Node, d driver.Driver) error {
+		nodeSessions, err := d.Occtl().OnlineSessions()
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, session := range *nodeSessions {
+			session.Server = node.Name
+			sessions = append(sessions, session)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return sessions, nil
+	return &sessions, nil
 }
 
 func (o *OcctlRepository) IPBans() (*[]models.IPBanPoints, error) {
@@ -89,28 +189,116 @@ func (o *OcctlRepository) IRoutes() (*[]models.IRoute, error) {
 	return iRoutes, nil
 }
 
+// Reload reloads the configs of every server.
 func (o *OcctlRepository) Reload() (string, error) {
-	result, err := o.commonOcservOcctlRepo.ReloadConfigs()
+	var result string
+	err := o.eachNode(func(node driver.Node, d driver.Driver) error {
+		res, err := d.Occtl().ReloadConfigs()
+		if err == nil && node.Server == nil {
+			result = res
+		}
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
 	return result, nil
 }
 
+// Disconnect disconnects username on every server.
 func (o *OcctlRepository) Disconnect(username string) (string, error) {
-	result, err := o.commonOcservOcctlRepo.DisconnectUser(username)
+	var (
+		mu     sync.Mutex
+		result string
+	)
+	err := o.eachNode(func(_ driver.Node, d driver.Driver) error {
+		res, err := d.Occtl().DisconnectUser(username)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		result = res
+		mu.Unlock()
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
 	return result, nil
 }
 
-func (o *OcctlRepository) ShowUserByUsername(username string) (models.OnlineUserSession, error) {
-	user, err := o.commonOcservOcctlRepo.ShowUser(username)
+// DisconnectIP disconnects every session connected from ip on every server and
+// returns how many were closed.
+func (o *OcctlRepository) DisconnectIP(ip string) (int, error) {
+	var disconnected atomic.Int64
+	err := o.eachNode(func(_ driver.Node, d driver.Driver) error {
+		sessions, err := d.Occtl().OnlineSessions()
+		if err != nil {
+			return err
+		}
+
+		for _, session := range *sessions {
+			if session.RemoteIP != ip {
+				continue
+			}
+			if _, err = d.Occtl().DisconnectID(strconv.Itoa(int(session.ID))); err != nil {
+				return err
+			}
+			disconnected.Add(1)
+		}
+		return nil
+	})
+	return int(disconnected.Load()), err
+}
+
+// DisconnectUserAgent disconnects the sessions of username opened by the userAgent client,
+// in its stored DeviceUserAgent form, on every server and returns how many were closed.
+func (o *OcctlRepository) DisconnectUserAgent(username, userAgent string) (int, error) {
+	var disconnected atomic.Int64
+	err := o.eachNode(func(_ driver.Node, d driver.Driver) error {
+		sessions, err := d.Occtl().ShowUser(username)
+		if err != nil {
+			return err
+		}
+
+		for _, session := range sessions {
+			if models.DeviceUserAgent(session.UserAgent) != userAgent {
+				continue
+			}
+			if _, err = d.Occtl().DisconnectID(strconv.Itoa(int(session.ID))); err != nil {
+				return err
+			}
+			disconnected.Add(1)
+		}
+		return nil
+	})
+	return int(disconnected.Load()), err
+}
+
+// ShowUserByUsername returns the sessions of username on every server tagged
+// with the server name.
+func (o *OcctlRepository) ShowUserByUsername(username string) ([]models.OnlineUserSession, error) {
+	var (
+		mu       sync.Mutex
+		sessions = make([]models.OnlineUserSession, 0)
+	)
+	err := o.eachNode(func(node driver.Node, d driver.Driver) error {
+		nodeSessions, err := d.Occtl().ShowUser(username)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, session := range nodeSessions {
+			session.Server = node.Name
+			sessions = append(sessions, session)
+		}
+		return nil
+	})
 	if err != nil {
-		return models.OnlineUserSession{}, err
+		return nil, err
 	}
-	return user, nil
+	return sessions, nil
 }
 
 func (o *OcctlRepository) ShowUserByID(uid string) (models.OnlineUserSession, error) {
@@ -121,7 +309,7 @@ func (o *OcctlRepository) ShowUserByID(uid string) (models.OnlineUserSession, er
 	return user, nil
 }
 
-func (o *OcctlRepository) ShowSessionsAll() (*[]interface{}, error) {
+func (o *OcctlRepository) ShowSessionsAll() (*[]models.OcctlSession, error) {
 	res, err := o.commonOcservOcctlRepo.ShowSessionAll()
 	if err != nil {
 		return nil, err
@@ -129,7 +317,7 @@ func (o *OcctlRepository) ShowSessionsAll() (*[]interface{}, error) {
 	return res, nil
 }
 
-func (o *OcctlRepository) ShowSessionsValid() (*[]interface{}, error) {
+func (o *OcctlRepository) ShowSessionsValid() (*[]models.OcctlSession, error) {
 	res, err := o.commonOcservOcctlRepo.ShowSessionsValid()
 	if err != nil {
 		return nil, err
@@ -137,7 +325,7 @@ func (o *OcctlRepository) ShowSessionsValid() (*[]interface{}, error) {
 	return res, nil
 }
 
-func (o *OcctlRepository) ShowSessionBySID(sid string) (map[string]interface{}, error) {
+func (o *OcctlRepository) ShowSessionBySID(sid string) (*models.OcctlSession, error) {
 	res, err := o.commonOcservOcctlRepo.ShowSession(sid)
 	if err != nil {
 		return nil, err
@@ -156,3 +344,7 @@ func (o *OcctlRepository) UnbanIP(ip string) (string, error) {
 func (o *OcctlRepository) ShowEvent() string {
 	return o.commonOcservOcctlRepo.ShowEvent()
 }
+
+func (o *OcctlRepository) Events(ctx context.Context, out chan<- models.OcctlEvent) error {
+	return o.commonOcservOcctlRepo.Events(ctx, out)
+}
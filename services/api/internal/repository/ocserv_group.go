@@ -4,9 +4,11 @@ import (
 	"context"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
 	"gorm.io/gorm"
 )
 
@@ -44,8 +46,8 @@ type OcservGroupRepositoryInterface interface {
 func NewOcservGroupRepository() *OcservGroupRepository {
 	return &OcservGroupRepository{
 		db:                    database.GetConnection(),
-		commonOcservGroupRepo: group.NewOcservGroup(),
-		commonOcservOcctlRepo: occtl.NewOcservOcctl(),
+		commonOcservGroupRepo: driver.Get().Groups(),
+		commonOcservOcctlRepo: driver.Get().Occtl(),
 	}
 }
 
@@ -70,7 +72,7 @@ func (o *OcservGroupRepository) Groups(ctx context.Context, pagination *request.
 	if owner != "" {
 		query = query.Where("owner = ?", owner)
 	}
-	err = query.Find(&ocservGroups).Error
+	err = query.Preload("Servers").Find(&ocservGroups).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -99,52 +101,77 @@ func (o *OcservGroupRepository) GroupsLookup(ctx context.Context, owner string)
 
 func (o *OcservGroupRepository) GetByID(ctx context.Context, id string) (*models.OcservGroup, error) {
 	var ocservGroup models.OcservGroup
-	err := o.db.WithContext(ctx).Where("id = ?", id).First(&ocservGroup).Error
+	err := o.db.WithContext(ctx).Preload("Servers").Where("id = ?", id).First(&ocservGroup).Error
 	if err != nil {
 		return nil, err
 	}
 	return &ocservGroup, nil
 }
 
+// Create adds the group to the database and to the ocserv of each of its servers.
 func (o *OcservGroupRepository) Create(ctx context.Context, ocservGroup *models.OcservGroup) (*models.OcservGroup, error) {
+	nodes := driver.Nodes(ocservGroup.Servers)
 	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(ocservGroup).Error; err != nil {
+		if err := tx.Omit("Servers.*").Create(ocservGroup).Error; err != nil {
 			return err
 		}
-		if err := o.commonOcservGroupRepo.Create(ocservGroup.Name, ocservGroup.Config); err != nil {
-			return err
-		}
-		return nil
+		return driver.FanOut(ctx, nodes, func(_ driver.Node, d driver.Driver) error {
+			return d.Groups().Create(ocservGroup.Name, ocservGroup.Config)
+		})
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	go func() {
-		_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
-	}()
+	reloadNodes(nodes)
 
 	return ocservGroup, nil
 }
 
+// Update saves the group and writes it to the ocserv of each of its servers. A
+// nil Servers keeps the servers of the group, otherwise the group is moved to
+// Servers and removed from the servers it is no longer assigned to.
 func (o *OcservGroupRepository) Update(ctx context.Context, ocservGroup *models.OcservGroup) (*models.OcservGroup, error) {
+	var dropped []driver.Node
 	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Model(ocservGroup).Save(ocservGroup).Error; err != nil {
+		var current []models.Server
+		if err := tx.Model(ocservGroup).Association("Servers").Find(&current); err != nil {
 			return err
 		}
-		if err := o.commonOcservGroupRepo.Create(ocservGroup.Name, ocservGroup.Config); err != nil {
+		if ocservGroup.Servers == nil {
+			ocservGroup.Servers = current
+		} else {
+			if err := tx.Model(ocservGroup).Omit("Servers.*").Association("Servers").Replace(ocservGroup.Servers); err != nil {
+				return err
+			}
+			dropped = droppedNodes(current, ocservGroup.Servers)
+		}
+
+		if err := tx.Model(ocservGroup).Omit("Servers").Save(ocservGroup).Error; err != nil {
 			return err
 		}
-		return nil
+		return driver.FanOut(ctx, driver.Nodes(ocservGroup.Servers), func(_ driver.Node, d driver.Driver) error {
+			return d.Groups().Create(ocservGroup.Name, ocservGroup.Config)
+		})
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	go func() {
-		_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
-	}()
+	if len(dropped) > 0 {
+		name := ocservGroup.Name
+		go func() {
+			if err := driver.FanOut(context.Background(), dropped, func(_ driver.Node, d driver.Driver) error {
+				return d.Groups().Delete(name)
+			}); err != nil {
+				logger.Warn("failed to remove ocserv group %s from its old servers: %v", name, err)
+			}
+			reloadNodes(dropped)
+		}()
+	}
+
+	reloadNodes(driver.Nodes(ocservGroup.Servers))
 
 	return ocservGroup, nil
 }
@@ -152,7 +179,7 @@ func (o *OcservGroupRepository) Update(ctx context.Context, ocservGroup *models.
 func (o *OcservGroupRepository) Delete(ctx context.Context, id string) (*models.OcservGroup, error) {
 	var ocservGroup models.OcservGroup
 	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("id = ?", id).First(&ocservGroup).Error; err != nil {
+		if err := tx.Preload("Servers").Where("id = ?", id).First(&ocservGroup).Error; err != nil {
 			return err
 		}
 
@@ -160,13 +187,14 @@ func (o *OcservGroupRepository) Delete(ctx context.Context, id string) (*models.
 			return err
 		}
 
-		if err := o.commonOcservGroupRepo.Delete(ocservGroup.Name); err != nil {
+		nodes := driver.Nodes(ocservGroup.Servers)
+		if err := driver.FanOut(ctx, nodes, func(_ driver.Node, d driver.Driver) error {
+			return d.Groups().Delete(ocservGroup.Name)
+		}); err != nil {
 			return err
 		}
 
-		go func() {
-			_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
-		}()
+		reloadNodes(nodes)
 
 		return nil
 	})
@@ -182,15 +210,21 @@ func (o *OcservGroupRepository) DefaultGroup() (*models.OcservGroupConfig, error
 	return defaultsGroup, nil
 }
 
+// UpdateDefaultGroup writes the defaults group of every server.
 func (o *OcservGroupRepository) UpdateDefaultGroup(groupConfig *models.OcservGroupConfig) error {
-	err := o.commonOcservGroupRepo.UpdateDefaultsGroup(groupConfig)
+	nodes, err := allNodes(context.Background(), o.db)
 	if err != nil {
 		return err
 	}
 
-	go func() {
-		_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
-	}()
+	err = driver.FanOut(context.Background(), nodes, func(_ driver.Node, d driver.Driver) error {
+		return d.Groups().UpdateDefaultsGroup(groupConfig)
+	})
+	if err != nil {
+		return err
+	}
+
+	reloadNodes(nodes)
 	return nil
 }
 
@@ -4,9 +4,10 @@ import (
 	"context"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
-	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
 	"gorm.io/gorm"
 	"strings"
 	"time"
@@ -28,18 +29,21 @@ type OcpasswdUser struct {
 }
 
 type OcservUserRepository struct {
-	db                    *gorm.DB
-	commonOcservUserRepo  user.OcservUserInterface
-	commonOcservOcctlRepo occtl.OcservOcctlInterface
+	db                   *gorm.DB
+	commonOcservUserRepo user.OcservUserInterface
 }
 
+// SaveCheck validates ocservUser in tx, the transaction saving it. The save is
+// rolled back when it fails.
+type SaveCheck func(tx *gorm.DB, ocservUser *models.OcservUser) error
+
 type OcservUserCRUD interface {
 	Users(ctx context.Context, pagination *request.Pagination, owner string, q string, filters string) ([]models.OcservUser, int64, error)
 	UsersByUsername(ctx context.Context, pagination *request.Pagination, owner string, usernames []string, q string) ([]models.OcservUser, int64, error)
-	Create(ctx context.Context, user *models.OcservUser) (*models.OcservUser, error)
+	Create(ctx context.Context, user *models.OcservUser, checks ...SaveCheck) (*models.OcservUser, error)
 	GetByUID(ctx context.Context, uid string) (*models.OcservUser, error)
 	GetByUsername(ctx context.Context, username string) (*models.OcservUser, error)
-	Update(ctx context.Context, ocservUser *models.OcservUser) (*models.OcservUser, error)
+	Update(ctx context.Context, ocservUser *models.OcservUser, checks ...SaveCheck) (*models.OcservUser, error)
 	Delete(ctx context.Context, uid string) (string, error)
 }
 
@@ -75,9 +79,8 @@ type OcservUserRepositoryInterface interface {
 
 func NewtOcservUserRepository() *OcservUserRepository {
 	return &OcservUserRepository{
-		db:                    database.GetConnection(),
-		commonOcservUserRepo:  user.NewOcservUser(),
-		commonOcservOcctlRepo: occtl.NewOcservOcctl(),
+		db:                   database.GetConnection(),
+		commonOcservUserRepo: driver.Get().Users(),
 	}
 }
 
@@ -122,7 +125,7 @@ func (o *OcservUserRepository) Users(
 	txPaginator := request.Paginator(ctx, o.db, pagination)
 
 	query := applyFilters(txPaginator.Model(&ocservUser))
-	if err := query.Find(&ocservUser).Error; err != nil {
+	if err := query.Preload("Servers").Find(&ocservUser).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -169,39 +172,54 @@ func (o *OcservUserRepository) UsersByUsername(
 
 	queryDB = request.Paginator(ctx, queryDB, pagination)
 
-	if err := queryDB.Find(&ocservUser).Error; err != nil {
+	if err := queryDB.Preload("Servers").Find(&ocservUser).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return ocservUser, totalRecords, nil
 }
 
-func (o *OcservUserRepository) Create(ctx context.Context, ocservUser *models.OcservUser) (*models.OcservUser, error) {
+// Create adds the user to the database, once checks pass in the same transaction,
+// and then to the ocserv of each of its servers. When a server fails, the user is
+// removed from the others and from the database.
+func (o *OcservUserRepository) Create(ctx context.Context, ocservUser *models.OcservUser, checks ...SaveCheck) (*models.OcservUser, error) {
 	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(ocservUser).Error; err != nil {
-			return err
-		}
-		if err := o.commonOcservUserRepo.Create(ocservUser.Group, ocservUser.Username, ocservUser.Password, ocservUser.Config); err != nil {
+		if err := runChecks(tx, ocservUser, checks); err != nil {
 			return err
 		}
-		return nil
+		return tx.Omit("Servers.*").Create(ocservUser).Error
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	nodes := driver.Nodes(ocservUser.Servers)
+	err = syncNodes(ctx, nodes,
+		func(_ driver.Node, d driver.Driver) error {
+			return d.Users().Create(ocservUser.Group, ocservUser.Username, ocservUser.Password, ocservUser.Config)
+		},
+		func(_ driver.Node, d driver.Driver) error {
+			_, err := d.Users().Delete(ocservUser.Username)
+			return err
+		},
+		func() error {
+			return o.db.WithContext(context.WithoutCancel(ctx)).Delete(&models.OcservUser{}, ocservUser.ID).Error
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	if ocservUser.Config != nil {
-		go func() {
-			_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
-		}()
+		reloadNodes(nodes)
 	}
 
-	return ocservUser, err
+	return ocservUser, nil
 }
 
 func (o *OcservUserRepository) GetByUID(ctx context.Context, uid string) (*models.OcservUser, error) {
 	var ocservUser models.OcservUser
-	err := o.db.WithContext(ctx).Where("uid = ?", uid).First(&ocservUser).Error
+	err := o.db.WithContext(ctx).Preload("Servers").Where("uid = ?", uid).First(&ocservUser).Error
 	if err != nil {
 		return nil, err
 	}
@@ -210,105 +228,202 @@ func (o *OcservUserRepository) GetByUID(ctx context.Context, uid string) (*model
 
 func (o *OcservUserRepository) GetByUsername(ctx context.Context, username string) (*models.OcservUser, error) {
 	var ocservUser models.OcservUser
-	err := o.db.WithContext(ctx).Where("username = ?", username).First(&ocservUser).Error
+	err := o.db.WithContext(ctx).Preload("Servers").Where("username = ?", username).First(&ocservUser).Error
 	if err != nil {
 		return nil, err
 	}
 	return &ocservUser, nil
 }
 
-func (o *OcservUserRepository) Update(ctx context.Context, ocservUser *models.OcservUser) (*models.OcservUser, error) {
-	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Save(&ocservUser).Error; err != nil {
-			return err
-		}
-		if err := o.commonOcservUserRepo.Create(ocservUser.Group, ocservUser.Username, ocservUser.Password, ocservUser.Config); err != nil {
+// Update saves the user, once checks pass in the same transaction, and then writes
+// it to the ocserv of each of its servers. A nil Servers keeps the servers of the
+// user, otherwise the user is moved to Servers and removed from the servers it is
+// no longer assigned to. When a server fails, the previous user is restored on the
+// others and in the database.
+func (o *OcservUserRepository) Update(ctx context.Context, ocservUser *models.OcservUser, checks ...SaveCheck) (*models.OcservUser, error) {
+	var previous models.OcservUser
+	if err := o.db.WithContext(ctx).Preload("Servers").First(&previous, ocservUser.ID).Error; err != nil {
+		return nil, err
+	}
+	if ocservUser.Servers == nil {
+		ocservUser.Servers = previous.Servers
+	}
+
+	if err := o.save(ctx, ocservUser, checks...); err != nil {
+		return nil, err
+	}
+
+	previousNodes := make(map[uint]bool)
+	for _, node := range driver.Nodes(previous.Servers) {
+		previousNodes[nodeID(node)] = true
+	}
+	err := syncNodes(ctx, driver.Nodes(ocservUser.Servers),
+		func(_ driver.Node, d driver.Driver) error {
+			return d.Users().Create(ocservUser.Group, ocservUser.Username, ocservUser.Password, ocservUser.Config)
+		},
+		func(node driver.Node, d driver.Driver) error {
+			if previousNodes[nodeID(node)] {
+				return d.Users().Create(previous.Group, previous.Username, previous.Password, previous.Config)
+			}
+			_, err := d.Users().Delete(ocservUser.Username)
 			return err
-		}
-		return nil
-	})
+		},
+		func() error {
+			return o.save(context.WithoutCancel(ctx), &previous)
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	if ocservUser.Config != nil {
+	if dropped := droppedNodes(previous.Servers, ocservUser.Servers); len(dropped) > 0 {
+		username := ocservUser.Username
 		go func() {
-			_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
+			if err := driver.FanOut(context.Background(), dropped, func(_ driver.Node, d driver.Driver) error {
+				_, _ = d.Occtl().DisconnectUser(username)
+				_, err := d.Users().Delete(username)
+				return err
+			}); err != nil {
+				logger.Warn("failed to remove ocserv user %s from its old servers: %v", username, err)
+			}
 		}()
 	}
 
+	if ocservUser.Config != nil {
+		reloadNodes(driver.Nodes(ocservUser.Servers))
+	}
+
 	return ocservUser, nil
 }
 
-func (o *OcservUserRepository) Lock(ctx context.Context, uid string) error {
-	var ocservUser models.OcservUser
-	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("uid = ?", uid).First(&ocservUser).Error; err != nil {
+// save writes ocservUser and its servers to the database once checks pass.
+func (o *OcservUserRepository) save(ctx context.Context, ocservUser *models.OcservUser, checks ...SaveCheck) error {
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := runChecks(tx, ocservUser, checks); err != nil {
 			return err
 		}
-		if err := tx.
-			Model(&models.OcservUser{}).
-			Where("uid = ?", uid).
-			Updates(map[string]interface{}{"is_locked": true}).Error; err != nil {
+		if err := tx.Model(ocservUser).Omit("Servers.*").Association("Servers").Replace(ocservUser.Servers); err != nil {
 			return err
 		}
+		return tx.Omit("Servers").Save(ocservUser).Error
+	})
+}
 
-		if _, err := o.commonOcservUserRepo.Lock(ocservUser.Username); err != nil {
+func runChecks(tx *gorm.DB, ocservUser *models.OcservUser, checks []SaveCheck) error {
+	for _, check := range checks {
+		if err := check(tx, ocservUser); err != nil {
 			return err
 		}
-		return nil
-	})
-	return err
+	}
+	return nil
+}
+
+// Lock locks the user in the database and then on the ocserv of each of its
+// servers. When a server fails, the user is unlocked again.
+func (o *OcservUserRepository) Lock(ctx context.Context, uid string) error {
+	return o.setLocked(ctx, uid, true)
 }
 
+// UnLock unlocks the user in the database and then on the ocserv of each of its
+// servers. When a server fails, the user is locked again.
 func (o *OcservUserRepository) UnLock(ctx context.Context, uid string) error {
+	return o.setLocked(ctx, uid, false)
+}
+
+func (o *OcservUserRepository) setLocked(ctx context.Context, uid string, locked bool) error {
 	var ocservUser models.OcservUser
-	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("uid = ?", uid).First(&ocservUser).Error; err != nil {
-			return err
-		}
-		if err := tx.
+	if err := o.db.WithContext(ctx).Preload("Servers").Where("uid = ?", uid).First(&ocservUser).Error; err != nil {
+		return err
+	}
+
+	update := func(ctx context.Context, locked bool, reason string) error {
+		return o.db.WithContext(ctx).
 			Model(&models.OcservUser{}).
 			Where("uid = ?", uid).
-			Updates(map[string]interface{}{"is_locked": false}).Error; err != nil {
-			return err
-		}
+			Updates(map[string]interface{}{"is_locked": locked, "lock_reason": reason}).Error
+	}
+	reason := ""
+	if locked {
+		reason = models.LockReasonManual
+	}
+	if err := update(ctx, locked, reason); err != nil {
+		return err
+	}
+
+	return syncNodes(ctx, driver.Nodes(ocservUser.Servers),
+		func(_ driver.Node, d driver.Driver) error {
+			return lockOcservUser(d, ocservUser.Username, locked)
+		},
+		func(_ driver.Node, d driver.Driver) error {
+			return lockOcservUser(d, ocservUser.Username, ocservUser.IsLocked)
+		},
+		func() error {
+			return update(context.WithoutCancel(ctx), ocservUser.IsLocked, ocservUser.LockReason)
+		},
+	)
+}
 
-		if _, err := o.commonOcservUserRepo.UnLock(ocservUser.Username); err != nil {
-			return err
-		}
-		return nil
-	})
+func lockOcservUser(d driver.Driver, username string, locked bool) error {
+	var err error
+	if locked {
+		_, err = d.Users().Lock(username)
+	} else {
+		_, err = d.Users().UnLock(username)
+	}
 	return err
 }
 
+// Delete removes the user from the ocserv of each of its servers and then from
+// the database. Unlike the other changes, the servers go first: deleting the row
+// cascades to the traffic statistics of the user, which a rollback could not
+// restore. When a server fails, the user is created again on the others.
 func (o *OcservUserRepository) Delete(ctx context.Context, uid string) (string, error) {
 	var ocservUser models.OcservUser
-	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("uid = ?", uid).First(&ocservUser).Error; err != nil {
+	if err := o.db.WithContext(ctx).Preload("Servers").Where("uid = ?", uid).First(&ocservUser).Error; err != nil {
+		return "", err
+	}
+
+	nodes := driver.Nodes(ocservUser.Servers)
+	restore := func(_ driver.Node, d driver.Driver) error {
+		if err := d.Users().Create(ocservUser.Group, ocservUser.Username, ocservUser.Password, ocservUser.Config); err != nil {
 			return err
 		}
-		if err := tx.Delete(&ocservUser).Error; err != nil {
-			return err
+		if ocservUser.IsLocked {
+			return lockOcservUser(d, ocservUser.Username, true)
 		}
-		if _, err := o.commonOcservUserRepo.Delete(ocservUser.Username); err != nil {
+		return nil
+	}
+	err := syncNodes(ctx, nodes,
+		func(_ driver.Node, d driver.Driver) error {
+			_, err := d.Users().Delete(ocservUser.Username)
 			return err
+		},
+		restore,
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err = o.db.WithContext(ctx).Delete(&ocservUser).Error; err != nil {
+		if restoreErr := driver.FanOut(context.WithoutCancel(ctx), nodes, restore); restoreErr != nil {
+			logger.FromContext(ctx).Error("failed to restore ocserv user %s on its servers: %v", ocservUser.Username, restoreErr)
 		}
-		return nil
-	})
+		return "", err
+	}
 
-	go func() {
-		_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
-	}()
+	reloadNodes(nodes)
 
-	return ocservUser.Username, err
+	return ocservUser.Username, nil
 }
 
+// UpdateUsersByDeleteGroup moves the users of groupName to the defaults group and
+// reloads the ocserv configs of their servers.
 func (o *OcservUserRepository) UpdateUsersByDeleteGroup(ctx context.Context, groupName string) ([]models.OcservUser, error) {
 	var users []models.OcservUser
 
 	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("`group` = ?", groupName).Select("id", "group", "username").Find(&users).Error; err != nil {
+		if err := tx.Where("`group` = ?", groupName).Select("id", "group", "username").Preload("Servers").Find(&users).Error; err != nil {
 			return err
 		}
 
@@ -321,9 +436,9 @@ func (o *OcservUserRepository) UpdateUsersByDeleteGroup(ctx context.Context, gro
 		return nil
 	})
 
-	go func() {
-		_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
-	}()
+	if err == nil && len(users) > 0 {
+		reloadNodes(usersNodes(users))
+	}
 
 	return users, err
 }
@@ -459,46 +574,65 @@ func (o *OcservUserRepository) OcpasswdSyncToDB(ctx context.Context, users []mod
 		return nil, err
 	}
 
-	// Reload configs if any user has a custom config
+	// Reload the configs of the servers of the users with a custom config
+	var custom []models.OcservUser
 	for _, u := range users {
 		if u.Config != nil {
-			go func() {
-				_, _ = o.commonOcservOcctlRepo.ReloadConfigs()
-			}()
-			break
+			custom = append(custom, u)
 		}
 	}
+	if len(custom) > 0 {
+		reloadNodes(usersNodes(custom))
+	}
 
 	return users, nil
 }
 
+// RestoreExpired unlocks the user uid with the new expiry date and resets its
+// traffic. The renewal anchors the billing cycle of the user at today. The user
+// is saved first and then unlocked on its servers; when a server fails, the user
+// is locked again on the others and restored in the database.
 func (o *OcservUserRepository) RestoreExpired(ctx context.Context, uid string, expireAt *time.Time) error {
-	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var u models.OcservUser
-		if err := tx.
-			Where("uid = ?", uid).
-			First(&u).Error; err != nil {
-			return err
-		}
-
-		if _, err := o.commonOcservUserRepo.UnLock(u.Username); err != nil {
-			return err
-		}
-
-		if err := tx.
-			Model(&u).
-			Updates(map[string]interface{}{
-				"expire_at":      expireAt,
-				"deactivated_at": nil,
-				"is_locked":      false,
-				"rx":             0,
-				"tx":             0,
-			}).Error; err != nil {
-			return err
-		}
+	var u models.OcservUser
+	if err := o.db.WithContext(ctx).Preload("Servers").Where("uid = ?", uid).First(&u).Error; err != nil {
+		return err
+	}
 
-		return nil
-	})
+	previous := map[string]interface{}{
+		"expire_at":      u.ExpireAt,
+		"deactivated_at": u.DeactivatedAt,
+		"is_locked":      u.IsLocked,
+		"lock_reason":    u.LockReason,
+		"rx":             u.Rx,
+		"tx":             u.Tx,
+		"cycle_anchor":   u.CycleAnchor,
+	}
+	if err := o.db.WithContext(ctx).
+		Model(&models.OcservUser{}).
+		Where("id = ?", u.ID).
+		Updates(map[string]interface{}{
+			"expire_at":      expireAt,
+			"deactivated_at": nil,
+			"is_locked":      false,
+			"lock_reason":    "",
+			"rx":             0,
+			"tx":             0,
+			"cycle_anchor":   time.Now(),
+		}).Error; err != nil {
+		return err
+	}
+
+	return syncNodes(ctx, driver.Nodes(u.Servers),
+		func(_ driver.Node, d driver.Driver) error {
+			return lockOcservUser(d, u.Username, false)
+		},
+		func(_ driver.Node, d driver.Driver) error {
+			return lockOcservUser(d, u.Username, u.IsLocked)
+		},
+		func() error {
+			return o.db.WithContext(context.WithoutCancel(ctx)).Model(&models.OcservUser{}).Where("id = ?", u.ID).Updates(previous).Error
+		},
+	)
 }
 
 func (o *OcservUserRepository) UserSessionLogs(
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OcservUserDeviceRepository struct {
+	db *gorm.DB
+}
+
+type OcservUserDeviceRepositoryInterface interface {
+	Devices(ctx context.Context, ocUserID uint) ([]models.OcservUserDevice, error)
+	RevokeDevice(ctx context.Context, ocUserID uint, id string) (*models.OcservUserDevice, error)
+	UnblockDevice(ctx context.Context, ocUserID uint, maxDevices int, id string) (*models.OcservUserDevice, error)
+	DeleteDevice(ctx context.Context, ocUserID uint, id string) error
+}
+
+func NewOcservUserDeviceRepository() *OcservUserDeviceRepository {
+	return &OcservUserDeviceRepository{
+		db: database.GetConnection(),
+	}
+}
+
+func (r *OcservUserDeviceRepository) Devices(ctx context.Context, ocUserID uint) ([]models.OcservUserDevice, error) {
+	var devices []models.OcservUserDevice
+	err := r.db.WithContext(ctx).
+		Where("oc_user_id = ?", ocUserID).
+		Order("last_seen_at DESC").
+		Find(&devices).Error
+	return devices, err
+}
+
+// RevokeDevice blocks the device so that it is disconnected whenever it connects again.
+func (r *OcservUserDeviceRepository) RevokeDevice(ctx context.Context, ocUserID uint, id string) (*models.OcservUserDevice, error) {
+	var device models.OcservUserDevice
+	if err := r.db.WithContext(ctx).Where("id = ? AND oc_user_id = ?", id, ocUserID).First(&device).Error; err != nil {
+		return nil, err
+	}
+
+	device.Blocked = true
+	device.BlockReason = models.DeviceBlockRevoked
+	err := r.db.WithContext(ctx).Model(&device).Updates(map[string]interface{}{
+		"blocked":      true,
+		"block_reason": models.DeviceBlockRevoked,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// UnblockDevice lets a revoked device, or one blocked beyond the device limit,
+// connect again. It is refused while the user has maxDevices active devices. The
+// row of the user is locked as when log_stream saves a new device, so that both
+// count the active devices one after the other.
+func (r *OcservUserDeviceRepository) UnblockDevice(ctx context.Context, ocUserID uint, maxDevices int, id string) (*models.OcservUserDevice, error) {
+	var device models.OcservUserDevice
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&models.OcservUser{}, ocUserID).Error
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Where("id = ? AND oc_user_id = ?", id, ocUserID).First(&device).Error; err != nil {
+			return err
+		}
+		if !device.Blocked {
+			return nil
+		}
+
+		if maxDevices > 0 {
+			var active int64
+			err = tx.Model(&models.OcservUserDevice{}).
+				Where("oc_user_id = ? AND NOT blocked", ocUserID).
+				Count(&active).Error
+			if err != nil {
+				return err
+			}
+			if active >= int64(maxDevices) {
+				return errors.New("device limit reached, revoke or delete another device first")
+			}
+		}
+
+		device.Blocked = false
+		device.BlockReason = ""
+		return tx.Model(&device).Updates(map[string]interface{}{
+			"blocked":      false,
+			"block_reason": "",
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// DeleteDevice forgets the device, freeing its slot of the user device limit.
+func (r *OcservUserDeviceRepository) DeleteDevice(ctx context.Context, ocUserID uint, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND oc_user_id = ?", id, ocUserID).Delete(&models.OcservUserDevice{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
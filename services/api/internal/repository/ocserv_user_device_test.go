@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestUnblockDevice(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxDevices int
+		active     int
+		wantErr    bool
+	}{
+		{name: "unlimited devices", active: 3},
+		{name: "within the limit", maxDevices: 2, active: 1},
+		{name: "at the limit", maxDevices: 2, active: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t, &models.OcservUser{}, &models.OcservUserDevice{})
+			repo := &OcservUserDeviceRepository{db: db}
+
+			ocUser := models.OcservUser{Username: "john", Password: "secret", TrafficType: models.Free, MaxDevices: tt.maxDevices}
+			require.NoError(t, db.Create(&ocUser).Error)
+			now := time.Now()
+			for i := 0; i < tt.active; i++ {
+				require.NoError(t, db.Create(&models.OcservUserDevice{
+					OcUserID: ocUser.ID, UserAgent: "OpenConnect v9.12-" + strconv.Itoa(i), FirstSeenAt: now, LastSeenAt: now,
+				}).Error)
+			}
+			blocked := models.OcservUserDevice{
+				OcUserID: ocUser.ID, UserAgent: "AnyConnect Windows 4.10", FirstSeenAt: now, LastSeenAt: now,
+				Blocked: true, BlockReason: models.DeviceBlockLimit,
+			}
+			require.NoError(t, db.Create(&blocked).Error)
+			id := strconv.Itoa(int(blocked.ID))
+
+			device, err := repo.UnblockDevice(context.Background(), ocUser.ID, tt.maxDevices, id)
+
+			var stored models.OcservUserDevice
+			require.NoError(t, db.First(&stored, blocked.ID).Error)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.True(t, stored.Blocked, "device unblocked beyond the limit")
+				return
+			}
+			require.NoError(t, err)
+			assert.False(t, device.Blocked)
+			assert.False(t, stored.Blocked)
+			assert.Empty(t, stored.BlockReason)
+		})
+	}
+
+	t.Run("unknown device", func(t *testing.T) {
+		db := dbtest.Open(t, &models.OcservUser{}, &models.OcservUserDevice{})
+		repo := &OcservUserDeviceRepository{db: db}
+		ocUser := models.OcservUser{Username: "john", Password: "secret", TrafficType: models.Free}
+		require.NoError(t, db.Create(&ocUser).Error)
+
+		_, err := repo.UnblockDevice(context.Background(), ocUser.ID, 0, "42")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
 	"gorm.io/gorm"
@@ -25,6 +26,26 @@ type ReportRepositoryInterface interface {
 	TotalBandWidthUser(ctx context.Context, uid string) (TotalBandwidths, error)
 	TenDaysStats(ctx context.Context) ([]models.DailyTraffic, error)
 	UsersStat(ctx context.Context) (UserStatsResult, error)
+	SessionsByCountry(ctx context.Context, dateStart, dateEnd *time.Time) ([]CountrySessions, error)
+	SessionsByASN(ctx context.Context, dateStart, dateEnd *time.Time) ([]ASNSessions, error)
+	NewCountrySessions(ctx context.Context, pagination *request.Pagination, dateStart, dateEnd *time.Time) (*[]models.OcservUserSessionLog, int64, error)
+	Alerts(ctx context.Context, pagination *request.Pagination, username, kind string) (*[]models.OcservUserAlert, int64, error)
+	AcknowledgeAlert(ctx context.Context, id string) (*models.OcservUserAlert, error)
+}
+
+// CountrySessions is the number of sessions started from a country and of distinct users behind them.
+type CountrySessions struct {
+	Country  string `json:"country" validate:"required"`
+	Sessions int64  `json:"sessions" validate:"required"`
+	Users    int64  `json:"users" validate:"required"`
+}
+
+// ASNSessions is the number of sessions started from an autonomous system and of distinct users behind them.
+type ASNSessions struct {
+	ASN          uint   `json:"asn" gorm:"column:asn" validate:"required"`
+	Organization string `json:"organization" validate:"required"`
+	Sessions     int64  `json:"sessions" validate:"required"`
+	Users        int64  `json:"users" validate:"required"`
 }
 
 type UserStatsResult struct {
@@ -36,7 +57,7 @@ type UserStatsResult struct {
 func NewtReportRepository() *ReportRepository {
 	return &ReportRepository{
 		db:                   database.GetConnection(),
-		commonOcservUserRepo: user.NewOcservUser(),
+		commonOcservUserRepo: driver.Get().Users(),
 	}
 }
 
@@ -244,3 +265,101 @@ func (r *ReportRepository) TotalBandWidthUser(ctx context.Context, uid string) (
 	}
 	return total, nil
 }
+
+// sessionStarts selects the session logs written once per session, in the given date range.
+func (r *ReportRepository) sessionStarts(ctx context.Context, dateStart, dateEnd *time.Time) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&models.OcservUserSessionLog{}).
+		Where("event IN ?", []string{models.EventConnect, models.EventUseragent})
+
+	if dateStart != nil {
+		query = query.Where("created_at >= ?", *dateStart)
+	}
+	if dateEnd != nil {
+		query = query.Where("created_at <= ?", *dateEnd)
+	}
+	return query
+}
+
+func (r *ReportRepository) SessionsByCountry(ctx context.Context, dateStart, dateEnd *time.Time) ([]CountrySessions, error) {
+	results := make([]CountrySessions, 0)
+	err := r.sessionStarts(ctx, dateStart, dateEnd).
+		Select("country, COUNT(*) AS sessions, COUNT(DISTINCT username) AS users").
+		Where("country <> ''").
+		Group("country").
+		Order("sessions DESC").
+		Scan(&results).Error
+	return results, err
+}
+
+func (r *ReportRepository) SessionsByASN(ctx context.Context, dateStart, dateEnd *time.Time) ([]ASNSessions, error) {
+	results := make([]ASNSessions, 0)
+	err := r.sessionStarts(ctx, dateStart, dateEnd).
+		Select("asn, MAX(asn_organization) AS organization, COUNT(*) AS sessions, COUNT(DISTINCT username) AS users").
+		Where("asn > 0").
+		Group("asn").
+		Order("sessions DESC").
+		Scan(&results).Error
+	return results, err
+}
+
+// NewCountrySessions returns the sessions flagged as started from a country new to their user.
+func (r *ReportRepository) NewCountrySessions(
+	ctx context.Context,
+	pagination *request.Pagination,
+	dateStart, dateEnd *time.Time,
+) (*[]models.OcservUserSessionLog, int64, error) {
+	var totalRecords int64
+
+	query := r.sessionStarts(ctx, dateStart, dateEnd).Where("new_country")
+	if err := query.Count(&totalRecords).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.OcservUserSessionLog
+	if err := request.Paginator(ctx, query, pagination).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return &logs, totalRecords, nil
+}
+
+// Alerts returns the account sharing alerts raised by the stats service, optionally
+// filtered by username and kind.
+func (r *ReportRepository) Alerts(
+	ctx context.Context,
+	pagination *request.Pagination,
+	username, kind string,
+) (*[]models.OcservUserAlert, int64, error) {
+	var totalRecords int64
+
+	query := r.db.WithContext(ctx).Model(&models.OcservUserAlert{})
+	if username != "" {
+		query = query.Where("username = ?", username)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	if err := query.Count(&totalRecords).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var alerts []models.OcservUserAlert
+	if err := request.Paginator(ctx, query, pagination).Find(&alerts).Error; err != nil {
+		return nil, 0, err
+	}
+	return &alerts, totalRecords, nil
+}
+
+func (r *ReportRepository) AcknowledgeAlert(ctx context.Context, id string) (*models.OcservUserAlert, error) {
+	var alert models.OcservUserAlert
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&alert).Error; err != nil {
+		return nil, err
+	}
+
+	alert.Acknowledged = true
+	if err := r.db.WithContext(ctx).Model(&alert).Update("acknowledged", true).Error; err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerHealth is the state of an ocserv node. The node of the configured driver
+// has no ID, RX and TX are the bytes transferred since the last stats reset.
+type ServerHealth struct {
+	ID             uint   `json:"id" validate:"omitempty"`
+	Name           string `json:"name" validate:"required"`
+	Endpoint       string `json:"endpoint" validate:"omitempty"`
+	Healthy        bool   `json:"healthy" validate:"required"`
+	Error          string `json:"error" validate:"omitempty"`
+	Latency        int64  `json:"latency" validate:"required" desc:"milliseconds of the status call"`
+	Status         string `json:"status" validate:"omitempty"`
+	UpSince        string `json:"up_since" validate:"omitempty"`
+	ActiveSessions int    `json:"active_sessions" validate:"required"`
+	RX             int64  `json:"rx" validate:"required"`
+	TX             int64  `json:"tx" validate:"required"`
+}
+
+type ServerRepository struct {
+	db *gorm.DB
+}
+
+type ServerCRUD interface {
+	Servers(ctx context.Context, pagination *request.Pagination) ([]models.Server, int64, error)
+	GetByID(ctx context.Context, id string) (*models.Server, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]models.Server, error)
+	Create(ctx context.Context, server *models.Server) (*models.Server, error)
+	Update(ctx context.Context, server *models.Server) (*models.Server, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type ServerHealthChecker interface {
+	Health(ctx context.Context) ([]ServerHealth, error)
+}
+
+type ServerRepositoryInterface interface {
+	ServerCRUD
+	ServerHealthChecker
+}
+
+func NewServerRepository() *ServerRepository {
+	return &ServerRepository{
+		db: database.GetConnection(),
+	}
+}
+
+func (r *ServerRepository) Servers(ctx context.Context, pagination *request.Pagination) ([]models.Server, int64, error) {
+	var totalRecords int64
+	if err := r.db.WithContext(ctx).Model(&models.Server{}).Count(&totalRecords).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var servers []models.Server
+	if err := request.Paginator(ctx, r.db, pagination).Find(&servers).Error; err != nil {
+		return nil, 0, err
+	}
+	return servers, totalRecords, nil
+}
+
+func (r *ServerRepository) GetByID(ctx context.Context, id string) (*models.Server, error) {
+	var server models.Server
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&server).Error; err != nil {
+		return nil, err
+	}
+	return &server, nil
+}
+
+// GetByIDs returns the servers of ids, it fails if one of them does not exist.
+func (r *ServerRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Server, error) {
+	servers := make([]models.Server, 0, len(ids))
+	if len(ids) == 0 {
+		return servers, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("id").Find(&servers).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]bool, len(servers))
+	for _, server := range servers {
+		found[server.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("server %d not found", id)
+		}
+	}
+	return servers, nil
+}
+
+func (r *ServerRepository) Create(ctx context.Context, server *models.Server) (*models.Server, error) {
+	if err := r.db.WithContext(ctx).Create(server).Error; err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+func (r *ServerRepository) Update(ctx context.Context, server *models.Server) (*models.Server, error) {
+	if err := r.db.WithContext(ctx).Save(server).Error; err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// Delete removes a server without users or groups, they must be moved to other
+// servers first.
+func (r *ServerRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var server models.Server
+		if err := tx.Where("id = ?", id).First(&server).Error; err != nil {
+			return err
+		}
+
+		var users, groups int64
+		if err := tx.Table("ocserv_user_servers").Where("server_id = ?", server.ID).Count(&users).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("ocserv_group_servers").Where("server_id = ?", server.ID).Count(&groups).Error; err != nil {
+			return err
+		}
+		if users > 0 || groups > 0 {
+			return fmt.Errorf("server %s still has %d users and %d groups", server.Name, users, groups)
+		}
+
+		return tx.Delete(&server).Error
+	})
+}
+
+// Health checks the status of every node concurrently.
+func (r *ServerRepository) Health(ctx context.Context) ([]ServerHealth, error) {
+	nodes, err := allNodes(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	health := make([]ServerHealth, len(nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			h := ServerHealth{Name: node.Name}
+			if node.Server != nil {
+				h.ID, h.Endpoint = node.Server.ID, node.Server.Endpoint
+			}
+
+			start := time.Now()
+			status, err := node.Driver.WithContext(ctx).Occtl().ShowStatus()
+			h.Latency = time.Since(start).Milliseconds()
+
+			if err != nil {
+				h.Error = err.Error()
+			} else {
+				h.Healthy = true
+				h.Status = status.Status
+				h.UpSince = status.UpSince
+				h.ActiveSessions = int(status.ActiveSessions)
+				h.RX, h.TX = int64(status.RawRX), int64(status.RawTX)
+			}
+			health[i] = h
+		}()
+	}
+	wg.Wait()
+
+	return health, nil
+}
+
+// allNodes returns the node of the configured driver followed by the nodes of
+// every server.
+func allNodes(ctx context.Context, db *gorm.DB) ([]driver.Node, error) {
+	var servers []models.Server
+	if err := db.WithContext(ctx).Order("id").Find(&servers).Error; err != nil {
+		return nil, err
+	}
+
+	nodes := driver.Nodes(nil)
+	for _, server := range servers {
+		nodes = append(nodes, driver.ServerNode(server))
+	}
+	return nodes, nil
+}
+
+// droppedNodes returns the nodes of the servers before that are not nodes of the
+// servers after, the ones a user or group has to be removed from.
+func droppedNodes(before, after []models.Server) []driver.Node {
+	kept := make(map[uint]bool)
+	for _, node := range driver.Nodes(after) {
+		kept[nodeID(node)] = true
+	}
+
+	var dropped []driver.Node
+	for _, node := range driver.Nodes(before) {
+		if !kept[nodeID(node)] {
+			dropped = append(dropped, node)
+		}
+	}
+	return dropped
+}
+
+// nodeID is the server ID of node, 0 for the node of the configured driver.
+func nodeID(node driver.Node) uint {
+	if node.Server == nil {
+		return 0
+	}
+	return node.Server.ID
+}
+
+// reloadNodes reloads the ocserv configs of nodes in the background.
+func reloadNodes(nodes []driver.Node) {
+	go func() {
+		_ = driver.FanOut(context.Background(), nodes, func(_ driver.Node, d driver.Driver) error {
+			_, err := d.Occtl().ReloadConfigs()
+			return err
+		})
+	}()
+}
+
+// syncNodes calls apply with the driver of every node once the database change
+// is committed. When some nodes fail, the change is rolled back: undo is called
+// on the nodes that succeeded and revert on the database. The error names the
+// failed servers.
+func syncNodes(
+	ctx context.Context,
+	nodes []driver.Node,
+	apply, undo func(node driver.Node, d driver.Driver) error,
+	revert func() error,
+) error {
+	err := driver.FanOut(ctx, nodes, apply)
+	var fanOutErr *driver.FanOutError
+	if !errors.As(err, &fanOutErr) {
+		return err
+	}
+
+	failed := make(map[string]bool, len(fanOutErr.Failed))
+	for _, name := range fanOutErr.Failed {
+		failed[name] = true
+	}
+	var succeeded []driver.Node
+	for _, node := range nodes {
+		if !failed[node.Name] {
+			succeeded = append(succeeded, node)
+		}
+	}
+
+	// the rollback runs even when the request is gone
+	ctx = context.WithoutCancel(ctx)
+	if undoErr := driver.FanOut(ctx, succeeded, undo); undoErr != nil {
+		logger.FromContext(ctx).Error("failed to roll back servers: %v", undoErr)
+	}
+	if revert != nil {
+		if revertErr := revert(); revertErr != nil {
+			logger.FromContext(ctx).Error("failed to roll back the database: %v", revertErr)
+		}
+	}
+
+	return fmt.Errorf("failed on servers %s, the change was rolled back: %w", strings.Join(fanOutErr.Failed, ", "), err)
+}
+
+// usersNodes returns the nodes of the servers of users, each node once.
+func usersNodes(users []models.OcservUser) []driver.Node {
+	seen := make(map[uint]bool)
+	var nodes []driver.Node
+	for _, u := range users {
+		for _, node := range driver.Nodes(u.Servers) {
+			if !seen[nodeID(node)] {
+				seen[nodeID(node)] = true
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
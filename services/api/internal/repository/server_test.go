@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSyncNodes(t *testing.T) {
+	a, b, c := driver.NewFake(), driver.NewFake(), driver.NewFake()
+	for _, f := range []*driver.FakeDriver{a, c} {
+		assert.NoError(t, f.Users().Create("defaults", "john", "pass", nil))
+	}
+	nodes := []driver.Node{{Name: "a", Driver: a}, {Name: "b", Driver: b}, {Name: "c", Driver: c}}
+
+	reverted := false
+	err := syncNodes(context.Background(), nodes,
+		func(_ driver.Node, d driver.Driver) error {
+			return lockOcservUser(d, "john", true)
+		},
+		func(_ driver.Node, d driver.Driver) error {
+			return lockOcservUser(d, "john", false)
+		},
+		func() error {
+			reverted = true
+			return nil
+		},
+	)
+
+	assert.ErrorContains(t, err, "failed on servers b,")
+	assert.NotContains(t, err.Error(), "server a:")
+	assert.True(t, reverted)
+	for _, f := range []*driver.FakeDriver{a, c} {
+		u, _ := f.User("john")
+		assert.False(t, u.Locked, "expected the lock to be rolled back")
+	}
+
+	reverted = false
+	err = syncNodes(context.Background(), []driver.Node{nodes[0], nodes[2]},
+		func(_ driver.Node, d driver.Driver) error {
+			return lockOcservUser(d, "john", true)
+		},
+		nil,
+		func() error {
+			reverted = true
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.False(t, reverted)
+	u, _ := a.User("john")
+	assert.True(t, u.Locked)
+}
+
+func TestUsersNodes(t *testing.T) {
+	edge1 := models.Server{ID: 1, Name: "edge-1", Endpoint: "http://10.0.0.2:8888"}
+	edge2 := models.Server{ID: 2, Name: "edge-2", Endpoint: "http://10.0.0.3:8888"}
+	users := []models.OcservUser{
+		{Username: "john", Servers: []models.Server{edge1, edge2}},
+		{Username: "jane", Servers: []models.Server{edge2}},
+		{Username: "joe"},
+	}
+
+	var names []string
+	for _, node := range usersNodes(users) {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"edge-1", "edge-2", driver.DefaultNode}, names)
+}
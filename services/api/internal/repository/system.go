@@ -75,6 +75,8 @@ func (s *SystemRepository) SystemUpdate(ctx context.Context, system *models.Syst
 				"google_captcha_site_key":    system.GoogleCaptchaSiteKey,
 				"auto_delete_inactive_users": system.AutoDeleteInactiveUsers,
 				"keep_inactive_user_days":    system.KeepInactiveUserDays,
+				"require_2fa_admins":         system.Require2FAAdmins,
+				"require_2fa_staffs":         system.Require2FAStaffs,
 			},
 		).Error; err != nil {
 		return nil, err
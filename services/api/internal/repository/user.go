@@ -6,6 +6,7 @@ import (
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/crypto"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/oklog/ulid/v2"
 	"gorm.io/gorm"
 	"time"
 )
@@ -22,7 +23,7 @@ type UserCRUD interface {
 }
 
 type UserAuth interface {
-	CreateToken(ctx context.Context, user *models.User, rememberMe bool) (string, error)
+	CreateToken(ctx context.Context, user *models.User, rememberMe bool, ip, userAgent string) (string, error)
 	ChangePassword(ctx context.Context, uid, password, salt string) error
 	UpdateLastLogin(ctx context.Context, user *models.User) error
 }
@@ -32,10 +33,20 @@ type UserQuery interface {
 	UsersLookup(ctx context.Context) (*[]models.UsersLookup, error)
 }
 
+type UserTwoFactor interface {
+	SetTOTPSecret(ctx context.Context, userID uint, secret string) error
+	EnableTOTP(ctx context.Context, userID uint, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, userID uint) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, recoveryCodeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uint, recoveryCodeHash string) (bool, error)
+	UseTOTPCounter(ctx context.Context, userID uint, counter int64) (bool, error)
+}
+
 type UserRepositoryInterface interface {
 	UserCRUD
 	UserAuth
 	UserQuery
+	UserTwoFactor
 }
 
 func NewUserRepository() *UserRepository {
@@ -53,22 +64,28 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*m
 	return &user, nil
 }
 
-func (r *UserRepository) CreateToken(ctx context.Context, user *models.User, rememberMe bool) (string, error) {
+func (r *UserRepository) CreateToken(ctx context.Context, user *models.User, rememberMe bool, ip, userAgent string) (string, error) {
 	expire := time.Now().Add(24 * time.Hour)
 	if rememberMe {
 		expire = expire.AddDate(0, 1, 0)
 	}
 
-	access, err := crypto.GenerateAccessToken(user.UID, user.Username, expire.Unix(), user.IsAdmin)
+	tokenUID := ulid.Make().String()
+	access, err := crypto.GenerateAccessTokenWithID(tokenUID, user.UID, user.Username, expire.Unix(), user.IsAdmin)
 	if err != nil {
 		return "", err
 	}
 
+	now := time.Now()
 	err = r.db.WithContext(ctx).Create(
 		&models.UserToken{
-			UserID:   user.ID,
-			Token:    access,
-			ExpireAt: expire,
+			UserID:     user.ID,
+			UID:        tokenUID,
+			Token:      access,
+			IP:         ip,
+			UserAgent:  userAgent,
+			LastSeenAt: &now,
+			ExpireAt:   expire,
 		},
 	).Error
 	if err != nil {
@@ -103,20 +120,26 @@ func (r *UserRepository) Users(ctx context.Context, pagination *request.Paginati
 	return staffs, totalRecords, nil
 }
 
+// ChangePassword updates the user password and revokes all of its tokens.
 func (r *UserRepository) ChangePassword(ctx context.Context, uid, password, salt string) error {
-	var user models.User
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("uid = ?", uid).First(&user).Error; err != nil {
+			return err
+		}
 
-	err := r.db.WithContext(ctx).Model(&user).Where("uid = ?", uid).Updates(
-		map[string]interface{}{
-			"password": password,
-			"salt":     salt,
-		},
-	).Error
-	if err != nil {
-		return err
-	}
+		err := tx.Model(&user).Updates(
+			map[string]interface{}{
+				"password": password,
+				"salt":     salt,
+			},
+		).Error
+		if err != nil {
+			return err
+		}
 
-	return nil
+		return revokeUserTokens(tx, user.ID, "")
+	})
 }
 
 func (r *UserRepository) DeleteUser(ctx context.Context, uid string) error {
@@ -126,11 +149,12 @@ func (r *UserRepository) DeleteUser(ctx context.Context, uid string) error {
 		return err
 	}
 
-	err = r.db.WithContext(ctx).Delete(&user).Error
-	if err != nil {
-		return err
-	}
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err = revokeUserTokens(tx, user.ID, ""); err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
 }
 
 func (r *UserRepository) GetByUID(ctx context.Context, uid string) (*models.User, error) {
@@ -160,3 +184,83 @@ func (r *UserRepository) UsersLookup(ctx context.Context) (*[]models.UsersLookup
 	}
 	return &users, nil
 }
+
+// SetTOTPSecret stores a pending TOTP secret. 2FA is enabled only after EnableTOTP.
+func (r *UserRepository) SetTOTPSecret(ctx context.Context, userID uint, secret string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("totp_secret", secret).Error
+}
+
+func (r *UserRepository) EnableTOTP(ctx context.Context, userID uint, recoveryCodeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Update("totp_enabled", true).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, recoveryCodeHashes)
+	})
+}
+
+func (r *UserRepository) DisableTOTP(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_enabled": false,
+				"totp_secret":  "",
+				"totp_counter": 0,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error
+	})
+}
+
+func (r *UserRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, recoveryCodeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, recoveryCodeHashes)
+	})
+}
+
+// UseRecoveryCode marks a matching unused recovery code as used and reports whether one was found.
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, userID uint, recoveryCodeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, recoveryCodeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UseTOTPCounter records the time step of an accepted TOTP code and reports whether it is
+// later than the last recorded one. A code of the same or an earlier step is a replay.
+func (r *UserRepository) UseTOTPCounter(ctx context.Context, userID uint, counter int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND totp_counter < ?", userID, counter).
+		Update("totp_counter", counter)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, recoveryCodeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(recoveryCodeHashes) == 0 {
+		return nil
+	}
+
+	codes := make([]models.UserRecoveryCode, 0, len(recoveryCodeHashes))
+	for _, hash := range recoveryCodeHashes {
+		codes = append(codes, models.UserRecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	commonModels "github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"slices"
+	"time"
+)
+
+type UserQuotaRepository struct {
+	db *gorm.DB
+}
+
+type UserQuotaCRUD interface {
+	GetQuota(ctx context.Context, userID uint) (*models.UserQuota, error)
+	UpsertQuota(ctx context.Context, quota *models.UserQuota) (*models.UserQuota, error)
+}
+
+type UserQuotaEnforcement interface {
+	Usage(ctx context.Context, owner string) (*models.UserQuotaUsage, error)
+	CheckQuota(tx *gorm.DB, owner string, ocservUser *commonModels.OcservUser) error
+}
+
+type UserQuotaRepositoryInterface interface {
+	UserQuotaCRUD
+	UserQuotaEnforcement
+}
+
+func NewUserQuotaRepository() *UserQuotaRepository {
+	return &UserQuotaRepository{
+		db: database.GetConnection(),
+	}
+}
+
+// GetQuota returns the quota of the given user, or an unlimited quota if none was set.
+func (r *UserQuotaRepository) GetQuota(ctx context.Context, userID uint) (*models.UserQuota, error) {
+	return getQuota(r.db.WithContext(ctx), userID)
+}
+
+func getQuota(db *gorm.DB, userID uint) (*models.UserQuota, error) {
+	var quota models.UserQuota
+	err := db.Where("user_id = ?", userID).First(&quota).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.UserQuota{UserID: userID, AllowedGroups: &commonModels.CSVStringList{}}, nil
+		}
+		return nil, err
+	}
+	if quota.AllowedGroups == nil {
+		quota.AllowedGroups = &commonModels.CSVStringList{}
+	}
+	return &quota, nil
+}
+
+func (r *UserQuotaRepository) UpsertQuota(ctx context.Context, quota *models.UserQuota) (*models.UserQuota, error) {
+	if quota.AllowedGroups == nil {
+		quota.AllowedGroups = &commonModels.CSVStringList{}
+	}
+	err := r.db.WithContext(ctx).Save(quota).Error
+	if err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// Usage counts the ocserv users owned by the operator and the traffic GiB allocated to them.
+func (r *UserQuotaRepository) Usage(ctx context.Context, owner string) (*models.UserQuotaUsage, error) {
+	var usage models.UserQuotaUsage
+	err := r.db.WithContext(ctx).
+		Model(&commonModels.OcservUser{}).
+		Select("COUNT(*) AS users, COALESCE(SUM(traffic_size), 0) AS traffic_gib").
+		Where("owner = ?", owner).
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// CheckQuota validates a new or updated ocserv user against the quota of its owner
+// in tx, the transaction saving the user. The row of the owner is locked until tx
+// ends, so the ocserv users of an owner saved at the same time are checked one
+// after the other. An ocserv user with a zero ID is treated as a new one.
+func (r *UserQuotaRepository) CheckQuota(tx *gorm.DB, owner string, ocservUser *commonModels.OcservUser) error {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("username = ?", owner).
+		First(&user).Error; err != nil {
+		return err
+	}
+
+	quota, err := getQuota(tx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxUsers > 0 && ocservUser.ID == 0 {
+		var count int64
+		if err = tx.
+			Model(&commonModels.OcservUser{}).
+			Where("owner = ?", owner).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(quota.MaxUsers) {
+			return fmt.Errorf("quota exceeded: maximum of %d users reached", quota.MaxUsers)
+		}
+	}
+
+	if quota.MaxTrafficGiB > 0 {
+		if ocservUser.TrafficType == commonModels.Free {
+			return errors.New("quota exceeded: free traffic is not allowed with a traffic quota")
+		}
+
+		var allocated int64
+		if err = tx.
+			Model(&commonModels.OcservUser{}).
+			Select("COALESCE(SUM(traffic_size), 0)").
+			Where("owner = ? AND id <> ?", owner, ocservUser.ID).
+			Scan(&allocated).Error; err != nil {
+			return err
+		}
+		if allocated+int64(ocservUser.TrafficSize) > int64(quota.MaxTrafficGiB) {
+			return fmt.Errorf(
+				"quota exceeded: %d GiB of %d GiB traffic already allocated",
+				allocated, quota.MaxTrafficGiB,
+			)
+		}
+	}
+
+	if len(*quota.AllowedGroups) > 0 && !slices.Contains(*quota.AllowedGroups, ocservUser.Group) {
+		return fmt.Errorf("quota exceeded: group %s is not allowed", ocservUser.Group)
+	}
+
+	if quota.MaxExpireDays > 0 {
+		if ocservUser.ExpireAt == nil {
+			return errors.New("quota exceeded: unlimited expiry is not allowed")
+		}
+		horizon := time.Now().AddDate(0, 0, quota.MaxExpireDays)
+		if ocservUser.ExpireAt.After(horizon) {
+			return fmt.Errorf("quota exceeded: expiry must be within %d days", quota.MaxExpireDays)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	commonModels "github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newQuotaFixture returns the repositories on a test database holding the
+// operator john with quota, ocserv users are created on the fake driver.
+func newQuotaFixture(t *testing.T, quota models.UserQuota) (*gorm.DB, *OcservUserRepository, *UserQuotaRepository) {
+	t.Helper()
+
+	t.Setenv("OCSERV_DRIVER", driver.Fake)
+	_, err := driver.Init(false)
+	require.NoError(t, err)
+
+	db := dbtest.Open(t, &models.User{}, &models.UserQuota{}, &commonModels.Server{}, &commonModels.OcservUser{})
+	operator := models.User{UID: "01HZX0000000000000000000U1", Username: "john", Password: "secret", Salt: "salt"}
+	require.NoError(t, db.Create(&operator).Error)
+	quota.UserID = operator.ID
+	require.NoError(t, db.Create(&quota).Error)
+
+	return db, &OcservUserRepository{db: db}, &UserQuotaRepository{db: db}
+}
+
+func quotaCheck(quotaRepo *UserQuotaRepository) SaveCheck {
+	return func(tx *gorm.DB, ocservUser *commonModels.OcservUser) error {
+		return quotaRepo.CheckQuota(tx, "john", ocservUser)
+	}
+}
+
+func TestCheckQuota_ConcurrentCreates(t *testing.T) {
+	db, ocservUserRepo, quotaRepo := newQuotaFixture(t, models.UserQuota{MaxUsers: 3})
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := ocservUserRepo.Create(context.Background(), &commonModels.OcservUser{
+				Owner:       "john",
+				Username:    fmt.Sprintf("user%d", i),
+				Password:    "secret",
+				Group:       "defaults",
+				TrafficType: commonModels.Free,
+			}, quotaCheck(quotaRepo))
+			if err != nil {
+				assert.ErrorContains(t, err, "quota exceeded")
+				return
+			}
+			mu.Lock()
+			created++
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, created)
+	var count int64
+	require.NoError(t, db.Model(&commonModels.OcservUser{}).Where("owner = ?", "john").Count(&count).Error)
+	assert.EqualValues(t, 3, count, "expected the users over the quota to be rolled back")
+}
+
+func TestCheckQuota(t *testing.T) {
+	inMonth := time.Now().AddDate(0, 1, 0)
+	inYear := time.Now().AddDate(1, 0, 0)
+	allowed := commonModels.CSVStringList{"defaults"}
+
+	tests := []struct {
+		name    string
+		quota   models.UserQuota
+		user    commonModels.OcservUser
+		wantErr string
+	}{
+		{
+			name: "unlimited quota",
+			user: commonModels.OcservUser{TrafficType: commonModels.Free},
+		},
+		{
+			name:  "traffic within the quota",
+			quota: models.UserQuota{MaxTrafficGiB: 30},
+			user:  commonModels.OcservUser{TrafficType: commonModels.MonthlyTransmit, TrafficSize: 20},
+		},
+		{
+			name:    "traffic over the quota",
+			quota:   models.UserQuota{MaxTrafficGiB: 30},
+			user:    commonModels.OcservUser{TrafficType: commonModels.MonthlyTransmit, TrafficSize: 21},
+			wantErr: "10 GiB of 30 GiB traffic already allocated",
+		},
+		{
+			name:    "free traffic with a traffic quota",
+			quota:   models.UserQuota{MaxTrafficGiB: 30},
+			user:    commonModels.OcservUser{TrafficType: commonModels.Free},
+			wantErr: "free traffic is not allowed",
+		},
+		{
+			name:    "group not allowed",
+			quota:   models.UserQuota{AllowedGroups: &allowed},
+			user:    commonModels.OcservUser{Group: "vip", TrafficType: commonModels.Free},
+			wantErr: "group vip is not allowed",
+		},
+		{
+			name:  "expiry within the quota",
+			quota: models.UserQuota{MaxExpireDays: 90},
+			user:  commonModels.OcservUser{TrafficType: commonModels.Free, ExpireAt: &inMonth},
+		},
+		{
+			name:    "expiry over the quota",
+			quota:   models.UserQuota{MaxExpireDays: 90},
+			user:    commonModels.OcservUser{TrafficType: commonModels.Free, ExpireAt: &inYear},
+			wantErr: "expiry must be within 90 days",
+		},
+		{
+			name:    "unlimited expiry",
+			quota:   models.UserQuota{MaxExpireDays: 90},
+			user:    commonModels.OcservUser{TrafficType: commonModels.Free},
+			wantErr: "unlimited expiry is not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, ocservUserRepo, quotaRepo := newQuotaFixture(t, tt.quota)
+			// an existing user with 10 GiB
+			require.NoError(t, db.Create(&commonModels.OcservUser{
+				Owner: "john", Username: "jane", Password: "secret", Group: "defaults",
+				TrafficType: commonModels.MonthlyTransmit, TrafficSize: 10,
+			}).Error)
+
+			user := tt.user
+			user.Owner = "john"
+			user.Username = "joe"
+			user.Password = "secret"
+			if user.Group == "" {
+				user.Group = "defaults"
+			}
+			_, err := ocservUserRepo.Create(context.Background(), &user, quotaCheck(quotaRepo))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUseTOTPCounter(t *testing.T) {
+	db := dbtest.Open(t, &models.User{}, &models.UserRecoveryCode{})
+	user := models.User{UID: "01HZX0000000000000000000U1", Username: "john", Password: "secret", Salt: "salt"}
+	require.NoError(t, db.Create(&user).Error)
+
+	repo := &UserRepository{db: db}
+	ctx := context.Background()
+
+	ok, err := repo.UseTOTPCounter(ctx, user.ID, 100)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.UseTOTPCounter(ctx, user.ID, 100)
+	require.NoError(t, err)
+	assert.False(t, ok, "the same code replayed")
+
+	ok, err = repo.UseTOTPCounter(ctx, user.ID, 99)
+	require.NoError(t, err)
+	assert.False(t, ok, "a code of an earlier step")
+
+	ok, err = repo.UseTOTPCounter(ctx, user.ID, 101)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, repo.DisableTOTP(ctx, user.ID))
+	ok, err = repo.UseTOTPCounter(ctx, user.ID, 50)
+	require.NoError(t, err)
+	assert.True(t, ok, "the counter is reset with the secret")
+}
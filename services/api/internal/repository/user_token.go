@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"gorm.io/gorm"
+	"time"
+)
+
+// lastSeenInterval bounds how often last_seen_at of a token in use is written.
+const lastSeenInterval = 30 * time.Second
+
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+type UserTokenValidation interface {
+	ValidateToken(ctx context.Context, tokenUID string) (bool, error)
+}
+
+type UserTokenSessions interface {
+	ActiveTokens(ctx context.Context, userUID string) ([]models.UserToken, error)
+	RevokeToken(ctx context.Context, userUID, tokenUID string) error
+	RevokeAllTokens(ctx context.Context, userUID, exceptTokenUID string) error
+}
+
+type UserTokenRepositoryInterface interface {
+	UserTokenValidation
+	UserTokenSessions
+}
+
+func NewUserTokenRepository() *UserTokenRepository {
+	return &UserTokenRepository{
+		db: database.GetConnection(),
+	}
+}
+
+// ValidateToken reports whether the token exists, is not revoked and not expired.
+// last_seen_at is refreshed when older than lastSeenInterval.
+func (r *UserTokenRepository) ValidateToken(ctx context.Context, tokenUID string) (bool, error) {
+	now := time.Now()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.UserToken{}).
+		Where("uid = ? AND revoked_at IS NULL AND expire_at > ?", tokenUID, now).
+		Where("last_seen_at IS NULL OR last_seen_at < ?", now.Add(-lastSeenInterval)).
+		Update("last_seen_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.UserToken{}).
+		Where("uid = ? AND revoked_at IS NULL AND expire_at > ?", tokenUID, now).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *UserTokenRepository) ActiveTokens(ctx context.Context, userUID string) ([]models.UserToken, error) {
+	var tokens []models.UserToken
+	err := r.db.WithContext(ctx).
+		Model(&models.UserToken{}).
+		Select("user_tokens.*").
+		Joins("JOIN users ON users.id = user_tokens.user_id").
+		Where("users.uid = ? AND user_tokens.revoked_at IS NULL AND user_tokens.expire_at > ?", userUID, time.Now()).
+		Order("user_tokens.last_seen_at DESC NULLS LAST, user_tokens.created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *UserTokenRepository) RevokeToken(ctx context.Context, userUID, tokenUID string) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.UserToken{}).
+		Where("uid = ? AND revoked_at IS NULL", tokenUID).
+		Where("user_id = (?)", r.db.Model(&models.User{}).Select("id").Where("uid = ?", userUID)).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeAllTokens revokes every active token of the user, except exceptTokenUID when not empty.
+func (r *UserTokenRepository) RevokeAllTokens(ctx context.Context, userUID, exceptTokenUID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("uid = ?", userUID).First(&user).Error; err != nil {
+			return err
+		}
+		return revokeUserTokens(tx, user.ID, exceptTokenUID)
+	})
+}
+
+// revokeUserTokens revokes the active tokens of a user inside tx.
+func revokeUserTokens(tx *gorm.DB, userID uint, exceptTokenUID string) error {
+	query := tx.Model(&models.UserToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptTokenUID != "" {
+		query = query.Where("uid <> ?", exceptTokenUID)
+	}
+
+	return query.Update("revoked_at", time.Now()).Error
+}
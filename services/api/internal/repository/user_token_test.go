@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestValidateToken_Revoked(t *testing.T) {
+	db := dbtest.Open(t, &models.User{}, &models.UserToken{})
+	user := models.User{UID: "01HZX0000000000000000000U1", Username: "john", Password: "secret", Salt: "salt"}
+	require.NoError(t, db.Create(&user).Error)
+	for _, uid := range []string{"01HZX0000000000000000000T1", "01HZX0000000000000000000T2"} {
+		require.NoError(t, db.Create(&models.UserToken{UserID: user.ID, UID: uid, ExpireAt: time.Now().Add(time.Hour)}).Error)
+	}
+
+	repo := &UserTokenRepository{db: db}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		valid, err := repo.ValidateToken(ctx, "01HZX0000000000000000000T1")
+		require.NoError(t, err)
+		assert.True(t, valid, "lookup %d", i)
+	}
+
+	require.NoError(t, repo.RevokeToken(ctx, user.UID, "01HZX0000000000000000000T1"))
+	valid, err := repo.ValidateToken(ctx, "01HZX0000000000000000000T1")
+	require.NoError(t, err)
+	assert.False(t, valid, "revoked right after a successful lookup")
+
+	require.NoError(t, repo.RevokeAllTokens(ctx, user.UID, ""))
+	valid, err = repo.ValidateToken(ctx, "01HZX0000000000000000000T2")
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = repo.ValidateToken(ctx, "01HZX0000000000000000000T9")
+	require.NoError(t, err)
+	assert.False(t, valid, "unknown token")
+}
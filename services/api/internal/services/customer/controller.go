@@ -1,26 +1,36 @@
 package customer
 
 import (
+	"context"
 	"errors"
 	"github.com/labstack/echo/v4"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/crypto"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/profile"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
 	"net/http"
 	"strconv"
 	"time"
 )
 
+// customerTokenTTL is the lifetime of tokens issued by the customer portal login.
+const customerTokenTTL = time.Hour
+
 type Controller struct {
-	request        request.CustomRequestInterface
-	ocservUserRepo repository.OcservUserRepositoryInterface
-	occtl          repository.OcctlRepositoryInterface
+	request          request.CustomRequestInterface
+	ocservUserRepo   repository.OcservUserRepositoryInterface
+	occtl            repository.OcctlRepositoryInterface
+	profileGenerator profile.GeneratorInterface
 }
 
 func New() *Controller {
 	return &Controller{
-		request:        request.NewCustomRequest(),
-		ocservUserRepo: repository.NewtOcservUserRepository(),
-		occtl:          repository.NewOcctlRepository(),
+		request:          request.NewCustomRequest(),
+		ocservUserRepo:   repository.NewtOcservUserRepository(),
+		occtl:            repository.NewOcctlRepository(),
+		profileGenerator: profile.NewGenerator(),
 	}
 }
 
@@ -56,38 +66,11 @@ func (ctl *Controller) Summary(c echo.Context) error {
 		return ctl.request.BadRequest(c, errors.New("invalid username or password"))
 	}
 
-	dateEnd := time.Now()
-	firstOfThisMonth := time.Date(dateEnd.Year(), dateEnd.Month(), 1, 0, 0, 0, 0, dateEnd.Location())
-	dateStart := firstOfThisMonth.AddDate(0, -1, 0)
-
-	usage, err := ctl.ocservUserRepo.TotalBandwidthUserDateRange(
-		c.Request().Context(),
-		strconv.Itoa(int(user.ID)),
-		&dateStart,
-		&dateEnd,
-	)
+	resp, err := ctl.summary(c.Request().Context(), user)
 	if err != nil {
 		return ctl.request.BadRequest(c, err)
 	}
-
-	return c.JSON(http.StatusOK, SummaryResponse{
-		OcservUser: ModelCustomer{
-			Owner:         user.Owner,
-			Username:      user.Username,
-			IsLocked:      user.IsLocked,
-			ExpireAt:      user.ExpireAt,
-			DeactivatedAt: user.DeactivatedAt,
-			TrafficType:   user.TrafficType,
-			TrafficSize:   user.TrafficSize,
-			Rx:            user.Rx,
-			Tx:            user.Tx,
-		},
-		Usage: UsageResponse{
-			DateStart:  dateStart,
-			DateEnd:    dateEnd,
-			Bandwidths: usage,
-		},
-	})
+	return c.JSON(http.StatusOK, resp)
 }
 
 // DisconnectSessions
@@ -122,7 +105,339 @@ func (ctl *Controller) DisconnectSessions(c echo.Context) error {
 		return ctl.request.BadRequest(c, errors.New("invalid username or password"))
 	}
 
-	_, _ = ctl.occtl.Disconnect(user.Username)
+	if _, err = ctl.occtl.Disconnect(user.Username); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
 
 	return c.JSON(http.StatusAccepted, nil)
 }
+
+// Login 	     Customer login
+//
+// @Summary      Customer login
+// @Description  Customer login with ocserv account username and password. Returns a short-lived customer token
+// @Tags         Customers
+// @Accept       json
+// @Produce      json
+// @Param        request body  LoginData  true "customer username and password (same ocserv account)."
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      429 {object} middlewares.TooManyRequests
+// @Success      200  {object} LoginResponse
+// @Router       /customers/login [post]
+func (ctl *Controller) Login(c echo.Context) error {
+	var data LoginData
+
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	if data.Password == "Secret-Ocpasswd" {
+		return ctl.request.BadRequest(c, errors.New("invalid username or password"))
+	}
+
+	user, err := ctl.ocservUserRepo.GetByUsername(c.Request().Context(), data.Username)
+	if err != nil || user.Password != data.Password {
+		return ctl.request.BadRequest(c, errors.New("invalid username or password"))
+	}
+
+	expire := time.Now().Add(customerTokenTTL)
+	token, err := crypto.GenerateCustomerToken(user.UID, user.Username, user.TokenVersion, expire.Unix())
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, LoginResponse{
+		Token:      token,
+		ExpireAt:   expire,
+		OcservUser: modelCustomer(user),
+	})
+}
+
+// AccountSummary 	     Customer account summary
+//
+// @Summary      Customer account summary
+// @Description  Customer account summary with the usage of the current and previous month
+// @Tags         Customers(Portal)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} SummaryResponse
+// @Router       /customers/me/summary [get]
+func (ctl *Controller) AccountSummary(c echo.Context) error {
+	user, err := ctl.customer(c)
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "customer not found")
+	}
+
+	resp, err := ctl.summary(c.Request().Context(), user)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// AccountStatistics 	     Customer daily usage
+//
+// @Summary      Customer daily usage
+// @Description  Customer daily usage chart data
+// @Tags         Customers(Portal)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 date_start query string false "date_start"
+// @Param 		 date_end query string false "date_end"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} StatisticsResponse
+// @Router       /customers/me/statistics [get]
+func (ctl *Controller) AccountStatistics(c echo.Context) error {
+	user, err := ctl.customer(c)
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "customer not found")
+	}
+
+	var data DateRangeData
+	if err = c.Bind(&data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	startDate, endDate, err := data.parse()
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	stats, err := ctl.ocservUserRepo.UserStatistics(c.Request().Context(), user.UID, startDate, endDate)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, StatisticsResponse{Statistics: stats})
+}
+
+// AccountSessionLogs 	     Customer session history
+//
+// @Summary      Customer session history
+// @Description  Customer session history
+// @Tags         Customers(Portal)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 page query int false "Page number, starting from 1" minimum(1)
+// @Param 		 size query int false "Number of items per page" minimum(1) maximum(100) name(size)
+// @Param 		 date_start query string false "date_start"
+// @Param 		 date_end query string false "date_end"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} SessionLogsResponse
+// @Router       /customers/me/session_logs [get]
+func (ctl *Controller) AccountSessionLogs(c echo.Context) error {
+	user, err := ctl.customer(c)
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "customer not found")
+	}
+
+	var data DateRangeData
+	if err = c.Bind(&data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	startDate, endDate, err := data.parse()
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	pagination := ctl.request.Pagination(c)
+
+	logs, total, err := ctl.ocservUserRepo.UserSessionLogs(c.Request().Context(), pagination, user.Username, startDate, endDate)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SessionLogsResponse{
+		Meta: request.Meta{
+			Page:         pagination.Page,
+			TotalRecords: total,
+			PageSize:     pagination.PageSize,
+		},
+		Result: logs,
+	})
+}
+
+// AccountSessions 	     Customer active sessions
+//
+// @Summary      Customer active sessions
+// @Description  Customer active (online) sessions
+// @Tags         Customers(Portal)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {array} models.OnlineUserSession
+// @Router       /customers/me/sessions [get]
+func (ctl *Controller) AccountSessions(c echo.Context) error {
+	user, err := ctl.customer(c)
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "customer not found")
+	}
+
+	onlineSessions, err := ctl.occtl.OnlineUsersInfo()
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	sessions := make([]models.OnlineUserSession, 0)
+	for _, session := range *onlineSessions {
+		if session.Username == user.Username {
+			sessions = append(sessions, session)
+		}
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// AccountDisconnectSessions 	     Customer disconnect sessions
+//
+// @Summary      Disconnect all online sessions of the customer
+// @Description  Disconnect all online sessions of the customer
+// @Tags         Customers(Portal)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      202  {object} nil
+// @Router       /customers/me/disconnect_sessions [post]
+func (ctl *Controller) AccountDisconnectSessions(c echo.Context) error {
+	user, err := ctl.customer(c)
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "customer not found")
+	}
+
+	if _, err = ctl.occtl.Disconnect(user.Username); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, nil)
+}
+
+// AccountChangePassword 	     Customer change password
+//
+// @Summary      Customer change password
+// @Description  Change the customer ocserv password. The ocpasswd file is updated as well and the customer tokens, this one included, are revoked
+// @Tags         Customers(Portal)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param        request body  ChangePasswordData  true "customer old and new password"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} nil
+// @Router       /customers/me/password [post]
+func (ctl *Controller) AccountChangePassword(c echo.Context) error {
+	user, err := ctl.customer(c)
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "customer not found")
+	}
+
+	var data ChangePasswordData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	if user.Password != data.OldPassword {
+		return ctl.request.BadRequest(c, errors.New("invalid old password"))
+	}
+	if data.NewPassword == "Secret-Ocpasswd" {
+		return ctl.request.BadRequest(c, errors.New("invalid new password"))
+	}
+
+	user.Password = data.NewPassword
+	user.TokenVersion++
+	if _, err = ctl.ocservUserRepo.Update(c.Request().Context(), user); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, nil)
+}
+
+// AccountConnectionProfile 	     Customer connection profile
+//
+// @Summary      Customer connection profile
+// @Description  Client connection profile of the customer as AnyConnect XML, OpenConnect command line or deep link QR code
+// @Tags         Customers(Portal)
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 format query string false "Profile format" Enums(json, anyconnect, openconnect, qr)
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} profile.Bundle
+// @Router       /customers/me/profile [get]
+func (ctl *Controller) AccountConnectionProfile(c echo.Context) error {
+	user, err := ctl.customer(c)
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "customer not found")
+	}
+
+	client := profile.NewClient(user.Username, user.Group)
+	if err = profile.Render(c, ctl.profileGenerator, client, c.QueryParam("format")); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return nil
+}
+
+// customer loads the ocserv user of the customer token set by CustomerAuthMiddleware.
+func (ctl *Controller) customer(c echo.Context) (*models.OcservUser, error) {
+	uid, ok := c.Get("customerUID").(string)
+	if !ok || uid == "" {
+		return nil, errors.New("invalid customer uid")
+	}
+	user, err := ctl.ocservUserRepo.GetByUID(c.Request().Context(), uid)
+	if err != nil {
+		return nil, err
+	}
+	// JSON numbers of the claims are decoded as float64
+	if version, _ := c.Get("customerTokenVersion").(float64); int(version) != user.TokenVersion {
+		return nil, errors.New("revoked customer token")
+	}
+	return user, nil
+}
+
+func (ctl *Controller) summary(ctx context.Context, user *models.OcservUser) (*SummaryResponse, error) {
+	dateEnd := time.Now()
+	firstOfThisMonth := time.Date(dateEnd.Year(), dateEnd.Month(), 1, 0, 0, 0, 0, dateEnd.Location())
+	dateStart := firstOfThisMonth.AddDate(0, -1, 0)
+
+	usage, err := ctl.ocservUserRepo.TotalBandwidthUserDateRange(
+		ctx,
+		strconv.Itoa(int(user.ID)),
+		&dateStart,
+		&dateEnd,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SummaryResponse{
+		OcservUser: modelCustomer(user),
+		Usage: UsageResponse{
+			DateStart:  dateStart,
+			DateEnd:    dateEnd,
+			Bandwidths: usage,
+		},
+	}, nil
+}
+
+func modelCustomer(user *models.OcservUser) ModelCustomer {
+	return ModelCustomer{
+		Owner:         user.Owner,
+		Username:      user.Username,
+		IsLocked:      user.IsLocked,
+		ExpireAt:      user.ExpireAt,
+		DeactivatedAt: user.DeactivatedAt,
+		CycleAnchor:   user.CycleAnchor,
+		TrafficType:   user.TrafficType,
+		TrafficSize:   user.TrafficSize,
+		Rx:            user.Rx,
+		Tx:            user.Tx,
+	}
+}
@@ -10,4 +10,14 @@ func Routes(e *echo.Group) {
 	g := e.Group("/customers")
 	g.POST("/summary", ctl.Summary, middlewares.RateLimitMiddleware(2, "m", 5))
 	g.POST("/disconnect_sessions", ctl.DisconnectSessions, middlewares.RateLimitMiddleware(1, "m", 2))
+	g.POST("/login", ctl.Login, middlewares.RateLimitMiddleware(5, "m", 5))
+
+	me := g.Group("/me", middlewares.CustomerAuthMiddleware())
+	me.GET("/summary", ctl.AccountSummary)
+	me.GET("/statistics", ctl.AccountStatistics)
+	me.GET("/session_logs", ctl.AccountSessionLogs)
+	me.GET("/sessions", ctl.AccountSessions)
+	me.POST("/disconnect_sessions", ctl.AccountDisconnectSessions, middlewares.RateLimitMiddleware(2, "m", 2))
+	me.POST("/password", ctl.AccountChangePassword, middlewares.RateLimitMiddleware(2, "m", 3))
+	me.GET("/profile", ctl.AccountConnectionProfile)
 }
@@ -1,7 +1,10 @@
 package customer
 
 import (
+	"fmt"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
 	"time"
 )
 
@@ -16,6 +19,7 @@ type ModelCustomer struct {
 	IsLocked      bool       `json:"is_locked" gorm:"default(false)" validate:"required"`
 	ExpireAt      *time.Time `json:"expire_at" gorm:"type:date" validate:"required"`
 	DeactivatedAt *time.Time `json:"deactivated_at" gorm:"type:date" validate:"required"`
+	CycleAnchor   time.Time  `json:"cycle_anchor" gorm:"type:date" validate:"required"` // monthly quotas reset on its day of month
 	TrafficType   string     `json:"traffic_type" gorm:"type:varchar(32);not null;default:1" enums:"Free,MonthlyTransmit,MonthlyReceive,TotallyTransmit,TotallyReceive" validate:"required"`
 	TrafficSize   int        `json:"traffic_size" gorm:"not null" validate:"required"` // in GiB  >> x * 1024 ** 3
 	Rx            int        `json:"rx" gorm:"not null;default:0" validate:"required"` // Receive in bytes
@@ -32,3 +36,55 @@ type SummaryResponse struct {
 	OcservUser ModelCustomer `json:"ocserv_user" validate:"required"`
 	Usage      UsageResponse `json:"usage" validate:"required"`
 }
+
+type LoginData struct {
+	Username string `json:"username" validate:"required,min=2,max=32"`
+	Password string `json:"password" validate:"required,min=2,max=32"`
+}
+
+type LoginResponse struct {
+	Token      string        `json:"token" validate:"required"`
+	ExpireAt   time.Time     `json:"expire_at" validate:"required"`
+	OcservUser ModelCustomer `json:"ocserv_user" validate:"required"`
+}
+
+type DateRangeData struct {
+	DateStart string `json:"date_start" query:"date_start" validate:"omitempty" example:"2025-1-31"`
+	DateEnd   string `json:"date_end" query:"date_end" validate:"omitempty" example:"2025-12-31"`
+}
+
+func (d *DateRangeData) parse() (*time.Time, *time.Time, error) {
+	var startDate, endDate *time.Time
+
+	if d.DateStart != "" {
+		t, err := time.Parse("2006-01-02", d.DateStart)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid date_start: %w", err)
+		}
+		startDate = &t
+	}
+
+	if d.DateEnd != "" {
+		t, err := time.Parse("2006-01-02", d.DateEnd)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid date_end: %w", err)
+		}
+		t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		endDate = &t
+	}
+	return startDate, endDate, nil
+}
+
+type StatisticsResponse struct {
+	Statistics []models.DailyTraffic `json:"statistics" validate:"required"`
+}
+
+type SessionLogsResponse struct {
+	Meta   request.Meta                   `json:"meta" validate:"required"`
+	Result *[]models.OcservUserSessionLog `json:"result" validate:"omitempty"`
+}
+
+type ChangePasswordData struct {
+	OldPassword string `json:"old_password" validate:"required,min=2,max=32"`
+	NewPassword string `json:"new_password" validate:"required,min=2,max=32"`
+}
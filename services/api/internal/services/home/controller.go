@@ -2,12 +2,16 @@ package home
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/labstack/echo/v4"
+	apiModels "github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/monitor"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
 	"golang.org/x/sync/errgroup"
 	"math"
@@ -29,6 +33,8 @@ type Controller struct {
 	occtlRepo      repository.OcctlRepositoryInterface
 	ocservUserRepo repository.OcservUserRepositoryInterface
 	reportRepo     repository.ReportRepositoryInterface
+	metricsRepo    repository.MetricsRepositoryInterface
+	serverRepo     repository.ServerRepositoryInterface
 }
 
 func New() *Controller {
@@ -37,6 +43,8 @@ func New() *Controller {
 		occtlRepo:      repository.NewOcctlRepository(),
 		ocservUserRepo: repository.NewtOcservUserRepository(),
 		reportRepo:     repository.NewtReportRepository(),
+		metricsRepo:    repository.NewMetricsRepository(),
+		serverRepo:     repository.NewServerRepository(),
 	}
 }
 
@@ -179,19 +187,49 @@ func (ctl *Controller) Home(c echo.Context) error {
 // @Success      200  {object} OcservStatusResponse
 // @Router       /home/ocserv-stats [get]
 func (ctl *Controller) OcservStats(c echo.Context) error {
-	var status OcservStatusResponse
-
 	serverStatus, err := ctl.occtlRepo.Status()
 	if err != nil {
-		return nil
-	}
-	if serverStatusMap, ok := serverStatus.(map[string]interface{}); ok {
-		status = ParseServerStatus(serverStatusMap)
+		logger.Warn("failed to get ocserv status: %v", err)
+		return c.JSON(http.StatusOK, OcservStatusResponse{})
 	}
+	status := ParseServerStatus(serverStatus)
 
 	return c.JSON(http.StatusOK, status)
 }
 
+// ServersHealth Health of the ocserv servers
+//
+// @Summary      Health of the ocserv servers
+// @Description  Status, latency, online sessions and traffic of the ocserv of the dashboard and of every server,
+// @Description  with the sessions and traffic summed over the healthy ones
+// @Tags         Home
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} ServersHealthResponse
+// @Router       /home/servers [get]
+func (ctl *Controller) ServersHealth(c echo.Context) error {
+	servers, err := ctl.serverRepo.Health(c.Request().Context())
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	resp := ServersHealthResponse{Servers: servers}
+	for _, server := range servers {
+		if !server.Healthy {
+			continue
+		}
+		resp.Healthy++
+		resp.ActiveSessions += server.ActiveSessions
+		resp.RX += server.RX
+		resp.TX += server.TX
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 // SystemUsageStats Content of os system usage stats
 //
 // @Summary      Content of os system usage stats
@@ -446,3 +484,111 @@ func (ctl *Controller) ContainerUsageStats(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, service)
 }
+
+// Metrics Historical server resource metrics
+//
+// @Summary      Historical server resource metrics
+// @Description  Stored samples of cpu, memory, disk, network throughput and online sessions for charts
+// @Tags         Home
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param        range query string false "range of samples" Enums(hour, day, week) default(hour)
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} MetricsResponse
+// @Router       /home/metrics [get]
+func (ctl *Controller) Metrics(c echo.Context) error {
+	var (
+		resolution string
+		since      time.Duration
+		bucket     time.Duration
+	)
+
+	rangeName := c.QueryParam("range")
+	switch rangeName {
+	case "", "hour":
+		rangeName = "hour"
+		resolution, since, bucket = apiModels.MetricResolutionRaw, time.Hour, config.Get().Metrics.Interval
+	case "day":
+		resolution, since, bucket = apiModels.MetricResolutionRaw, 24*time.Hour, 5*time.Minute
+	case "week":
+		resolution, since, bucket = apiModels.MetricResolutionHour, 7*24*time.Hour, time.Hour
+	default:
+		return ctl.request.BadRequest(c, fmt.Errorf("invalid range: %s", rangeName))
+	}
+
+	metrics, err := ctl.metricsRepo.Metrics(c.Request().Context(), resolution, time.Now().Add(-since), bucket)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, MetricsResponse{
+		Range:    rangeName,
+		Interval: int(bucket.Seconds()),
+		Result:   metrics,
+	})
+}
+
+// Bandwidth Live server and session bandwidth
+//
+// @Summary      Live server and session bandwidth
+// @Description  Current throughput in bits per second of every interface and ocserv session (tun device)
+// @Tags         Home
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} monitor.BandwidthSnapshot
+// @Router       /home/bandwidth [get]
+func (ctl *Controller) Bandwidth(c echo.Context) error {
+	snapshot := monitor.Bandwidth().Snapshot()
+	if snapshot == nil {
+		return ctl.request.BadRequest(c, fmt.Errorf("bandwidth is not sampled yet"))
+	}
+	return c.JSON(http.StatusOK, snapshot)
+}
+
+// BandwidthStream Live server and session bandwidth stream
+//
+// @Summary      Live server and session bandwidth stream
+// @Description  Server-Sent Events stream of bandwidth snapshots, one "bandwidth" event per sample
+// @Tags         Home
+// @Produce      text/event-stream
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} monitor.BandwidthSnapshot
+// @Router       /home/bandwidth/stream [get]
+func (ctl *Controller) BandwidthStream(c echo.Context) error {
+	bandwidth := monitor.Bandwidth()
+	ch := bandwidth.Subscribe()
+	defer bandwidth.Unsubscribe(ch)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case snapshot, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(snapshot)
+			if err != nil {
+				return err
+			}
+			if _, err = fmt.Fprintf(res, "event: bandwidth\ndata: %s\n\n", data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
@@ -2,15 +2,20 @@ package home
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 )
 
 func Routes(e *echo.Group) {
 	ctl := New()
-	g := e.Group("/home", middlewares.AuthMiddleware())
+	g := e.Group("/home", middlewares.AuthMiddleware(repository.NewUserTokenRepository()))
 
 	g.GET("", ctl.Home)
 	g.GET("/ocserv-stats", ctl.OcservStats)
+	g.GET("/servers", ctl.ServersHealth)
 	g.GET("/system-stats", ctl.SystemUsageStats)
 	g.GET("/container-stats", ctl.ContainerUsageStats)
+	g.GET("/metrics", ctl.Metrics)
+	g.GET("/bandwidth", ctl.Bandwidth)
+	g.GET("/bandwidth/stream", ctl.BandwidthStream)
 }
@@ -1,6 +1,7 @@
 package home
 
 import (
+	apiModels "github.com/mmtaee/ocserv-dashboard/api/internal/models"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
 )
@@ -70,6 +71,14 @@ type GetHomeResponse struct {
 	//IRoutes    *[]models.Iroute       `json:"iroutes" validate:"omitempty"` // has bug on version 1.2.4
 }
 
+type ServersHealthResponse struct {
+	Servers        []repository.ServerHealth `json:"servers" validate:"required"`
+	Healthy        int                       `json:"healthy" validate:"required"`
+	ActiveSessions int                       `json:"active_sessions" validate:"required"`
+	RX             int64                     `json:"rx" validate:"required"` // bytes
+	TX             int64                     `json:"tx" validate:"required"` // bytes
+}
+
 type CPU struct {
 	AvgPercent float64 `json:"avg_percent"`
 	UsedUnits  float64 `json:"used_units"`
@@ -114,3 +123,9 @@ type ServerStatusResponse struct {
 	Swap Swap `json:"swap"`
 	Disk Disk `json:"disk"`
 }
+
+type MetricsResponse struct {
+	Range    string                   `json:"range" validate:"required" example:"hour"`
+	Interval int                      `json:"interval" validate:"required" desc:"seconds between points"`
+	Result   []apiModels.ServerMetric `json:"result" validate:"omitempty"`
+}
@@ -1,69 +1,51 @@
 package home
 
-func ParseServerStatus(flat map[string]interface{}) OcservStatusResponse {
-	// Helper funcs
-	getStr := func(key string) string {
-		if v, ok := flat[key].(string); ok {
-			return v
-		}
-		return ""
-	}
-	getInt := func(key string) int {
-		if v, ok := flat[key].(float64); ok {
-			return int(v)
-		}
-		return 0
-	}
-	getInt64 := func(key string) int64 {
-		if v, ok := flat[key].(float64); ok {
-			return int64(v)
-		}
-		return 0
-	}
+import "github.com/mmtaee/ocserv-dashboard/common/models"
 
+func ParseServerStatus(status *models.ServerStatus) OcservStatusResponse {
 	return OcservStatusResponse{
 		GeneralInfo: GeneralInfo{
-			ServerPID:           getInt("Server PID"),
-			SecModPID:           getInt("Sec-mod PID"),
-			SecModInstanceCount: getInt("Sec-mod instance count"),
-			Status:              getStr("Status"),
-			UpSince:             getStr("Up since"),
-			UpSinceDuration:     getStr("_Up since"),
-			ActiveSessions:      getInt("Active sessions"),
-			TotalSessions:       getInt("Total sessions"),
-			TotalAuthFailures:   getInt("Total authentication failures"),
-			IPsInBanList:        getInt("IPs in ban list"),
-			MedianLatency:       getStr("Median latency"),
-			STDEVLatency:        getStr("STDEV latency"),
+			ServerPID:           int(status.ServerPID),
+			SecModPID:           int(status.SecModPID),
+			SecModInstanceCount: int(status.SecModInstanceCount),
+			Status:              status.Status,
+			UpSince:             status.UpSince,
+			UpSinceDuration:     status.UpSinceDuration,
+			ActiveSessions:      int(status.ActiveSessions),
+			TotalSessions:       int(status.TotalSessions),
+			TotalAuthFailures:   int(status.TotalAuthFailures),
+			IPsInBanList:        int(status.IPsInBanList),
+			MedianLatency:       status.MedianLatency,
+			STDEVLatency:        status.STDEVLatency,
 
-			RawMedianLatency: getInt64("raw_median_latency"),
-			RawSTDEVLatency:  getInt64("raw_stdev_latency"),
-			RawUpSince:       getInt64("raw_up_since"),
-			Uptime:           getInt64("uptime"),
+			RawMedianLatency: int64(status.RawMedianLatency),
+			RawSTDEVLatency:  int64(status.RawSTDEVLatency),
+			RawUpSince:       int64(status.RawUpSince),
+			Uptime:           int64(status.Uptime),
 		},
 
 		CurrentStats: CurrentStats{
-			LastStatsReset:           getStr("Last stats reset"),
-			LastStatsResetDuration:   getStr("_Last stats reset"),
-			SessionsHandled:          getInt("Sessions handled"),
-			TimedOutSessions:         getInt("Timed out sessions"),
-			TimedOutIdleSessions:     getInt("Timed out (idle) sessions"),
-			ClosedDueToErrorSessions: getInt("Closed due to error sessions"),
-			AuthenticationFailures:   getInt("Authentication failures"),
-			AverageAuthTime:          getStr("Average auth time"),
-			MaxAuthTime:              getStr("Max auth time"),
-			AverageSessionTime:       getStr("Average session time"),
-			MaxSessionTime:           getStr("Max session time"),
-			RX:                       getStr("RX"),
-			TX:                       getStr("TX"),
+			LastStatsReset:           status.LastStatsReset,
+			LastStatsResetDuration:   status.LastStatsResetDuration,
+			SessionsHandled:          int(status.SessionsHandled),
+			TimedOutSessions:         int(status.TimedOutSessions),
+			TimedOutIdleSessions:     int(status.TimedOutIdleSessions),
+			ClosedDueToErrorSessions: int(status.ClosedDueToErrorSessions),
+			AuthenticationFailures:   int(status.AuthenticationFailures),
+			AverageAuthTime:          status.AverageAuthTime,
+			MaxAuthTime:              status.MaxAuthTime,
+			AverageSessionTime:       status.AverageSessionTime,
+			MaxSessionTime:           status.MaxSessionTime,
+			RX:                       status.RX,
+			TX:                       status.TX,
 
-			RawRX:             getInt64("raw_rx"),
-			RawTX:             getInt64("raw_tx"),
-			RawAvgAuthTime:    getInt64("raw_avg_auth_time"),
-			RawMaxAuthTime:    getInt64("raw_max_auth_time"),
-			RawAvgSessionTime: getInt64("raw_avg_session_time"),
-			RawMaxSessionTime: getInt64("raw_max_session_time"),
-			RawLastStatsReset: getInt64("raw_last_stats_reset"),
+			RawRX:             int64(status.RawRX),
+			RawTX:             int64(status.RawTX),
+			RawAvgAuthTime:    int64(status.RawAvgAuthTime),
+			RawMaxAuthTime:    int64(status.RawMaxAuthTime),
+			RawAvgSessionTime: int64(status.RawAvgSessionTime),
+			RawMaxSessionTime: int64(status.RawMaxSessionTime),
+			RawLastStatsReset: int64(status.RawLastStatsReset),
 		},
 	}
 }
@@ -0,0 +1,267 @@
+package ip_ban
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/geoip"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Controller struct {
+	request   request.CustomRequestInterface
+	banRepo   repository.IPBanRepositoryInterface
+	occtlRepo repository.OcctlRepositoryInterface
+}
+
+func New() *Controller {
+	return &Controller{
+		request:   request.NewCustomRequest(),
+		banRepo:   repository.NewIPBanRepository(),
+		occtlRepo: repository.NewOcctlRepository(),
+	}
+}
+
+// IPBans 	 List of manual IP bans
+//
+// @Summary      List of manual IP bans
+// @Description  List of IP bans added by operators, expired bans are removed by the ban poller
+// @Tags         IP Bans
+// @Accept       json
+// @Produce      json
+// @Param 		 page query int false "Page number, starting from 1" minimum(1)
+// @Param 		 size query int false "Number of items per page" minimum(1) maximum(100) name(size)
+// @Param 		 order query string false "Field to order by"
+// @Param 		 sort query string false "Sort order, either ASC or DESC" Enums(ASC, DESC)
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  IPBansResponse
+// @Router       /ip_bans [get]
+func (ctl *Controller) IPBans(c echo.Context) error {
+	pagination := ctl.request.Pagination(c)
+
+	bans, total, err := ctl.banRepo.Bans(c.Request().Context(), pagination)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, IPBansResponse{
+		Meta: request.Meta{
+			Page:         pagination.Page,
+			PageSize:     pagination.PageSize,
+			TotalRecords: total,
+		},
+		Result: bans,
+	})
+}
+
+// CreateIPBan 	 Manual IP ban
+//
+// @Summary      Manual IP ban
+// @Description  Ban an IP address for a duration or until removed. Sessions from the address are disconnected
+// @Description  immediately and whenever they connect again. Allowlisted addresses cannot be banned.
+// @Tags         IP Bans
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param        request    body  CreateIPBanData  true "ip ban data"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      201  {object}  models.IPBan
+// @Router       /ip_bans [post]
+func (ctl *Controller) CreateIPBan(c echo.Context) error {
+	var data CreateIPBanData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	ip := net.ParseIP(data.IP)
+	if ip == nil {
+		return ctl.request.BadRequest(c, errors.New("invalid ip"))
+	}
+
+	allowlist, err := ctl.banRepo.Allowlist(c.Request().Context())
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	for _, entry := range allowlist {
+		if entry.Contains(ip) {
+			return ctl.request.BadRequest(c, errors.New("ip is in the allowlist ("+entry.CIDR+")"))
+		}
+	}
+
+	username, _ := c.Get("username").(string)
+	ban := &models.IPBan{
+		IP:        ip.String(),
+		Reason:    data.Reason,
+		Country:   geoip.Country(ip.String()),
+		CreatedBy: username,
+	}
+	if data.Duration > 0 {
+		expiresAt := time.Now().Add(time.Duration(data.Duration) * time.Minute)
+		ban.ExpiresAt = &expiresAt
+	}
+
+	ban, err = ctl.banRepo.CreateBan(c.Request().Context(), ban)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	go func(ip string) {
+		if _, err := ctl.occtlRepo.DisconnectIP(ip); err != nil {
+			logger.Error("Failed to disconnect banned IP %s: %v", ip, err)
+		}
+	}(ban.IP)
+
+	return c.JSON(http.StatusCreated, ban)
+}
+
+// DeleteIPBan 	 Remove manual IP ban
+//
+// @Summary      Remove manual IP ban
+// @Description  Remove manual IP ban
+// @Tags         IP Bans
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 id path int true "IP ban ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      204  {object} nil
+// @Router       /ip_bans/{id} [delete]
+func (ctl *Controller) DeleteIPBan(c echo.Context) error {
+	err := ctl.banRepo.DeleteBan(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// IPBanHistory 	 IP ban history
+//
+// @Summary      IP ban history
+// @Description  Addresses seen in the ocserv ban list with their first and last sight, last and highest score
+// @Tags         IP Bans
+// @Accept       json
+// @Produce      json
+// @Param 		 page query int false "Page number, starting from 1" minimum(1)
+// @Param 		 size query int false "Number of items per page" minimum(1) maximum(100) name(size)
+// @Param 		 order query string false "Field to order by"
+// @Param 		 sort query string false "Sort order, either ASC or DESC" Enums(ASC, DESC)
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  IPBanHistoryResponse
+// @Router       /ip_bans/history [get]
+func (ctl *Controller) IPBanHistory(c echo.Context) error {
+	pagination := ctl.request.Pagination(c)
+
+	history, total, err := ctl.banRepo.BanHistory(c.Request().Context(), pagination)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, IPBanHistoryResponse{
+		Meta: request.Meta{
+			Page:         pagination.Page,
+			PageSize:     pagination.PageSize,
+			TotalRecords: total,
+		},
+		Result: history,
+	})
+}
+
+// Allowlist 	 IP allowlist
+//
+// @Summary      IP allowlist
+// @Description  Addresses and networks that are never kept banned
+// @Tags         IP Bans
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {array}  models.IPAllowlist
+// @Router       /ip_bans/allowlist [get]
+func (ctl *Controller) Allowlist(c echo.Context) error {
+	allowlist, err := ctl.banRepo.Allowlist(c.Request().Context())
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, allowlist)
+}
+
+// CreateAllowlist 	 Add to IP allowlist
+//
+// @Summary      Add to IP allowlist
+// @Description  Allowlist an IP address or CIDR network. ocserv bans of matching addresses are lifted by the ban poller.
+// @Tags         IP Bans
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param        request    body  CreateAllowlistData  true "allowlist data"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      201  {object}  models.IPAllowlist
+// @Router       /ip_bans/allowlist [post]
+func (ctl *Controller) CreateAllowlist(c echo.Context) error {
+	var data CreateAllowlistData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	entry := &models.IPAllowlist{Note: data.Note}
+	if _, network, err := net.ParseCIDR(data.CIDR); err == nil {
+		entry.CIDR = network.String()
+	} else if ip := net.ParseIP(data.CIDR); ip != nil {
+		entry.CIDR = ip.String()
+	} else {
+		return ctl.request.BadRequest(c, errors.New("invalid ip or cidr"))
+	}
+
+	entry, err := ctl.banRepo.CreateAllowlist(c.Request().Context(), entry)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusCreated, entry)
+}
+
+// DeleteAllowlist 	 Remove from IP allowlist
+//
+// @Summary      Remove from IP allowlist
+// @Description  Remove from IP allowlist
+// @Tags         IP Bans
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 id path int true "Allowlist entry ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      204  {object} nil
+// @Router       /ip_bans/allowlist/{id} [delete]
+func (ctl *Controller) DeleteAllowlist(c echo.Context) error {
+	err := ctl.banRepo.DeleteAllowlist(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
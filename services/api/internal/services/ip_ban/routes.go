@@ -0,0 +1,19 @@
+package ip_ban
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
+)
+
+func Routes(e *echo.Group) {
+	ctl := New()
+	g := e.Group("/ip_bans", middlewares.AuthMiddleware(repository.NewUserTokenRepository()))
+	g.GET("", ctl.IPBans)
+	g.POST("", ctl.CreateIPBan, middlewares.AdminPermission())
+	g.DELETE("/:id", ctl.DeleteIPBan, middlewares.AdminPermission())
+	g.GET("/history", ctl.IPBanHistory)
+	g.GET("/allowlist", ctl.Allowlist)
+	g.POST("/allowlist", ctl.CreateAllowlist, middlewares.AdminPermission())
+	g.DELETE("/allowlist/:id", ctl.DeleteAllowlist, middlewares.AdminPermission())
+}
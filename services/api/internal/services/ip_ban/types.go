@@ -0,0 +1,27 @@
+package ip_ban
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+)
+
+type CreateIPBanData struct {
+	IP       string `json:"ip" validate:"required,ip"`
+	Duration int    `json:"duration" validate:"omitempty,min=1" desc:"ban duration in minutes, empty bans until removed"`
+	Reason   string `json:"reason" validate:"omitempty,max=512"`
+}
+
+type CreateAllowlistData struct {
+	CIDR string `json:"cidr" validate:"required" desc:"IP address or CIDR network"`
+	Note string `json:"note" validate:"omitempty,max=512"`
+}
+
+type IPBansResponse struct {
+	Meta   request.Meta   `json:"meta" validate:"required"`
+	Result []models.IPBan `json:"result" validate:"omitempty"`
+}
+
+type IPBanHistoryResponse struct {
+	Meta   request.Meta          `json:"meta" validate:"required"`
+	Result []models.IPBanHistory `json:"result" validate:"omitempty"`
+}
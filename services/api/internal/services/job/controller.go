@@ -0,0 +1,181 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/schedule"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type Controller struct {
+	request request.CustomRequestInterface
+	jobRepo repository.JobRepositoryInterface
+}
+
+func New() *Controller {
+	return &Controller{
+		request: request.NewCustomRequest(),
+		jobRepo: repository.NewJobRepository(),
+	}
+}
+
+// Jobs 	 List of scheduled jobs
+//
+// @Summary      List of scheduled jobs
+// @Description  List of the jobs of the user expiry service with their schedules, next and latest runs
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  JobsResponse
+// @Router       /jobs [get]
+func (ctl *Controller) Jobs(c echo.Context) error {
+	jobs, err := ctl.jobRepo.Jobs(c.Request().Context())
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, JobsResponse{Result: jobs})
+}
+
+// Job 	 Scheduled job detail
+//
+// @Summary      Scheduled job detail
+// @Description  Scheduled job detail
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 name path string true "Job name"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {object}  repository.JobStatus
+// @Router       /jobs/{name} [get]
+func (ctl *Controller) Job(c echo.Context) error {
+	job, err := ctl.jobRepo.GetByName(c.Request().Context(), c.Param("name"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// JobRuns 	 Run history of a job
+//
+// @Summary      Run history of a job
+// @Description  Runs of a job with their trigger, duration, affected users and errors, latest first
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 name path string true "Job name"
+// @Param 		 page query int false "Page number, starting from 1" minimum(1)
+// @Param 		 size query int false "Number of items per page" minimum(1) maximum(100) name(size)
+// @Param 		 order query string false "Field to order by"
+// @Param 		 sort query string false "Sort order, either ASC or DESC" Enums(ASC, DESC)
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {object}  JobRunsResponse
+// @Router       /jobs/{name}/runs [get]
+func (ctl *Controller) JobRuns(c echo.Context) error {
+	pagination := ctl.request.Pagination(c)
+
+	runs, total, err := ctl.jobRepo.Runs(c.Request().Context(), c.Param("name"), pagination)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, JobRunsResponse{
+		Meta: request.Meta{
+			Page:         pagination.Page,
+			PageSize:     pagination.PageSize,
+			TotalRecords: total,
+		},
+		Result: runs,
+	})
+}
+
+// UpdateJob 	 Scheduled job update
+//
+// @Summary      Scheduled job update
+// @Description  Change the schedule of a job or pause it, paused jobs only run when triggered
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 name path string true "Job name"
+// @Param        request    body  UpdateJobData  true "job data"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {object}  repository.JobStatus
+// @Router       /jobs/{name} [patch]
+func (ctl *Controller) UpdateJob(c echo.Context) error {
+	var data UpdateJobData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	job, err := ctl.jobRepo.GetByName(c.Request().Context(), c.Param("name"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	if data.Schedule != nil && *data.Schedule != "" {
+		if _, err = schedule.Parse(*data.Schedule); err != nil {
+			return ctl.request.BadRequest(c, fmt.Errorf("invalid schedule: %w", err))
+		}
+		job.Schedule = *data.Schedule
+	}
+	if data.Paused != nil {
+		job.Paused = *data.Paused
+	}
+
+	updated, err := ctl.jobRepo.Update(c.Request().Context(), &job.Job)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// TriggerJob 	 Run a job now
+//
+// @Summary      Run a job now
+// @Description  Ask the user expiry service to run a job now, paused jobs included. The run shows up in the job history within seconds.
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 name path string true "Job name"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      202  {object}  repository.JobStatus
+// @Router       /jobs/{name}/trigger [post]
+func (ctl *Controller) TriggerJob(c echo.Context) error {
+	job, err := ctl.jobRepo.Trigger(c.Request().Context(), c.Param("name"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
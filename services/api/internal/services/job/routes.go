@@ -0,0 +1,17 @@
+package job
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
+)
+
+func Routes(e *echo.Group) {
+	ctl := New()
+	g := e.Group("/jobs", middlewares.AuthMiddleware(repository.NewUserTokenRepository()))
+	g.GET("", ctl.Jobs)
+	g.GET("/:name", ctl.Job)
+	g.GET("/:name/runs", ctl.JobRuns)
+	g.PATCH("/:name", ctl.UpdateJob, middlewares.AdminPermission())
+	g.POST("/:name/trigger", ctl.TriggerJob, middlewares.AdminPermission())
+}
@@ -0,0 +1,21 @@
+package job
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+)
+
+type UpdateJobData struct {
+	Schedule *string `json:"schedule" validate:"omitempty,max=64" example:"0 1 0 * * *" desc:"cron spec with a leading seconds field"`
+	Paused   *bool   `json:"paused" validate:"omitempty"`
+}
+
+type JobsResponse struct {
+	Result []repository.JobStatus `json:"result" validate:"omitempty"`
+}
+
+type JobRunsResponse struct {
+	Meta   request.Meta    `json:"meta" validate:"required"`
+	Result []models.JobRun `json:"result" validate:"omitempty"`
+}
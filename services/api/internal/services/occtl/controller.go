@@ -2,12 +2,16 @@ package occtl
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/monitor"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/services/home"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
 	"net/http"
 	"strings"
@@ -44,19 +48,12 @@ func (ctl *Controller) ServerInfo(c echo.Context) error {
 
 	serverStatus, err := ctl.occtlRepo.Status()
 	if err != nil {
-		logger.Error("Get server status error", err)
+		logger.Error("Get server status error: %v", err)
 		info.Status = "error"
 		return c.JSON(http.StatusOK, info)
 	}
 
-	serverStatusMap, ok := serverStatus.(map[string]interface{})
-	if !ok {
-		logger.Error("Invalid server status format")
-		info.Status = "error"
-		return c.JSON(http.StatusOK, info)
-	}
-
-	status := home.ParseServerStatus(serverStatusMap)
+	status := home.ParseServerStatus(serverStatus)
 	if status.GeneralInfo.Status != "" {
 		info.Status = status.GeneralInfo.Status
 	}
@@ -67,7 +64,8 @@ func (ctl *Controller) ServerInfo(c echo.Context) error {
 // Commands 	 Occtl Commands
 //
 // @Summary      Occtl Commands
-// @Description  Occtl Commands
+// @Description  Occtl Commands by numeric action ID. Kept for compatibility, use the typed occtl endpoints instead.
+// @Description  Actions changing the server state (4, 9, 13) require admin permission
 // @Tags         OCCTL
 // @Accept       json
 // @Produce      json
@@ -76,7 +74,9 @@ func (ctl *Controller) ServerInfo(c echo.Context) error {
 // @Param        value   query   string  false  "Optional parameter depending on command"
 // @Failure      400 {object} request.ErrorResponse
 // @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
 // @Success      200  {object}  string
+// @Deprecated
 // @Router       /occtl/commands [get]
 func (ctl *Controller) Commands(c echo.Context) error {
 	var data CommandParamsData
@@ -109,6 +109,11 @@ func (ctl *Controller) Commands(c echo.Context) error {
 	if !exists {
 		return ctl.request.BadRequest(c, fmt.Errorf("unknown action %d", data.Action))
 	}
+	if adminActions[data.Action] {
+		if isAdmin, _ := c.Get("isAdmin").(bool); !isAdmin {
+			return middlewares.PermissionDeniedError(c, "Admin permission required")
+		}
+	}
 
 	res, err = handler(data.Value)
 	if err != nil {
@@ -122,3 +127,206 @@ func (ctl *Controller) Commands(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, strings.TrimSpace(string(results)))
 }
+
+// Sessions 	 Occtl sessions
+//
+// @Summary      List ocserv sessions
+// @Description  List ocserv sessions (occtl show sessions)
+// @Tags         OCCTL
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param        state query string false "sessions state" Enums(all, valid) default(all)
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {array}  models.OcctlSession
+// @Router       /occtl/sessions [get]
+func (ctl *Controller) Sessions(c echo.Context) error {
+	var data SessionsQueryData
+	if err := c.Bind(&data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	var (
+		sessions *[]models.OcctlSession
+		err      error
+	)
+	if data.State == "valid" {
+		sessions, err = ctl.occtlRepo.ShowSessionsValid()
+	} else {
+		sessions, err = ctl.occtlRepo.ShowSessionsAll()
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// Session 	 Occtl session
+//
+// @Summary      Get ocserv session
+// @Description  Get ocserv session by session ID (occtl show session)
+// @Tags         OCCTL
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param        sid path string true "Session ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {object}  models.OcctlSession
+// @Router       /occtl/sessions/{sid} [get]
+func (ctl *Controller) Session(c echo.Context) error {
+	session, err := ctl.occtlRepo.ShowSessionBySID(c.Param("sid"))
+	if err != nil {
+		if errors.Is(err, occtl.ErrNotFound) {
+			return ctl.request.NotFound(c)
+		}
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, session)
+}
+
+// IPBans 	 Occtl IP bans
+//
+// @Summary      List banned IPs
+// @Description  List banned IPs with their scores (occtl show ip bans points)
+// @Tags         OCCTL
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {array}  models.IPBanPoints
+// @Router       /occtl/bans [get]
+func (ctl *Controller) IPBans(c echo.Context) error {
+	bans, err := ctl.occtlRepo.IPBans()
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, bans)
+}
+
+// UnbanIP 	 Occtl unban IP
+//
+// @Summary      Unban IP
+// @Description  Remove the ban of an IP (occtl unban ip)
+// @Tags         OCCTL
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param        ip path string true "IP address"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      200  {object}  CommandResponse
+// @Router       /occtl/bans/{ip} [delete]
+func (ctl *Controller) UnbanIP(c echo.Context) error {
+	output, err := ctl.occtlRepo.UnbanIP(c.Param("ip"))
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, CommandResponse{Output: strings.TrimSpace(output)})
+}
+
+// Reload 	 Occtl reload
+//
+// @Summary      Reload ocserv
+// @Description  Reload the ocserv configuration (occtl reload)
+// @Tags         OCCTL
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      200  {object}  CommandResponse
+// @Router       /occtl/reload [post]
+func (ctl *Controller) Reload(c echo.Context) error {
+	output, err := ctl.occtlRepo.Reload()
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, CommandResponse{Output: strings.TrimSpace(output)})
+}
+
+// IRoutes 	 Occtl iroutes
+//
+// @Summary      List iroutes
+// @Description  List the routes announced by connected users (occtl show iroutes)
+// @Tags         OCCTL
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {array}  models.IRoute
+// @Router       /occtl/iroutes [get]
+func (ctl *Controller) IRoutes(c echo.Context) error {
+	routes, err := ctl.occtlRepo.IRoutes()
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, routes)
+}
+
+// Events 	 Occtl events
+//
+// @Summary      Occtl events
+// @Description  Snapshot of the ocserv events (occtl show events)
+// @Tags         OCCTL
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  CommandResponse
+// @Router       /occtl/events [get]
+func (ctl *Controller) Events(c echo.Context) error {
+	return c.JSON(http.StatusOK, CommandResponse{Output: ctl.occtlRepo.ShowEvent()})
+}
+
+// EventsStream 	 Occtl events stream
+//
+// @Summary      Occtl events stream
+// @Description  Server-Sent Events stream of the ocserv session events (occtl show events), one "connect" or "disconnect" event per session change
+// @Tags         OCCTL
+// @Produce      text/event-stream
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  models.OcctlEvent
+// @Router       /occtl/events/stream [get]
+func (ctl *Controller) EventsStream(c echo.Context) error {
+	events := monitor.Events()
+	ch := events.Subscribe()
+	defer events.Unsubscribe(ch)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if _, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
@@ -2,12 +2,24 @@ package occtl
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 )
 
 func Routes(e *echo.Group) {
 	ctl := New()
+	authentication := middlewares.AuthMiddleware(repository.NewUserTokenRepository())
 	g := e.Group("/occtl")
 	g.GET("/server_info", ctl.ServerInfo)
-	g.GET("/commands", ctl.Commands, middlewares.AuthMiddleware())
+	g.GET("/commands", ctl.Commands, authentication)
+
+	auth := g.Group("", authentication)
+	auth.GET("/sessions", ctl.Sessions)
+	auth.GET("/sessions/:sid", ctl.Session)
+	auth.GET("/bans", ctl.IPBans)
+	auth.DELETE("/bans/:ip", ctl.UnbanIP, middlewares.AdminPermission())
+	auth.POST("/reload", ctl.Reload, middlewares.AdminPermission())
+	auth.GET("/iroutes", ctl.IRoutes)
+	auth.GET("/events", ctl.Events)
+	auth.GET("/events/stream", ctl.EventsStream)
 }
@@ -4,3 +4,14 @@ type CommandParamsData struct {
 	Action int    `query:"action" validate:"required,min=1,max=13"`
 	Value  string `query:"value" validate:"omitempty"`
 }
+
+// adminActions are the legacy command actions that change the server state.
+var adminActions = map[int]bool{4: true, 9: true, 13: true}
+
+type SessionsQueryData struct {
+	State string `query:"state" validate:"omitempty,oneof=all valid"`
+}
+
+type CommandResponse struct {
+	Output string `json:"output" validate:"required"`
+}
@@ -18,6 +18,7 @@ type Controller struct {
 	request         request.CustomRequestInterface
 	ocservGroupRepo repository.OcservGroupRepositoryInterface
 	ocservUserRepo  repository.OcservUserRepositoryInterface
+	serverRepo      repository.ServerRepositoryInterface
 }
 
 func New() *Controller {
@@ -25,6 +26,7 @@ func New() *Controller {
 		request:         request.NewCustomRequest(),
 		ocservGroupRepo: repository.NewOcservGroupRepository(),
 		ocservUserRepo:  repository.NewtOcservUserRepository(),
+		serverRepo:      repository.NewServerRepository(),
 	}
 }
 
@@ -151,10 +153,16 @@ func (ctl *Controller) CreateOcservGroup(c echo.Context) error {
 		return ctl.request.BadRequest(c, errors.New("admin or staff username not found"))
 	}
 
+	servers, err := ctl.serverRepo.GetByIDs(c.Request().Context(), data.ServerIDs)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
 	ocservGroup := models.OcservGroup{
-		Name:   data.Name,
-		Owner:  owner,
-		Config: data.Config,
+		Name:    data.Name,
+		Owner:   owner,
+		Config:  data.Config,
+		Servers: servers,
 	}
 
 	newOcservGroup, err := ctl.ocservGroupRepo.Create(c.Request().Context(), &ocservGroup)
@@ -194,6 +202,11 @@ func (ctl *Controller) UpdateOcservGroup(c echo.Context) error {
 		return ctl.request.BadRequest(c, err)
 	}
 	ocservGroup.Config = data.Config
+	if data.ServerIDs != nil {
+		if ocservGroup.Servers, err = ctl.serverRepo.GetByIDs(c.Request().Context(), *data.ServerIDs); err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+	}
 	updatedOcservGroup, err := ctl.ocservGroupRepo.Update(c.Request().Context(), ocservGroup)
 	if err != nil {
 		return ctl.request.BadRequest(c, err)
@@ -2,12 +2,13 @@ package ocserv_group
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 )
 
 func Routes(e *echo.Group) {
 	ctl := New()
-	g := e.Group("/ocserv/groups", middlewares.AuthMiddleware())
+	g := e.Group("/ocserv/groups", middlewares.AuthMiddleware(repository.NewUserTokenRepository()))
 	g.GET("", ctl.OcservGroups)
 	g.GET("/lookup", ctl.OcservGroupsLookup)
 	g.GET("/:id", ctl.OcservGroup)
@@ -7,12 +7,14 @@ import (
 )
 
 type CreateOcservGroupData struct {
-	Name   string                    `json:"name" validate:"required"`
-	Config *models.OcservGroupConfig `json:"config" validate:"required"`
+	Name      string                    `json:"name" validate:"required"`
+	ServerIDs []uint                    `json:"server_ids" validate:"omitempty" desc:"servers to deploy the group to, empty deploys it to the ocserv of the dashboard"`
+	Config    *models.OcservGroupConfig `json:"config" validate:"required"`
 }
 
 type UpdateOcservGroupData struct {
-	Config *models.OcservGroupConfig `json:"config" validate:"required"`
+	ServerIDs *[]uint                   `json:"server_ids" validate:"omitempty" desc:"servers to move the group to, empty moves it to the ocserv of the dashboard"`
+	Config    *models.OcservGroupConfig `json:"config" validate:"required"`
 }
 
 type OcservGroupsResponse struct {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/labstack/echo/v4"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/profile"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
@@ -19,19 +20,27 @@ import (
 )
 
 type Controller struct {
-	request         request.CustomRequestInterface
-	userRepo        repository.UserRepositoryInterface
-	ocservUserRepo  repository.OcservUserRepositoryInterface
-	ocservOcctlRepo repository.OcctlRepositoryInterface
-	reportRepo      repository.ReportRepositoryInterface
+	request          request.CustomRequestInterface
+	userRepo         repository.UserRepositoryInterface
+	ocservUserRepo   repository.OcservUserRepositoryInterface
+	ocservOcctlRepo  repository.OcctlRepositoryInterface
+	reportRepo       repository.ReportRepositoryInterface
+	deviceRepo       repository.OcservUserDeviceRepositoryInterface
+	userQuotaRepo    repository.UserQuotaRepositoryInterface
+	serverRepo       repository.ServerRepositoryInterface
+	profileGenerator profile.GeneratorInterface
 }
 
 func New() *Controller {
 	return &Controller{
-		request:         request.NewCustomRequest(),
-		ocservUserRepo:  repository.NewtOcservUserRepository(),
-		ocservOcctlRepo: repository.NewOcctlRepository(),
-		reportRepo:      repository.NewtReportRepository(),
+		request:          request.NewCustomRequest(),
+		ocservUserRepo:   repository.NewtOcservUserRepository(),
+		ocservOcctlRepo:  repository.NewOcctlRepository(),
+		reportRepo:       repository.NewtReportRepository(),
+		deviceRepo:       repository.NewOcservUserDeviceRepository(),
+		userQuotaRepo:    repository.NewUserQuotaRepository(),
+		serverRepo:       repository.NewServerRepository(),
+		profileGenerator: profile.NewGenerator(),
 	}
 }
 
@@ -219,6 +228,11 @@ func (ctl *Controller) CreateOcservUser(c echo.Context) error {
 		data.TrafficSize = 0
 	}
 
+	servers, err := ctl.serverRepo.GetByIDs(c.Request().Context(), data.ServerIDs)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
 	ocUser := &models.OcservUser{
 		Owner:       owner,
 		Username:    data.Username,
@@ -227,10 +241,12 @@ func (ctl *Controller) CreateOcservUser(c echo.Context) error {
 		ExpireAt:    expireAt,
 		TrafficSize: data.TrafficSize,
 		TrafficType: data.TrafficType,
+		MaxDevices:  data.MaxDevices,
 		Config:      data.Config,
+		Servers:     servers,
 	}
 
-	u, err := ctl.ocservUserRepo.Create(c.Request().Context(), ocUser)
+	u, err := ctl.ocservUserRepo.Create(c.Request().Context(), ocUser, ctl.quotaChecks(c, owner)...)
 	if err != nil {
 		return ctl.request.BadRequest(c, err)
 	}
@@ -272,6 +288,10 @@ func (ctl *Controller) UpdateOcservUser(c echo.Context) error {
 		ocservUser.Group = *data.Group
 	}
 	if data.Password != nil {
+		// the customer portal tokens of the old password are revoked
+		if *data.Password != ocservUser.Password {
+			ocservUser.TokenVersion++
+		}
 		ocservUser.Password = *data.Password
 	}
 	if data.Description != nil {
@@ -283,9 +303,17 @@ func (ctl *Controller) UpdateOcservUser(c echo.Context) error {
 	if data.TrafficType != nil && slices.Contains([]string{"Free", "MonthlyTransmit", "MonthlyReceive", "TotallyTransmit", "TotallyReceive"}, *data.TrafficType) {
 		ocservUser.TrafficType = *data.TrafficType
 	}
+	if data.MaxDevices != nil {
+		ocservUser.MaxDevices = *data.MaxDevices
+	}
 	if data.Config != nil {
 		ocservUser.Config = data.Config
 	}
+	if data.ServerIDs != nil {
+		if ocservUser.Servers, err = ctl.serverRepo.GetByIDs(c.Request().Context(), *data.ServerIDs); err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+	}
 
 	if data.Unlimited {
 		ocservUser.ExpireAt = nil
@@ -295,7 +323,8 @@ func (ctl *Controller) UpdateOcservUser(c echo.Context) error {
 		}
 	}
 
-	updatedOcservUser, err := ctl.ocservUserRepo.Update(c.Request().Context(), ocservUser)
+	owner, _ := c.Get("username").(string)
+	updatedOcservUser, err := ctl.ocservUserRepo.Update(c.Request().Context(), ocservUser, ctl.quotaChecks(c, owner)...)
 	if err != nil {
 		return ctl.request.BadRequest(c, err)
 	}
@@ -363,11 +392,12 @@ func (ctl *Controller) LockOcservUser(c echo.Context) error {
 
 		u, err := ctl.ocservUserRepo.GetByUID(ctx, userID)
 		if err != nil {
-			logger.Error("failed to fetch ocserv user error: ", err)
+			logger.Error("failed to fetch ocserv user error: %v", err)
+			return
 		}
 		_, err = ctl.ocservOcctlRepo.Disconnect(u.Username)
 		if err != nil {
-			logger.Error("failed to disconnect ocserv user error: ", err)
+			logger.Error("failed to disconnect ocserv user error: %v", err)
 		}
 		return
 	}()
@@ -619,7 +649,7 @@ func (ctl *Controller) SyncToDB(c echo.Context) error {
 // ActivateExpiredOcservUsers     Restore and activate expired Ocserv User accounts
 //
 // @Summary      Restore and activate expired Ocserv User accounts
-// @Description  Restore and activate expired Ocserv User accounts
+// @Description  Restore and activate expired Ocserv User accounts, the renewal starts a new billing cycle from today
 // @Tags         Ocserv(Users)
 // @Accept       json
 // @Produce      json
@@ -714,7 +744,7 @@ func (ctl *Controller) OcservUserSessionLogs(c echo.Context) error {
 	u, err := ctl.ocservUserRepo.GetByUID(c.Request().Context(), userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.JSON(http.StatusNotFound, nil)
+			return ctl.request.NotFound(c)
 		}
 		return ctl.request.BadRequest(c, err)
 	}
@@ -733,3 +763,198 @@ func (ctl *Controller) OcservUserSessionLogs(c echo.Context) error {
 		Result: logs,
 	})
 }
+
+// OcservUserProfile 	     Ocserv User connection profile
+//
+// @Summary      Ocserv User connection profile
+// @Description  Client connection profile as AnyConnect XML, OpenConnect command line or deep link QR code
+// @Tags         Ocserv(Users)
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 uid path string true "Ocserv User UID"
+// @Param 		 format query string false "Profile format" Enums(json, anyconnect, openconnect, qr)
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object} profile.Bundle
+// @Router       /ocserv/users/{uid}/profile [get]
+func (ctl *Controller) OcservUserProfile(c echo.Context) error {
+	userID := c.Param("uid")
+	if userID == "" {
+		return ctl.request.BadRequest(c, errors.New("user id is required"))
+	}
+
+	u, err := ctl.ocservUserRepo.GetByUID(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctl.request.NotFound(c)
+		}
+		return ctl.request.BadRequest(c, err)
+	}
+
+	if isAdmin, ok := c.Get("isAdmin").(bool); !ok || !isAdmin {
+		if owner, _ := c.Get("username").(string); owner != u.Owner {
+			return ctl.request.NotFound(c)
+		}
+	}
+
+	client := profile.NewClient(u.Username, u.Group)
+	if err = profile.Render(c, ctl.profileGenerator, client, c.QueryParam("format")); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return nil
+}
+
+// OcservUserDevices 	     Ocserv User devices
+//
+// @Summary      Ocserv User devices
+// @Description  Clients the user connected with, identified by user agent, most recently seen first
+// @Tags         Ocserv(Users)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 uid path string true "Ocserv User UID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {array} models.OcservUserDevice
+// @Router       /ocserv/users/{uid}/devices [get]
+func (ctl *Controller) OcservUserDevices(c echo.Context) error {
+	u, err := ctl.ocservUserRepo.GetByUID(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctl.request.NotFound(c)
+		}
+		return ctl.request.BadRequest(c, err)
+	}
+
+	devices, err := ctl.deviceRepo.Devices(c.Request().Context(), u.ID)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, devices)
+}
+
+// RevokeOcservUserDevice 	     Revoke Ocserv User device
+//
+// @Summary      Revoke Ocserv User device
+// @Description  Block the device and disconnect its sessions, it is disconnected again whenever it connects
+// @Tags         Ocserv(Users)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 uid path string true "Ocserv User UID"
+// @Param 		 id path int true "Device ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {object} models.OcservUserDevice
+// @Router       /ocserv/users/{uid}/devices/{id}/revoke [post]
+func (ctl *Controller) RevokeOcservUserDevice(c echo.Context) error {
+	u, err := ctl.ocservUserRepo.GetByUID(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctl.request.NotFound(c)
+		}
+		return ctl.request.BadRequest(c, err)
+	}
+
+	device, err := ctl.deviceRepo.RevokeDevice(c.Request().Context(), u.ID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctl.request.NotFound(c)
+		}
+		return ctl.request.BadRequest(c, err)
+	}
+
+	go func(username, userAgent string) {
+		if _, err := ctl.ocservOcctlRepo.DisconnectUserAgent(username, userAgent); err != nil {
+			logger.Error("Failed to disconnect revoked device of %s: %v", username, err)
+		}
+	}(u.Username, device.UserAgent)
+
+	return c.JSON(http.StatusOK, device)
+}
+
+// UnblockOcservUserDevice 	     Unblock Ocserv User device
+//
+// @Summary      Unblock Ocserv User device
+// @Description  Let a revoked device, or one blocked beyond the device limit, connect again. Refused while the user has max devices active ones.
+// @Tags         Ocserv(Users)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 uid path string true "Ocserv User UID"
+// @Param 		 id path int true "Device ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {object} models.OcservUserDevice
+// @Router       /ocserv/users/{uid}/devices/{id}/unblock [post]
+func (ctl *Controller) UnblockOcservUserDevice(c echo.Context) error {
+	u, err := ctl.ocservUserRepo.GetByUID(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctl.request.NotFound(c)
+		}
+		return ctl.request.BadRequest(c, err)
+	}
+
+	device, err := ctl.deviceRepo.UnblockDevice(c.Request().Context(), u.ID, u.MaxDevices, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctl.request.NotFound(c)
+		}
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, device)
+}
+
+// DeleteOcservUserDevice 	     Delete Ocserv User device
+//
+// @Summary      Delete Ocserv User device
+// @Description  Forget the device, freeing its slot of the user device limit. It is tracked again as a new device on its next connection.
+// @Tags         Ocserv(Users)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 uid path string true "Ocserv User UID"
+// @Param 		 id path int true "Device ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      204  {object} nil
+// @Router       /ocserv/users/{uid}/devices/{id} [delete]
+func (ctl *Controller) DeleteOcservUserDevice(c echo.Context) error {
+	u, err := ctl.ocservUserRepo.GetByUID(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctl.request.NotFound(c)
+		}
+		return ctl.request.BadRequest(c, err)
+	}
+
+	err = ctl.deviceRepo.DeleteDevice(c.Request().Context(), u.ID, c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// quotaChecks returns the checks of the quota of owner run in the transaction
+// saving an ocserv user, admins have no quota.
+func (ctl *Controller) quotaChecks(c echo.Context, owner string) []repository.SaveCheck {
+	if isAdmin, ok := c.Get("isAdmin").(bool); ok && isAdmin {
+		return nil
+	}
+	return []repository.SaveCheck{
+		func(tx *gorm.DB, ocservUser *models.OcservUser) error {
+			return ctl.userQuotaRepo.CheckQuota(tx, owner, ocservUser)
+		},
+	}
+}
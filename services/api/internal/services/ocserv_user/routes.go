@@ -2,12 +2,13 @@ package ocserv_user
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 )
 
 func Routes(e *echo.Group) {
 	ctl := New()
-	g := e.Group("/ocserv/users", middlewares.AuthMiddleware())
+	g := e.Group("/ocserv/users", middlewares.AuthMiddleware(repository.NewUserTokenRepository()))
 
 	g.GET("", ctl.OcservUsers)
 	g.GET("/:uid", ctl.OcservUser)
@@ -20,6 +21,11 @@ func Routes(e *echo.Group) {
 	g.POST("/:username/disconnect", ctl.DisconnectOcservUser)
 	g.GET("/:uid/session_logs", ctl.OcservUserSessionLogs)
 	g.GET("/:uid/statistics", ctl.OcservUserStatistics)
+	g.GET("/:uid/profile", ctl.OcservUserProfile)
+	g.GET("/:uid/devices", ctl.OcservUserDevices)
+	g.POST("/:uid/devices/:id/revoke", ctl.RevokeOcservUserDevice, middlewares.AdminPermission())
+	g.POST("/:uid/devices/:id/unblock", ctl.UnblockOcservUserDevice, middlewares.AdminPermission())
+	g.DELETE("/:uid/devices/:id", ctl.DeleteOcservUserDevice, middlewares.AdminPermission())
 
 	g.GET("/ocpasswd", ctl.OcpasswdUsers, middlewares.AdminPermission())
 	g.POST("/ocpasswd/sync", ctl.SyncToDB, middlewares.AdminPermission())
@@ -16,6 +16,8 @@ type CreateOcservUserData struct {
 	TrafficType string                   `json:"traffic_type" validate:"required,oneof=Free MonthlyTransmit MonthlyReceive TotallyTransmit TotallyReceive" example:"MonthlyTransmit"`
 	TrafficSize int                      `json:"traffic_size" validate:"omitempty,gte=0" example:"10737418240"` // 10 GiB
 	Description string                   `json:"description" validate:"omitempty,max=1024" example:"User for testing VPN access"`
+	MaxDevices  int                      `json:"max_devices" validate:"omitempty,gte=0" example:"2"` // 0 is unlimited
+	ServerIDs   []uint                   `json:"server_ids" validate:"omitempty" desc:"servers to deploy the user to, empty deploys it to the ocserv of the dashboard"`
 	Config      *models.OcservUserConfig `json:"config" validate:"required"`
 }
 
@@ -27,6 +29,8 @@ type UpdateOcservUserData struct {
 	TrafficType *string                  `json:"traffic_type" validate:"oneof=Free MonthlyTransmit MonthlyReceive TotallyTransmit TotallyReceive" example:"MonthlyTransmit"`
 	TrafficSize *int                     `json:"traffic_size" validate:"gte=0" example:"10737418240"` // 10 GiB
 	Description *string                  `json:"description" validate:"omitempty,max=1024" example:"User for testing VPN access"`
+	MaxDevices  *int                     `json:"max_devices" validate:"omitempty,gte=0" example:"2"` // 0 is unlimited
+	ServerIDs   *[]uint                  `json:"server_ids" validate:"omitempty" desc:"servers to move the user to, empty moves it to the ocserv of the dashboard"`
 	Config      *models.OcservUserConfig `json:"config" validate:"omitempty"`
 }
 
@@ -6,6 +6,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"gorm.io/gorm"
 	"net/http"
 	"strings"
 	"sync"
@@ -247,3 +248,196 @@ func (ctl *Controller) OcservUserReport(c echo.Context) error {
 		Locked:      result.Locked,
 	})
 }
+
+// SessionsByCountry 	 Sessions by country
+//
+// @Summary      Sessions by country
+// @Description  Number of sessions and distinct users per client country, from the GeoIP enriched session logs
+// @Tags         Report
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 date_start query string false "date_start"
+// @Param 		 date_end query string false "date_end"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200 {array} repository.CountrySessions
+// @Router       /reports/sessions/countries [get]
+func (ctl *Controller) SessionsByCountry(c echo.Context) error {
+	startDate, endDate, err := ctl.sessionsDateRange(c)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	results, err := ctl.reportRepo.SessionsByCountry(c.Request().Context(), startDate, endDate)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+// SessionsByASN 	 Sessions by ASN
+//
+// @Summary      Sessions by ASN
+// @Description  Number of sessions and distinct users per client autonomous system, from the GeoIP enriched session logs
+// @Tags         Report
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 date_start query string false "date_start"
+// @Param 		 date_end query string false "date_end"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200 {array} repository.ASNSessions
+// @Router       /reports/sessions/asns [get]
+func (ctl *Controller) SessionsByASN(c echo.Context) error {
+	startDate, endDate, err := ctl.sessionsDateRange(c)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	results, err := ctl.reportRepo.SessionsByASN(c.Request().Context(), startDate, endDate)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+// NewCountrySessions 	 New country sessions
+//
+// @Summary      New country sessions
+// @Description  Sessions started from a country the user was not seen in before, a hint of shared or stolen accounts
+// @Tags         Report
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 page query int false "Page number, starting from 1" minimum(1)
+// @Param 		 size query int false "Number of items per page" minimum(1) maximum(100) name(size)
+// @Param 		 order query string false "Field to order by"
+// @Param 		 sort query string false "Sort order, either ASC or DESC" Enums(ASC, DESC)
+// @Param 		 date_start query string false "date_start"
+// @Param 		 date_end query string false "date_end"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200 {object} NewCountrySessionsResponse
+// @Router       /reports/sessions/new_countries [get]
+func (ctl *Controller) NewCountrySessions(c echo.Context) error {
+	startDate, endDate, err := ctl.sessionsDateRange(c)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	pagination := ctl.request.Pagination(c)
+
+	logs, total, err := ctl.reportRepo.NewCountrySessions(c.Request().Context(), pagination, startDate, endDate)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, NewCountrySessionsResponse{
+		Meta: request.Meta{
+			Page:         pagination.Page,
+			TotalRecords: total,
+			PageSize:     pagination.PageSize,
+		},
+		Result: logs,
+	})
+}
+
+// Alerts 	 Account sharing alerts
+//
+// @Summary      Account sharing alerts
+// @Description  Concurrent sessions, impossible travels and unusual user agents detected by the stats service
+// @Tags         Report
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 page query int false "Page number, starting from 1" minimum(1)
+// @Param 		 size query int false "Number of items per page" minimum(1) maximum(100) name(size)
+// @Param 		 order query string false "Field to order by"
+// @Param 		 sort query string false "Sort order, either ASC or DESC" Enums(ASC, DESC)
+// @Param 		 username query string false "Filter by ocserv username"
+// @Param 		 kind query string false "Filter by alert kind" Enums(concurrent_sessions, impossible_travel, user_agents)
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200 {object} AlertsResponse
+// @Router       /reports/alerts [get]
+func (ctl *Controller) Alerts(c echo.Context) error {
+	var data AlertsData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	pagination := ctl.request.Pagination(c)
+
+	alerts, total, err := ctl.reportRepo.Alerts(c.Request().Context(), pagination, data.Username, data.Kind)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, AlertsResponse{
+		Meta: request.Meta{
+			Page:         pagination.Page,
+			TotalRecords: total,
+			PageSize:     pagination.PageSize,
+		},
+		Result: alerts,
+	})
+}
+
+// AcknowledgeAlert 	 Acknowledge account sharing alert
+//
+// @Summary      Acknowledge account sharing alert
+// @Description  Mark an alert as reviewed. Users locked by the alert stay locked until unlocked.
+// @Tags         Report
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 id path int true "Alert ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200 {object} models.OcservUserAlert
+// @Router       /reports/alerts/{id}/acknowledge [post]
+func (ctl *Controller) AcknowledgeAlert(c echo.Context) error {
+	alert, err := ctl.reportRepo.AcknowledgeAlert(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, alert)
+}
+
+// sessionsDateRange parses the optional date_start and date_end query params, date_end is inclusive.
+func (ctl *Controller) sessionsDateRange(c echo.Context) (*time.Time, *time.Time, error) {
+	var data SessionsGeoData
+	if err := c.Bind(&data); err != nil {
+		return nil, nil, err
+	}
+
+	var startDate, endDate *time.Time
+
+	if data.DateStart != "" {
+		t, err := time.Parse("2006-01-02", data.DateStart)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid date_start: %w", err)
+		}
+		startDate = &t
+	}
+
+	if data.DateEnd != "" {
+		t, err := time.Parse("2006-01-02", data.DateEnd)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid date_end: %w", err)
+		}
+		t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second + 999999999*time.Nanosecond)
+		endDate = &t
+	}
+
+	if startDate != nil && endDate != nil && startDate.After(*endDate) {
+		return nil, nil, errors.New("date start is after end")
+	}
+	return startDate, endDate, nil
+}
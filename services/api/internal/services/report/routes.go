@@ -2,15 +2,21 @@ package report
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 )
 
 func Routes(e *echo.Group) {
 	ctl := New()
-	g := e.Group("/reports", middlewares.AuthMiddleware(), middlewares.AdminPermission())
+	g := e.Group("/reports", middlewares.AuthMiddleware(repository.NewUserTokenRepository()), middlewares.AdminPermission())
 
 	g.GET("/session_logs", ctl.SessionLogs)
 	g.GET("/statistics", ctl.Statistics)
 	g.GET("/users", ctl.OcservUserReport)
 	g.GET("/total-bandwidth", ctl.TotalBandwidth)
+	g.GET("/sessions/countries", ctl.SessionsByCountry)
+	g.GET("/sessions/asns", ctl.SessionsByASN)
+	g.GET("/sessions/new_countries", ctl.NewCountrySessions)
+	g.GET("/alerts", ctl.Alerts)
+	g.POST("/alerts/:id/acknowledge", ctl.AcknowledgeAlert)
 }
@@ -37,3 +37,23 @@ type OcservUserReportResponse struct {
 	Deactivated int64 `json:"deactivated"`
 	Locked      int64 `json:"locked"`
 }
+
+type SessionsGeoData struct {
+	DateStart string `json:"date_start" query:"date_start" validate:"omitempty" example:"2025-1-31"`
+	DateEnd   string `json:"date_end" query:"date_end" validate:"omitempty" example:"2025-12-31"`
+}
+
+type NewCountrySessionsResponse struct {
+	Meta   request.Meta                   `json:"meta" validate:"required"`
+	Result *[]models.OcservUserSessionLog `json:"result" validate:"omitempty"`
+}
+
+type AlertsData struct {
+	Username string `json:"username" query:"username" validate:"omitempty" example:"john_doe"`
+	Kind     string `json:"kind" query:"kind" validate:"omitempty,oneof=concurrent_sessions impossible_travel user_agents" example:"impossible_travel"`
+}
+
+type AlertsResponse struct {
+	Meta   request.Meta              `json:"meta" validate:"required"`
+	Result *[]models.OcservUserAlert `json:"result" validate:"omitempty"`
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/secret"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+type Controller struct {
+	request    request.CustomRequestInterface
+	serverRepo repository.ServerRepositoryInterface
+}
+
+func New() *Controller {
+	return &Controller{
+		request:    request.NewCustomRequest(),
+		serverRepo: repository.NewServerRepository(),
+	}
+}
+
+// Servers 	 List of ocserv servers
+//
+// @Summary      List of ocserv servers
+// @Description  List of the ocserv nodes managed by the dashboard through their webhook service or node agent
+// @Tags         Servers
+// @Accept       json
+// @Produce      json
+// @Param 		 page query int false "Page number, starting from 1" minimum(1)
+// @Param 		 size query int false "Number of items per page" minimum(1) maximum(100) name(size)
+// @Param 		 order query string false "Field to order by"
+// @Param 		 sort query string false "Sort order, either ASC or DESC" Enums(ASC, DESC)
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  ServersResponse
+// @Router       /servers [get]
+func (ctl *Controller) Servers(c echo.Context) error {
+	pagination := ctl.request.Pagination(c)
+
+	servers, total, err := ctl.serverRepo.Servers(c.Request().Context(), pagination)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	return c.JSON(http.StatusOK, ServersResponse{
+		Meta: request.Meta{
+			Page:         pagination.Page,
+			PageSize:     pagination.PageSize,
+			TotalRecords: total,
+		},
+		Result: servers,
+	})
+}
+
+// Server 	 Ocserv server detail
+//
+// @Summary      Ocserv server detail
+// @Description  Ocserv server detail
+// @Tags         Servers
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 id path int true "Server ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {object}  models.Server
+// @Router       /servers/{id} [get]
+func (ctl *Controller) Server(c echo.Context) error {
+	server, err := ctl.serverRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, server)
+}
+
+// CreateServer 	 Ocserv server creation
+//
+// @Summary      Ocserv server creation
+// @Description  Add an ocserv node running the webhook service or the node agent. Users and groups assigned to it are deployed to it.
+// @Tags         Servers
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param        request    body  CreateServerData  true "server data"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      201  {object}  models.Server
+// @Router       /servers [post]
+func (ctl *Controller) CreateServer(c echo.Context) error {
+	var data CreateServerData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	key, err := secret.Encrypt(data.Secret)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	server, err := ctl.serverRepo.Create(c.Request().Context(), &models.Server{
+		Name:        data.Name,
+		Endpoint:    strings.TrimRight(data.Endpoint, "/"),
+		Secret:      key,
+		Description: data.Description,
+	})
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusCreated, server)
+}
+
+// UpdateServer 	 Ocserv server update
+//
+// @Summary      Ocserv server update
+// @Description  Ocserv server update, an empty secret keeps the current one
+// @Tags         Servers
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 id path int true "Server ID"
+// @Param        request    body  UpdateServerData  true "server data"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      200  {object}  models.Server
+// @Router       /servers/{id} [patch]
+func (ctl *Controller) UpdateServer(c echo.Context) error {
+	var data UpdateServerData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	server, err := ctl.serverRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	if data.Name != nil && *data.Name != "" {
+		server.Name = *data.Name
+	}
+	if data.Endpoint != nil && *data.Endpoint != "" {
+		server.Endpoint = strings.TrimRight(*data.Endpoint, "/")
+	}
+	if data.Secret != nil && *data.Secret != "" {
+		if server.Secret, err = secret.Encrypt(*data.Secret); err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+	}
+	if data.Description != nil {
+		server.Description = *data.Description
+	}
+
+	server, err = ctl.serverRepo.Update(c.Request().Context(), server)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, server)
+}
+
+// DeleteServer 	 Ocserv server delete
+//
+// @Summary      Ocserv server delete
+// @Description  Remove an ocserv server. Servers with users or groups cannot be removed.
+// @Tags         Servers
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Param 		 id path int true "Server ID"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Failure      404 {object} request.ErrorResponse
+// @Success      204  {object} nil
+// @Router       /servers/{id} [delete]
+func (ctl *Controller) DeleteServer(c echo.Context) error {
+	err := ctl.serverRepo.Delete(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ctl.request.NotFound(c)
+	}
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,17 @@
+package server
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
+)
+
+func Routes(e *echo.Group) {
+	ctl := New()
+	g := e.Group("/servers", middlewares.AuthMiddleware(repository.NewUserTokenRepository()))
+	g.GET("", ctl.Servers)
+	g.GET("/:id", ctl.Server)
+	g.POST("", ctl.CreateServer, middlewares.AdminPermission())
+	g.PATCH("/:id", ctl.UpdateServer, middlewares.AdminPermission())
+	g.DELETE("/:id", ctl.DeleteServer, middlewares.AdminPermission())
+}
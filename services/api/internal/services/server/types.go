@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+)
+
+type CreateServerData struct {
+	Name        string `json:"name" validate:"required,max=64" example:"edge-1"`
+	Endpoint    string `json:"endpoint" validate:"required,url,max=255" example:"http://10.0.0.2:8888" desc:"webhook or node agent URL of the node"`
+	Secret      string `json:"secret" validate:"required,min=16,max=255" desc:"WEBHOOK_SECRET or AGENT_SECRET of the node"`
+	Description string `json:"description" validate:"omitempty,max=1024"`
+}
+
+type UpdateServerData struct {
+	Name        *string `json:"name" validate:"omitempty,max=64" example:"edge-1"`
+	Endpoint    *string `json:"endpoint" validate:"omitempty,url,max=255" example:"http://10.0.0.2:8888"`
+	Secret      *string `json:"secret" validate:"omitempty,min=16,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=1024"`
+}
+
+type ServersResponse struct {
+	Meta   request.Meta    `json:"meta" validate:"required"`
+	Result []models.Server `json:"result" validate:"omitempty"`
+}
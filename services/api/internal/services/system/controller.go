@@ -2,6 +2,7 @@ package system
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"github.com/labstack/echo/v4"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
@@ -10,18 +11,34 @@ import (
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/crypto"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/totp"
+	commonModels "github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/token"
+	"github.com/skip2/go-qrcode"
 	"gorm.io/gorm"
 	"net/http"
 	"strings"
 	"time"
 )
 
+const (
+	// twoFactorChallengeTTL is the lifetime of the token between the login steps.
+	twoFactorChallengeTTL = 5 * time.Minute
+	// twoFactorIssuer is the issuer shown in authenticator apps.
+	twoFactorIssuer = "Ocserv Dashboard"
+	// recoveryCodesCount is the number of recovery codes issued on enrollment.
+	recoveryCodesCount = 10
+)
+
 type Controller struct {
 	request         request.CustomRequestInterface
 	systemRepo      repository.SystemRepositoryInterface
 	userRepo        repository.UserRepositoryInterface
 	captchaVerifier captcha.GoogleCaptchaInterface
 	cryptoRepo      crypto.CustomPasswordInterface
+	userQuotaRepo   repository.UserQuotaRepositoryInterface
+	userTokenRepo   repository.UserTokenRepositoryInterface
 }
 
 func New() *Controller {
@@ -31,6 +48,8 @@ func New() *Controller {
 		userRepo:        repository.NewUserRepository(),
 		captchaVerifier: captcha.NewGoogleVerifier(),
 		cryptoRepo:      crypto.NewCustomPassword(),
+		userQuotaRepo:   repository.NewUserQuotaRepository(),
+		userTokenRepo:   repository.NewUserTokenRepository(),
 	}
 }
 
@@ -79,7 +98,7 @@ func (ctl *Controller) SetupSystem(c echo.Context) error {
 		return ctl.request.BadRequest(c, err)
 	}
 
-	token, err := ctl.userRepo.CreateToken(c.Request().Context(), newUser, true)
+	token, err := ctl.userRepo.CreateToken(c.Request().Context(), newUser, true, c.RealIP(), c.Request().UserAgent())
 	if err != nil {
 		return ctl.request.BadRequest(c, err)
 	}
@@ -140,6 +159,13 @@ func (ctl *Controller) System(c echo.Context) error {
 		GoogleCaptchaSecretKey:  config.GoogleCaptchaSecretKey,
 		AutoDeleteInactiveUsers: config.AutoDeleteInactiveUsers,
 		KeepInactiveUserDays:    config.KeepInactiveUserDays,
+		Require2FAAdmins:        config.Require2FAAdmins,
+		Require2FAStaffs:        config.Require2FAStaffs,
+		AnomalyDetection:        config.AnomalyDetection,
+		AnomalyAutoLock:         config.AnomalyAutoLock,
+		AnomalyMaxConcurrentIPs: config.AnomalyMaxConcurrentIPs,
+		AnomalyMaxTravelKmh:     config.AnomalyMaxTravelKmh,
+		AnomalyMaxUserAgents:    config.AnomalyMaxUserAgents,
 	})
 }
 
@@ -163,7 +189,10 @@ func (ctl *Controller) SystemUpdate(c echo.Context) error {
 		return ctl.request.BadRequest(c, err)
 	}
 
-	system := models.System{}
+	system, err := ctl.systemRepo.System(c.Request().Context())
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
 
 	if data.GoogleCaptchaSiteKey != nil {
 		system.GoogleCaptchaSiteKey = *data.GoogleCaptchaSiteKey
@@ -181,9 +210,30 @@ func (ctl *Controller) SystemUpdate(c echo.Context) error {
 		}
 		system.KeepInactiveUserDays = inactiveDays
 	}
+	if data.Require2FAAdmins != nil {
+		system.Require2FAAdmins = *data.Require2FAAdmins
+	}
+	if data.Require2FAStaffs != nil {
+		system.Require2FAStaffs = *data.Require2FAStaffs
+	}
+	if data.AnomalyDetection != nil {
+		system.AnomalyDetection = *data.AnomalyDetection
+	}
+	if data.AnomalyAutoLock != nil {
+		system.AnomalyAutoLock = *data.AnomalyAutoLock
+	}
+	if data.AnomalyMaxConcurrentIPs != nil {
+		system.AnomalyMaxConcurrentIPs = *data.AnomalyMaxConcurrentIPs
+	}
+	if data.AnomalyMaxTravelKmh != nil {
+		system.AnomalyMaxTravelKmh = *data.AnomalyMaxTravelKmh
+	}
+	if data.AnomalyMaxUserAgents != nil {
+		system.AnomalyMaxUserAgents = *data.AnomalyMaxUserAgents
+	}
 
 	ctx := context.WithValue(c.Request().Context(), "userUID", userUID)
-	updatedConfig, err := ctl.systemRepo.SystemUpdate(ctx, &system)
+	updatedConfig, err := ctl.systemRepo.SystemUpdate(ctx, system)
 	if err != nil {
 		return ctl.request.BadRequest(c, err)
 	}
@@ -193,6 +243,13 @@ func (ctl *Controller) SystemUpdate(c echo.Context) error {
 		GoogleCaptchaSecretKey:  updatedConfig.GoogleCaptchaSecretKey,
 		AutoDeleteInactiveUsers: updatedConfig.AutoDeleteInactiveUsers,
 		KeepInactiveUserDays:    updatedConfig.KeepInactiveUserDays,
+		Require2FAAdmins:        updatedConfig.Require2FAAdmins,
+		Require2FAStaffs:        updatedConfig.Require2FAStaffs,
+		AnomalyDetection:        updatedConfig.AnomalyDetection,
+		AnomalyAutoLock:         updatedConfig.AnomalyAutoLock,
+		AnomalyMaxConcurrentIPs: updatedConfig.AnomalyMaxConcurrentIPs,
+		AnomalyMaxTravelKmh:     updatedConfig.AnomalyMaxTravelKmh,
+		AnomalyMaxUserAgents:    updatedConfig.AnomalyMaxUserAgents,
 	})
 }
 
@@ -235,25 +292,117 @@ func (ctl *Controller) Login(c echo.Context) error {
 		return ctl.request.BadRequest(c, errors.New("invalid username or password"))
 	}
 
-	token, err := ctl.userRepo.CreateToken(c.Request().Context(), user, data.RememberMe)
+	if user.TOTPEnabled || system.Requires2FA(user.IsAdmin) {
+		expire := time.Now().Add(twoFactorChallengeTTL)
+		challenge, err := crypto.GenerateTwoFactorChallengeToken(user.UID, user.Username, expire.Unix())
+		if err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+		return c.JSON(http.StatusOK, UserLoginResponse{
+			TwoFactor: &TwoFactorChallenge{
+				ChallengeToken:     challenge,
+				ExpireAt:           expire,
+				EnrollmentRequired: !user.TOTPEnabled,
+			},
+		})
+	}
+
+	resp, err := ctl.completeLogin(c, user, data.RememberMe)
 	if err != nil {
-		return ctl.request.BadRequest(c, err, "user created")
+		return ctl.request.BadRequest(c, err)
 	}
+	return c.JSON(http.StatusOK, resp)
+}
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), "userUID", user.UID), 10*time.Second)
-		ctx = context.WithValue(ctx, "username", user.Username)
-		defer cancel()
+// LoginTwoFactor		 Admin users login second step
+//
+// @Summary      Admin users login second step
+// @Description  Complete the login with the challenge token and a TOTP code or a recovery code.
+// @Description  For users who must enroll, the first valid code enables 2FA and recovery codes are returned once
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        request body TwoFactorLoginData  true "2fa login data"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      429 {object} middlewares.TooManyRequests
+// @Success      200 {object} UserLoginResponse
+// @Router       /system/users/login/2fa [post]
+func (ctl *Controller) LoginTwoFactor(c echo.Context) error {
+	var data TwoFactorLoginData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
 
-		now := time.Now()
-		user.LastLogin = &now
-		_ = ctl.userRepo.UpdateLastLogin(ctx, user)
-	}()
+	user, err := ctl.challengeUser(c.Request().Context(), data.ChallengeToken)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
 
-	return c.JSON(http.StatusOK, UserLoginResponse{
-		User:  user,
-		Token: token,
-	})
+	var recoveryCodes []string
+
+	switch {
+	case user.TOTPEnabled && data.Code != "":
+		if err = ctl.checkTOTP(c.Request().Context(), user, data.Code); err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+	case user.TOTPEnabled && data.RecoveryCode != "":
+		ok, err := ctl.userRepo.UseRecoveryCode(c.Request().Context(), user.ID, totp.HashRecoveryCode(data.RecoveryCode))
+		if err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+		if !ok {
+			return ctl.request.BadRequest(c, errors.New("invalid recovery code"))
+		}
+	case !user.TOTPEnabled && user.TOTPSecret != "" && data.Code != "":
+		if err = ctl.checkTOTP(c.Request().Context(), user, data.Code); err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+		recoveryCodes, err = ctl.enableTOTP(c.Request().Context(), user)
+		if err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+	case !user.TOTPEnabled:
+		return ctl.request.BadRequest(c, errors.New("two-factor enrollment required"))
+	default:
+		return ctl.request.BadRequest(c, errors.New("code or recovery_code is required"))
+	}
+
+	resp, err := ctl.completeLogin(c, user, data.RememberMe)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	resp.RecoveryCodes = recoveryCodes
+	return c.JSON(http.StatusOK, resp)
+}
+
+// LoginTwoFactorEnroll		 Admin users login 2fa enrollment
+//
+// @Summary      Admin users login 2fa enrollment
+// @Description  Start 2FA enrollment with the challenge token of a user required to use 2FA by policy
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        request body TwoFactorChallengeData  true "2fa challenge token"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      429 {object} middlewares.TooManyRequests
+// @Success      200 {object} TwoFactorEnrollResponse
+// @Router       /system/users/login/2fa/enroll [post]
+func (ctl *Controller) LoginTwoFactorEnroll(c echo.Context) error {
+	var data TwoFactorChallengeData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	user, err := ctl.challengeUser(c.Request().Context(), data.ChallengeToken)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	resp, err := ctl.enroll(c.Request().Context(), user)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
 // CreateUser	 Create user
@@ -433,7 +582,7 @@ func (ctl *Controller) ChangePasswordBySelf(c echo.Context) error {
 // @Param        Authorization header string true "Bearer TOKEN"
 // @Failure      400 {object} request.ErrorResponse
 // @Failure      401 {object} middlewares.Unauthorized
-// @Success      200  {object}  models.User
+// @Success      200  {object}  ProfileResponse
 // @Router       /system/users/profile [get]
 func (ctl *Controller) Profile(c echo.Context) error {
 	userUID := c.Get("userUID").(string)
@@ -441,7 +590,21 @@ func (ctl *Controller) Profile(c echo.Context) error {
 	if err != nil {
 		return middlewares.UnauthorizedError(c, "user not found")
 	}
-	return c.JSON(http.StatusOK, user)
+
+	resp := ProfileResponse{User: user}
+	if !user.IsAdmin {
+		quota, err := ctl.userQuotaRepo.GetQuota(c.Request().Context(), user.ID)
+		if err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+		usage, err := ctl.userQuotaRepo.Usage(c.Request().Context(), user.Username)
+		if err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+		resp.Quota = quota
+		resp.Usage = usage
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
 // UsersLookup 	 List of Users Lookup
@@ -464,3 +627,543 @@ func (ctl *Controller) UsersLookup(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, users)
 }
+
+// UserQuota 	 Get simple user quota
+//
+// @Summary      Get simple user quota
+// @Description  Get simple user quota and current usage
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param 		 uid path string true "User UID"
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      200  {object}  UserQuotaResponse
+// @Router       /system/users/{uid}/quota [get]
+func (ctl *Controller) UserQuota(c echo.Context) error {
+	user, err := ctl.userRepo.GetByUID(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	if user.IsAdmin {
+		return ctl.request.BadRequest(c, errors.New("admin users have no quota"))
+	}
+
+	quota, err := ctl.userQuotaRepo.GetQuota(c.Request().Context(), user.ID)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	usage, err := ctl.userQuotaRepo.Usage(c.Request().Context(), user.Username)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, UserQuotaResponse{Quota: quota, Usage: usage})
+}
+
+// UpdateUserQuota 	 Update simple user quota
+//
+// @Summary      Update simple user quota
+// @Description  Update simple user quota. Zero values mean unlimited and empty allowed groups allow all groups
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param 		 uid path string true "User UID"
+// @Param        request    body  PatchUserQuotaData   true "user quota data"
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      200  {object}  UserQuotaResponse
+// @Router       /system/users/{uid}/quota [patch]
+func (ctl *Controller) UpdateUserQuota(c echo.Context) error {
+	var data PatchUserQuotaData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	user, err := ctl.userRepo.GetByUID(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	if user.IsAdmin {
+		return ctl.request.BadRequest(c, errors.New("admin users have no quota"))
+	}
+
+	quota, err := ctl.userQuotaRepo.GetQuota(c.Request().Context(), user.ID)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	if data.MaxUsers != nil {
+		quota.MaxUsers = *data.MaxUsers
+	}
+	if data.MaxTrafficGiB != nil {
+		quota.MaxTrafficGiB = *data.MaxTrafficGiB
+	}
+	if data.AllowedGroups != nil {
+		groups := commonModels.CSVStringList(*data.AllowedGroups)
+		quota.AllowedGroups = &groups
+	}
+	if data.MaxExpireDays != nil {
+		quota.MaxExpireDays = *data.MaxExpireDays
+	}
+
+	quota, err = ctl.userQuotaRepo.UpsertQuota(c.Request().Context(), quota)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	usage, err := ctl.userQuotaRepo.Usage(c.Request().Context(), user.Username)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, UserQuotaResponse{Quota: quota, Usage: usage})
+}
+
+// TwoFactorEnroll 	 Start 2FA enrollment
+//
+// @Summary      Start 2FA enrollment
+// @Description  Generate a new TOTP secret and QR code. 2FA is enabled after verifying a code
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  TwoFactorEnrollResponse
+// @Router       /system/users/2fa/enroll [post]
+func (ctl *Controller) TwoFactorEnroll(c echo.Context) error {
+	user, err := ctl.userRepo.GetByUID(c.Request().Context(), c.Get("userUID").(string))
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "user not found")
+	}
+
+	resp, err := ctl.enroll(c.Request().Context(), user)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// TwoFactorVerify 	 Verify 2FA enrollment
+//
+// @Summary      Verify 2FA enrollment
+// @Description  Enable 2FA with a code of the enrolled secret. Recovery codes are returned once
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        request body  TwoFactorCodeData  true "totp code"
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  RecoveryCodesResponse
+// @Router       /system/users/2fa/verify [post]
+func (ctl *Controller) TwoFactorVerify(c echo.Context) error {
+	var data TwoFactorCodeData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	user, err := ctl.userRepo.GetByUID(c.Request().Context(), c.Get("userUID").(string))
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "user not found")
+	}
+
+	if user.TOTPEnabled {
+		return ctl.request.BadRequest(c, errors.New("two-factor authentication is already enabled"))
+	}
+	if user.TOTPSecret == "" {
+		return ctl.request.BadRequest(c, errors.New("two-factor enrollment not started"))
+	}
+	if err = ctl.checkTOTP(c.Request().Context(), user, data.Code); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	codes, err := ctl.enableTOTP(c.Request().Context(), user)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// TwoFactorDisable 	 Disable 2FA
+//
+// @Summary      Disable 2FA
+// @Description  Disable 2FA of the current user, or cancel a pending enrollment. The code is required only when 2FA is enabled. Not allowed when the policy requires 2FA for the user role
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        request body  TwoFactorDisableData  true "password and totp code"
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      204  {object}  nil
+// @Router       /system/users/2fa/disable [post]
+func (ctl *Controller) TwoFactorDisable(c echo.Context) error {
+	var data TwoFactorDisableData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	user, err := ctl.userRepo.GetByUID(c.Request().Context(), c.Get("userUID").(string))
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "user not found")
+	}
+	if !user.TOTPEnabled && user.TOTPSecret == "" {
+		return ctl.request.BadRequest(c, errors.New("two-factor authentication is not enabled"))
+	}
+
+	system, err := ctl.systemRepo.System(c.Request().Context())
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	if system.Requires2FA(user.IsAdmin) {
+		return ctl.request.BadRequest(c, errors.New("two-factor authentication is required by policy"))
+	}
+
+	if ok := ctl.cryptoRepo.CheckPassword(data.Password, user.Password, user.Salt); !ok {
+		return ctl.request.BadRequest(c, errors.New("invalid password"))
+	}
+	if user.TOTPEnabled {
+		if data.Code == "" {
+			return ctl.request.BadRequest(c, errors.New("code is required"))
+		}
+		if err = ctl.checkTOTP(c.Request().Context(), user, data.Code); err != nil {
+			return ctl.request.BadRequest(c, err)
+		}
+	}
+
+	if err = ctl.userRepo.DisableTOTP(c.Request().Context(), user.ID); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// TwoFactorRecoveryCodes 	 Regenerate 2FA recovery codes
+//
+// @Summary      Regenerate 2FA recovery codes
+// @Description  Replace all recovery codes of the current user. Previous codes stop working
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        request body  TwoFactorCodeData  true "totp code"
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {object}  RecoveryCodesResponse
+// @Router       /system/users/2fa/recovery_codes [post]
+func (ctl *Controller) TwoFactorRecoveryCodes(c echo.Context) error {
+	var data TwoFactorCodeData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	user, err := ctl.userRepo.GetByUID(c.Request().Context(), c.Get("userUID").(string))
+	if err != nil {
+		return middlewares.UnauthorizedError(c, "user not found")
+	}
+
+	if !user.TOTPEnabled {
+		return ctl.request.BadRequest(c, errors.New("two-factor authentication is not enabled"))
+	}
+	if err = ctl.checkTOTP(c.Request().Context(), user, data.Code); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	codes, hashes, err := recoveryCodes()
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	if err = ctl.userRepo.ReplaceRecoveryCodes(c.Request().Context(), user.ID, hashes); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// ResetUserTwoFactor 	 Reset user 2FA by admin
+//
+// @Summary      Reset user 2FA by admin
+// @Description  Disable 2FA of a user who lost the authenticator and recovery codes
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param 		 uid path string true "User UID"
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      204  {object}  nil
+// @Router       /system/users/{uid}/2fa/reset [post]
+func (ctl *Controller) ResetUserTwoFactor(c echo.Context) error {
+	user, err := ctl.userRepo.GetByUID(c.Request().Context(), c.Param("uid"))
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	if err = ctl.userRepo.DisableTOTP(c.Request().Context(), user.ID); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// Sessions 	 List of active sessions
+//
+// @Summary      List of active sessions
+// @Description  List of active login sessions (tokens) of the current user
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      200  {array}  UserSession
+// @Router       /system/users/sessions [get]
+func (ctl *Controller) Sessions(c echo.Context) error {
+	userUID := c.Get("userUID").(string)
+	currentTokenUID, _ := c.Get("tokenUID").(string)
+
+	tokens, err := ctl.userTokenRepo.ActiveTokens(c.Request().Context(), userUID)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	sessions := make([]UserSession, 0, len(tokens))
+	for _, t := range tokens {
+		sessions = append(sessions, UserSession{
+			UID:        t.UID,
+			IP:         t.IP,
+			UserAgent:  t.UserAgent,
+			CreatedAt:  t.CreatedAt,
+			LastSeenAt: t.LastSeenAt,
+			ExpireAt:   t.ExpireAt,
+			Current:    t.UID == currentTokenUID,
+		})
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession 	 Revoke session
+//
+// @Summary      Revoke session
+// @Description  Revoke one login session of the current user
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param 		 uid path string true "Session UID"
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      204  {object}  nil
+// @Router       /system/users/sessions/{uid} [delete]
+func (ctl *Controller) RevokeSession(c echo.Context) error {
+	userUID := c.Get("userUID").(string)
+
+	if err := ctl.userTokenRepo.RevokeToken(c.Request().Context(), userUID, c.Param("uid")); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// RevokeOtherSessions 	 Revoke other sessions
+//
+// @Summary      Revoke other sessions
+// @Description  Revoke all login sessions of the current user except the current one
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      204  {object}  nil
+// @Router       /system/users/sessions [delete]
+func (ctl *Controller) RevokeOtherSessions(c echo.Context) error {
+	userUID := c.Get("userUID").(string)
+	currentTokenUID, _ := c.Get("tokenUID").(string)
+
+	if err := ctl.userTokenRepo.RevokeAllTokens(c.Request().Context(), userUID, currentTokenUID); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// Logout 	 Logout
+//
+// @Summary      Logout
+// @Description  Revoke the current login session
+// @Tags         System(Users)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Success      204  {object}  nil
+// @Router       /system/users/logout [post]
+func (ctl *Controller) Logout(c echo.Context) error {
+	userUID := c.Get("userUID").(string)
+	currentTokenUID, _ := c.Get("tokenUID").(string)
+
+	if err := ctl.userTokenRepo.RevokeToken(c.Request().Context(), userUID, currentTokenUID); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// completeLogin issues the dashboard token and records the login time.
+func (ctl *Controller) completeLogin(c echo.Context, user *models.User, rememberMe bool) (*UserLoginResponse, error) {
+	token, err := ctl.userRepo.CreateToken(c.Request().Context(), user, rememberMe, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), "userUID", user.UID), 10*time.Second)
+		ctx = context.WithValue(ctx, "username", user.Username)
+		defer cancel()
+
+		now := time.Now()
+		user.LastLogin = &now
+		_ = ctl.userRepo.UpdateLastLogin(ctx, user)
+	}()
+
+	return &UserLoginResponse{
+		User:  user,
+		Token: token,
+	}, nil
+}
+
+// challengeUser returns the user of a valid 2FA challenge token.
+func (ctl *Controller) challengeUser(ctx context.Context, challengeToken string) (*models.User, error) {
+	claims, ok := token.Check(challengeToken)
+	if !ok {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+	if scope, _ := claims["scope"].(string); scope != crypto.ScopeTwoFactor {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+
+	uid, _ := claims["sub"].(string)
+	user, err := ctl.userRepo.GetByUID(ctx, uid)
+	if err != nil {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+	return user, nil
+}
+
+func (ctl *Controller) enroll(ctx context.Context, user *models.User) (*TwoFactorEnrollResponse, error) {
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err = ctl.userRepo.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	uri := totp.URI(twoFactorIssuer, user.Username, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollResponse{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+func (ctl *Controller) enableTOTP(ctx context.Context, user *models.User) ([]string, error) {
+	codes, hashes, err := recoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = ctl.userRepo.EnableTOTP(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	user.TOTPEnabled = true
+	return codes, nil
+}
+
+// checkTOTP validates a TOTP code of the user and records its time step, so that the
+// code is not accepted again within its validity window.
+func (ctl *Controller) checkTOTP(ctx context.Context, user *models.User, code string) error {
+	counter, ok := totp.Match(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return errors.New("invalid two-factor code")
+	}
+	ok, err := ctl.userRepo.UseTOTPCounter(ctx, user.ID, counter)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("two-factor code already used")
+	}
+	return nil
+}
+
+func recoveryCodes() ([]string, []string, error) {
+	codes, err := totp.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, totp.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// Logging
+// @Summary      Logging settings
+// @Description  Format, minimum level and counters of the api logger
+// @Tags         System
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      200  {object}  LoggingResponse
+// @Router       /system/logging [get]
+func (ctl *Controller) Logging(c echo.Context) error {
+	return c.JSON(http.StatusOK, LoggingResponse{
+		Format: logger.GetFormat(),
+		Level:  string(logger.GetLevel()),
+		Stats:  logger.GetStats(),
+	})
+}
+
+// LoggingUpdate
+// @Summary      Update logging level
+// @Description  Change the minimum level of the api logger until the next restart, LOG_LEVEL sets it at startup
+// @Tags         System
+// @Accept       json
+// @Produce      json
+// @Param        request    body  PatchLoggingData   true "logging level data"
+// @Param        Authorization header string true "Bearer TOKEN"
+// @Failure      400 {object} request.ErrorResponse
+// @Failure      401 {object} middlewares.Unauthorized
+// @Failure      403 {object} middlewares.PermissionDenied
+// @Success      200  {object}  LoggingResponse
+// @Router       /system/logging [patch]
+func (ctl *Controller) LoggingUpdate(c echo.Context) error {
+	var data PatchLoggingData
+	if err := ctl.request.DoValidate(c, &data); err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+
+	level, err := logger.ParseLevel(data.Level)
+	if err != nil {
+		return ctl.request.BadRequest(c, err)
+	}
+	logger.SetLevel(level)
+
+	username, _ := c.Get("username").(string)
+	logger.FromContext(c.Request().Context()).With("username", username).Warn("Log level changed to %s", level)
+
+	return ctl.Logging(c)
+}
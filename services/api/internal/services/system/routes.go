@@ -2,6 +2,7 @@ package system
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 )
 
@@ -10,16 +11,31 @@ func Routes(e *echo.Group) {
 	e.GET("/system/init", ctl.SystemInit)
 	e.POST("/system/setup", ctl.SetupSystem)
 	e.POST("/system/users/login", ctl.Login, middlewares.RateLimitMiddleware(2, "m", 3))
+	e.POST("/system/users/login/2fa", ctl.LoginTwoFactor, middlewares.RateLimitMiddleware(5, "m", 5))
+	e.POST("/system/users/login/2fa/enroll", ctl.LoginTwoFactorEnroll, middlewares.RateLimitMiddleware(2, "m", 3))
 
-	g := e.Group("/system", middlewares.AuthMiddleware())
+	g := e.Group("/system", middlewares.AuthMiddleware(repository.NewUserTokenRepository()))
 	g.GET("", ctl.System)
 	g.POST("/users/password", ctl.ChangePasswordBySelf)
 	g.GET("/users/profile", ctl.Profile)
+	g.POST("/users/logout", ctl.Logout)
+	g.GET("/users/sessions", ctl.Sessions)
+	g.DELETE("/users/sessions", ctl.RevokeOtherSessions)
+	g.DELETE("/users/sessions/:uid", ctl.RevokeSession)
+	g.POST("/users/2fa/enroll", ctl.TwoFactorEnroll)
+	g.POST("/users/2fa/verify", ctl.TwoFactorVerify)
+	g.POST("/users/2fa/disable", ctl.TwoFactorDisable)
+	g.POST("/users/2fa/recovery_codes", ctl.TwoFactorRecoveryCodes)
 
 	g.PATCH("", ctl.SystemUpdate, middlewares.AdminPermission())
+	g.GET("/logging", ctl.Logging, middlewares.AdminPermission())
+	g.PATCH("/logging", ctl.LoggingUpdate, middlewares.AdminPermission())
 	g.POST("/users", ctl.CreateUser, middlewares.AdminPermission())
 	g.POST("/users/:uid/password", ctl.ChangeUserPasswordByAdmin, middlewares.AdminPermission())
 	g.DELETE("/users/:uid", ctl.DeleteUser, middlewares.AdminPermission())
+	g.GET("/users/:uid/quota", ctl.UserQuota, middlewares.AdminPermission())
+	g.PATCH("/users/:uid/quota", ctl.UpdateUserQuota, middlewares.AdminPermission())
+	g.POST("/users/:uid/2fa/reset", ctl.ResetUserTwoFactor, middlewares.AdminPermission())
 	g.GET("/users", ctl.Users, middlewares.AdminPermission())
 	g.GET("/users/lookup", ctl.UsersLookup, middlewares.AdminPermission())
 }
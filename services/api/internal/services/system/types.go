@@ -3,6 +3,8 @@ package system
 import (
 	"github.com/mmtaee/ocserv-dashboard/api/internal/models"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"time"
 )
 
 type GetSystemInitResponse struct {
@@ -14,6 +16,13 @@ type GetSystemResponse struct {
 	GoogleCaptchaSecretKey  string `json:"google_captcha_secret_key" validate:"omitempty"`
 	AutoDeleteInactiveUsers bool   `json:"auto_delete_inactive_users" validate:"omitempty"`
 	KeepInactiveUserDays    int    `json:"keep_inactive_user_days" validate:"omitempty"`
+	Require2FAAdmins        bool   `json:"require_2fa_admins" validate:"omitempty"`
+	Require2FAStaffs        bool   `json:"require_2fa_staffs" validate:"omitempty"`
+	AnomalyDetection        bool   `json:"anomaly_detection" validate:"omitempty"`
+	AnomalyAutoLock         bool   `json:"anomaly_auto_lock" validate:"omitempty"`
+	AnomalyMaxConcurrentIPs int    `json:"anomaly_max_concurrent_ips" validate:"omitempty"`
+	AnomalyMaxTravelKmh     int    `json:"anomaly_max_travel_kmh" validate:"omitempty"`
+	AnomalyMaxUserAgents    int    `json:"anomaly_max_user_agents" validate:"omitempty"`
 }
 
 type PatchSystemUpdateData struct {
@@ -21,6 +30,13 @@ type PatchSystemUpdateData struct {
 	GoogleCaptchaSecretKey  *string `json:"google_captcha_secret_key" validate:"required"`
 	AutoDeleteInactiveUsers *bool   `json:"auto_delete_inactive_users" validate:"required"`
 	KeepInactiveUserDays    *int    `json:"keep_inactive_user_days" validate:"required"`
+	Require2FAAdmins        *bool   `json:"require_2fa_admins" validate:"omitempty"`
+	Require2FAStaffs        *bool   `json:"require_2fa_staffs" validate:"omitempty"`
+	AnomalyDetection        *bool   `json:"anomaly_detection" validate:"omitempty"`
+	AnomalyAutoLock         *bool   `json:"anomaly_auto_lock" validate:"omitempty"`
+	AnomalyMaxConcurrentIPs *int    `json:"anomaly_max_concurrent_ips" validate:"omitempty,gte=1" example:"1"`
+	AnomalyMaxTravelKmh     *int    `json:"anomaly_max_travel_kmh" validate:"omitempty,gte=1" example:"900"`
+	AnomalyMaxUserAgents    *int    `json:"anomaly_max_user_agents" validate:"omitempty,gte=1" example:"3"`
 }
 
 type LoginData struct {
@@ -31,8 +47,46 @@ type LoginData struct {
 }
 
 type UserLoginResponse struct {
-	User  *models.User `json:"user" validate:"required"`
-	Token string       `json:"token" validate:"required"`
+	User          *models.User        `json:"user,omitempty" validate:"omitempty"`
+	Token         string              `json:"token,omitempty" validate:"omitempty"`
+	TwoFactor     *TwoFactorChallenge `json:"two_factor,omitempty" validate:"omitempty" desc:"set when a second login step is required"`
+	RecoveryCodes []string            `json:"recovery_codes,omitempty" validate:"omitempty" desc:"returned once when 2FA is enabled during login"`
+}
+
+type TwoFactorChallenge struct {
+	ChallengeToken     string    `json:"challenge_token" validate:"required"`
+	ExpireAt           time.Time `json:"expire_at" validate:"required"`
+	EnrollmentRequired bool      `json:"enrollment_required" validate:"required"`
+}
+
+type TwoFactorLoginData struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"omitempty,len=6,numeric" example:"123456"`
+	RecoveryCode   string `json:"recovery_code" validate:"omitempty" example:"a1b2c-3d4e5"`
+	RememberMe     bool   `json:"remember_me" desc:"remember for a month"`
+}
+
+type TwoFactorChallengeData struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret" validate:"required"`
+	URI    string `json:"uri" validate:"required"`
+	QRCode string `json:"qr_code" validate:"required" desc:"PNG data URI of the otpauth URI"`
+}
+
+type TwoFactorCodeData struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+type TwoFactorDisableData struct {
+	Password string `json:"password" validate:"required,min=4,max=16"`
+	Code     string `json:"code" validate:"omitempty,len=6,numeric" example:"123456"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" validate:"required"`
 }
 
 type CreateUserData struct {
@@ -69,3 +123,41 @@ type SetupSystemResponse struct {
 	System models.System `json:"system" validate:"required"`
 	Token  string        `json:"token" validate:"required"`
 }
+
+type ProfileResponse struct {
+	*models.User
+	Quota *models.UserQuota      `json:"quota,omitempty" validate:"omitempty"`
+	Usage *models.UserQuotaUsage `json:"usage,omitempty" validate:"omitempty"`
+}
+
+type PatchUserQuotaData struct {
+	MaxUsers      *int      `json:"max_users" validate:"omitempty,gte=0" example:"50"`
+	MaxTrafficGiB *int      `json:"max_traffic_gib" validate:"omitempty,gte=0" example:"500"`
+	AllowedGroups *[]string `json:"allowed_groups" validate:"omitempty" example:"defaults"`
+	MaxExpireDays *int      `json:"max_expire_days" validate:"omitempty,gte=0" example:"90"`
+}
+
+type UserQuotaResponse struct {
+	Quota *models.UserQuota      `json:"quota" validate:"required"`
+	Usage *models.UserQuotaUsage `json:"usage" validate:"required"`
+}
+
+type UserSession struct {
+	UID        string     `json:"uid" validate:"required"`
+	IP         string     `json:"ip" validate:"required"`
+	UserAgent  string     `json:"user_agent" validate:"required"`
+	CreatedAt  time.Time  `json:"created_at" validate:"required"`
+	LastSeenAt *time.Time `json:"last_seen_at" validate:"omitempty"`
+	ExpireAt   time.Time  `json:"expire_at" validate:"required"`
+	Current    bool       `json:"current" validate:"required"`
+}
+
+type LoggingResponse struct {
+	Format string       `json:"format" validate:"required" enums:"text,json,logfmt"`
+	Level  string       `json:"level" validate:"required" enums:"DEBUG,INFO,WARNING,ERROR"`
+	Stats  logger.Stats `json:"stats" validate:"required"`
+}
+
+type PatchLoggingData struct {
+	Level string `json:"level" validate:"required,oneof=debug info warn warning error DEBUG INFO WARN WARNING ERROR" example:"debug"`
+}
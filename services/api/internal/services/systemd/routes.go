@@ -2,12 +2,13 @@ package systemd
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/repository"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 )
 
 func Routes(e *echo.Group) {
 	ctl := New()
-	g := e.Group("/systemd", middlewares.AuthMiddleware(), middlewares.AdminPermission())
+	g := e.Group("/systemd", middlewares.AuthMiddleware(repository.NewUserTokenRepository()), middlewares.AdminPermission())
 
 	g.GET("/status", ctl.Status)
 	g.POST("/restart",
@@ -11,6 +11,23 @@ import (
 var Migrations = []*gormigrate.Migration{
 	migrations.Migration001,
 	migrations.Migration002,
+	migrations.Migration003,
+	migrations.Migration004,
+	migrations.Migration005,
+	migrations.Migration006,
+	migrations.Migration007,
+	migrations.Migration008,
+	migrations.Migration009,
+	migrations.Migration010,
+	migrations.Migration011,
+	migrations.Migration012,
+	migrations.Migration013,
+	migrations.Migration014,
+	migrations.Migration015,
+	migrations.Migration016,
+	migrations.Migration017,
+	migrations.Migration018,
+	migrations.Migration019,
 }
 
 func Migrate() {
@@ -2,9 +2,12 @@ package bootstrap
 
 import (
 	"context"
+	"github.com/mmtaee/ocserv-dashboard/api/internal/monitor"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/leader"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
 	"os"
 	"os/signal"
@@ -16,7 +19,13 @@ func Serve(debug bool, host string, port int) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	logger.Init(ctx, 100)
+	// the logger outlives the shutdown timeout and is drained on return
+	logCtx, stopLogger := context.WithCancel(context.Background())
+	defer func() {
+		stopLogger()
+		<-logger.Done()
+	}()
+	logger.Init(logCtx, "api", 1000)
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -30,6 +39,30 @@ func Serve(debug bool, host string, port int) {
 	database.Connect()
 	defer database.Close()
 
+	// the api runs next to ocserv, OCSERV_DRIVER selects another driver
+	ocservDriver, err := driver.Init(false)
+	if err != nil {
+		logger.Fatal("Failed to create ocserv driver: %v", err)
+	}
+
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	go monitor.NewSampler().Start(monitorCtx)
+	go monitor.Bandwidth().Start(monitorCtx)
+	if ocservDriver.SupportsEvents() {
+		go monitor.Events().Start(monitorCtx)
+	}
+
+	// replicas share the ocserv ban list and the ban history, only the leader polls it
+	banElector := leader.New(database.GetConnection(), "api_bans")
+	banElectorDone := make(chan struct{})
+	go func() {
+		defer close(banElectorDone)
+		banElector.Run(monitorCtx, func(ctx context.Context) {
+			monitor.NewBanPoller().Start(ctx)
+		})
+	}()
+
 	go routing.Serve(cfg)
 
 	quit := make(chan os.Signal, 1)
@@ -45,6 +78,8 @@ func Serve(debug bool, host string, port int) {
 
 	logger.Warn("Shutting down... Signal Reason: %s", sig.String())
 
+	stopMonitor()
+	<-banElectorDone
 	routing.Shutdown(ctx)
 	database.Close()
 
@@ -7,12 +7,26 @@ import (
 	"time"
 )
 
+// ScopeCustomer marks tokens issued to ocserv users by the customer portal.
+// Dashboard tokens carry no scope claim.
+const ScopeCustomer = "customer"
+
+// ScopeTwoFactor marks the short-lived challenge token returned by the dashboard
+// login when a second factor is still required.
+const ScopeTwoFactor = "2fa"
+
 func GenerateAccessToken(userID, username string, expire int64, isAdmin bool) (string, error) {
+	return GenerateAccessTokenWithID(ulid.Make().String(), userID, username, expire, isAdmin)
+}
+
+// GenerateAccessTokenWithID is GenerateAccessToken with a caller provided jti,
+// used to bind the token to its stored UserToken row.
+func GenerateAccessTokenWithID(tokenID, userID, username string, expire int64, isAdmin bool) (string, error) {
 	cfg := config.Get()
 
 	claims := jwt.MapClaims{
 		"sub":      userID,
-		"jti":      ulid.Make().String(),
+		"jti":      tokenID,
 		"exp":      expire,
 		"iat":      time.Now().Unix(),
 		"isAdmin":  isAdmin,
@@ -22,3 +36,38 @@ func GenerateAccessToken(userID, username string, expire int64, isAdmin bool) (s
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(cfg.JWTSecret))
 }
+
+// GenerateCustomerToken issues a customer portal token. version is the token
+// version of the ocserv user, the token is revoked when it is bumped.
+func GenerateCustomerToken(ocservUserUID, username string, version int, expire int64) (string, error) {
+	cfg := config.Get()
+
+	claims := jwt.MapClaims{
+		"sub":      ocservUserUID,
+		"jti":      ulid.Make().String(),
+		"exp":      expire,
+		"iat":      time.Now().Unix(),
+		"scope":    ScopeCustomer,
+		"username": username,
+		"ver":      version,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.JWTSecret))
+}
+
+func GenerateTwoFactorChallengeToken(userID, username string, expire int64) (string, error) {
+	cfg := config.Get()
+
+	claims := jwt.MapClaims{
+		"sub":      userID,
+		"jti":      ulid.Make().String(),
+		"exp":      expire,
+		"iat":      time.Now().Unix(),
+		"scope":    ScopeTwoFactor,
+		"username": username,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.JWTSecret))
+}
@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
@@ -13,6 +14,8 @@ func TestGenerateAccessToken(t *testing.T) {
 	adminUsername := "admin"
 	secret := "my-secret-key"
 	err := os.Setenv("JWT_SECRET", secret)
+	assert.NoError(t, err)
+	config.Init(false, "", 0)
 	expire := time.Now().Add(time.Hour).Unix()
 
 	tokenString, err := GenerateAccessToken(userID, adminUsername, expire, true)
@@ -33,3 +36,27 @@ func TestGenerateAccessToken(t *testing.T) {
 	assert.Equal(t, userID, claims["sub"])
 	assert.Equal(t, true, claims["isAdmin"])
 }
+
+func TestGenerateCustomerToken(t *testing.T) {
+	secret := "my-secret-key"
+	err := os.Setenv("JWT_SECRET", secret)
+	assert.NoError(t, err)
+	config.Init(false, "", 0)
+	expire := time.Now().Add(time.Hour).Unix()
+
+	tokenString, err := GenerateCustomerToken("01JABCDEF", "customer1", 2, expire)
+	assert.NoError(t, err)
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, token.Valid)
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	assert.True(t, ok)
+	assert.Equal(t, ScopeCustomer, claims["scope"])
+	assert.Equal(t, "customer1", claims["username"])
+	assert.Equal(t, float64(2), claims["ver"])
+	assert.Nil(t, claims["isAdmin"])
+}
@@ -0,0 +1,99 @@
+package netdev
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPath is the kernel interface statistics file.
+const DefaultPath = "/proc/net/dev"
+
+// Counters are the cumulative byte and packet counters of a network interface.
+type Counters struct {
+	Interface string
+	RxBytes   uint64
+	RxPackets uint64
+	TxBytes   uint64
+	TxPackets uint64
+}
+
+// Read parses DefaultPath.
+func Read() (map[string]Counters, error) {
+	return ReadFile(DefaultPath)
+}
+
+// ReadFile parses a file in /proc/net/dev format.
+func ReadFile(path string) (map[string]Counters, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads /proc/net/dev content and returns the counters keyed by interface name.
+//
+//	Inter-|   Receive                            |  Transmit
+//	 face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets ...
+//	vpns0: 1024     8       0    0    0    0     0          0         2048     16      ...
+func Parse(r io.Reader) (map[string]Counters, error) {
+	counters := make(map[string]Counters)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		name, data, ok := strings.Cut(line, ":")
+		if !ok || strings.Contains(name, "|") {
+			continue
+		}
+
+		fields := strings.Fields(data)
+		if len(fields) < 10 {
+			return nil, fmt.Errorf("invalid interface line: %q", line)
+		}
+
+		values := make([]uint64, 10)
+		for _, i := range []int{0, 1, 8, 9} {
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid counter in line %q: %w", line, err)
+			}
+			values[i] = v
+		}
+
+		name = strings.TrimSpace(name)
+		counters[name] = Counters{
+			Interface: name,
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return counters, nil
+}
+
+// Rate returns the receive and transmit rates in bits per second between two
+// samples of the same interface. A counter reset (e.g. a recreated tun device) yields zero.
+func Rate(prev, current Counters, elapsed time.Duration) (rxBps, txBps float64) {
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return 0, 0
+	}
+	if current.RxBytes >= prev.RxBytes {
+		rxBps = float64(current.RxBytes-prev.RxBytes) * 8 / seconds
+	}
+	if current.TxBytes >= prev.TxBytes {
+		txBps = float64(current.TxBytes-prev.TxBytes) * 8 / seconds
+	}
+	return rxBps, txBps
+}
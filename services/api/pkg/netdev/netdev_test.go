@@ -0,0 +1,51 @@
+package netdev
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadFile(t *testing.T) {
+	counters, err := ReadFile(filepath.Join("testdata", "net_dev.txt"))
+	assert.NoError(t, err)
+	assert.Len(t, counters, 4)
+
+	assert.Equal(t, Counters{
+		Interface: "eth0",
+		RxBytes:   987654321,
+		RxPackets: 654321,
+		TxBytes:   123456789,
+		TxPackets: 98765,
+	}, counters["eth0"])
+	assert.Equal(t, uint64(4096), counters["vpns0"].RxBytes)
+	assert.Equal(t, uint64(8192), counters["vpns0"].TxBytes)
+	assert.Equal(t, uint64(0), counters["vpns1"].TxBytes)
+}
+
+func TestParse_InvalidLine(t *testing.T) {
+	_, err := Parse(strings.NewReader("eth0: 1 2 3\n"))
+	assert.Error(t, err)
+
+	_, err = Parse(strings.NewReader("eth0: 1 2 3 4 5 6 7 8 x 10\n"))
+	assert.Error(t, err)
+}
+
+func TestRate(t *testing.T) {
+	prev := Counters{RxBytes: 1000, TxBytes: 5000}
+	current := Counters{RxBytes: 3000, TxBytes: 5500}
+
+	rx, tx := Rate(prev, current, 2*time.Second)
+	assert.Equal(t, float64(8000), rx)
+	assert.Equal(t, float64(2000), tx)
+
+	rx, tx = Rate(current, prev, 2*time.Second)
+	assert.Zero(t, rx)
+	assert.Zero(t, tx)
+
+	rx, tx = Rate(prev, current, 0)
+	assert.Zero(t, rx)
+	assert.Zero(t, tx)
+}
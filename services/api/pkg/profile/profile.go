@@ -0,0 +1,246 @@
+package profile
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/skip2/go-qrcode"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Client holds everything needed to build a client connection profile.
+type Client struct {
+	Name     string
+	Host     string
+	Port     string
+	Username string
+	Group    string
+	CertPin  string
+}
+
+// Bundle is the JSON representation of all supported profile formats.
+type Bundle struct {
+	AnyConnect  string `json:"anyconnect" validate:"required"`
+	OpenConnect string `json:"openconnect" validate:"required"`
+	DeepLink    string `json:"deep_link" validate:"required"`
+	QRCode      string `json:"qr_code" validate:"required" desc:"PNG data URI of the deep link"`
+}
+
+type Generator struct{}
+
+type GeneratorInterface interface {
+	AnyConnect(client *Client) ([]byte, error)
+	OpenConnect(client *Client) string
+	DeepLink(client *Client) string
+	QRCode(client *Client, size int) ([]byte, error)
+	Bundle(client *Client) (*Bundle, error)
+}
+
+func NewGenerator() *Generator {
+	return &Generator{}
+}
+
+type anyConnectProfile struct {
+	XMLName              xml.Name `xml:"AnyConnectProfile"`
+	XMLNS                string   `xml:"xmlns,attr"`
+	ClientInitialization struct {
+		UseStartBeforeLogon       bool `xml:"UseStartBeforeLogon"`
+		StrictCertificateTrust    bool `xml:"StrictCertificateTrust"`
+		RestrictPreferenceCaching bool `xml:"RestrictPreferenceCaching"`
+		BypassDownloader          bool `xml:"BypassDownloader"`
+		AutoUpdate                bool `xml:"AutoUpdate"`
+	} `xml:"ClientInitialization"`
+	ServerList struct {
+		HostEntry []anyConnectHostEntry `xml:"HostEntry"`
+	} `xml:"ServerList"`
+}
+
+type anyConnectHostEntry struct {
+	HostName    string `xml:"HostName"`
+	HostAddress string `xml:"HostAddress"`
+	UserGroup   string `xml:"UserGroup,omitempty"`
+}
+
+// AnyConnect returns an AnyConnect XML client profile with a single host entry.
+func (g *Generator) AnyConnect(client *Client) ([]byte, error) {
+	if client.Host == "" {
+		return nil, errors.New("server host is required")
+	}
+
+	p := anyConnectProfile{XMLNS: "http://schemas.xmlsoap.org/encoding/"}
+	p.ClientInitialization.BypassDownloader = true
+	p.ServerList.HostEntry = []anyConnectHostEntry{
+		{
+			HostName:    client.name(),
+			HostAddress: client.address(),
+			UserGroup:   client.group(),
+		},
+	}
+
+	out, err := xml.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// OpenConnect returns an openconnect command line for the client.
+func (g *Generator) OpenConnect(client *Client) string {
+	args := []string{"openconnect", "--protocol=anyconnect", "--user=" + shellQuote(client.Username)}
+	if group := client.group(); group != "" {
+		args = append(args, "--authgroup="+shellQuote(group))
+	}
+	if client.CertPin != "" {
+		args = append(args, "--servercert="+client.CertPin)
+	}
+	args = append(args, "https://"+client.address())
+	return strings.Join(args, " ")
+}
+
+// DeepLink returns an anyconnect:// URI that creates the connection entry on mobile clients.
+func (g *Generator) DeepLink(client *Client) string {
+	query := url.Values{}
+	query.Set("name", client.name())
+	query.Set("host", client.address())
+	if group := client.group(); group != "" {
+		query.Set("usergroup", group)
+	}
+	return "anyconnect://create/?" + query.Encode()
+}
+
+// QRCode returns a PNG QR code of the deep link.
+func (g *Generator) QRCode(client *Client, size int) ([]byte, error) {
+	return qrcode.Encode(g.DeepLink(client), qrcode.Medium, size)
+}
+
+// Bundle returns every profile format of the client at once.
+func (g *Generator) Bundle(client *Client) (*Bundle, error) {
+	anyConnect, err := g.AnyConnect(client)
+	if err != nil {
+		return nil, err
+	}
+	png, err := g.QRCode(client, 256)
+	if err != nil {
+		return nil, err
+	}
+	return &Bundle{
+		AnyConnect:  string(anyConnect),
+		OpenConnect: g.OpenConnect(client),
+		DeepLink:    g.DeepLink(client),
+		QRCode:      "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// NewClient builds a client of the given ocserv user from the configured server
+// address. The certificate pin is left empty when the certificate cannot be read.
+func NewClient(username, group string) *Client {
+	cfg := config.Get()
+	pin, err := CertPin(cfg.Ocserv.CertPath)
+	if err != nil {
+		logger.Warn("failed to compute certificate pin: %v", err)
+	}
+	return &Client{
+		Host:     cfg.Ocserv.Host,
+		Port:     cfg.Ocserv.Port,
+		Username: username,
+		Group:    group,
+		CertPin:  pin,
+	}
+}
+
+// Render writes the profile in the requested format:
+// json (default), anyconnect, openconnect or qr.
+func Render(c echo.Context, g GeneratorInterface, client *Client, format string) error {
+	switch format {
+	case "", "json":
+		bundle, err := g.Bundle(client)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, bundle)
+	case "anyconnect":
+		out, err := g.AnyConnect(client)
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set(
+			echo.HeaderContentDisposition,
+			fmt.Sprintf("attachment; filename=%s.xml", client.Username),
+		)
+		return c.Blob(http.StatusOK, echo.MIMEApplicationXMLCharsetUTF8, out)
+	case "openconnect":
+		return c.String(http.StatusOK, g.OpenConnect(client))
+	case "qr":
+		png, err := g.QRCode(client, 256)
+		if err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, "image/png", png)
+	default:
+		return fmt.Errorf("invalid profile format: %s", format)
+	}
+}
+
+// CertPin returns the openconnect pin-sha256 of the PEM certificate at path.
+func CertPin(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "pin-sha256:" + base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+func (c *Client) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Host
+}
+
+func (c *Client) address() string {
+	if c.Port == "" || c.Port == "443" {
+		return c.Host
+	}
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+// group is empty for the defaults group, which needs no explicit selection.
+func (c *Client) group() string {
+	if c.Group == "defaults" {
+		return ""
+	}
+	return c.Group
+}
+
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '-' || r == '_' || r == '.' || r == '@' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,99 @@
+package profile
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerator_AnyConnect(t *testing.T) {
+	client := &Client{Name: "Office", Host: "vpn.example.com", Port: "4443", Username: "john", Group: "staff"}
+
+	out, err := NewGenerator().AnyConnect(client)
+	assert.NoError(t, err)
+
+	xml := string(out)
+	assert.True(t, strings.HasPrefix(xml, "<?xml"))
+	assert.Contains(t, xml, "<HostName>Office</HostName>")
+	assert.Contains(t, xml, "<HostAddress>vpn.example.com:4443</HostAddress>")
+	assert.Contains(t, xml, "<UserGroup>staff</UserGroup>")
+}
+
+func TestGenerator_AnyConnect_DefaultsGroup(t *testing.T) {
+	client := &Client{Host: "vpn.example.com", Port: "443", Username: "john", Group: "defaults"}
+
+	out, err := NewGenerator().AnyConnect(client)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "<HostAddress>vpn.example.com</HostAddress>")
+	assert.NotContains(t, string(out), "UserGroup")
+}
+
+func TestGenerator_AnyConnect_MissingHost(t *testing.T) {
+	_, err := NewGenerator().AnyConnect(&Client{Username: "john"})
+	assert.Error(t, err)
+}
+
+func TestGenerator_OpenConnect(t *testing.T) {
+	client := &Client{
+		Host:     "vpn.example.com",
+		Port:     "8443",
+		Username: "john doe",
+		Group:    "staff",
+		CertPin:  "pin-sha256:abc=",
+	}
+
+	cmd := NewGenerator().OpenConnect(client)
+	assert.Equal(
+		t,
+		"openconnect --protocol=anyconnect --user='john doe' --authgroup=staff --servercert=pin-sha256:abc= https://vpn.example.com:8443",
+		cmd,
+	)
+}
+
+func TestGenerator_DeepLinkAndQRCode(t *testing.T) {
+	client := &Client{Name: "My VPN", Host: "vpn.example.com", Port: "443", Username: "john", Group: "defaults"}
+	g := NewGenerator()
+
+	link := g.DeepLink(client)
+	assert.Equal(t, "anyconnect://create/?host=vpn.example.com&name=My+VPN", link)
+
+	png, err := g.QRCode(client, 256)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(png), "\x89PNG"))
+}
+
+func TestCertPin(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vpn.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	assert.NoError(t, err)
+
+	pin, err := CertPin(path)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pin, "pin-sha256:"))
+	assert.Len(t, strings.TrimPrefix(pin, "pin-sha256:"), 44)
+
+	_, err = CertPin(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"net/http"
+)
+
+// RequestID returns the X-Request-ID set on c by the request logger middleware.
+func RequestID(c echo.Context) string {
+	requestID, _ := c.Get("requestID").(string)
+	return requestID
+}
+
+// NotFound answers 404 with an ErrorResponse, for records missing from the database.
+func (r *Request) NotFound(c echo.Context, msg ...string) error {
+	return c.JSON(http.StatusNotFound, ErrorResponse{
+		Error:     []string{http.StatusText(http.StatusNotFound)},
+		Message:   msg,
+		RequestID: RequestID(c),
+	})
+}
+
+// HTTPErrorHandler replaces the echo default error handler, so the errors
+// returned by handlers and middlewares, unknown routes and panics included, are
+// answered with an ErrorResponse carrying the request ID. Errors that are not an
+// echo.HTTPError are logged and answered as 500 without their details.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	code := http.StatusInternalServerError
+	message := http.StatusText(code)
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		code = he.Code
+		switch m := he.Message.(type) {
+		case string:
+			message = m
+		case error:
+			message = m.Error()
+		default:
+			message = fmt.Sprint(m)
+		}
+	} else {
+		logger.FromContext(c.Request().Context()).Error("%s %s: %v", c.Request().Method, c.Request().URL.Path, err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(code)
+	} else {
+		err = c.JSON(code, ErrorResponse{
+			Error:     []string{message},
+			RequestID: RequestID(c),
+		})
+	}
+	if err != nil {
+		logger.Error("Error sending the error response: %v", err)
+	}
+}
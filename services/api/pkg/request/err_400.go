@@ -9,8 +9,9 @@ import (
 )
 
 type ErrorResponse struct {
-	Error   []string `json:"error" validate:"required"`
-	Message []string `json:"message" validate:"required"`
+	Error     []string `json:"error" validate:"required"`
+	Message   []string `json:"message" validate:"required"`
+	RequestID string   `json:"request_id,omitempty" validate:"omitempty" desc:"X-Request-ID of the request, to find it in the service logs"`
 }
 
 func (r *Request) BadRequest(c echo.Context, err interface{}, msg ...string) error {
@@ -35,5 +36,6 @@ func (r *Request) BadRequest(c echo.Context, err interface{}, msg ...string) err
 		response.Error = append(response.Error, err.(string))
 	}
 	response.Message = append(response.Message, msg...)
+	response.RequestID = RequestID(c)
 	return c.JSON(http.StatusBadRequest, response)
 }
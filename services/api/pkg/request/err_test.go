@@ -0,0 +1,68 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCode  int
+		wantError string
+	}{
+		{
+			name:      "echo error",
+			err:       echo.NewHTTPError(http.StatusRequestTimeout, "Request Timeout"),
+			wantCode:  http.StatusRequestTimeout,
+			wantError: "Request Timeout",
+		},
+		{
+			name:      "unknown route",
+			err:       echo.ErrNotFound,
+			wantCode:  http.StatusNotFound,
+			wantError: "Not Found",
+		},
+		{
+			name:      "handler error",
+			err:       errors.New("connection refused"),
+			wantCode:  http.StatusInternalServerError,
+			wantError: "Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/servers", nil), rec)
+			c.Set("requestID", "req-1")
+
+			HTTPErrorHandler(tt.err, c)
+
+			var response ErrorResponse
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, []string{tt.wantError}, response.Error)
+			assert.Equal(t, "req-1", response.RequestID)
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/servers/1", nil), rec)
+	c.Set("requestID", "req-2")
+
+	assert.NoError(t, NewCustomRequest().NotFound(c))
+
+	var response ErrorResponse
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "req-2", response.RequestID)
+}
@@ -12,6 +12,7 @@ type Request struct {
 type CustomRequestInterface interface {
 	DoValidate(echo.Context, interface{}) interface{}
 	BadRequest(c echo.Context, err interface{}, msg ...string) error
+	NotFound(c echo.Context, msg ...string) error
 	Pagination(c echo.Context) *Pagination
 	//Response(c echo.Context, p *Pagination, total int64, result interface{}) error
 }
@@ -27,7 +27,16 @@ func (l *WrapperLogger) Prefix() string { return "" }
 func (l *WrapperLogger) SetPrefix(p string) {}
 
 func (l *WrapperLogger) Level() LabstackLog.Lvl {
-	return LabstackLog.INFO
+	switch logger.GetLevel() {
+	case logger.DebugLevel:
+		return LabstackLog.DEBUG
+	case logger.WarnLevel:
+		return LabstackLog.WARN
+	case logger.ErrorLevel:
+		return LabstackLog.ERROR
+	default:
+		return LabstackLog.INFO
+	}
 }
 
 func (l *WrapperLogger) SetLevel(v LabstackLog.Lvl) {}
@@ -35,18 +44,21 @@ func (l *WrapperLogger) SetLevel(v LabstackLog.Lvl) {}
 func (l *WrapperLogger) SetHeader(h string) {}
 
 func (l *WrapperLogger) send(level logger.LogLevel, format string, args ...interface{}) {
-	if l.Log != nil {
-		msg := logger.SafeSprintf(format, args...)
-		switch level {
-		case logger.InfoLevel:
-			l.send(logger.InfoLevel, msg)
-		case logger.WarnLevel:
-			l.send(logger.WarnLevel, msg)
-		case logger.ErrorLevel:
-			l.send(logger.ErrorLevel, msg)
-		case logger.FatalLevel:
-			l.send(logger.FatalLevel, msg)
-		}
+	if l.Log == nil {
+		return
+	}
+	entry := logger.With("component", "echo")
+	switch level {
+	case logger.DebugLevel:
+		entry.Debug(format, args...)
+	case logger.InfoLevel:
+		entry.Info(format, args...)
+	case logger.WarnLevel:
+		entry.Warn(format, args...)
+	case logger.ErrorLevel:
+		entry.Error(format, args...)
+	case logger.FatalLevel:
+		entry.Fatal(format, args...)
 	}
 }
 
@@ -63,15 +75,15 @@ func (l *WrapperLogger) Printj(j LabstackLog.JSON) {
 }
 
 func (l *WrapperLogger) Debug(i ...interface{}) {
-	l.send(logger.InfoLevel, "%v", fmt.Sprint(i...))
+	l.send(logger.DebugLevel, "%v", fmt.Sprint(i...))
 }
 
 func (l *WrapperLogger) Debugf(format string, args ...interface{}) {
-	l.send(logger.InfoLevel, format, args...)
+	l.send(logger.DebugLevel, format, args...)
 }
 
 func (l *WrapperLogger) Debugj(j LabstackLog.JSON) {
-	l.send(logger.InfoLevel, "%v", j)
+	l.send(logger.DebugLevel, "%v", j)
 }
 
 func (l *WrapperLogger) Info(i ...interface{}) {
@@ -124,18 +136,18 @@ func (l *WrapperLogger) Fatalj(j LabstackLog.JSON) {
 
 func (l *WrapperLogger) Panic(i ...interface{}) {
 	msg := logger.SafeSprintf("%v", fmt.Sprint(i...))
-	l.send(logger.FatalLevel, msg)
+	l.send(logger.ErrorLevel, "%s", msg)
 	panic(msg)
 }
 
 func (l *WrapperLogger) Panicf(format string, args ...interface{}) {
 	msg := logger.SafeSprintf(format, args...)
-	l.send(logger.FatalLevel, msg)
+	l.send(logger.ErrorLevel, "%s", msg)
 	panic(msg)
 }
 
 func (l *WrapperLogger) Panicj(j LabstackLog.JSON) {
 	msg := logger.SafeSprintf("%v", j)
-	l.send(logger.FatalLevel, msg)
+	l.send(logger.ErrorLevel, "%s", msg)
 	panic(msg)
 }
@@ -1,12 +1,21 @@
 package middlewares
 
 import (
+	"context"
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/crypto"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/token"
 	"strings"
 )
 
-func AuthMiddleware() echo.MiddlewareFunc {
+// TokenValidator reports whether a dashboard token, by its jti claim, is neither revoked nor expired.
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, tokenUID string) (bool, error)
+}
+
+// AuthMiddleware accepts only dashboard tokens the validator reports valid, looked up
+// on every request, and sets tokenUID, userUID, isAdmin and username in the context.
+func AuthMiddleware(tokens TokenValidator) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
@@ -21,6 +30,18 @@ func AuthMiddleware() echo.MiddlewareFunc {
 				return UnauthorizedError(c, "invalid token")
 			}
 
+			// scoped tokens (e.g. customer portal) are not valid for the dashboard
+			if _, scoped := claims["scope"]; scoped {
+				return UnauthorizedError(c, "invalid token")
+			}
+
+			tokenUID, _ := claims["jti"].(string)
+			valid, err := tokens.ValidateToken(c.Request().Context(), tokenUID)
+			if err != nil || !valid {
+				return UnauthorizedError(c, "token revoked or expired")
+			}
+
+			c.Set("tokenUID", tokenUID)
 			c.Set("userUID", claims["sub"])
 			c.Set("isAdmin", claims["isAdmin"])
 			c.Set("username", claims["username"])
@@ -28,3 +49,32 @@ func AuthMiddleware() echo.MiddlewareFunc {
 		}
 	}
 }
+
+// CustomerAuthMiddleware accepts only tokens issued by the customer portal login
+// and sets customerUID, customerUsername and customerTokenVersion in the context.
+func CustomerAuthMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+				return UnauthorizedError(c, "missing or invalid Authorization header")
+			}
+
+			tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+
+			claims, ok := token.Check(tokenStr)
+			if !ok {
+				return UnauthorizedError(c, "invalid token")
+			}
+
+			if scope, _ := claims["scope"].(string); scope != crypto.ScopeCustomer {
+				return UnauthorizedError(c, "invalid token")
+			}
+
+			c.Set("customerUID", claims["sub"])
+			c.Set("customerUsername", claims["username"])
+			c.Set("customerTokenVersion", claims["ver"])
+			return next(c)
+		}
+	}
+}
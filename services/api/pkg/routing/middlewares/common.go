@@ -2,29 +2,40 @@ package middlewares
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"net/http"
+	"strings"
 )
 
 type Unauthorized struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty" desc:"X-Request-ID of the request, to find it in the service logs"`
 }
 
 type PermissionDenied struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty" desc:"X-Request-ID of the request, to find it in the service logs"`
 }
 
 type TooManyRequests struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty" desc:"X-Request-ID of the request, to find it in the service logs"`
 }
 
 func UnauthorizedError(c echo.Context, msg string) error {
-	return c.JSON(http.StatusUnauthorized, Unauthorized{Error: msg})
+	return c.JSON(http.StatusUnauthorized, Unauthorized{Error: msg, RequestID: request.RequestID(c)})
 }
 
 func PermissionDeniedError(c echo.Context, msg string) error {
-	return c.JSON(http.StatusForbidden, PermissionDenied{Error: msg})
+	return c.JSON(http.StatusForbidden, PermissionDenied{Error: msg, RequestID: request.RequestID(c)})
 }
 
 func TooManyRequestsError(c echo.Context, msg string) error {
-	return c.JSON(http.StatusTooManyRequests, TooManyRequests{Error: msg})
+	return c.JSON(http.StatusTooManyRequests, TooManyRequests{Error: msg, RequestID: request.RequestID(c)})
+}
+
+// IsStream reports whether the route is a long-lived Server-Sent Events stream,
+// which must bypass the request timeout and response compression.
+func IsStream(c echo.Context) bool {
+	return strings.HasSuffix(c.Path(), "/stream")
 }
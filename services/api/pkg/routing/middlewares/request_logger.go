@@ -3,28 +3,42 @@ package middlewares
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
 	"net/http"
 	"time"
 )
 
+// RequestLoggerMiddleware tags every request with a request ID, taken from the
+// X-Request-ID header or generated, returns it in the response header and adds it
+// to the request context for logging and for calls to the other services.
 func RequestLoggerMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			start := time.Now()
 
+			req := c.Request()
+			requestID := req.Header.Get(requestid.Header)
+			if !requestid.Valid(requestID) {
+				requestID = requestid.New()
+			}
+			c.Set("requestID", requestID)
+			c.Response().Header().Set(requestid.Header, requestID)
+			c.SetRequest(req.WithContext(requestid.NewContext(req.Context(), requestID)))
+
 			err := next(c)
 
-			req := c.Request()
 			res := c.Response()
 
-			logger.Info(
-				"%s %s | %s | %d %s | %.3fs",
+			logger.FromContext(c.Request().Context()).With(
+				"ip", c.RealIP(),
+				"status", res.Status,
+				"latency_ms", time.Since(start).Milliseconds(),
+			).Info(
+				"%s %s | %d %s",
 				req.Method,
 				req.URL.Path,
-				c.RealIP(),
 				res.Status,
 				http.StatusText(res.Status),
-				time.Since(start).Seconds(),
 			)
 
 			return err
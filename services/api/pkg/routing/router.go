@@ -8,6 +8,7 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	LabstackLog "github.com/labstack/gommon/log"
 	"github.com/mmtaee/ocserv-dashboard/api/internal/providers/routing"
+	"github.com/mmtaee/ocserv-dashboard/api/pkg/request"
 	"github.com/mmtaee/ocserv-dashboard/api/pkg/routing/middlewares"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
@@ -40,7 +41,8 @@ func Serve(cfg *config.Config) {
 
 	e = echo.New()
 
-	//e.Logger = NewLoggerWrapper(logger.GetLogger())
+	e.Logger = NewLoggerWrapper(logger.GetLogger())
+	e.HTTPErrorHandler = request.HTTPErrorHandler
 
 	e.Pre(middleware.RemoveTrailingSlash())
 	e.Use(middlewares.RequestLoggerMiddleware())
@@ -86,6 +88,10 @@ func Serve(cfg *config.Config) {
 
 	e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
 		Skipper: func(c echo.Context) bool {
+			if middlewares.IsStream(c) {
+				return true
+			}
+
 			path := c.Path()
 
 			switch {
@@ -99,13 +105,13 @@ func Serve(cfg *config.Config) {
 		},
 	}))
 
+	logger.Info("Starting server at %s", server)
+
 	err := e.Start(server)
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		fmt.Printf("shutting down the server: %v\n", err) // use fmt, not Fatal
 		os.Exit(1)
 	}
-
-	logger.Info("Starting server at " + server)
 }
 
 func Shutdown(ctx context.Context) {
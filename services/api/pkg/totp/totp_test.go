@@ -0,0 +1,88 @@
+package totp
+
+import (
+	"encoding/base32"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for ts, expected := range vectors {
+		code, err := Code(rfcSecret, time.Unix(ts, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "timestamp %d", ts)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	assert.True(t, Validate(rfcSecret, "081804", now))
+	assert.True(t, Validate(rfcSecret, "081804", now.Add(Period*time.Second)), "previous period within skew")
+	assert.False(t, Validate(rfcSecret, "081804", now.Add(3*Period*time.Second)), "outside skew")
+	assert.False(t, Validate(rfcSecret, "000000", now))
+	assert.False(t, Validate(rfcSecret, "12345", now))
+	assert.False(t, Validate("not-base32!", "081804", now))
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	counter, ok := Match(rfcSecret, "081804", now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1111111109/Period), counter)
+
+	counter, ok = Match(rfcSecret, "081804", now.Add(Period*time.Second))
+	assert.True(t, ok, "previous period within skew")
+	assert.Equal(t, int64(1111111109/Period), counter, "the step of the code, not of now")
+
+	_, ok = Match(rfcSecret, "000000", now)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := Code(secret, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, Validate(secret, code, time.Now()))
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Ocserv Dashboard", "admin", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Ocserv%20Dashboard:admin?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Ocserv+Dashboard")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Len(t, code, 11)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	assert.Equal(t, HashRecoveryCode("abcde-12345"), HashRecoveryCode(" ABCDE12345 "))
+	assert.NotEqual(t, HashRecoveryCode("abcde-12345"), HashRecoveryCode("abcde-12346"))
+}
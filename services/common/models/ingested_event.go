@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// IngestedEvent is the ID of an occtl event pushed by the agent of a server. The
+// ID is the primary key, so an event pushed twice, by a retry of the agent or to
+// another log_stream replica, is only handled once.
+type IngestedEvent struct {
+	ID        string    `gorm:"type:varchar(26);primaryKey"`
+	ServerID  uint      `gorm:"not null;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `gorm:"index"`
+}
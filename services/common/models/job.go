@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Triggers of job runs.
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerMissed   = "missed"
+	JobTriggerManual   = "manual"
+)
+
+// Statuses of job runs.
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// Job is a scheduled job of user_expiry. Schedule is a cron spec with a leading
+// seconds field. Paused jobs only run when triggered, TriggerRequestedAt asks the
+// leader replica to run the job now.
+type Job struct {
+	Name               string     `json:"name" gorm:"primaryKey;type:varchar(64)" validate:"required"`
+	Schedule           string     `json:"schedule" gorm:"type:varchar(64);not null" validate:"required" example:"0 1 0 * * *"`
+	Paused             bool       `json:"paused" gorm:"not null;default:false" validate:"required"`
+	LastRunAt          *time.Time `json:"last_run_at" validate:"omitempty"`
+	TriggerRequestedAt *time.Time `json:"trigger_requested_at" validate:"omitempty"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime" validate:"required"`
+	UpdatedAt          time.Time  `json:"updated_at" gorm:"autoUpdateTime" validate:"omitempty"`
+}
+
+// JobRun is a run of a job. Affected is the number of users it changed, Error
+// the failures of the run.
+type JobRun struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement" validate:"required"`
+	JobName    string     `json:"job_name" gorm:"type:varchar(64);not null;index" validate:"required"`
+	Trigger    string     `json:"trigger" gorm:"type:varchar(16);not null" validate:"required" enums:"schedule,missed,manual"`
+	Status     string     `json:"status" gorm:"type:varchar(16);not null" validate:"required" enums:"running,succeeded,failed"`
+	StartedAt  time.Time  `json:"started_at" gorm:"not null" validate:"required"`
+	FinishedAt *time.Time `json:"finished_at" validate:"omitempty"`
+	Affected   int        `json:"affected" gorm:"not null;default:0" validate:"required"`
+	Error      string     `json:"error" gorm:"type:text" validate:"omitempty"`
+	RequestID  string     `json:"request_id" gorm:"type:varchar(32)" validate:"omitempty"`
+}
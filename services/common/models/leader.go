@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// LeaderLease is held by the replica of a service running its singleton work, e.g.
+// the cron jobs of user_expiry. The holder renews it before ExpiresAt, after that
+// another replica may take it over.
+type LeaderLease struct {
+	Name      string    `json:"name" gorm:"primaryKey;type:varchar(64)"`
+	Holder    string    `json:"holder" gorm:"type:varchar(128);not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
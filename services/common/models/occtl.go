@@ -1,5 +1,13 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
+// occtlTimeLayout is the layout of the human-readable dates printed by occtl, in server local time.
+const occtlTimeLayout = "2006-01-02 15:04"
+
 type IPBan struct {
 	IP       string `json:"IP"`
 	Since    string `json:"Since"`
@@ -16,12 +24,166 @@ type Iroute struct {
 	IRoutes  []string `json:"iRoutes"`
 }
 
+// OnlineUserSession is an entry of `occtl -j show users`, `show user <name>` and `show id <id>`.
+// JSON keys are kept as printed by occtl; ConnectedAt is derived from raw_connected_at
+// or, on versions without it, from "Connected at".
 type OnlineUserSession struct {
-	Username    string `json:"Username"`
-	Group       string `json:"Groupname"`
-	AverageRX   string `json:"Average RX"`
-	AverageTX   string `json:"Average TX"`
-	ConnectedAt string `json:"_Connected at"`
+	ID                 OcctlID      `json:"ID" swaggertype:"string"`
+	Username           string       `json:"Username"`
+	Group              string       `json:"Groupname"`
+	State              string       `json:"State"`
+	VHost              string       `json:"vhost"`
+	Device             string       `json:"Device"`
+	MTU                OcctlInt     `json:"MTU"`
+	RemoteIP           string       `json:"Remote IP"`
+	Location           string       `json:"Location"`
+	LocalDeviceIP      string       `json:"Local Device IP"`
+	IPv4               string       `json:"IPv4"`
+	PtPIPv4            string       `json:"P-t-P IPv4"`
+	IPv6               string       `json:"IPv6"`
+	PtPIPv6            string       `json:"P-t-P IPv6"`
+	UserAgent          string       `json:"User-Agent"`
+	Hostname           string       `json:"Hostname"`
+	RX                 OcctlInt     `json:"RX"` // bytes
+	TX                 OcctlInt     `json:"TX"` // bytes
+	RXHuman            string       `json:"_RX"`
+	TXHuman            string       `json:"_TX"`
+	AverageRX          string       `json:"Average RX"`
+	AverageTX          string       `json:"Average TX"`
+	DPD                OcctlInt     `json:"DPD"`
+	KeepAlive          OcctlInt     `json:"KeepAlive"`
+	ConnectedSince     string       `json:"_Connected at"` // e.g. "2h:15m"
+	RawConnectedAt     OcctlInt     `json:"raw_connected_at"`
+	ConnectedAt        time.Time    `json:"connected_at"`
+	FullSession        string       `json:"Full session"`
+	Session            string       `json:"Session"`
+	TLSCiphersuite     string       `json:"TLS ciphersuite"`
+	DTLSCipher         string       `json:"DTLS cipher"`
+	CSTPCompression    string       `json:"CSTP compression"`
+	DTLSCompression    string       `json:"DTLS compression"`
+	DNS                OcctlStrings `json:"DNS"`
+	NBNS               OcctlStrings `json:"NBNS"`
+	SplitDNSDomains    OcctlStrings `json:"Split-DNS-Domains"`
+	Routes             OcctlStrings `json:"Routes"`
+	NoRoutes           OcctlStrings `json:"No-routes"`
+	IRoutes            OcctlRoutes  `json:"iRoutes" swaggertype:"string"`
+	RestrictedToRoutes OcctlBool    `json:"Restricted to routes"`
+	RestrictedToPorts  OcctlStrings `json:"Restricted to ports"`
+	// Server is the name of the node of the session when sessions of several nodes are listed.
+	Server string `json:"server,omitempty"`
+}
+
+func (s *OnlineUserSession) UnmarshalJSON(b []byte) error {
+	type alias OnlineUserSession
+	aux := struct {
+		*alias
+		ConnectedAtText string `json:"Connected at"`
+	}{alias: (*alias)(s)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	// connected_at is already set when the session went through the webhook RPC API
+	if t := occtlTime(s.RawConnectedAt, aux.ConnectedAtText); !t.IsZero() {
+		s.ConnectedAt = t
+	}
+	return nil
+}
+
+// OcctlSession is an entry of `occtl -j show sessions all|valid` and `show session <sid>`.
+type OcctlSession struct {
+	Session       string    `json:"Session"`
+	FullSession   string    `json:"Full session"`
+	State         string    `json:"State"`
+	Username      string    `json:"Username"`
+	Group         string    `json:"Groupname"`
+	VHost         string    `json:"vhost"`
+	UserAgent     string    `json:"User-Agent"`
+	RemoteIP      string    `json:"Remote IP"`
+	Location      string    `json:"Location"`
+	SessionIsOpen OcctlBool `json:"session_is_open"`
+	TLSAuthOK     OcctlBool `json:"tls_auth_ok"`
+	InUse         OcctlBool `json:"in_use"`
+	CreatedSince  string    `json:"_Created"`
+	RawCreated    OcctlInt  `json:"raw_created"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (s *OcctlSession) UnmarshalJSON(b []byte) error {
+	type alias OcctlSession
+	aux := struct {
+		*alias
+		CreatedText string `json:"Created"`
+	}{alias: (*alias)(s)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	if t := occtlTime(s.RawCreated, aux.CreatedText); !t.IsZero() {
+		s.CreatedAt = t
+	}
+	return nil
+}
+
+// ServerStatus is the output of `occtl -j show status`.
+type ServerStatus struct {
+	Status              string   `json:"Status"`
+	ServerPID           OcctlInt `json:"Server PID"`
+	SecModPID           OcctlInt `json:"Sec-mod PID"`
+	SecModInstanceCount OcctlInt `json:"Sec-mod instance count"`
+	UpSince             string   `json:"Up since"`
+	UpSinceDuration     string   `json:"_Up since"`
+	RawUpSince          OcctlInt `json:"raw_up_since"`
+	Uptime              OcctlInt `json:"uptime"`
+	ActiveSessions      OcctlInt `json:"Active sessions"`
+	TotalSessions       OcctlInt `json:"Total sessions"`
+	TotalAuthFailures   OcctlInt `json:"Total authentication failures"`
+	IPsInBanList        OcctlInt `json:"IPs in ban list"`
+	MedianLatency       string   `json:"Median latency"`
+	STDEVLatency        string   `json:"STDEV latency"`
+	RawMedianLatency    OcctlInt `json:"raw_median_latency"`
+	RawSTDEVLatency     OcctlInt `json:"raw_stdev_latency"`
+
+	LastStatsReset           string   `json:"Last stats reset"`
+	LastStatsResetDuration   string   `json:"_Last stats reset"`
+	RawLastStatsReset        OcctlInt `json:"raw_last_stats_reset"`
+	SessionsHandled          OcctlInt `json:"Sessions handled"`
+	TimedOutSessions         OcctlInt `json:"Timed out sessions"`
+	TimedOutIdleSessions     OcctlInt `json:"Timed out (idle) sessions"`
+	ClosedDueToErrorSessions OcctlInt `json:"Closed due to error sessions"`
+	AuthenticationFailures   OcctlInt `json:"Authentication failures"`
+	AverageAuthTime          string   `json:"Average auth time"`
+	RawAvgAuthTime           OcctlInt `json:"raw_avg_auth_time"`
+	MaxAuthTime              string   `json:"Max auth time"`
+	RawMaxAuthTime           OcctlInt `json:"raw_max_auth_time"`
+	AverageSessionTime       string   `json:"Average session time"`
+	RawAvgSessionTime        OcctlInt `json:"raw_avg_session_time"`
+	MaxSessionTime           string   `json:"Max session time"`
+	RawMaxSessionTime        OcctlInt `json:"raw_max_session_time"`
+	RX                       string   `json:"RX"`
+	TX                       string   `json:"TX"`
+	RawRX                    OcctlInt `json:"raw_rx"`
+	RawTX                    OcctlInt `json:"raw_tx"`
+}
+
+const (
+	OcctlEventConnect    = "connect"
+	OcctlEventDisconnect = "disconnect"
+)
+
+// OcctlEvent is a connect or disconnect event printed by `occtl -j show events`.
+// RX and TX are the session totals in bytes, reported on disconnect.
+type OcctlEvent struct {
+	Type      string    `json:"type" enums:"connect,disconnect" validate:"required"`
+	Time      time.Time `json:"time" validate:"required"`
+	ID        int       `json:"id" validate:"required"`
+	Username  string    `json:"username" validate:"required"`
+	Group     string    `json:"group"`
+	RemoteIP  string    `json:"remote_ip"`
+	IPv4      string    `json:"ipv4"`
+	Device    string    `json:"device"`
+	UserAgent string    `json:"user_agent"`
+	RX        int64     `json:"rx"`
+	TX        int64     `json:"tx"`
+	Reason    string    `json:"reason,omitempty"`
 }
 
 type ServerVersion struct {
@@ -35,17 +197,27 @@ type OcservInfo struct {
 }
 
 type IPBanPoints struct {
-	IP    string `json:"IP"`
-	Since string `json:"Since"`
-	Until string `json:"_Since"`
-	Score int    `json:"Score"`
+	IP    string   `json:"IP"`
+	Since string   `json:"Since"`
+	Until string   `json:"_Since"`
+	Score OcctlInt `json:"Score"`
 }
 
 type IRoute struct {
-	ID       string `json:"ID"`
-	Username string `json:"Username"`
-	Vhost    string `json:"vhost"`
-	Device   string `json:"Device"`
-	IP       string `json:"IP"`
-	IRoute   string `json:"iRoutes"`
+	ID       OcctlID     `json:"ID" swaggertype:"string"`
+	Username string      `json:"Username"`
+	Vhost    string      `json:"vhost"`
+	Device   string      `json:"Device"`
+	IP       string      `json:"IP"`
+	IRoutes  OcctlRoutes `json:"iRoutes" swaggertype:"string"`
+}
+
+func occtlTime(raw OcctlInt, text string) time.Time {
+	if raw > 0 {
+		return time.Unix(int64(raw), 0)
+	}
+	if t, err := time.ParseInLocation(occtlTimeLayout, text, time.Local); err == nil {
+		return t
+	}
+	return time.Time{}
 }
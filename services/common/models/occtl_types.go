@@ -0,0 +1,131 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OcctlInt decodes occtl numbers, which depending on the field and ocserv version
+// are printed as JSON numbers or as quoted strings (e.g. "MTU": "1434").
+type OcctlInt int64
+
+func (i *OcctlInt) UnmarshalJSON(b []byte) error {
+	s := strings.TrimSpace(string(b))
+	if s == "null" {
+		return nil
+	}
+	s = strings.TrimSpace(strings.Trim(s, `"`))
+	if s == "" {
+		*i = 0
+		return nil
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return fmt.Errorf("OcctlInt: invalid number %s", b)
+		}
+		v = int64(f)
+	}
+	*i = OcctlInt(v)
+	return nil
+}
+
+// OcctlID decodes occtl IDs like OcctlInt but encodes them as JSON strings, the
+// wire type of session and iroute IDs in the API responses since before occtl
+// output was typed.
+type OcctlID int64
+
+func (i *OcctlID) UnmarshalJSON(b []byte) error {
+	return (*OcctlInt)(i).UnmarshalJSON(b)
+}
+
+func (i OcctlID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(i), 10))
+}
+
+// OcctlBool decodes true/false, 0/1 and "True"/"False" style occtl flags.
+type OcctlBool bool
+
+func (v *OcctlBool) UnmarshalJSON(b []byte) error {
+	s := strings.ToLower(strings.Trim(strings.TrimSpace(string(b)), `"`))
+	switch s {
+	case "true", "1", "yes":
+		*v = true
+	case "false", "0", "no", "", "null":
+		*v = false
+	default:
+		return fmt.Errorf("OcctlBool: invalid value %s", b)
+	}
+	return nil
+}
+
+// OcctlStrings decodes occtl lists, which are printed either as arrays or, when
+// there is a single value on some versions, as a plain string (e.g. "Routes": "defaultroute").
+// It always encodes as an array.
+type OcctlStrings []string
+
+func (s *OcctlStrings) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		*s = OcctlStrings{}
+		return nil
+	case len(b) > 0 && b[0] == '[':
+		var arr []string
+		if err := json.Unmarshal(b, &arr); err != nil {
+			return err
+		}
+		*s = arr
+		return nil
+	default:
+		var str string
+		if err := json.Unmarshal(b, &str); err != nil {
+			return err
+		}
+		if str = strings.TrimSpace(str); str == "" {
+			*s = OcctlStrings{}
+		} else {
+			*s = OcctlStrings{str}
+		}
+		return nil
+	}
+}
+
+func (s OcctlStrings) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(s))
+}
+
+// OcctlRoutes decodes occtl route lists like OcctlStrings, splitting a plain string
+// on commas, but encodes them as one comma separated string, the wire type of
+// iRoutes in the API responses since before occtl output was typed.
+type OcctlRoutes []string
+
+func (r *OcctlRoutes) UnmarshalJSON(b []byte) error {
+	var list OcctlStrings
+	if err := list.UnmarshalJSON(b); err != nil {
+		return err
+	}
+
+	routes := OcctlRoutes{}
+	for _, item := range list {
+		for _, route := range strings.Split(item, ",") {
+			if route = strings.TrimSpace(route); route != "" {
+				routes = append(routes, route)
+			}
+		}
+	}
+	*r = routes
+	return nil
+}
+
+func (r OcctlRoutes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(r, ", "))
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestIRouteWireTypes(t *testing.T) {
+	var route IRoute
+	err := json.Unmarshal([]byte(`{"ID": 1375, "iRoutes": ["10.10.0.0/255.255.0.0", "10.20.0.0/255.255.0.0"]}`), &route)
+	require.NoError(t, err)
+	assert.Equal(t, OcctlID(1375), route.ID)
+	assert.Equal(t, OcctlRoutes{"10.10.0.0/255.255.0.0", "10.20.0.0/255.255.0.0"}, route.IRoutes)
+
+	// IDs and iRoutes keep the string wire types of the API
+	b, err := json.Marshal(route)
+	require.NoError(t, err)
+	var wire map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &wire))
+	assert.Equal(t, "1375", wire["ID"])
+	assert.Equal(t, "10.10.0.0/255.255.0.0, 10.20.0.0/255.255.0.0", wire["iRoutes"])
+
+	// the encoded form decodes back, e.g. through the webhook RPC API
+	var decoded IRoute
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, route, decoded)
+}
+
+func TestOcctlRoutes_Empty(t *testing.T) {
+	var session OnlineUserSession
+	require.NoError(t, json.Unmarshal([]byte(`{"ID": "7", "iRoutes": []}`), &session))
+	assert.Equal(t, OcctlID(7), session.ID)
+	assert.Empty(t, session.IRoutes)
+
+	b, err := json.Marshal(session.IRoutes)
+	require.NoError(t, err)
+	assert.JSONEq(t, `""`, string(b))
+}
@@ -87,10 +87,11 @@ type OcservGroupConfig struct {
 }
 
 type OcservGroup struct {
-	ID     uint               `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name   string             `json:"name" gorm:"type:varchar(255);not null;uniqueIndex" validate:"required"`
-	Owner  string             `json:"owner" gorm:"type:varchar(32);default:''" validate:"required"`
-	Config *OcservGroupConfig `json:"config" gorm:"type:json"`
+	ID      uint               `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name    string             `json:"name" gorm:"type:varchar(255);not null;uniqueIndex" validate:"required"`
+	Owner   string             `json:"owner" gorm:"type:varchar(32);default:''" validate:"required"`
+	Config  *OcservGroupConfig `json:"config" gorm:"type:json"`
+	Servers []Server           `json:"servers" gorm:"many2many:ocserv_group_servers;joinForeignKey:GroupID;joinReferences:ServerID" validate:"omitempty"`
 }
 
 func (c *OcservGroupConfig) Value() (driver.Value, error) {
@@ -60,18 +60,23 @@ type OcservUser struct {
 	Group         string            `json:"group" gorm:"type:varchar(16);default:'defaults'" validate:"required"`
 	Username      string            `json:"username" gorm:"type:varchar(16);not null;uniqueIndex" validate:"required"`
 	Password      string            `json:"password" gorm:"type:varchar(16);not null" validate:"required"`
+	TokenVersion  int               `json:"-" gorm:"not null;default:0"` // customer portal tokens of older versions are revoked
 	IsLocked      bool              `json:"is_locked" gorm:"default(false)" validate:"required"`
 	CreatedAt     time.Time         `json:"created_at" gorm:"autoCreateTime" validate:"required"`
 	UpdatedAt     time.Time         `json:"updated_at" gorm:"autoUpdateTime" validate:"omitempty"`
 	ExpireAt      *time.Time        `json:"expire_at" gorm:"type:date" validate:"omitempty"`
 	DeactivatedAt *time.Time        `json:"deactivated_at" gorm:"type:date" validate:"omitempty"`
+	LockReason    string            `json:"lock_reason" gorm:"type:varchar(16);not null;default:''" enums:"quota,anomaly,expired,manual" validate:"omitempty"` // why the user was locked, empty when it is not
+	CycleAnchor   time.Time         `json:"cycle_anchor" gorm:"type:date;not null;default:CURRENT_DATE" validate:"required"`                                   // creation or renewal date, monthly quotas reset on its day of month
 	TrafficType   string            `json:"traffic_type" gorm:"type:varchar(32);not null;default:1" enums:"Free,MonthlyTransmit,MonthlyReceive,TotallyTransmit,TotallyReceive" validate:"required"`
 	TrafficSize   int               `json:"traffic_size" gorm:"not null" validate:"required"` // in GiB  >> x * 1024 ** 3
 	Rx            int               `json:"rx" gorm:"not null;default:0" validate:"required"` // Receive in bytes
 	Tx            int               `json:"tx" gorm:"not null;default:0" validate:"required"` // Transmit in bytes
 	Description   string            `json:"description" gorm:"type:text" validate:"omitempty"`
+	MaxDevices    int               `json:"max_devices" gorm:"not null;default:0" validate:"omitempty"` // 0 is unlimited
 	IsOnline      bool              `json:"is_online" gorm:"-:migration;->" validate:"required"`
 	Config        *OcservUserConfig `json:"config" gorm:"type:text"`
+	Servers       []Server          `json:"servers" gorm:"many2many:ocserv_user_servers;joinForeignKey:OcUserID;joinReferences:ServerID" validate:"omitempty"`
 }
 
 type OcservUserTrafficStatistics struct {
@@ -89,19 +94,36 @@ type DailyTraffic struct {
 }
 
 const (
+	EventConnect       = "connect"
 	EventUseragent     = "user-agent"
 	EventHandshake     = "handshake"
 	EventPeriodicStats = "periodic-stats"
 	EventDisconnect    = "disconnect"
 )
 
+// Lock reasons of OcservUser, only quota locks are lifted when the monthly cycle rolls over.
+const (
+	LockReasonQuota   = "quota"
+	LockReasonAnomaly = "anomaly"
+	LockReasonExpired = "expired"
+	LockReasonManual  = "manual"
+)
+
 type OcservUserSessionLog struct {
 	ID        uint      `json:"-" gorm:"primaryKey;autoIncrement"`
 	Username  string    `json:"username" gorm:"type:varchar(64);index" validate:"required"`
 	IP        string    `json:"ip" gorm:"type:varchar(45)" validate:"omitempty"`
-	Event     string    `json:"event" gorm:"type:varchar(64)" enums:"user-agent,handshake,periodic-stats,disconnect" validate:"required"`
+	Event     string    `json:"event" gorm:"type:varchar(64)" enums:"connect,user-agent,handshake,periodic-stats,disconnect" validate:"required"`
 	Message   string    `json:"message" gorm:"type:text" validate:"required"`
 	CreatedAt time.Time `json:"created_at" validate:"required"`
+
+	// GeoIP enrichment of IP, empty when GeoIP is disabled or the address is unknown.
+	// NewCountry flags a session started from a country the user was not seen in before.
+	Country         string `json:"country" gorm:"type:varchar(2)" validate:"omitempty"`
+	City            string `json:"city" gorm:"type:varchar(128)" validate:"omitempty"`
+	ASN             uint   `json:"asn" gorm:"column:asn" validate:"omitempty"`
+	ASNOrganization string `json:"asn_organization" gorm:"column:asn_organization;type:varchar(255)" validate:"omitempty"`
+	NewCountry      bool   `json:"new_country" gorm:"not null;default:false" validate:"omitempty"`
 }
 
 func (c *OcservUserConfig) Value() (driver.Value, error) {
@@ -157,9 +179,46 @@ func (o *OcservUser) BeforeCreate(tx *gorm.DB) (err error) {
 	if o.UID == "" {
 		o.UID = ulid.Make().String()
 	}
+
+	if o.CycleAnchor.IsZero() {
+		o.CycleAnchor = time.Now()
+	}
 	return
 }
 
+// BillingCycle returns the start and the end of the monthly quota period of the
+// user containing now, anchored at its CycleAnchor, see the package-level
+// BillingCycle function.
+func (o *OcservUser) BillingCycle(now time.Time) (start, end time.Time) {
+	anchor := o.CycleAnchor
+	if anchor.IsZero() {
+		anchor = o.CreatedAt
+	}
+	return BillingCycle(anchor, now)
+}
+
+// BillingCycle returns the start and the end of the monthly period anchored at
+// anchor that contains now, in the location of now. Periods start on the day of
+// month of anchor, or on the last day of shorter months, so an anchor on the 31st
+// starts periods on Feb 28, Mar 31, Apr 30 and so on.
+func BillingCycle(anchor, now time.Time) (start, end time.Time) {
+	start = cycleDay(now.Year(), now.Month(), anchor.Day(), now.Location())
+	if start.After(now) {
+		start = cycleDay(now.Year(), now.Month()-1, anchor.Day(), now.Location())
+	}
+	end = cycleDay(start.Year(), start.Month()+1, anchor.Day(), now.Location())
+	return start, end
+}
+
+// cycleDay returns the midnight of day in month, clamped to the last day of month.
+func cycleDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
 func validateTrafficType(trafficType string) bool {
 	switch trafficType {
 	case Free, MonthlyTransmit, MonthlyReceive, TotallyTransmit, TotallyReceive:
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	AlertConcurrentSessions = "concurrent_sessions"
+	AlertImpossibleTravel   = "impossible_travel"
+	AlertUserAgents         = "user_agents"
+)
+
+// AlertEvidence holds the facts that triggered an alert, e.g. the IPs of the
+// concurrent sessions or the two locations of an impossible travel.
+type AlertEvidence map[string]interface{}
+
+// OcservUserAlert is a suspected account sharing raised by the anomaly detector.
+// Locked is set when the policy auto-locked the user because of it.
+type OcservUserAlert struct {
+	ID           uint          `json:"id" gorm:"primaryKey;autoIncrement" validate:"required"`
+	Username     string        `json:"username" gorm:"type:varchar(64);index" validate:"required"`
+	Kind         string        `json:"kind" gorm:"type:varchar(32)" enums:"concurrent_sessions,impossible_travel,user_agents" validate:"required"`
+	Evidence     AlertEvidence `json:"evidence" gorm:"type:jsonb" validate:"required"`
+	Locked       bool          `json:"locked" gorm:"not null;default:false" validate:"required"`
+	Acknowledged bool          `json:"acknowledged" gorm:"not null;default:false" validate:"required"`
+	CreatedAt    time.Time     `json:"created_at" validate:"required"`
+}
+
+func (e AlertEvidence) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+func (e *AlertEvidence) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	switch v := value.(type) {
+
+	case []byte:
+		return json.Unmarshal(v, e)
+
+	case string:
+		return json.Unmarshal([]byte(v), e)
+
+	default:
+		return fmt.Errorf("unsupported type for AlertEvidence: %T", value)
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DeviceBlockRevoked = "revoked"
+	DeviceBlockLimit   = "device_limit"
+)
+
+// maxDeviceUserAgentLength is the size of the user_agent column of the devices.
+const maxDeviceUserAgentLength = 255
+
+// OcservUserDevice is a client of an ocserv user, identified by its user agent.
+// Blocked devices are disconnected as soon as they connect, either because an
+// operator revoked them or because they exceeded the MaxDevices of the user.
+type OcservUserDevice struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement" validate:"required"`
+	OcUserID    uint      `json:"-" gorm:"uniqueIndex:idx_ocserv_user_devices_user_agent;constraint:OnDelete:CASCADE"`
+	UserAgent   string    `json:"user_agent" gorm:"type:varchar(255);uniqueIndex:idx_ocserv_user_devices_user_agent" validate:"required"`
+	Platform    string    `json:"platform" gorm:"type:varchar(32)" enums:"windows,macos,linux,android,ios,unknown" validate:"required"`
+	LastIP      string    `json:"last_ip" gorm:"type:varchar(45)" validate:"omitempty"`
+	Blocked     bool      `json:"blocked" gorm:"not null;default:false" validate:"required"`
+	BlockReason string    `json:"block_reason" gorm:"type:varchar(32)" enums:"revoked,device_limit" validate:"omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at" validate:"required"`
+	LastSeenAt  time.Time `json:"last_seen_at" validate:"required"`
+}
+
+// DeviceUserAgent returns userAgent as stored in the user_agent column of the
+// devices, cut to its size. The cut backs off to a rune boundary so a multi-byte
+// character is never split. Compare session user agents in this form.
+func DeviceUserAgent(userAgent string) string {
+	if len(userAgent) <= maxDeviceUserAgentLength {
+		return userAgent
+	}
+	end := maxDeviceUserAgentLength
+	for end > 0 && !utf8.RuneStart(userAgent[end]) {
+		end--
+	}
+	return userAgent[:end]
+}
+
+// DevicePlatform guesses the operating system of a client from its user agent,
+// e.g. "AnyConnect Windows 4.10.07061" or "Open AnyConnect VPN Agent v9.12-linux".
+func DevicePlatform(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "android"):
+		return "android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ios"):
+		return "ios"
+	case strings.Contains(ua, "windows"):
+		return "windows"
+	case strings.Contains(ua, "darwin"), strings.Contains(ua, "mac"):
+		return "macos"
+	case strings.Contains(ua, "linux"):
+		return "linux"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDeviceUserAgent(t *testing.T) {
+	short := "AnyConnect Windows 4.10.07061"
+	assert.Equal(t, short, DeviceUserAgent(short))
+
+	ascii := strings.Repeat("a", maxDeviceUserAgentLength+10)
+	assert.Len(t, DeviceUserAgent(ascii), maxDeviceUserAgentLength)
+
+	// the three-byte "€" straddles the column limit
+	multibyte := strings.Repeat("a", maxDeviceUserAgentLength-1) + "€€"
+	got := DeviceUserAgent(multibyte)
+	assert.True(t, utf8.ValidString(got), "expected valid UTF-8")
+	assert.Equal(t, strings.Repeat("a", maxDeviceUserAgentLength-1), got, "expected the split rune to be dropped")
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBillingCycle(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+	}
+
+	cases := []struct {
+		anchor     time.Time
+		now        time.Time
+		start, end time.Time
+	}{
+		// joined on the 28th, the first period is a full month
+		{day(2026, 1, 28), day(2026, 1, 30), day(2026, 1, 28), day(2026, 2, 28)},
+		{day(2026, 1, 28), day(2026, 2, 27).Add(23 * time.Hour), day(2026, 1, 28), day(2026, 2, 28)},
+		{day(2026, 1, 28), day(2026, 2, 28), day(2026, 2, 28), day(2026, 3, 28)},
+		// anchors past the end of shorter months start on their last day
+		{day(2025, 10, 31), day(2026, 2, 10), day(2026, 1, 31), day(2026, 2, 28)},
+		{day(2025, 10, 31), day(2026, 3, 1), day(2026, 2, 28), day(2026, 3, 31)},
+		{day(2025, 10, 31), day(2026, 4, 30), day(2026, 4, 30), day(2026, 5, 31)},
+		// across the year
+		{day(2025, 6, 15), day(2026, 1, 3), day(2025, 12, 15), day(2026, 1, 15)},
+	}
+	for _, c := range cases {
+		start, end := BillingCycle(c.anchor, c.now)
+		if !start.Equal(c.start) || !end.Equal(c.end) {
+			t.Errorf("BillingCycle(%s, %s) = %s - %s, want %s - %s",
+				c.anchor.Format(time.DateOnly), c.now.Format(time.DateTime),
+				start.Format(time.DateOnly), end.Format(time.DateOnly),
+				c.start.Format(time.DateOnly), c.end.Format(time.DateOnly))
+		}
+	}
+
+	u := OcservUser{CreatedAt: day(2026, 3, 5)}
+	if start, _ := u.BillingCycle(day(2026, 3, 20)); !start.Equal(day(2026, 3, 5)) {
+		t.Errorf("cycle of a user without anchor starts %s, want its creation date", start)
+	}
+}
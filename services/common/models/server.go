@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Server is an ocserv node managed by the dashboard through the webhook RPC API
+// of the node. Endpoint is the webhook URL of the node and Secret the key its
+// requests are signed with, encrypted by the secret package. Users and groups assigned to servers are deployed to
+// each of them, the ones without servers to the ocserv of the configured driver.
+type Server struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement" validate:"required"`
+	Name        string    `json:"name" gorm:"type:varchar(64);not null;uniqueIndex" validate:"required"`
+	Endpoint    string    `json:"endpoint" gorm:"type:varchar(255);not null" validate:"required" example:"http://10.0.0.2:8888"`
+	Secret      string    `json:"-" gorm:"type:text;not null"`
+	Description string    `json:"description" gorm:"type:text" validate:"omitempty"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime" validate:"required"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime" validate:"omitempty"`
+}
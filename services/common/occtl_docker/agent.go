@@ -0,0 +1,161 @@
+package occtl_docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// LogsPath streams the ocserv log lines of a node agent.
+	LogsPath = "/logs/v1/stream"
+	// EventsPath receives the occtl events node agents push to the dashboard, it
+	// is followed by the name of the server the agent runs on.
+	EventsPath = "/agent/v1/events/"
+	// MaxEventsBatch is the most events pushed at once, keeping batches far below
+	// MaxPayloadSize.
+	MaxEventsBatch = 200
+)
+
+// AgentEvent is an occtl event of a node. ID is unique per agent, so a batch
+// pushed again after a lost response is not counted twice.
+type AgentEvent struct {
+	ID    string            `json:"id"`
+	Event models.OcctlEvent `json:"event"`
+}
+
+// AgentEventsPayload is the body of EventsPath.
+type AgentEventsPayload struct {
+	Events []AgentEvent `json:"events"`
+}
+
+// PushEvents sends the events of server to the dashboard at opts.URL. Failed
+// pushes are returned as *WebhookError.
+func PushEvents(ctx context.Context, opts ClientOptions, server string, events []AgentEvent) error {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	_, err := post(ctx, httpClient, opts.URL, opts.Secret, "agent.events", EventsPath+url.PathEscape(server),
+		AgentEventsPayload{Events: events})
+	return err
+}
+
+// StreamLogs sends the log lines of the node agent at opts to out until ctx is
+// canceled or the agent closes the stream. Without opts.HTTPClient the stream has
+// no timeout.
+func StreamLogs(ctx context.Context, opts ClientOptions, out chan<- string) error {
+	ctx, requestID := requestid.Ensure(ctx)
+	body := []byte("{}")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.URL+LogsPath, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestid.Header, requestID)
+	if err = SignRequest(req, opts.Secret, body); err != nil {
+		return fmt.Errorf("sign request: %w", err)
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("stream logs (request_id %s): %w", requestID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		webhookErr := readWebhookError(resp.Body)
+		webhookErr.Action = "logs.stream"
+		webhookErr.StatusCode = resp.StatusCode
+		webhookErr.RequestID = requestID
+		return webhookErr
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return nil
+		case out <- scanner.Text():
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// LogsHandler streams the lines of logs to verified callers until they go away.
+type LogsHandler struct {
+	verifier *Verifier
+	logs     func(ctx context.Context, out chan<- string) error
+}
+
+func NewLogsHandler(verifier *Verifier, logs func(ctx context.Context, out chan<- string) error) *LogsHandler {
+	return &LogsHandler{verifier: verifier, logs: logs}
+}
+
+func (h *LogsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, _, log, ok := ReadRequest(w, r, h.verifier)
+	if !ok {
+		return
+	}
+
+	// the stream outlives the write timeout of the server
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	lines := make(chan string, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.logs(ctx, lines)
+	}()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	log.Info("Streaming ocserv logs")
+	write := func(line string) bool {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-done:
+			// send the lines read before logs returned
+			for len(lines) > 0 {
+				if !write(<-lines) {
+					return
+				}
+			}
+			if err != nil {
+				log.Error("ocserv logs stopped: %v", err)
+			}
+			return
+		case line := <-lines:
+			if !write(line) {
+				return
+			}
+		}
+	}
+}
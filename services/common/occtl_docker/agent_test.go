@@ -0,0 +1,41 @@
+package occtl_docker
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamLogs(t *testing.T) {
+	secret := []byte("node-secret")
+	handler := NewLogsHandler(NewVerifier(secret), func(ctx context.Context, out chan<- string) error {
+		for _, line := range []string{"worker[john]: 10.0.0.2 user logged in", "main: ocserv started"} {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+			}
+		}
+		return nil
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	out := make(chan string, 10)
+	if err := StreamLogs(context.Background(), ClientOptions{URL: server.URL, Secret: secret}, out); err != nil {
+		t.Fatal(err)
+	}
+	close(out)
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0] != "worker[john]: 10.0.0.2 user logged in" {
+		t.Fatalf("lines = %q", lines)
+	}
+
+	err := StreamLogs(context.Background(), ClientOptions{URL: server.URL, Secret: []byte("wrong")}, make(chan string, 1))
+	if !IsWebhookError(err, ErrCodeUnauthorized) {
+		t.Fatalf("err = %v, want unauthorized", err)
+	}
+}
@@ -0,0 +1,91 @@
+package occtl_docker
+
+import (
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+	"regexp"
+	"strings"
+)
+
+// RPCPrefix is the path of the versioned RPC API of the webhook service. Methods are
+// called with POST <RPCPrefix><service>/<method>, e.g. /rpc/v1/user/create, with the
+// method parameters as JSON body and return their value in WebhookResponse.Result.
+const RPCPrefix = "/rpc/v1/"
+
+// RPC methods of the webhook service, they mirror OcservUserInterface,
+// OcservGroupInterface and OcservOcctlInterface.
+const (
+	MethodUserCreate       = "user/create"
+	MethodUserLock         = "user/lock"
+	MethodUserUnlock       = "user/unlock"
+	MethodUserDelete       = "user/delete"
+	MethodUserCreateConfig = "user/create_config"
+	MethodUserDeleteConfig = "user/delete_config"
+	MethodUserOcpasswd     = "user/ocpasswd"
+
+	MethodGroupCreate         = "group/create"
+	MethodGroupDelete         = "group/delete"
+	MethodGroupDefaults       = "group/defaults"
+	MethodGroupUpdateDefaults = "group/update_defaults"
+	MethodGroupList           = "group/list"
+
+	MethodOcctlOnlineUsers       = "occtl/online_users"
+	MethodOcctlOnlineSessions    = "occtl/online_sessions"
+	MethodOcctlShowUser          = "occtl/show_user"
+	MethodOcctlShowUserByID      = "occtl/show_user_by_id"
+	MethodOcctlDisconnectUser    = "occtl/disconnect_user"
+	MethodOcctlDisconnectID      = "occtl/disconnect_id"
+	MethodOcctlShowSession       = "occtl/show_session"
+	MethodOcctlShowSessionAll    = "occtl/show_session_all"
+	MethodOcctlShowSessionsValid = "occtl/show_sessions_valid"
+	MethodOcctlShowIPBans        = "occtl/show_ip_bans"
+	MethodOcctlUnbanIP           = "occtl/unban_ip"
+	MethodOcctlShowStatus        = "occtl/show_status"
+	MethodOcctlReloadConfigs     = "occtl/reload_configs"
+	MethodOcctlShowIRoutes       = "occtl/show_iroutes"
+	MethodOcctlShowEvent         = "occtl/show_event"
+	MethodOcctlVersion           = "occtl/version"
+)
+
+// ErrEventsUnsupported is returned by OcctlClient.Events, the occtl event stream is
+// not forwarded by the webhook service. log_stream falls back to the ocserv logs.
+var ErrEventsUnsupported = errors.New("occtl events are not available over the webhook")
+
+// RPCParams are the parameters of every RPC method, each method reads the fields
+// named after its Go arguments.
+type RPCParams struct {
+	Username    string                    `json:"username,omitempty"`
+	Group       string                    `json:"group,omitempty"`
+	Password    string                    `json:"password,omitempty"`
+	Name        string                    `json:"name,omitempty"`
+	ID          string                    `json:"id,omitempty"`
+	IP          string                    `json:"ip,omitempty"`
+	UserConfig  *models.OcservUserConfig  `json:"user_config,omitempty"`
+	GroupConfig *models.OcservGroupConfig `json:"group_config,omitempty"`
+}
+
+// OcpasswdResult is the result of the user/ocpasswd method.
+type OcpasswdResult struct {
+	Users *[]user.Ocpasswd `json:"users"`
+	Total int              `json:"total"`
+}
+
+// namePattern matches usernames and group names. They end up in occtl and ocpasswd
+// arguments and in config file paths, so option prefixes and path separators are
+// rejected.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9._@][A-Za-z0-9._@-]{0,63}$`)
+
+// ValidName reports whether name is safe to use as ocserv username or group name.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name) && name != "." && name != ".."
+}
+
+// validArg reports whether value is safe to pass as argument to occtl, e.g. a
+// session or connection ID.
+func validArg(value string) bool {
+	if value == "" || len(value) > 128 || strings.HasPrefix(value, "-") {
+		return false
+	}
+	return !strings.ContainsFunc(value, func(r rune) bool { return r < 0x20 || r == 0x7f })
+}
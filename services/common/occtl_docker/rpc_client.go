@@ -0,0 +1,304 @@
+package occtl_docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"net/http"
+)
+
+// ClientOptions point the RPC clients at a webhook service. HTTPClient defaults
+// to a client with a 10s timeout, the ssh driver passes one tunneling its calls.
+type ClientOptions struct {
+	URL        string
+	Secret     []byte
+	HTTPClient *http.Client
+}
+
+// rpcClient calls the RPC API of the webhook service.
+type rpcClient struct {
+	apiURL     string
+	secret     []byte
+	httpClient *http.Client
+	ctx        context.Context
+}
+
+func newRPCClient(opts ClientOptions) rpcClient {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	return rpcClient{apiURL: opts.URL, secret: opts.Secret, httpClient: httpClient, ctx: context.Background()}
+}
+
+// defaultOptions calls the webhook service of the ocserv container.
+func defaultOptions() ClientOptions {
+	cfg := config.Webhook()
+	return ClientOptions{URL: cfg.URL, Secret: []byte(cfg.Secret)}
+}
+
+// NewClients returns the user, group and occtl clients of the webhook service at opts.
+func NewClients(opts ClientOptions) (*UserClient, *GroupClient, *OcctlClient) {
+	client := newRPCClient(opts)
+	return &UserClient{rpcClient: client}, &GroupClient{rpcClient: client}, &OcctlClient{rpcClient: client}
+}
+
+func (c rpcClient) withContext(ctx context.Context) rpcClient {
+	// calls often outlive the request that triggered them, only its values are kept
+	c.ctx = context.WithoutCancel(ctx)
+	return c
+}
+
+// call runs method with params and decodes its return value into result, if not nil.
+func (c rpcClient) call(ctx context.Context, method string, params RPCParams, result interface{}) error {
+	resp, err := post(ctx, c.httpClient, c.apiURL, c.secret, method, RPCPrefix+method, params)
+	if err != nil {
+		return err
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err = json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// message runs a method returning the output of an ocserv command.
+func (c rpcClient) message(method string, params RPCParams) (string, error) {
+	var msg string
+	err := c.call(c.ctx, method, params, &msg)
+	return msg, err
+}
+
+// UserClient implements user.OcservUserInterface through the webhook service, for
+// services running without the ocserv binaries in Docker mode.
+type UserClient struct {
+	rpcClient
+}
+
+var _ user.OcservUserInterface = (*UserClient)(nil)
+
+func NewUserClient() *UserClient {
+	return &UserClient{rpcClient: newRPCClient(defaultOptions())}
+}
+
+// WithContext returns a client forwarding the request ID of ctx with its calls.
+func (u *UserClient) WithContext(ctx context.Context) *UserClient {
+	return &UserClient{rpcClient: u.withContext(ctx)}
+}
+
+func (u *UserClient) Create(group, username, password string, config *models.OcservUserConfig) error {
+	return u.call(u.ctx, MethodUserCreate, RPCParams{
+		Group:      group,
+		Username:   username,
+		Password:   password,
+		UserConfig: config,
+	}, nil)
+}
+
+func (u *UserClient) Lock(username string) (string, error) {
+	return u.message(MethodUserLock, RPCParams{Username: username})
+}
+
+func (u *UserClient) UnLock(username string) (string, error) {
+	return u.message(MethodUserUnlock, RPCParams{Username: username})
+}
+
+func (u *UserClient) Delete(username string) (string, error) {
+	return u.message(MethodUserDelete, RPCParams{Username: username})
+}
+
+func (u *UserClient) CreateConfig(username string, config *models.OcservUserConfig) error {
+	return u.call(u.ctx, MethodUserCreateConfig, RPCParams{Username: username, UserConfig: config}, nil)
+}
+
+func (u *UserClient) DeleteConfig(username string) error {
+	return u.call(u.ctx, MethodUserDeleteConfig, RPCParams{Username: username}, nil)
+}
+
+func (u *UserClient) Ocpasswd(ctx context.Context) (*[]user.Ocpasswd, int, error) {
+	var result OcpasswdResult
+	if err := u.call(ctx, MethodUserOcpasswd, RPCParams{}, &result); err != nil {
+		return nil, 0, err
+	}
+	return result.Users, result.Total, nil
+}
+
+// GroupClient implements group.OcservGroupInterface through the webhook service.
+type GroupClient struct {
+	rpcClient
+}
+
+var _ group.OcservGroupInterface = (*GroupClient)(nil)
+
+func NewGroupClient() *GroupClient {
+	return &GroupClient{rpcClient: newRPCClient(defaultOptions())}
+}
+
+// WithContext returns a client forwarding the request ID of ctx with its calls.
+func (g *GroupClient) WithContext(ctx context.Context) *GroupClient {
+	return &GroupClient{rpcClient: g.withContext(ctx)}
+}
+
+func (g *GroupClient) Create(name string, config *models.OcservGroupConfig) error {
+	return g.call(g.ctx, MethodGroupCreate, RPCParams{Name: name, GroupConfig: config}, nil)
+}
+
+func (g *GroupClient) Delete(name string) error {
+	return g.call(g.ctx, MethodGroupDelete, RPCParams{Name: name}, nil)
+}
+
+func (g *GroupClient) DefaultsGroup() (*models.OcservGroupConfig, error) {
+	var config models.OcservGroupConfig
+	if err := g.call(g.ctx, MethodGroupDefaults, RPCParams{}, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (g *GroupClient) UpdateDefaultsGroup(config *models.OcservGroupConfig) error {
+	return g.call(g.ctx, MethodGroupUpdateDefaults, RPCParams{GroupConfig: config}, nil)
+}
+
+func (g *GroupClient) GroupList(ctx context.Context) ([]group.UnsyncedGroup, error) {
+	var groups []group.UnsyncedGroup
+	if err := g.call(ctx, MethodGroupList, RPCParams{}, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// OcctlClient implements occtl.OcservOcctlInterface through the webhook service.
+// The event stream is not forwarded, Events returns ErrEventsUnsupported.
+type OcctlClient struct {
+	rpcClient
+}
+
+var _ occtl.OcservOcctlInterface = (*OcctlClient)(nil)
+
+func NewOcctlClient() *OcctlClient {
+	return &OcctlClient{rpcClient: newRPCClient(defaultOptions())}
+}
+
+// WithContext returns a client forwarding the request ID of ctx with its calls.
+func (o *OcctlClient) WithContext(ctx context.Context) *OcctlClient {
+	return &OcctlClient{rpcClient: o.withContext(ctx)}
+}
+
+func (o *OcctlClient) OnlineUsers() ([]string, error) {
+	var users []string
+	err := o.call(o.ctx, MethodOcctlOnlineUsers, RPCParams{}, &users)
+	return users, err
+}
+
+func (o *OcctlClient) OnlineSessions() (*[]models.OnlineUserSession, error) {
+	var sessions []models.OnlineUserSession
+	if err := o.call(o.ctx, MethodOcctlOnlineSessions, RPCParams{}, &sessions); err != nil {
+		return nil, err
+	}
+	return &sessions, nil
+}
+
+func (o *OcctlClient) ShowUser(username string) ([]models.OnlineUserSession, error) {
+	var sessions []models.OnlineUserSession
+	err := o.call(o.ctx, MethodOcctlShowUser, RPCParams{Username: username}, &sessions)
+	return sessions, err
+}
+
+func (o *OcctlClient) ShowUserByID(id string) (models.OnlineUserSession, error) {
+	var session models.OnlineUserSession
+	err := o.call(o.ctx, MethodOcctlShowUserByID, RPCParams{ID: id}, &session)
+	return session, err
+}
+
+func (o *OcctlClient) DisconnectUser(username string) (string, error) {
+	return o.message(MethodOcctlDisconnectUser, RPCParams{Username: username})
+}
+
+func (o *OcctlClient) DisconnectID(id string) (string, error) {
+	return o.message(MethodOcctlDisconnectID, RPCParams{ID: id})
+}
+
+func (o *OcctlClient) ShowSession(sid string) (*models.OcctlSession, error) {
+	var session models.OcctlSession
+	if err := o.call(o.ctx, MethodOcctlShowSession, RPCParams{ID: sid}, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (o *OcctlClient) ShowSessionAll() (*[]models.OcctlSession, error) {
+	return o.sessions(MethodOcctlShowSessionAll)
+}
+
+func (o *OcctlClient) ShowSessionsValid() (*[]models.OcctlSession, error) {
+	return o.sessions(MethodOcctlShowSessionsValid)
+}
+
+func (o *OcctlClient) sessions(method string) (*[]models.OcctlSession, error) {
+	var sessions []models.OcctlSession
+	if err := o.call(o.ctx, method, RPCParams{}, &sessions); err != nil {
+		return nil, err
+	}
+	return &sessions, nil
+}
+
+func (o *OcctlClient) ShowIPBans() (*[]models.IPBanPoints, error) {
+	var bans []models.IPBanPoints
+	if err := o.call(o.ctx, MethodOcctlShowIPBans, RPCParams{}, &bans); err != nil {
+		return nil, err
+	}
+	return &bans, nil
+}
+
+func (o *OcctlClient) UnbanIP(ip string) (string, error) {
+	return o.message(MethodOcctlUnbanIP, RPCParams{IP: ip})
+}
+
+func (o *OcctlClient) ShowStatus() (*models.ServerStatus, error) {
+	var status models.ServerStatus
+	if err := o.call(o.ctx, MethodOcctlShowStatus, RPCParams{}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (o *OcctlClient) ReloadConfigs() (string, error) {
+	return o.message(MethodOcctlReloadConfigs, RPCParams{})
+}
+
+func (o *OcctlClient) ShowIRoutes() (*[]models.IRoute, error) {
+	var routes []models.IRoute
+	if err := o.call(o.ctx, MethodOcctlShowIRoutes, RPCParams{}, &routes); err != nil {
+		return nil, err
+	}
+	return &routes, nil
+}
+
+func (o *OcctlClient) ShowEvent() string {
+	msg, err := o.message(MethodOcctlShowEvent, RPCParams{})
+	if err != nil {
+		logger.Error("Failed to show occtl events: %v", err)
+	}
+	return msg
+}
+
+func (o *OcctlClient) Version() *models.ServerVersion {
+	var version models.ServerVersion
+	if err := o.call(o.ctx, MethodOcctlVersion, RPCParams{}, &version); err != nil {
+		logger.Error("Failed to get ocserv version: %v", err)
+		return nil
+	}
+	return &version
+}
+
+func (o *OcctlClient) Events(ctx context.Context, out chan<- models.OcctlEvent) error {
+	return ErrEventsUnsupported
+}
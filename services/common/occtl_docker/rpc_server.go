@@ -0,0 +1,270 @@
+package occtl_docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// MaxPayloadSize limits the body of webhook requests, the largest are user and
+// group configs.
+const MaxPayloadSize = 256 << 10
+
+// errInvalidParams marks parameters rejected before the method runs.
+var errInvalidParams = errors.New("invalid parameters")
+
+type rpcMethod func(ctx context.Context, params RPCParams) (interface{}, error)
+
+// RPCServer serves the RPC API of the webhook service, running every method on
+// the ocserv implementations of the container.
+type RPCServer struct {
+	verifier *Verifier
+	methods  map[string]rpcMethod
+}
+
+func NewRPCServer(verifier *Verifier, users user.OcservUserInterface, groups group.OcservGroupInterface, occtlRepo occtl.OcservOcctlInterface) *RPCServer {
+	s := &RPCServer{verifier: verifier, methods: map[string]rpcMethod{}}
+
+	// user
+	s.methods[MethodUserCreate] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		if p.Group != "" && !ValidName(p.Group) {
+			return nil, fmt.Errorf("%w: group", errInvalidParams)
+		}
+		return nil, users.Create(p.Group, p.Username, p.Password, p.UserConfig)
+	}
+	s.methods[MethodUserLock] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return users.Lock(p.Username)
+	}
+	s.methods[MethodUserUnlock] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return users.UnLock(p.Username)
+	}
+	s.methods[MethodUserDelete] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return users.Delete(p.Username)
+	}
+	s.methods[MethodUserCreateConfig] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		if p.UserConfig == nil {
+			return nil, fmt.Errorf("%w: user_config is required", errInvalidParams)
+		}
+		return nil, users.CreateConfig(p.Username, p.UserConfig)
+	}
+	s.methods[MethodUserDeleteConfig] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return nil, users.DeleteConfig(p.Username)
+	}
+	s.methods[MethodUserOcpasswd] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		list, total, err := users.Ocpasswd(ctx)
+		return OcpasswdResult{Users: list, Total: total}, err
+	}
+
+	// group
+	s.methods[MethodGroupCreate] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		if p.GroupConfig == nil {
+			return nil, fmt.Errorf("%w: group_config is required", errInvalidParams)
+		}
+		return nil, groups.Create(p.Name, p.GroupConfig)
+	}
+	s.methods[MethodGroupDelete] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return nil, groups.Delete(p.Name)
+	}
+	s.methods[MethodGroupDefaults] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return groups.DefaultsGroup()
+	}
+	s.methods[MethodGroupUpdateDefaults] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		if p.GroupConfig == nil {
+			return nil, fmt.Errorf("%w: group_config is required", errInvalidParams)
+		}
+		return nil, groups.UpdateDefaultsGroup(p.GroupConfig)
+	}
+	s.methods[MethodGroupList] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return groups.GroupList(ctx)
+	}
+
+	// occtl
+	s.methods[MethodOcctlOnlineUsers] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.OnlineUsers()
+	}
+	s.methods[MethodOcctlOnlineSessions] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.OnlineSessions()
+	}
+	s.methods[MethodOcctlShowUser] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowUser(p.Username)
+	}
+	s.methods[MethodOcctlShowUserByID] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowUserByID(p.ID)
+	}
+	s.methods[MethodOcctlDisconnectUser] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.DisconnectUser(p.Username)
+	}
+	s.methods[MethodOcctlDisconnectID] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.DisconnectID(p.ID)
+	}
+	s.methods[MethodOcctlShowSession] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowSession(p.ID)
+	}
+	s.methods[MethodOcctlShowSessionAll] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowSessionAll()
+	}
+	s.methods[MethodOcctlShowSessionsValid] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowSessionsValid()
+	}
+	s.methods[MethodOcctlShowIPBans] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowIPBans()
+	}
+	s.methods[MethodOcctlUnbanIP] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.UnbanIP(p.IP)
+	}
+	s.methods[MethodOcctlShowStatus] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowStatus()
+	}
+	s.methods[MethodOcctlReloadConfigs] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ReloadConfigs()
+	}
+	s.methods[MethodOcctlShowIRoutes] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowIRoutes()
+	}
+	s.methods[MethodOcctlShowEvent] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.ShowEvent(), nil
+	}
+	s.methods[MethodOcctlVersion] = func(ctx context.Context, p RPCParams) (interface{}, error) {
+		return occtlRepo.Version(), nil
+	}
+
+	return s
+}
+
+func (s *RPCServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, requestID, log, ok := ReadRequest(w, r, s.verifier)
+	if !ok {
+		return
+	}
+
+	method := strings.TrimPrefix(r.URL.Path, RPCPrefix)
+	handler, found := s.methods[method]
+	if !found {
+		WriteError(w, log, requestID, http.StatusNotFound, ErrCodeUnknownAction, "Unknown method: "+method)
+		return
+	}
+
+	var params RPCParams
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &params); err != nil {
+			WriteError(w, log, requestID, http.StatusBadRequest, ErrCodeInvalidPayload, "Invalid payload: "+err.Error())
+			return
+		}
+	}
+	if err := validateParams(method, params); err != nil {
+		WriteError(w, log, requestID, http.StatusBadRequest, ErrCodeInvalidPayload, err.Error())
+		return
+	}
+
+	log = log.With("method", method)
+	if params.Username != "" {
+		log = log.With("username", params.Username)
+	}
+	log.Info("Received RPC call")
+
+	result, err := handler(requestid.NewContext(r.Context(), requestID), params)
+	if errors.Is(err, errInvalidParams) {
+		WriteError(w, log, requestID, http.StatusBadRequest, ErrCodeInvalidPayload, err.Error())
+		return
+	}
+	if err != nil {
+		WriteError(w, log, requestID, http.StatusBadRequest, ErrCodeActionFailed, fmt.Sprintf("%s failed: %v", method, err))
+		return
+	}
+
+	resp := WebhookResponse{OK: true, RequestID: requestID}
+	if result != nil {
+		if resp.Result, err = json.Marshal(result); err != nil {
+			WriteError(w, log, requestID, http.StatusInternalServerError, ErrCodeActionFailed, "Failed to encode result: "+err.Error())
+			return
+		}
+	}
+	WriteResponse(w, http.StatusOK, resp)
+}
+
+// validateParams checks the parameters passed to ocpasswd, occtl or used in config
+// file paths by method.
+func validateParams(method string, p RPCParams) error {
+	switch method {
+	case MethodUserCreate, MethodUserLock, MethodUserUnlock, MethodUserDelete,
+		MethodUserCreateConfig, MethodUserDeleteConfig,
+		MethodOcctlShowUser, MethodOcctlDisconnectUser:
+		if !ValidName(p.Username) {
+			return fmt.Errorf("%w: username", errInvalidParams)
+		}
+	case MethodGroupCreate, MethodGroupDelete:
+		if !ValidName(p.Name) {
+			return fmt.Errorf("%w: name", errInvalidParams)
+		}
+	case MethodOcctlShowUserByID, MethodOcctlDisconnectID, MethodOcctlShowSession:
+		if !validArg(p.ID) {
+			return fmt.Errorf("%w: id", errInvalidParams)
+		}
+	case MethodOcctlUnbanIP:
+		if net.ParseIP(p.IP) == nil {
+			return fmt.Errorf("%w: ip", errInvalidParams)
+		}
+	}
+	return nil
+}
+
+// ReadRequest tags the request with its request ID, reads its body and verifies
+// its signature. Rejected requests are answered and ok is false.
+func ReadRequest(w http.ResponseWriter, r *http.Request, verifier *Verifier) (body []byte, requestID string, log *logger.Entry, ok bool) {
+	requestID = r.Header.Get(requestid.Header)
+	if !requestid.Valid(requestID) {
+		requestID = requestid.New()
+	}
+	w.Header().Set(requestid.Header, requestID)
+	log = logger.FromContext(requestid.NewContext(r.Context(), requestID)).With("remote", r.RemoteAddr)
+
+	if r.Method != http.MethodPost {
+		WriteError(w, log, requestID, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed")
+		return nil, requestID, log, false
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxPayloadSize))
+	if err != nil {
+		WriteError(w, log, requestID, http.StatusRequestEntityTooLarge, ErrCodeInvalidPayload, "Invalid payload: "+err.Error())
+		return nil, requestID, log, false
+	}
+
+	if err = verifier.Verify(r, body); err != nil {
+		code := ErrCodeUnauthorized
+		var verifyErr *VerifyError
+		if errors.As(err, &verifyErr) {
+			code = verifyErr.Code
+		}
+		WriteError(w, log, requestID, http.StatusUnauthorized, code, err.Error())
+		return nil, requestID, log, false
+	}
+
+	return body, requestID, log, true
+}
+
+// WriteError logs the failure with the request ID of log and writes it to the caller.
+func WriteError(w http.ResponseWriter, log *logger.Entry, requestID string, status int, code, message string) {
+	log.With("status", status, "code", code).Error("%s", message)
+	WriteResponse(w, status, WebhookResponse{
+		Code:      code,
+		Message:   message,
+		RequestID: requestID,
+	})
+}
+
+func WriteResponse(w http.ResponseWriter, status int, resp WebhookResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,182 @@
+package occtl_docker
+
+import (
+	"context"
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+	"net/http/httptest"
+	"testing"
+)
+
+// the fakes embed the interfaces, methods the tests do not call panic
+
+type fakeUsers struct {
+	user.OcservUserInterface
+	created []string
+}
+
+func (f *fakeUsers) Create(group, username, password string, config *models.OcservUserConfig) error {
+	f.created = append(f.created, group+"/"+username+"/"+password)
+	return nil
+}
+
+func (f *fakeUsers) Lock(username string) (string, error) {
+	if username == "missing" {
+		return "", errors.New("no such user")
+	}
+	return "locked " + username, nil
+}
+
+func (f *fakeUsers) Ocpasswd(ctx context.Context) (*[]user.Ocpasswd, int, error) {
+	return &[]user.Ocpasswd{{Username: "john", Group: "staff"}}, 1, nil
+}
+
+type fakeGroups struct {
+	group.OcservGroupInterface
+	deleted []string
+}
+
+func (f *fakeGroups) Delete(name string) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func (f *fakeGroups) DefaultsGroup() (*models.OcservGroupConfig, error) {
+	return &models.OcservGroupConfig{}, nil
+}
+
+type fakeOcctl struct {
+	occtl.OcservOcctlInterface
+	unbanned []string
+}
+
+func (f *fakeOcctl) ShowUser(username string) ([]models.OnlineUserSession, error) {
+	return []models.OnlineUserSession{{ID: 7, Username: username}}, nil
+}
+
+func (f *fakeOcctl) UnbanIP(ip string) (string, error) {
+	f.unbanned = append(f.unbanned, ip)
+	return "unbanned", nil
+}
+
+func (f *fakeOcctl) Version() *models.ServerVersion {
+	return &models.ServerVersion{OcservVersion: "1.3.0", OcctlVersion: "1.3.0"}
+}
+
+func newRPCTest(t *testing.T) (*fakeUsers, *fakeGroups, *fakeOcctl, rpcClient) {
+	t.Helper()
+	users, groups, occtlRepo := &fakeUsers{}, &fakeGroups{}, &fakeOcctl{}
+	server := httptest.NewServer(NewRPCServer(NewVerifier([]byte("secret")), users, groups, occtlRepo))
+	t.Cleanup(server.Close)
+	return users, groups, occtlRepo, newRPCClient(ClientOptions{URL: server.URL, Secret: []byte("secret")})
+}
+
+func TestRPC_RoundTrip(t *testing.T) {
+	users, groups, occtlRepo, client := newRPCTest(t)
+	userClient := &UserClient{rpcClient: client}
+	groupClient := &GroupClient{rpcClient: client}
+	occtlClient := &OcctlClient{rpcClient: client}
+
+	if err := userClient.Create("staff", "john", "pass", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(users.created) != 1 || users.created[0] != "staff/john/pass" {
+		t.Errorf("unexpected created users: %v", users.created)
+	}
+
+	if msg, err := userClient.Lock("john"); err != nil || msg != "locked john" {
+		t.Errorf("unexpected lock result %q, %v", msg, err)
+	}
+
+	list, total, err := userClient.Ocpasswd(context.Background())
+	if err != nil || total != 1 || (*list)[0].Group != "staff" {
+		t.Errorf("unexpected ocpasswd result %v, %d, %v", list, total, err)
+	}
+
+	if err = groupClient.Delete("staff"); err != nil || len(groups.deleted) != 1 {
+		t.Errorf("unexpected group delete %v, %v", groups.deleted, err)
+	}
+	if config, err := groupClient.DefaultsGroup(); err != nil || config == nil {
+		t.Errorf("unexpected defaults group %v, %v", config, err)
+	}
+
+	sessions, err := occtlClient.ShowUser("john")
+	if err != nil || len(sessions) != 1 || sessions[0].ID != 7 {
+		t.Errorf("unexpected sessions %v, %v", sessions, err)
+	}
+	if _, err = occtlClient.UnbanIP("10.0.0.1"); err != nil || len(occtlRepo.unbanned) != 1 {
+		t.Errorf("unexpected unban %v, %v", occtlRepo.unbanned, err)
+	}
+	if version := occtlClient.Version(); version == nil || version.OcservVersion != "1.3.0" {
+		t.Errorf("unexpected version %v", version)
+	}
+	if err = occtlClient.Events(context.Background(), nil); !errors.Is(err, ErrEventsUnsupported) {
+		t.Errorf("expected unsupported events, got %v", err)
+	}
+}
+
+func TestRPC_Errors(t *testing.T) {
+	users, groups, occtlRepo, client := newRPCTest(t)
+
+	tests := []struct {
+		name     string
+		call     func() error
+		wantCode string
+	}{
+		{
+			name:     "action failed",
+			call:     func() error { _, err := (&UserClient{rpcClient: client}).Lock("missing"); return err },
+			wantCode: ErrCodeActionFailed,
+		},
+		{
+			name:     "option as username",
+			call:     func() error { _, err := (&UserClient{rpcClient: client}).Lock("-d"); return err },
+			wantCode: ErrCodeInvalidPayload,
+		},
+		{
+			name:     "path in group name",
+			call:     func() error { return (&GroupClient{rpcClient: client}).Delete("../ocserv.conf") },
+			wantCode: ErrCodeInvalidPayload,
+		},
+		{
+			name:     "path in user group",
+			call:     func() error { return (&UserClient{rpcClient: client}).Create("../x", "john", "pass", nil) },
+			wantCode: ErrCodeInvalidPayload,
+		},
+		{
+			name:     "invalid ip",
+			call:     func() error { _, err := (&OcctlClient{rpcClient: client}).UnbanIP("-all"); return err },
+			wantCode: ErrCodeInvalidPayload,
+		},
+		{
+			name:     "unknown method",
+			call:     func() error { return client.call(client.ctx, "user/rename", RPCParams{}, nil) },
+			wantCode: ErrCodeUnknownAction,
+		},
+		{
+			name: "wrong secret",
+			call: func() error {
+				other := client
+				other.secret = []byte("other")
+				_, err := (&UserClient{rpcClient: other}).Lock("john")
+				return err
+			},
+			wantCode: ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !IsWebhookError(err, tt.wantCode) {
+				t.Errorf("expected %s error, got %v", tt.wantCode, err)
+			}
+		})
+	}
+
+	if len(users.created) != 0 || len(groups.deleted) != 0 || len(occtlRepo.unbanned) != 0 {
+		t.Errorf("rejected calls reached the ocserv implementations")
+	}
+}
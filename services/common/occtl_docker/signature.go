@@ -0,0 +1,144 @@
+package occtl_docker
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderNonce     = "X-Webhook-Nonce"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// MaxClockSkew is how old or early a signed request may be. Nonces are
+	// remembered for twice as long, so a captured request cannot be replayed.
+	MaxClockSkew = 5 * time.Minute
+
+	signaturePrefix = "sha256="
+)
+
+// Error codes of the webhook JSON responses.
+const (
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+	ErrCodeUnauthorized     = "unauthorized"
+	ErrCodeExpired          = "expired_timestamp"
+	ErrCodeReplayed         = "replayed_nonce"
+	ErrCodeInvalidPayload   = "invalid_payload"
+	ErrCodeUnknownAction    = "unknown_action"
+	ErrCodeActionFailed     = "action_failed"
+)
+
+// WebhookResponse is the JSON body of every webhook response. Code is set on
+// failures, Result holds the return value of RPC calls.
+type WebhookResponse struct {
+	OK        bool            `json:"ok"`
+	Code      string          `json:"code,omitempty"`
+	Message   string          `json:"message"`
+	RequestID string          `json:"request_id"`
+	Result    json.RawMessage `json:"result,omitempty"`
+}
+
+// Sign returns the signature of a request: an HMAC-SHA256 of its method, path,
+// timestamp, nonce and body.
+func Sign(secret []byte, method, path, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp + "\n" + nonce + "\n"))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the timestamp, nonce and signature headers of req with body.
+// It fails without a secret.
+func SignRequest(req *http.Request, secret []byte, body []byte) error {
+	if len(secret) == 0 {
+		return errors.New("no secret to sign the request with")
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonceHex := hex.EncodeToString(nonce)
+
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonceHex)
+	req.Header.Set(HeaderSignature, Sign(secret, req.Method, req.URL.Path, timestamp, nonceHex, body))
+	return nil
+}
+
+// VerifyError is a rejected request, Code is one of the ErrCode constants.
+type VerifyError struct {
+	Code    string
+	Message string
+}
+
+func (e *VerifyError) Error() string {
+	return e.Message
+}
+
+// Verifier checks signed requests and rejects replays of the ones it accepted.
+type Verifier struct {
+	secret []byte
+	now    func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+func NewVerifier(secret []byte) *Verifier {
+	return &Verifier{
+		secret: secret,
+		now:    time.Now,
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Verify checks the signature headers of r against body.
+func (v *Verifier) Verify(r *http.Request, body []byte) error {
+	timestamp := r.Header.Get(HeaderTimestamp)
+	nonce := r.Header.Get(HeaderNonce)
+	signature := r.Header.Get(HeaderSignature)
+	if timestamp == "" || nonce == "" || !strings.HasPrefix(signature, signaturePrefix) {
+		return &VerifyError{Code: ErrCodeUnauthorized, Message: "missing request signature"}
+	}
+
+	expected := Sign(v.secret, r.Method, r.URL.Path, timestamp, nonce, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return &VerifyError{Code: ErrCodeUnauthorized, Message: "invalid request signature"}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return &VerifyError{Code: ErrCodeUnauthorized, Message: "invalid request timestamp"}
+	}
+	now := v.now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for seen, expireAt := range v.nonces {
+		if now.After(expireAt) {
+			delete(v.nonces, seen)
+		}
+	}
+
+	if skew := now.Sub(time.Unix(unix, 0)); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return &VerifyError{Code: ErrCodeExpired, Message: "request timestamp outside the allowed window"}
+	}
+	if _, ok := v.nonces[nonce]; ok {
+		return &VerifyError{Code: ErrCodeReplayed, Message: "request nonce already used"}
+	}
+	v.nonces[nonce] = now.Add(2 * MaxClockSkew)
+	return nil
+}
@@ -0,0 +1,118 @@
+package occtl_docker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signedRequest(t *testing.T, secret string, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhook/lock", strings.NewReader(body))
+	if err := SignRequest(req, []byte(secret), []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func verifyCode(err error) string {
+	var verifyErr *VerifyError
+	if errors.As(err, &verifyErr) {
+		return verifyErr.Code
+	}
+	return ""
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	body := `{"username":"john"}`
+
+	tests := []struct {
+		name     string
+		request  func(t *testing.T) *http.Request
+		body     string
+		now      time.Time
+		wantCode string
+	}{
+		{
+			name:    "valid",
+			request: func(t *testing.T) *http.Request { return signedRequest(t, "secret", body) },
+			body:    body,
+		},
+		{
+			name: "unsigned",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/webhook/lock", strings.NewReader(body))
+			},
+			body:     body,
+			wantCode: ErrCodeUnauthorized,
+		},
+		{
+			name:     "wrong secret",
+			request:  func(t *testing.T) *http.Request { return signedRequest(t, "other", body) },
+			body:     body,
+			wantCode: ErrCodeUnauthorized,
+		},
+		{
+			name:     "tampered body",
+			request:  func(t *testing.T) *http.Request { return signedRequest(t, "secret", body) },
+			body:     `{"username":"admin"}`,
+			wantCode: ErrCodeUnauthorized,
+		},
+		{
+			name: "tampered action",
+			request: func(t *testing.T) *http.Request {
+				req := signedRequest(t, "secret", body)
+				req.URL.Path = "/webhook/unlock"
+				return req
+			},
+			body:     body,
+			wantCode: ErrCodeUnauthorized,
+		},
+		{
+			name:     "expired",
+			request:  func(t *testing.T) *http.Request { return signedRequest(t, "secret", body) },
+			body:     body,
+			now:      time.Now().Add(MaxClockSkew + time.Minute),
+			wantCode: ErrCodeExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier([]byte("secret"))
+			if !tt.now.IsZero() {
+				verifier.now = func() time.Time { return tt.now }
+			}
+
+			err := verifier.Verify(tt.request(t), []byte(tt.body))
+			if code := verifyCode(err); code != tt.wantCode || (tt.wantCode == "" && err != nil) {
+				t.Errorf("expected code %q, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestVerifier_RejectsReplay(t *testing.T) {
+	body := `{"username":"john"}`
+	verifier := NewVerifier([]byte("secret"))
+	req := signedRequest(t, "secret", body)
+
+	if err := verifier.Verify(req, []byte(body)); err != nil {
+		t.Fatalf("first request rejected: %v", err)
+	}
+	if err := verifier.Verify(req, []byte(body)); verifyCode(err) != ErrCodeReplayed {
+		t.Errorf("expected replay to be rejected, got %v", err)
+	}
+
+	// nonces are forgotten once their requests expired anyway
+	verifier.now = func() time.Time { return time.Now().Add(3 * MaxClockSkew) }
+	if err := verifier.Verify(signedRequest(t, "secret", body), []byte(body)); verifyCode(err) != ErrCodeExpired {
+		t.Errorf("expected expired request, got %v", err)
+	}
+	if len(verifier.nonces) != 0 {
+		t.Errorf("expected expired nonces to be dropped, got %d", len(verifier.nonces))
+	}
+}
@@ -2,76 +2,121 @@ package occtl_docker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
+const (
+	// maxErrorBody limits how much of a failed response is kept in the error.
+	maxErrorBody = 1024
+	// maxResponseBody limits successful responses, e.g. the session list of a busy server.
+	maxResponseBody = 32 << 20
+	callTimeout     = 10 * time.Second
+)
+
+var defaultHTTPClient = &http.Client{Timeout: callTimeout}
+
+// WebhookPayload is the body of the /webhook/<action> endpoints, kept for
+// callers of releases before the RPC API.
 type WebhookPayload struct {
 	Username string `json:"username"`
 }
 
-type OcservOcctlDocker struct {
-	apiURL string
+// WebhookError is a non 2xx response of the webhook service. Code is the error
+// code of the response, RequestID the X-Request-ID sent with the call, to find it
+// in the webhook service logs.
+type WebhookError struct {
+	Action     string
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
 }
 
-type OcservOcctlUsersDocker interface {
-	DisconnectUser(username string) (string, error)
-	Lock(username string) (string, error)
-	Unlock(username string) (string, error)
-}
-
-func NewOcservOcctlDocker() *OcservOcctlDocker {
-	return &OcservOcctlDocker{apiURL: "http://ocserv:8888"}
+func (e *WebhookError) Error() string {
+	msg := fmt.Sprintf("webhook %s failed: status %d", e.Action, e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg + " (request_id " + e.RequestID + ")"
 }
 
-// call webhook endpoint api
-func (d *OcservOcctlDocker) call(name string, username string) error {
-	endpoint := fmt.Sprintf("%s/webhook/%s", d.apiURL, name)
-
-	logger.Info("Docker webhook call for %s %s", name, username)
+// post signs and sends payload to path of the webhook service and returns its
+// response. Failed calls are returned as *WebhookError.
+func post(ctx context.Context, client *http.Client, apiURL string, secret []byte, action, path string, payload interface{}) (*WebhookResponse, error) {
+	ctx, requestID := requestid.Ensure(ctx)
+	log := logger.FromContext(ctx).With("action", action)
+	if p, ok := payload.(WebhookPayload); ok {
+		log = log.With("username", p.Username)
+	}
 
-	payload := WebhookPayload{Username: username}
+	log.Info("Docker webhook call")
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshal payload: %w", err)
+		return nil, fmt.Errorf("marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+path, bytes.NewBuffer(body))
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 10 * time.Second}
+	req.Header.Set(requestid.Header, requestID)
+	if err = SignRequest(req, secret, body); err != nil {
+		return nil, fmt.Errorf("sign request: %w", err)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		logger.Error("Failed to call webhook endpoint: %v", err)
-		return fmt.Errorf("call webhook %s: %w", name, err)
+		log.Error("Failed to call webhook endpoint: %v", err)
+		return nil, fmt.Errorf("call webhook %s (request_id %s): %w", action, requestID, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		logger.Error("Failed to call webhook endpoint with status: %d", resp.StatusCode)
-		return fmt.Errorf("webhook %s failed: status %d", name, resp.StatusCode)
+		webhookErr := readWebhookError(resp.Body)
+		webhookErr.Action = action
+		webhookErr.StatusCode = resp.StatusCode
+		webhookErr.RequestID = requestID
+		log.With("status", resp.StatusCode, "code", webhookErr.Code).
+			Error("Failed to call webhook endpoint: %s", webhookErr.Message)
+		return nil, webhookErr
 	}
 
-	return nil
+	var result WebhookResponse
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseBody)).Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode webhook %s response (request_id %s): %w", action, requestID, err)
+	}
+	return &result, nil
 }
 
-func (d *OcservOcctlDocker) DisconnectUser(username string) (string, error) {
-	return "", d.call("disconnect", username)
-}
+// readWebhookError reads the JSON error of a failed call, falling back to the raw
+// body for responses of older webhook services or proxies in between.
+func readWebhookError(body io.Reader) *WebhookError {
+	message, _ := io.ReadAll(io.LimitReader(body, maxErrorBody))
 
-func (d *OcservOcctlDocker) Lock(username string) (string, error) {
-	return "", d.call("lock", username)
+	var resp WebhookResponse
+	if err := json.Unmarshal(message, &resp); err == nil && resp.Code != "" {
+		return &WebhookError{Code: resp.Code, Message: resp.Message}
+	}
+	return &WebhookError{Message: strings.TrimSpace(string(message))}
 }
 
-func (d *OcservOcctlDocker) Unlock(username string) (string, error) {
-	return "", d.call("unlock", username)
+// IsWebhookError reports whether err is a webhook response with the given code.
+func IsWebhookError(err error, code string) bool {
+	var webhookErr *WebhookError
+	return errors.As(err, &webhookErr) && webhookErr.Code == code
 }
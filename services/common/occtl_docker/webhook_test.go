@@ -0,0 +1,98 @@
+package occtl_docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookClient_ForwardsRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(requestid.Header)
+		if r.URL.Path != RPCPrefix+MethodUserLock {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(WebhookResponse{
+			Code:    ErrCodeActionFailed,
+			Message: "Failed to lock user: no such user",
+		})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(requestid.NewContext(context.Background(), "01TESTREQUEST"))
+	cancel() // the call must not depend on the lifetime of the triggering request
+
+	client, _, _ := NewClients(ClientOptions{URL: server.URL, Secret: []byte("secret")})
+	_, err := client.WithContext(ctx).Lock("john")
+
+	if received != "01TESTREQUEST" {
+		t.Errorf("expected forwarded request ID, got %q", received)
+	}
+
+	var webhookErr *WebhookError
+	if !errors.As(err, &webhookErr) {
+		t.Fatalf("expected WebhookError, got %v", err)
+	}
+	if webhookErr.StatusCode != http.StatusBadRequest || webhookErr.RequestID != "01TESTREQUEST" ||
+		!IsWebhookError(err, ErrCodeActionFailed) {
+		t.Errorf("unexpected error: %+v", webhookErr)
+	}
+	if !strings.Contains(err.Error(), "no such user") || !strings.Contains(err.Error(), "01TESTREQUEST") {
+		t.Errorf("error misses the webhook message or request ID: %v", err)
+	}
+}
+
+func TestWebhookClient_GeneratesRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(requestid.Header)
+	}))
+	defer server.Close()
+
+	_, _, client := NewClients(ClientOptions{URL: server.URL, Secret: []byte("secret")})
+	if _, err := client.DisconnectUser("john"); err != nil {
+		t.Fatal(err)
+	}
+	if !requestid.Valid(received) {
+		t.Errorf("expected a generated request ID, got %q", received)
+	}
+}
+
+func TestWebhookClient_SignsRequests(t *testing.T) {
+	verifier := NewVerifier([]byte("secret"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := verifier.Verify(r, body); err != nil {
+			t.Errorf("expected a valid signature, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, _, _ := NewClients(ClientOptions{URL: server.URL, Secret: []byte("secret")})
+	if _, err := client.UnLock("john"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebhookClient_RawErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, _, _ := NewClients(ClientOptions{URL: server.URL, Secret: []byte("secret")})
+	_, err := client.Lock("john")
+
+	var webhookErr *WebhookError
+	if !errors.As(err, &webhookErr) || webhookErr.Code != "" || webhookErr.Message != "bad gateway" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
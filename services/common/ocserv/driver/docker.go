@@ -0,0 +1,130 @@
+package driver
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// rawStreamType is the content type of container logs of a TTY container, any
+// other logs are multiplexed with an 8 byte header per frame.
+const rawStreamType = "application/vnd.docker.raw-stream"
+
+// dockerDriver calls the webhook service of the ocserv container and reads the
+// container logs from the Docker Engine API.
+type dockerDriver struct {
+	rpcDriver
+	container string
+	socket    string
+}
+
+func newDocker(cfg config.DriverConfig) (Driver, error) {
+	webhook := config.Webhook()
+	return &dockerDriver{
+		rpcDriver: newRPCDriver(occtlDocker.ClientOptions{
+			URL:    webhook.URL,
+			Secret: []byte(webhook.Secret),
+		}),
+		container: cfg.DockerContainer,
+		socket:    cfg.DockerSocket,
+	}, nil
+}
+
+func (d *dockerDriver) Name() string {
+	return Docker
+}
+
+// Logs follows the logs of the ocserv container from its last 100 lines, only
+// lines of the ocserv process are sent.
+func (d *dockerDriver) Logs(ctx context.Context, out chan<- string) error {
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", d.socket)
+		},
+	}}
+
+	query := url.Values{"follow": {"1"}, "stdout": {"1"}, "stderr": {"1"}, "tail": {"100"}}
+	endpoint := "http://docker/containers/" + url.PathEscape(d.container) + "/logs?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("docker logs of %s: status %d: %s", d.container, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var logs io.Reader = resp.Body
+	if resp.Header.Get("Content-Type") != rawStreamType {
+		pr, pw := io.Pipe()
+		defer pr.Close()
+		go func() {
+			pw.CloseWithError(demuxLogs(pw, resp.Body))
+		}()
+		logs = pr
+	}
+
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "ocserv[") {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case out <- line:
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// demuxLogs copies the payload of the multiplexed stdout and stderr frames of src
+// to dst. Every frame starts with the stream type, 3 zero bytes and the payload
+// size as big endian uint32.
+func demuxLogs(dst io.Writer, src io.Reader) error {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(src, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(dst, src, size); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *dockerDriver) SupportsEvents() bool {
+	return false
+}
+
+func (d *dockerDriver) WithContext(ctx context.Context) Driver {
+	return &dockerDriver{
+		rpcDriver: d.rpcDriver.withContext(ctx),
+		container: d.container,
+		socket:    d.socket,
+	}
+}
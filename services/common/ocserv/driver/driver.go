@@ -0,0 +1,126 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"sort"
+	"sync"
+)
+
+// Names of the built-in drivers.
+const (
+	Local  = "local"
+	Docker = "docker"
+	SSH    = "ssh"
+	Fake   = "fake"
+)
+
+// Driver controls one ocserv installation: its users, groups and their config
+// files, occtl and the ocserv logs. Services only talk to ocserv through a
+// driver, so they behave the same whichever way ocserv is reached.
+type Driver interface {
+	Name() string
+	Users() user.OcservUserInterface
+	Groups() group.OcservGroupInterface
+	Occtl() occtl.OcservOcctlInterface
+	// Logs sends the ocserv log lines to out until ctx is canceled or the source ends.
+	Logs(ctx context.Context, out chan<- string) error
+	// SupportsEvents reports whether Occtl().Events streams the occtl events.
+	SupportsEvents() bool
+	// WithContext returns a driver forwarding the request ID of ctx with its calls.
+	WithContext(ctx context.Context) Driver
+}
+
+// Factory creates a driver from the driver settings.
+type Factory func(cfg config.DriverConfig) (Driver, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+	current   Driver
+)
+
+func init() {
+	Register(Local, newLocal)
+	Register(Docker, newDocker)
+	Register(SSH, newSSH)
+	Register(Fake, func(config.DriverConfig) (Driver, error) { return NewFake(), nil })
+}
+
+// Register makes a driver available under name. It panics if name is taken.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := factories[name]; ok {
+		panic("ocserv driver registered twice: " + name)
+	}
+	factories[name] = factory
+}
+
+// Names returns the registered driver names.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the driver registered under name.
+func New(name string, cfg config.DriverConfig) (Driver, error) {
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown ocserv driver %q, available: %v", name, Names())
+	}
+	return factory(cfg)
+}
+
+// Init creates the driver selected by OCSERV_DRIVER, or by dockerMode when it is
+// not set, and makes it the one returned by Get.
+func Init(dockerMode bool) (Driver, error) {
+	cfg := config.Driver()
+
+	name := cfg.Name
+	if name == "" {
+		name = Local
+		if dockerMode {
+			name = Docker
+		}
+	}
+
+	d, err := New(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Using ocserv driver: %s", d.Name())
+
+	mu.Lock()
+	current = d
+	mu.Unlock()
+	return d, nil
+}
+
+// Get returns the driver created by Init, the local driver before Init.
+func Get() Driver {
+	mu.RLock()
+	d := current
+	mu.RUnlock()
+
+	if d == nil {
+		d, _ = newLocal(config.Driver())
+	}
+	return d
+}
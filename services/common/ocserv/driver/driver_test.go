@@ -0,0 +1,224 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/secret"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInit_SelectsDriver(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		dockerMode bool
+		want       string
+	}{
+		{name: "local by default", want: Local},
+		{name: "docker mode flag", dockerMode: true, want: Docker},
+		{name: "setting wins over the flag", env: Fake, dockerMode: true, want: Fake},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OCSERV_DRIVER", tt.env)
+
+			d, err := Init(tt.dockerMode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Name() != tt.want || Get().Name() != tt.want {
+				t.Errorf("expected driver %s, got %s", tt.want, d.Name())
+			}
+		})
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	if _, err := New("carrier-pigeon", config.DriverConfig{}); err == nil {
+		t.Error("expected an unknown driver error")
+	}
+	if _, err := New(SSH, config.DriverConfig{}); err == nil {
+		t.Error("expected the ssh driver to require its settings")
+	}
+	if want := []string{Docker, Fake, Local, SSH}; !reflect.DeepEqual(Names(), want) {
+		t.Errorf("expected drivers %v, got %v", want, Names())
+	}
+}
+
+func TestFakeDriver(t *testing.T) {
+	f := NewFake()
+	users, groups, occtl := f.Users(), f.Groups(), f.Occtl()
+
+	if err := groups.Create("staff", &models.OcservGroupConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Create("staff", "john", "pass", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.Lock("john"); err != nil {
+		t.Fatal(err)
+	}
+	if u, ok := f.User("john"); !ok || !u.Locked || u.Group != "staff" {
+		t.Errorf("unexpected user %+v", u)
+	}
+	if _, err := users.Lock("jane"); err == nil {
+		t.Error("expected locking a missing user to fail")
+	}
+
+	events := make(chan models.OcctlEvent, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = occtl.Events(ctx, events) }()
+
+	id := f.Connect(models.OnlineUserSession{Username: "john", RemoteIP: "10.0.0.1"})
+	f.Connect(models.OnlineUserSession{Username: "jane"})
+
+	if sessions, _ := occtl.ShowUser("john"); len(sessions) != 1 || int(sessions[0].ID) != id {
+		t.Errorf("unexpected sessions %+v", sessions)
+	}
+	if _, err := occtl.DisconnectUser("john"); err != nil {
+		t.Fatal(err)
+	}
+	if online, _ := occtl.OnlineUsers(); !reflect.DeepEqual(online, []string{"jane"}) {
+		t.Errorf("unexpected online users %v", online)
+	}
+
+	var kinds []string
+	for len(kinds) < 3 {
+		select {
+		case event := <-events:
+			kinds = append(kinds, event.Type+" "+event.Username)
+		case <-time.After(time.Second):
+			t.Fatalf("missing events, got %v", kinds)
+		}
+	}
+	if want := []string{"connect john", "connect jane", "disconnect john"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("expected events %v, got %v", want, kinds)
+	}
+
+	if err := groups.Delete("staff"); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := f.User("john"); u.Group != "" {
+		t.Errorf("expected group of john to be reset, got %q", u.Group)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	a, b := NewFake(), NewFake()
+	if err := a.Users().Create("defaults", "john", "pass", nil); err != nil {
+		t.Fatal(err)
+	}
+	nodes := []Node{{Name: "a", Driver: a}, {Name: "b", Driver: b}}
+
+	err := FanOut(context.Background(), nodes, func(_ Node, d Driver) error {
+		_, err := d.Users().Lock("john")
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "server b:") || strings.Contains(err.Error(), "server a:") {
+		t.Errorf("expected only node b to fail, got %v", err)
+	}
+	var fanOutErr *FanOutError
+	if !errors.As(err, &fanOutErr) || !reflect.DeepEqual(fanOutErr.Failed, []string{"b"}) {
+		t.Errorf("expected the failed nodes [b], got %v", err)
+	}
+	if err = FanOut(context.Background(), nodes, func(Node, Driver) error { return nil }); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if u, _ := a.User("john"); !u.Locked {
+		t.Error("expected john to be locked on node a")
+	}
+
+	if nodes = Nodes(nil); len(nodes) != 1 || nodes[0].Name != DefaultNode || nodes[0].Server != nil {
+		t.Errorf("expected the default node, got %+v", nodes)
+	}
+	nodes = Nodes([]models.Server{{Name: "edge-1", Endpoint: "http://10.0.0.2:8888"}})
+	if len(nodes) != 1 || nodes[0].Name != "edge-1" || nodes[0].Driver.Name() != Remote {
+		t.Errorf("expected the remote node edge-1, got %+v", nodes)
+	}
+}
+
+func TestServerNode_DecryptsSecret(t *testing.T) {
+	t.Setenv("SECRET_KEY", "0123456789abcdef0123456789abcdef")
+	node := NewFake()
+	verifier := occtlDocker.NewVerifier([]byte("node-secret-0123456789"))
+	rpc := httptest.NewServer(occtlDocker.NewRPCServer(verifier, node.Users(), node.Groups(), node.Occtl()))
+	defer rpc.Close()
+
+	encrypted, err := secret.Encrypt("node-secret-0123456789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := models.Server{Name: "edge-1", Endpoint: rpc.URL, Secret: encrypted}
+	if err = ServerNode(server).Driver.Users().Create("defaults", "john", "pass", nil); err != nil {
+		t.Fatalf("expected the request signed with the decrypted secret, got %v", err)
+	}
+	if _, ok := node.User("john"); !ok {
+		t.Error("expected john to be created on the node")
+	}
+
+	server.Secret = "node-secret-0123456789"
+	if err = ServerNode(server).Driver.Users().Create("defaults", "jane", "pass", nil); err == nil {
+		t.Error("expected a plaintext secret to be refused")
+	}
+}
+
+// frame returns a multiplexed docker log frame of stream.
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestDockerDriver_Logs(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	var path string
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		var body bytes.Buffer
+		body.Write(frame(1, "ocserv[12]: main: user connected\n"))
+		body.Write(frame(2, "entrypoint: starting\nocserv[12]: worker: "))
+		body.Write(frame(1, "split line\n"))
+		_, _ = w.Write(body.Bytes())
+	})}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	d := &dockerDriver{container: "ocserv", socket: socket}
+	out := make(chan string, 10)
+	if err = d.Logs(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+	close(out)
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+	want := []string{"ocserv[12]: main: user connected", "ocserv[12]: worker: split line"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("expected lines %v, got %v", want, lines)
+	}
+	if path != "/containers/ocserv/logs" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
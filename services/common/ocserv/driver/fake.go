@@ -0,0 +1,443 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// FakeUser is a user of the fake driver.
+type FakeUser struct {
+	Group    string
+	Password string
+	Locked   bool
+	Config   *models.OcservUserConfig
+}
+
+// FakeDriver keeps users, groups and sessions in memory, for tests and for
+// running the services without ocserv. Sessions are added with Connect and log
+// lines with Log.
+type FakeDriver struct {
+	mu       sync.Mutex
+	users    map[string]*FakeUser
+	groups   map[string]*models.OcservGroupConfig
+	defaults *models.OcservGroupConfig
+	sessions []models.OnlineUserSession
+	bans     []models.IPBanPoints
+	nextID   int
+	logs     chan string
+	events   chan models.OcctlEvent
+}
+
+var _ Driver = (*FakeDriver)(nil)
+
+func NewFake() *FakeDriver {
+	return &FakeDriver{
+		users:    map[string]*FakeUser{},
+		groups:   map[string]*models.OcservGroupConfig{},
+		defaults: &models.OcservGroupConfig{},
+		nextID:   1,
+		logs:     make(chan string, 100),
+		events:   make(chan models.OcctlEvent, 100),
+	}
+}
+
+func (f *FakeDriver) Name() string {
+	return Fake
+}
+
+func (f *FakeDriver) Users() user.OcservUserInterface {
+	return fakeUsers{f}
+}
+
+func (f *FakeDriver) Groups() group.OcservGroupInterface {
+	return fakeGroups{f}
+}
+
+func (f *FakeDriver) Occtl() occtl.OcservOcctlInterface {
+	return fakeOcctl{f}
+}
+
+// Logs sends the lines added with Log.
+func (f *FakeDriver) Logs(ctx context.Context, out chan<- string) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line := <-f.logs:
+			select {
+			case <-ctx.Done():
+				return nil
+			case out <- line:
+			}
+		}
+	}
+}
+
+func (f *FakeDriver) SupportsEvents() bool {
+	return true
+}
+
+func (f *FakeDriver) WithContext(context.Context) Driver {
+	return f
+}
+
+// Log adds a line to the ocserv logs.
+func (f *FakeDriver) Log(line string) {
+	f.logs <- line
+}
+
+// User returns a copy of the user named username.
+func (f *FakeDriver) User(username string) (FakeUser, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	u, ok := f.users[username]
+	if !ok {
+		return FakeUser{}, false
+	}
+	return *u, true
+}
+
+// Connect opens a session of session.Username with the next session ID and
+// returns the ID.
+func (f *FakeDriver) Connect(session models.OnlineUserSession) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session.ID = models.OcctlID(f.nextID)
+	f.nextID++
+	f.sessions = append(f.sessions, session)
+	f.emit("connect", session)
+	return int(session.ID)
+}
+
+// Ban adds ip to the banned IPs.
+func (f *FakeDriver) Ban(ip string, score int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.bans = append(f.bans, models.IPBanPoints{IP: ip, Score: models.OcctlInt(score)})
+}
+
+// disconnect closes the sessions matched by match, the caller holds f.mu.
+func (f *FakeDriver) disconnect(match func(models.OnlineUserSession) bool) int {
+	kept := f.sessions[:0]
+	closed := 0
+	for _, session := range f.sessions {
+		if match(session) {
+			f.emit("disconnect", session)
+			closed++
+			continue
+		}
+		kept = append(kept, session)
+	}
+	f.sessions = kept
+	return closed
+}
+
+// emit queues an occtl event, dropping it when nobody reads them.
+func (f *FakeDriver) emit(kind string, session models.OnlineUserSession) {
+	select {
+	case f.events <- models.OcctlEvent{
+		Type:     kind,
+		ID:       int(session.ID),
+		Username: session.Username,
+		RemoteIP: session.RemoteIP,
+	}:
+	default:
+	}
+}
+
+var errFakeNotFound = errors.New("not found")
+
+type fakeUsers struct{ f *FakeDriver }
+
+func (u fakeUsers) Create(group, username, password string, config *models.OcservUserConfig) error {
+	u.f.mu.Lock()
+	defer u.f.mu.Unlock()
+
+	u.f.users[username] = &FakeUser{Group: group, Password: password, Config: config}
+	return nil
+}
+
+func (u fakeUsers) setLocked(username string, locked bool) (string, error) {
+	u.f.mu.Lock()
+	defer u.f.mu.Unlock()
+
+	existing, ok := u.f.users[username]
+	if !ok {
+		return "", fmt.Errorf("user %s: %w", username, errFakeNotFound)
+	}
+	existing.Locked = locked
+	return "", nil
+}
+
+func (u fakeUsers) Lock(username string) (string, error) {
+	return u.setLocked(username, true)
+}
+
+func (u fakeUsers) UnLock(username string) (string, error) {
+	return u.setLocked(username, false)
+}
+
+func (u fakeUsers) Delete(username string) (string, error) {
+	u.f.mu.Lock()
+	defer u.f.mu.Unlock()
+
+	if _, ok := u.f.users[username]; !ok {
+		return "", fmt.Errorf("user %s: %w", username, errFakeNotFound)
+	}
+	delete(u.f.users, username)
+	return "", nil
+}
+
+func (u fakeUsers) CreateConfig(username string, config *models.OcservUserConfig) error {
+	u.f.mu.Lock()
+	defer u.f.mu.Unlock()
+
+	existing, ok := u.f.users[username]
+	if !ok {
+		return fmt.Errorf("user %s: %w", username, errFakeNotFound)
+	}
+	existing.Config = config
+	return nil
+}
+
+func (u fakeUsers) DeleteConfig(username string) error {
+	return u.CreateConfig(username, nil)
+}
+
+func (u fakeUsers) Ocpasswd(context.Context) (*[]user.Ocpasswd, int, error) {
+	u.f.mu.Lock()
+	defer u.f.mu.Unlock()
+
+	list := make([]user.Ocpasswd, 0, len(u.f.users))
+	for username, existing := range u.f.users {
+		list = append(list, user.Ocpasswd{Username: username, Group: existing.Group})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Username < list[j].Username })
+	return &list, len(list), nil
+}
+
+type fakeGroups struct{ f *FakeDriver }
+
+func (g fakeGroups) Create(name string, config *models.OcservGroupConfig) error {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+
+	g.f.groups[name] = config
+	return nil
+}
+
+func (g fakeGroups) Delete(name string) error {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+
+	if _, ok := g.f.groups[name]; !ok {
+		return fmt.Errorf("group %s: %w", name, errFakeNotFound)
+	}
+	delete(g.f.groups, name)
+	for _, existing := range g.f.users {
+		if existing.Group == name {
+			existing.Group = ""
+		}
+	}
+	return nil
+}
+
+func (g fakeGroups) DefaultsGroup() (*models.OcservGroupConfig, error) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+
+	return g.f.defaults, nil
+}
+
+func (g fakeGroups) UpdateDefaultsGroup(config *models.OcservGroupConfig) error {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+
+	g.f.defaults = config
+	return nil
+}
+
+func (g fakeGroups) GroupList(context.Context) ([]group.UnsyncedGroup, error) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+
+	list := make([]group.UnsyncedGroup, 0, len(g.f.groups))
+	for name, config := range g.f.groups {
+		list = append(list, group.UnsyncedGroup{Name: name, Config: config})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+type fakeOcctl struct{ f *FakeDriver }
+
+func (o fakeOcctl) OnlineUsers() ([]string, error) {
+	sessions, _ := o.OnlineSessions()
+	users := make([]string, 0, len(*sessions))
+	for _, session := range *sessions {
+		users = append(users, session.Username)
+	}
+	return users, nil
+}
+
+func (o fakeOcctl) OnlineSessions() (*[]models.OnlineUserSession, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	sessions := append([]models.OnlineUserSession(nil), o.f.sessions...)
+	return &sessions, nil
+}
+
+func (o fakeOcctl) ShowUser(username string) ([]models.OnlineUserSession, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	var sessions []models.OnlineUserSession
+	for _, session := range o.f.sessions {
+		if session.Username == username {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (o fakeOcctl) ShowUserByID(id string) (models.OnlineUserSession, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	for _, session := range o.f.sessions {
+		if strconv.Itoa(int(session.ID)) == id {
+			return session, nil
+		}
+	}
+	return models.OnlineUserSession{}, fmt.Errorf("session %s: %w", id, errFakeNotFound)
+}
+
+func (o fakeOcctl) DisconnectUser(username string) (string, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	o.f.disconnect(func(session models.OnlineUserSession) bool { return session.Username == username })
+	return "", nil
+}
+
+func (o fakeOcctl) DisconnectID(id string) (string, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	if o.f.disconnect(func(session models.OnlineUserSession) bool { return strconv.Itoa(int(session.ID)) == id }) == 0 {
+		return "", fmt.Errorf("session %s: %w", id, errFakeNotFound)
+	}
+	return "", nil
+}
+
+func (o fakeOcctl) ShowSession(sid string) (*models.OcctlSession, error) {
+	sessions, _ := o.ShowSessionAll()
+	for _, session := range *sessions {
+		if session.Session == sid {
+			return &session, nil
+		}
+	}
+	return nil, fmt.Errorf("session %s: %w", sid, errFakeNotFound)
+}
+
+func (o fakeOcctl) ShowSessionAll() (*[]models.OcctlSession, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	sessions := make([]models.OcctlSession, 0, len(o.f.sessions))
+	for _, session := range o.f.sessions {
+		sessions = append(sessions, models.OcctlSession{
+			Session:       session.Session,
+			FullSession:   session.FullSession,
+			State:         session.State,
+			Username:      session.Username,
+			Group:         session.Group,
+			UserAgent:     session.UserAgent,
+			RemoteIP:      session.RemoteIP,
+			SessionIsOpen: true,
+			CreatedAt:     session.ConnectedAt,
+		})
+	}
+	return &sessions, nil
+}
+
+func (o fakeOcctl) ShowSessionsValid() (*[]models.OcctlSession, error) {
+	return o.ShowSessionAll()
+}
+
+func (o fakeOcctl) ShowIPBans() (*[]models.IPBanPoints, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	bans := append([]models.IPBanPoints{}, o.f.bans...)
+	return &bans, nil
+}
+
+func (o fakeOcctl) UnbanIP(ip string) (string, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	kept := o.f.bans[:0]
+	for _, ban := range o.f.bans {
+		if ban.IP != ip {
+			kept = append(kept, ban)
+		}
+	}
+	o.f.bans = kept
+	return "", nil
+}
+
+func (o fakeOcctl) ShowStatus() (*models.ServerStatus, error) {
+	o.f.mu.Lock()
+	defer o.f.mu.Unlock()
+
+	return &models.ServerStatus{
+		Status:         "online",
+		ActiveSessions: models.OcctlInt(len(o.f.sessions)),
+		TotalSessions:  models.OcctlInt(o.f.nextID - 1),
+	}, nil
+}
+
+func (o fakeOcctl) ReloadConfigs() (string, error) {
+	return "", nil
+}
+
+func (o fakeOcctl) ShowIRoutes() (*[]models.IRoute, error) {
+	return &[]models.IRoute{}, nil
+}
+
+func (o fakeOcctl) ShowEvent() string {
+	return ""
+}
+
+func (o fakeOcctl) Version() *models.ServerVersion {
+	return &models.ServerVersion{OcservVersion: "fake", OcctlVersion: "fake"}
+}
+
+// Events sends the connects and disconnects of the fake sessions.
+func (o fakeOcctl) Events(ctx context.Context, out chan<- models.OcctlEvent) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-o.f.events:
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- event:
+			}
+		}
+	}
+}
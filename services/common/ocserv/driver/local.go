@@ -0,0 +1,85 @@
+package driver
+
+import (
+	"bufio"
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"os/exec"
+)
+
+// journalctlArgs follows the ocserv unit from its last 100 lines.
+var journalctlArgs = []string{"-n", "100", "-fu", "ocserv", "--output=cat"}
+
+// localDriver runs the ocserv tools of this host and reads its logs from journald.
+type localDriver struct {
+	users  user.OcservUserInterface
+	groups group.OcservGroupInterface
+	occtl  occtl.OcservOcctlInterface
+}
+
+func newLocal(config.DriverConfig) (Driver, error) {
+	return &localDriver{
+		users:  user.NewOcservUser(),
+		groups: group.NewOcservGroup(),
+		occtl:  occtl.New(),
+	}, nil
+}
+
+func (d *localDriver) Name() string {
+	return Local
+}
+
+func (d *localDriver) Users() user.OcservUserInterface {
+	return d.users
+}
+
+func (d *localDriver) Groups() group.OcservGroupInterface {
+	return d.groups
+}
+
+func (d *localDriver) Occtl() occtl.OcservOcctlInterface {
+	return d.occtl
+}
+
+// Logs follows the ocserv unit.
+// Executes: journalctl -n 100 -fu ocserv --output=cat
+func (d *localDriver) Logs(ctx context.Context, out chan<- string) error {
+	cmd := exec.CommandContext(ctx, "journalctl", journalctlArgs...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	defer cmd.Wait()
+
+	return scanLines(ctx, bufio.NewScanner(stdout), out)
+}
+
+func (d *localDriver) SupportsEvents() bool {
+	return true
+}
+
+// WithContext returns d, local calls have no request to forward the ID with.
+func (d *localDriver) WithContext(context.Context) Driver {
+	return d
+}
+
+// scanLines sends the lines of scanner to out until ctx is canceled.
+func scanLines(ctx context.Context, scanner *bufio.Scanner, out chan<- string) error {
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return nil
+		case out <- scanner.Text():
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
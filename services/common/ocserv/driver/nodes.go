@@ -0,0 +1,97 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/secret"
+	"sync"
+)
+
+// DefaultNode is the name of the node of the configured driver.
+const DefaultNode = "default"
+
+// Node is an ocserv a user or group is deployed to. Server is nil for the ocserv
+// of the configured driver.
+type Node struct {
+	Name   string
+	Server *models.Server
+	Driver Driver
+}
+
+// ServerNode returns the node of server, signing its requests with the decrypted
+// secret of the server. A secret that cannot be decrypted is logged and the
+// requests to the node fail.
+func ServerNode(server models.Server) Node {
+	key, err := secret.Decrypt(server.Secret)
+	if err != nil {
+		logger.Error("Failed to decrypt the secret of server %s: %v", server.Name, err)
+	}
+	return Node{
+		Name:   server.Name,
+		Server: &server,
+		Driver: NewRemote(server.Endpoint, []byte(key)),
+	}
+}
+
+// Nodes returns the nodes of servers, the node of the configured driver when
+// servers is empty.
+func Nodes(servers []models.Server) []Node {
+	if len(servers) == 0 {
+		return []Node{{Name: DefaultNode, Driver: Get()}}
+	}
+
+	nodes := make([]Node, 0, len(servers))
+	for _, server := range servers {
+		nodes = append(nodes, ServerNode(server))
+	}
+	return nodes
+}
+
+// FanOutError is the error of FanOut when some nodes failed.
+type FanOutError struct {
+	// Failed holds the names of the failed nodes.
+	Failed []string
+	errs   []error
+}
+
+func (e *FanOutError) Error() string {
+	return errors.Join(e.errs...).Error()
+}
+
+func (e *FanOutError) Unwrap() []error {
+	return e.errs
+}
+
+// FanOut calls fn with the driver of every node concurrently, forwarding the
+// request ID of ctx. It waits for all nodes and returns a *FanOutError with the
+// errors of the failed ones prefixed by the node name.
+func FanOut(ctx context.Context, nodes []Node, fn func(node Node, d Driver) error) error {
+	errs := make([]error, len(nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(node, node.Driver.WithContext(ctx)); err != nil {
+				errs[i] = fmt.Errorf("server %s: %w", node.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	fanOutErr := &FanOutError{}
+	for i, err := range errs {
+		if err != nil {
+			fanOutErr.Failed = append(fanOutErr.Failed, nodes[i].Name)
+			fanOutErr.errs = append(fanOutErr.errs, err)
+		}
+	}
+	if len(fanOutErr.errs) == 0 {
+		return nil
+	}
+	return fanOutErr
+}
@@ -0,0 +1,79 @@
+package driver
+
+import (
+	"context"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+)
+
+// Remote is the name of the drivers of the servers added to the dashboard.
+const Remote = "remote"
+
+// rpcDriver calls the webhook RPC API of an ocserv host, it is shared by the
+// drivers reaching ocserv through the webhook service.
+type rpcDriver struct {
+	users  *occtlDocker.UserClient
+	groups *occtlDocker.GroupClient
+	occtl  *occtlDocker.OcctlClient
+}
+
+func newRPCDriver(opts occtlDocker.ClientOptions) rpcDriver {
+	users, groups, occtlClient := occtlDocker.NewClients(opts)
+	return rpcDriver{users: users, groups: groups, occtl: occtlClient}
+}
+
+func (d rpcDriver) Users() user.OcservUserInterface {
+	return d.users
+}
+
+func (d rpcDriver) Groups() group.OcservGroupInterface {
+	return d.groups
+}
+
+func (d rpcDriver) Occtl() occtl.OcservOcctlInterface {
+	return d.occtl
+}
+
+func (d rpcDriver) withContext(ctx context.Context) rpcDriver {
+	return rpcDriver{
+		users:  d.users.WithContext(ctx),
+		groups: d.groups.WithContext(ctx),
+		occtl:  d.occtl.WithContext(ctx),
+	}
+}
+
+// remoteDriver controls the ocserv of a server through the webhook service or the
+// node agent listening on endpoint. Only node agents stream the ocserv logs.
+type remoteDriver struct {
+	rpcDriver
+	opts occtlDocker.ClientOptions
+}
+
+// NewRemote returns the driver of the ocserv whose webhook service or node agent
+// listens on endpoint and verifies requests with secret.
+func NewRemote(endpoint string, secret []byte) Driver {
+	opts := occtlDocker.ClientOptions{
+		URL:    endpoint,
+		Secret: secret,
+	}
+	return &remoteDriver{rpcDriver: newRPCDriver(opts), opts: opts}
+}
+
+func (d *remoteDriver) Name() string {
+	return Remote
+}
+
+// Logs follows the ocserv logs streamed by the node agent of the server.
+func (d *remoteDriver) Logs(ctx context.Context, out chan<- string) error {
+	return occtlDocker.StreamLogs(ctx, d.opts, out)
+}
+
+func (d *remoteDriver) SupportsEvents() bool {
+	return false
+}
+
+func (d *remoteDriver) WithContext(ctx context.Context) Driver {
+	return &remoteDriver{rpcDriver: d.rpcDriver.withContext(ctx), opts: d.opts}
+}
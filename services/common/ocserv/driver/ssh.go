@@ -0,0 +1,182 @@
+package driver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sshDialTimeout = 10 * time.Second
+
+// sshDriver controls ocserv on a remote host running the webhook service. The
+// RPC calls are tunneled through an SSH connection to the webhook address of
+// the remote host, logs are read from its journald.
+type sshDriver struct {
+	rpcDriver
+	tunnel *sshTunnel
+}
+
+// sshTunnel holds the SSH connection shared by the calls, it is dialed on first
+// use and again after it broke.
+type sshTunnel struct {
+	address string
+	config  *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+func newSSH(cfg config.DriverConfig) (Driver, error) {
+	if cfg.SSH.Address == "" || cfg.SSH.KeyFile == "" || cfg.SSH.KnownHostsFile == "" {
+		return nil, errors.New("ssh driver requires OCSERV_SSH_ADDRESS, OCSERV_SSH_KEY_FILE and OCSERV_SSH_KNOWN_HOSTS")
+	}
+
+	key, err := os.ReadFile(cfg.SSH.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ssh key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh key: %w", err)
+	}
+	hostKeyCallback, err := knownhosts.New(cfg.SSH.KnownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ssh known hosts: %w", err)
+	}
+
+	address := cfg.SSH.Address
+	if _, _, err = net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "22")
+	}
+
+	tunnel := &sshTunnel{
+		address: address,
+		config: &ssh.ClientConfig{
+			User:            cfg.SSH.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         sshDialTimeout,
+		},
+	}
+
+	webhookAddress := cfg.SSH.WebhookAddress
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			// every call goes to the webhook service of the remote host
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return tunnel.dial(webhookAddress)
+			},
+		},
+	}
+
+	return &sshDriver{
+		rpcDriver: newRPCDriver(occtlDocker.ClientOptions{
+			URL:        "http://" + webhookAddress,
+			Secret:     []byte(config.Webhook().Secret),
+			HTTPClient: httpClient,
+		}),
+		tunnel: tunnel,
+	}, nil
+}
+
+// connect returns the SSH connection, dialing it if needed.
+func (t *sshTunnel) connect() (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client != nil {
+		return t.client, nil
+	}
+	client, err := ssh.Dial("tcp", t.address, t.config)
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", t.address, err)
+	}
+	t.client = client
+	go func() {
+		_ = client.Wait()
+		t.reset(client)
+	}()
+	return client, nil
+}
+
+// reset drops client if it is still the current connection.
+func (t *sshTunnel) reset(client *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client == client {
+		t.client = nil
+	}
+	_ = client.Close()
+}
+
+// dial opens a connection to address as seen from the remote host. A broken SSH
+// connection is redialed once.
+func (t *sshTunnel) dial(address string) (net.Conn, error) {
+	for attempt := 0; ; attempt++ {
+		client, err := t.connect()
+		if err != nil {
+			return nil, err
+		}
+		conn, err := client.Dial("tcp", address)
+		if err == nil || attempt > 0 {
+			return conn, err
+		}
+		t.reset(client)
+	}
+}
+
+func (d *sshDriver) Name() string {
+	return SSH
+}
+
+// Logs follows the ocserv unit of the remote host.
+// Executes: journalctl -n 100 -fu ocserv --output=cat
+func (d *sshDriver) Logs(ctx context.Context, out chan<- string) error {
+	client, err := d.tunnel.connect()
+	if err != nil {
+		return err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = session.Start("journalctl " + strings.Join(journalctlArgs, " ")); err != nil {
+		return err
+	}
+
+	// closing the session ends the scan when ctx is canceled
+	stop := context.AfterFunc(ctx, func() { _ = session.Close() })
+	defer stop()
+
+	return scanLines(ctx, bufio.NewScanner(stdout), out)
+}
+
+func (d *sshDriver) SupportsEvents() bool {
+	return false
+}
+
+func (d *sshDriver) WithContext(ctx context.Context) Driver {
+	return &sshDriver{
+		rpcDriver: d.rpcDriver.withContext(ctx),
+		tunnel:    d.tunnel,
+	}
+}
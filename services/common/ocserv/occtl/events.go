@@ -0,0 +1,254 @@
+package occtl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OcservOcctlEvents interface {
+	Events(ctx context.Context, out chan<- models.OcctlEvent) error
+}
+
+const (
+	eventsRestartDelay    = time.Second
+	eventsMaxRestartDelay = 30 * time.Second
+)
+
+// the key holding the disconnect reason differs between ocserv versions
+var disconnectReasonKeys = []string{"Disconnect reason", "disconnect_reason", "Reason"}
+
+var (
+	eventLineRe   = regexp.MustCompile(`(?i)\b(connected|disconnected)\s+user\s+'?([^'\s(]+)'?\s*(?:\((?:id\s*)?(\d+)\))?`)
+	eventRxTxRe   = regexp.MustCompile(`(?i)rx:\s*(\d+),\s*tx:\s*(\d+)`)
+	eventReasonRe = regexp.MustCompile(`(?i)reason:?\s*'?([^',]+)'?`)
+)
+
+// Events runs `occtl -j show events` and sends every connect and disconnect event
+// to out until ctx is canceled or occtl exits.
+// Executes: occtl -j show events
+func (o *OcservOcctl) Events(ctx context.Context, out chan<- models.OcctlEvent) error {
+	cmd := exec.CommandContext(ctx, occtlExec, "-j", "show", "events")
+
+	// occtl quits on 'q' or EOF, the pipe keeps stdin open for the lifetime of the process
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	defer stdin.Close()
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	var parser eventParser
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+scan:
+	for scanner.Scan() {
+		for _, event := range parser.Feed(scanner.Text()) {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				break scan
+			}
+		}
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+	if err == nil {
+		err = errors.New("occtl show events exited")
+	}
+	return err
+}
+
+// WatchEvents keeps an events consumer running until ctx is canceled. It is restarted
+// with a growing delay whenever occtl exits or cannot be started, for example while
+// ocserv restarts.
+func WatchEvents(ctx context.Context, source OcservOcctlEvents, out chan<- models.OcctlEvent) {
+	delay := eventsRestartDelay
+	for {
+		startedAt := time.Now()
+		err := source.Events(ctx, out)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(startedAt) > eventsMaxRestartDelay {
+			delay = eventsRestartDelay
+		}
+
+		logger.Warn("occtl events consumer stopped, restarting in %s: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, eventsMaxRestartDelay)
+	}
+}
+
+// eventParser turns the output of `occtl -j show events` into events. occtl prints
+// the user info of every new session as a JSON block, and the same block with the
+// disconnect reason when a session closes. Older versions print disconnects as a
+// single text line, which is parsed as a fallback.
+type eventParser struct {
+	block    []byte
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// Feed consumes one line of output and returns the events completed by it.
+func (p *eventParser) Feed(line string) []models.OcctlEvent {
+	if p.depth == 0 {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			return nil
+		}
+		if trimmed[0] != '{' && trimmed[0] != '[' {
+			if event, ok := parseEventLine(trimmed); ok {
+				return []models.OcctlEvent{event}
+			}
+			return nil
+		}
+	}
+
+	p.block = append(p.block, line...)
+	p.block = append(p.block, '\n')
+	p.track(line)
+	if p.depth > 0 {
+		return nil
+	}
+
+	events, err := parseEventBlock(p.block)
+	p.block, p.depth, p.inString, p.escaped = p.block[:0], 0, false, false
+	if err != nil {
+		logger.Warn("failed to parse occtl event: %v", err)
+		return nil
+	}
+	return events
+}
+
+// track updates the bracket depth of the current JSON block, ignoring brackets in strings.
+func (p *eventParser) track(line string) {
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case ch == '\\':
+				p.escaped = true
+			case ch == '"':
+				p.inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			p.inString = true
+		case '{', '[':
+			p.depth++
+		case '}', ']':
+			p.depth--
+		}
+	}
+}
+
+func parseEventBlock(block []byte) ([]models.OcctlEvent, error) {
+	sessions, err := decodeList[models.OnlineUserSession](block)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := decodeList[map[string]json.RawMessage](block)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.OcctlEvent, 0, len(sessions))
+	for i, session := range sessions {
+		event := models.OcctlEvent{
+			Type:      models.OcctlEventConnect,
+			Time:      time.Now(),
+			ID:        int(session.ID),
+			Username:  session.Username,
+			Group:     session.Group,
+			RemoteIP:  session.RemoteIP,
+			IPv4:      session.IPv4,
+			Device:    session.Device,
+			UserAgent: session.UserAgent,
+			RX:        int64(session.RX),
+			TX:        int64(session.TX),
+		}
+
+		for _, key := range disconnectReasonKeys {
+			raw, ok := fields[i][key]
+			if !ok {
+				continue
+			}
+			var reason string
+			if err = json.Unmarshal(raw, &reason); err != nil {
+				reason = strings.Trim(string(raw), `"`)
+			}
+			event.Type = models.OcctlEventDisconnect
+			event.Reason = reason
+			break
+		}
+
+		if event.Type == models.OcctlEventConnect && !session.ConnectedAt.IsZero() {
+			event.Time = session.ConnectedAt
+		}
+		if event.Username != "" {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// parseEventLine parses the text form, e.g.
+// "disconnected user 'john' (id 1375), rx: 1342213, tx: 7723744, reason: user disconnected".
+func parseEventLine(line string) (models.OcctlEvent, bool) {
+	m := eventLineRe.FindStringSubmatch(line)
+	if m == nil {
+		return models.OcctlEvent{}, false
+	}
+
+	event := models.OcctlEvent{
+		Type:     models.OcctlEventConnect,
+		Time:     time.Now(),
+		Username: m[2],
+	}
+	if strings.EqualFold(m[1], "disconnected") {
+		event.Type = models.OcctlEventDisconnect
+	}
+	event.ID, _ = strconv.Atoi(m[3])
+
+	if rxTx := eventRxTxRe.FindStringSubmatch(line); rxTx != nil {
+		event.RX, _ = strconv.ParseInt(rxTx[1], 10, 64)
+		event.TX, _ = strconv.ParseInt(rxTx[2], 10, 64)
+	}
+	if reason := eventReasonRe.FindStringSubmatch(line); reason != nil {
+		event.Reason = strings.TrimSpace(reason[1])
+	}
+	return event, true
+}
@@ -0,0 +1,53 @@
+package occtl
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventParser(t *testing.T) {
+	var (
+		parser eventParser
+		events []models.OcctlEvent
+	)
+	for _, line := range strings.Split(string(golden(t, "show_events.txt")), "\n") {
+		events = append(events, parser.Feed(line)...)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d: %+v", len(events), events)
+	}
+
+	connect := events[0]
+	if connect.Type != models.OcctlEventConnect || connect.ID != 1375 || connect.Username != "john" ||
+		connect.Group != "staff" || connect.Device != "vpns0" || connect.RemoteIP != "203.0.113.7" {
+		t.Errorf("unexpected connect event: %+v", connect)
+	}
+	if !connect.Time.Equal(time.Unix(1740824100, 0)) || connect.UserAgent != "AnyConnect Windows 4.10.07062" {
+		t.Errorf("unexpected connect details: %v %q", connect.Time, connect.UserAgent)
+	}
+
+	disconnect := events[1]
+	if disconnect.Type != models.OcctlEventDisconnect || disconnect.Username != "john" ||
+		disconnect.RX != 1342213 || disconnect.TX != 7723744 || disconnect.Reason != "user disconnected {idle}" {
+		t.Errorf("unexpected disconnect event: %+v", disconnect)
+	}
+
+	text := events[2]
+	if text.Type != models.OcctlEventDisconnect || text.ID != 1380 || text.Username != "jane" ||
+		text.RX != 2048 || text.TX != 1024 || text.Reason != "idle timeout" {
+		t.Errorf("unexpected text event: %+v", text)
+	}
+}
+
+func TestEventParser_Array(t *testing.T) {
+	var parser eventParser
+	events := parser.Feed(`[{"ID": 7, "Username": "bob", "Device": "vpns2"}, {"ID": 8, "Username": "eve"}]`)
+	if len(events) != 2 || events[0].Username != "bob" || events[1].ID != 8 {
+		t.Errorf("unexpected events: %+v", events)
+	}
+	if events := parser.Feed("not an event"); events != nil {
+		t.Errorf("expected no events, got %+v", events)
+	}
+}
@@ -1,7 +1,6 @@
 package occtl
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/utils"
@@ -15,15 +14,16 @@ type OcservOcctl struct{}
 type OcservOcctlUsers interface {
 	OnlineUsers() ([]string, error)
 	OnlineSessions() (*[]models.OnlineUserSession, error)
-	ShowUser(username string) (models.OnlineUserSession, error)
+	ShowUser(username string) ([]models.OnlineUserSession, error)
 	ShowUserByID(id string) (models.OnlineUserSession, error)
 	DisconnectUser(username string) (string, error)
+	DisconnectID(id string) (string, error)
 }
 
 type OcservOcctlSessions interface {
-	ShowSession(sid string) (map[string]interface{}, error)
-	ShowSessionAll() (*[]interface{}, error)
-	ShowSessionsValid() (*[]interface{}, error)
+	ShowSession(sid string) (*models.OcctlSession, error)
+	ShowSessionAll() (*[]models.OcctlSession, error)
+	ShowSessionsValid() (*[]models.OcctlSession, error)
 }
 
 type OcservOcctlIPBans interface {
@@ -32,7 +32,7 @@ type OcservOcctlIPBans interface {
 }
 
 type OcservOcctlServer interface {
-	ShowStatus(raw bool) (interface{}, error)
+	ShowStatus() (*models.ServerStatus, error)
 	ReloadConfigs() (string, error)
 	ShowIRoutes() (*[]models.IRoute, error)
 	ShowEvent() string
@@ -44,6 +44,7 @@ type OcservOcctlInterface interface {
 	OcservOcctlSessions
 	OcservOcctlIPBans
 	OcservOcctlServer
+	OcservOcctlEvents
 }
 
 const occtlExec = "/usr/bin/occtl"
@@ -52,40 +53,26 @@ func NewOcservOcctl() *OcservOcctl {
 	return &OcservOcctl{}
 }
 
-// OnlineUsers returns a list of currently connected usernames.
-// Executes: occtl -j show users | jq -r '.[].Username'
+// OnlineUsers returns the username of every connected session.
+// Executes: occtl -j show users
 func (o *OcservOcctl) OnlineUsers() ([]string, error) {
-	var users []string
-
-	command := "-j show users | jq -r '.[].Username'"
-	cmd := exec.Command("sh", "-c", fmt.Sprintf("%s %s", occtlExec, command))
-	result, err := cmd.Output()
+	sessions, err := o.OnlineSessions()
 	if err != nil {
 		return nil, err
 	}
-
-	for _, user := range strings.Split(string(result), "\n") {
-		trimmed := strings.TrimSpace(user)
-		if trimmed != "" {
-			users = append(users, trimmed)
-		}
-	}
-
-	return users, nil
+	return usernames(*sessions), nil
 }
 
 // OnlineSessions returns a list of currently connected user info.
 // Executes: occtl -j show users
 func (o *OcservOcctl) OnlineSessions() (*[]models.OnlineUserSession, error) {
-	command := "-j show users"
-	cmd := exec.Command("sh", "-c", fmt.Sprintf("%s %s", occtlExec, command))
-	result, err := cmd.Output()
+	result, err := exec.Command(occtlExec, "-j", "show", "users").Output()
 	if err != nil {
 		return nil, err
 	}
 
-	var sessions []models.OnlineUserSession
-	if err = json.Unmarshal(result, &sessions); err != nil {
+	sessions, err := parseUsers(result)
+	if err != nil {
 		return nil, err
 	}
 	return &sessions, nil
@@ -102,6 +89,17 @@ func (o *OcservOcctl) DisconnectUser(username string) (string, error) {
 	return string(out), nil
 }
 
+// DisconnectID disconnects the session with the given ID.
+// Executes: occtl disconnect id <id>
+func (o *OcservOcctl) DisconnectID(id string) (string, error) {
+	cmd := exec.Command(occtlExec, "disconnect", "id", id)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
 // ReloadConfigs reloads the ocserv configuration.
 // Executes: occtl reload
 func (o *OcservOcctl) ReloadConfigs() (string, error) {
@@ -122,15 +120,11 @@ func (o *OcservOcctl) ShowIPBans() (*[]models.IPBanPoints, error) {
 		return nil, err
 	}
 
-	var ipBans []models.IPBanPoints
-	if output := string(out); output != "" {
-		if err = json.Unmarshal(out, &ipBans); err != nil {
-			return nil, err
-		}
+	ipBans, err := parseIPBans(out)
+	if err != nil {
+		return nil, err
 	}
-
 	return &ipBans, nil
-
 }
 
 // UnbanIP removes an IP ban from the given IP address.
@@ -151,70 +145,39 @@ func (o *OcservOcctl) UnbanIP(ip string) (string, error) {
 
 // ShowStatus returns the current status of ocserv.
 // Executes: occtl -j show status
-// Executes: occtl show status
-func (o *OcservOcctl) ShowStatus(raw bool) (interface{}, error) {
-	cmd := exec.Command(occtlExec, "-j", "show", "status")
-	if raw {
-		cmd = exec.Command(occtlExec, "show", "status")
-	}
-
-	out, err := cmd.Output()
+func (o *OcservOcctl) ShowStatus() (*models.ServerStatus, error) {
+	out, err := exec.Command(occtlExec, "-j", "show", "status").Output()
 	if err != nil {
-		return "", err
-	}
-
-	if raw {
-		return string(out), nil
-	}
-
-	var status map[string]interface{}
-	if err = json.Unmarshal(out, &status); err != nil {
 		return nil, err
 	}
-	return status, nil
+	return parseStatus(out)
 }
 
 // ShowIRoutes returns the current iRoutes information.
 // Executes: occtl -j show iroutes
 func (o *OcservOcctl) ShowIRoutes() (*[]models.IRoute, error) {
-	var routes []models.IRoute
-
-	version := utils.GetOcservVersion()
-	if version == "1.2.4" { // has bug on IRoute Command
-		return &routes, nil
-	}
-
 	cmd := exec.Command(occtlExec, "-j", "show", "iroutes")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, err
 	}
 
-	outString := strings.TrimSpace(string(out))
-	if strings.HasPrefix(outString, "{") {
-		return &routes, nil
-	}
-
-	if err = json.Unmarshal(out, &routes); err != nil {
+	routes, err := parseIRoutes(out)
+	if err != nil {
 		return nil, err
 	}
 	return &routes, nil
 }
 
-// ShowUser returns detailed information about a specific user by username.
+// ShowUser returns detailed information about the sessions of a specific user by username.
 // Executes: occtl -j show user <username>
-func (o *OcservOcctl) ShowUser(username string) (models.OnlineUserSession, error) {
-	var session models.OnlineUserSession
-
+func (o *OcservOcctl) ShowUser(username string) ([]models.OnlineUserSession, error) {
 	cmd := exec.Command(occtlExec, "-j", "show", "user", username)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return session, err
-	}
-	if err = json.Unmarshal(out, &session); err != nil {
-		return session, err
+		return nil, err
 	}
-	return session, nil
+	return parseUsers(out)
 }
 
 // Version returns detailed information about ocserv version.
@@ -231,46 +194,41 @@ func (o *OcservOcctl) Version() *models.ServerVersion {
 // ShowUserByID returns detailed information about a specific user by ID.
 // Executes: occtl -j show id <id>
 func (o *OcservOcctl) ShowUserByID(id string) (models.OnlineUserSession, error) {
-	var session models.OnlineUserSession
 	cmd := exec.Command(occtlExec, "-j", "show", "id", id)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return session, err
-	}
-	if err = json.Unmarshal(out, &session); err != nil {
-		return session, err
+		return models.OnlineUserSession{}, err
 	}
-	return session, nil
+	return decodeOne[models.OnlineUserSession](out)
 }
 
 // ShowSession returns detailed information about a specific session by SID.
 // Executes: occtl -j show session <SID>
-func (o *OcservOcctl) ShowSession(sid string) (map[string]interface{}, error) {
+func (o *OcservOcctl) ShowSession(sid string) (*models.OcctlSession, error) {
 	cmd := exec.Command(occtlExec, "-j", "show", "session", sid)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, err
 	}
 
-	var session map[string]interface{}
-	if err = json.Unmarshal(out, &session); err != nil {
+	session, err := decodeOne[models.OcctlSession](out)
+	if err != nil {
 		return nil, err
 	}
-	return session, nil
+	return &session, nil
 }
 
 // ShowSessionAll returns detailed information about all sessions.
 // Executes: occtl -j show sessions all
-func (o *OcservOcctl) ShowSessionAll() (*[]interface{}, error) {
-	var sessions []interface{}
-
+func (o *OcservOcctl) ShowSessionAll() (*[]models.OcctlSession, error) {
 	cmd := exec.Command(occtlExec, "-j", "show", "sessions", "all")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, err
 	}
 
-	if err = json.Unmarshal(out, &sessions); err != nil {
+	sessions, err := parseSessions(out)
+	if err != nil {
 		return nil, err
 	}
 	return &sessions, nil
@@ -278,16 +236,15 @@ func (o *OcservOcctl) ShowSessionAll() (*[]interface{}, error) {
 
 // ShowSessionsValid returns detailed information  about all valid sessions.
 // Executes: occtl -j show sessions valid
-func (o *OcservOcctl) ShowSessionsValid() (*[]interface{}, error) {
-	var sessions []interface{}
-
+func (o *OcservOcctl) ShowSessionsValid() (*[]models.OcctlSession, error) {
 	cmd := exec.Command(occtlExec, "-j", "show", "sessions", "valid")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, err
 	}
 
-	if err = json.Unmarshal(out, &sessions); err != nil {
+	sessions, err := parseSessions(out)
+	if err != nil {
 		return nil, err
 	}
 	return &sessions, nil
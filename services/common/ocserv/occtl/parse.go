@@ -0,0 +1,141 @@
+package occtl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+)
+
+// ErrNotFound is returned when occtl prints an empty result for a single user, id or session.
+var ErrNotFound = errors.New("occtl: not found")
+
+// sanitize normalizes occtl -j output before decoding. Some ocserv versions print
+// trailing commas before closing brackets, values of a list without a comma between
+// them (show iroutes of 1.2.4), an empty output instead of [] and interactive
+// prompts around the JSON document.
+func sanitize(out []byte) []byte {
+	out = bytes.TrimSpace(out)
+	if start := bytes.IndexAny(out, "[{"); start > 0 {
+		out = out[start:]
+	}
+	if end := bytes.LastIndexAny(out, "]}"); end >= 0 && end < len(out)-1 {
+		out = out[:end+1]
+	}
+
+	var (
+		result   = make([]byte, 0, len(out))
+		inString bool
+		escaped  bool
+	)
+	for i := 0; i < len(out); i++ {
+		ch := out[i]
+		if inString {
+			result = append(result, ch)
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			// add the missing comma after the previous value of the list
+			j := len(result) - 1
+			for j >= 0 && (result[j] == ' ' || result[j] == '\t' || result[j] == '\n' || result[j] == '\r') {
+				j--
+			}
+			if j >= 0 && (result[j] == '}' || result[j] == ']') {
+				result = append(result[:j+1], append([]byte{','}, result[j+1:]...)...)
+			}
+		case ',':
+			// drop the comma when the next significant character closes the value
+			j := i + 1
+			for j < len(out) && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+				j++
+			}
+			if j < len(out) && (out[j] == ']' || out[j] == '}') {
+				continue
+			}
+		}
+		result = append(result, ch)
+	}
+	return result
+}
+
+// decodeList decodes an array of T. An empty output or an empty object (printed
+// by some versions when there is nothing to list) yields an empty slice and a
+// single object yields a slice of one.
+func decodeList[T any](out []byte) ([]T, error) {
+	out = sanitize(out)
+	items := make([]T, 0)
+
+	switch {
+	case len(out) == 0:
+		return items, nil
+	case out[0] == '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(out, &fields); err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			return items, nil
+		}
+
+		var item T
+		if err := json.Unmarshal(out, &item); err != nil {
+			return nil, err
+		}
+		return append(items, item), nil
+	}
+
+	if err := json.Unmarshal(out, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// decodeOne decodes a single T from either an object or the first element of an array.
+func decodeOne[T any](out []byte) (T, error) {
+	var item T
+
+	items, err := decodeList[T](out)
+	if err != nil {
+		return item, err
+	}
+	if len(items) == 0 {
+		return item, ErrNotFound
+	}
+	return items[0], nil
+}
+
+func parseUsers(out []byte) ([]models.OnlineUserSession, error) {
+	return decodeList[models.OnlineUserSession](out)
+}
+
+func parseSessions(out []byte) ([]models.OcctlSession, error) {
+	return decodeList[models.OcctlSession](out)
+}
+
+func parseIPBans(out []byte) ([]models.IPBanPoints, error) {
+	return decodeList[models.IPBanPoints](out)
+}
+
+func parseIRoutes(out []byte) ([]models.IRoute, error) {
+	return decodeList[models.IRoute](out)
+}
+
+func parseStatus(out []byte) (*models.ServerStatus, error) {
+	status, err := decodeOne[models.ServerStatus](out)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,158 @@
+package occtl
+
+import (
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func golden(t *testing.T, name string) []byte {
+	t.Helper()
+	out, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestParseUsers(t *testing.T) {
+	users, err := parseUsers(golden(t, "show_users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+
+	john := users[0]
+	if john.ID != 1375 || john.Username != "john" || john.Group != "staff" || john.Device != "vpns0" {
+		t.Errorf("unexpected identity: %+v", john)
+	}
+	if john.MTU != 1434 || john.RX != 1342213 || john.TX != 7723744 {
+		t.Errorf("unexpected counters: mtu=%d rx=%d tx=%d", john.MTU, john.RX, john.TX)
+	}
+	if john.UserAgent != "AnyConnect Windows 4.10.07062" || john.DTLSCipher != "(DTLS1.2)-(ECDHE-RSA)-(AES-256-GCM)" {
+		t.Errorf("unexpected client info: %q %q", john.UserAgent, john.DTLSCipher)
+	}
+	if !john.ConnectedAt.Equal(time.Unix(1740824100, 0)) {
+		t.Errorf("unexpected connected at: %v", john.ConnectedAt)
+	}
+	if !reflect.DeepEqual([]string(john.Routes), []string{"defaultroute"}) {
+		t.Errorf("unexpected routes: %v", john.Routes)
+	}
+	if john.RestrictedToRoutes {
+		t.Error("expected unrestricted routes")
+	}
+
+	jane := users[1]
+	if jane.MTU != 1399 || jane.RX != 0 || jane.TX != 1024 {
+		t.Errorf("unexpected counters: mtu=%d rx=%d tx=%d", jane.MTU, jane.RX, jane.TX)
+	}
+	expected := time.Date(2025, 3, 1, 11, 2, 0, 0, time.Local)
+	if !jane.ConnectedAt.Equal(expected) {
+		t.Errorf("expected connected at from text %v, got %v", expected, jane.ConnectedAt)
+	}
+	if len(jane.Routes) != 2 || len(jane.DNS) != 1 || !bool(jane.RestrictedToRoutes) {
+		t.Errorf("unexpected lists: routes=%v dns=%v restricted=%v", jane.Routes, jane.DNS, jane.RestrictedToRoutes)
+	}
+}
+
+func TestParseUsers_Empty(t *testing.T) {
+	for _, out := range [][]byte{golden(t, "show_user_empty.json"), nil, []byte("  \n")} {
+		users, err := parseUsers(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if users == nil || len(users) != 0 {
+			t.Errorf("expected empty slice, got %#v", users)
+		}
+	}
+
+	_, err := decodeOne[struct{}](golden(t, "show_user_empty.json"))
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestParseSessions(t *testing.T) {
+	sessions, err := parseSessions(golden(t, "show_sessions_all.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+
+	if s := sessions[0]; s.Session != "Zr0bUQ" || !bool(s.SessionIsOpen) || !bool(s.TLSAuthOK) || !bool(s.InUse) ||
+		!s.CreatedAt.Equal(time.Unix(1740824100, 0)) {
+		t.Errorf("unexpected session: %+v", s)
+	}
+	if s := sessions[1]; bool(s.InUse) || s.RemoteIP != "192.0.2.44" ||
+		!s.CreatedAt.Equal(time.Date(2025, 3, 1, 11, 20, 0, 0, time.Local)) {
+		t.Errorf("unexpected session: %+v", s)
+	}
+}
+
+func TestParseIPBans(t *testing.T) {
+	bans, err := parseIPBans(golden(t, "show_ip_bans_points.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bans) != 2 || bans[0].Score != 20 || bans[1].Score != 85 || bans[1].IP != "198.51.100.9" {
+		t.Errorf("unexpected bans: %+v", bans)
+	}
+}
+
+func TestParseIRoutes(t *testing.T) {
+	routes, err := parseIRoutes(golden(t, "show_iroutes.json"))
+	require.NoError(t, err)
+	require.Len(t, routes, 1)
+	assert.Equal(t, models.OcctlID(1375), routes[0].ID)
+	assert.Equal(t, models.OcctlRoutes{"10.10.0.0/255.255.0.0"}, routes[0].IRoutes)
+
+	// ocserv 1.2.4 prints an empty object instead of a list
+	routes, err = parseIRoutes(golden(t, "show_iroutes_1.2.4.json"))
+	require.NoError(t, err)
+	assert.Empty(t, routes)
+
+	// and no comma between the entries
+	routes, err = parseIRoutes(golden(t, "show_iroutes_1.2.4_routes.json"))
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	assert.Equal(t, "john", routes[0].Username)
+	assert.Equal(t, models.OcctlID(1376), routes[1].ID)
+	assert.Equal(t, models.OcctlRoutes{"10.20.0.0/255.255.0.0", "10.30.0.0/255.255.0.0"}, routes[1].IRoutes)
+}
+
+func TestParseStatus(t *testing.T) {
+	status, err := parseStatus(golden(t, "show_status.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != "online" || status.ActiveSessions != 2 || status.TotalSessions != 15 ||
+		status.RawTX != 1200000000 || status.MaxSessionTime != " 2h:01m" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	cases := map[string]string{
+		`[{"a": [1, 2, ], }, ]`:           `[{"a": [1, 2 ] } ]`,
+		`{"ua": "x, ]", "b": 1,}`:         `{"ua": "x, ]", "b": 1}`,
+		`{"ua": "quote \", }", "b": [],}`: `{"ua": "quote \", }", "b": []}`,
+		"Press 'q' to quit\n[1]\n":        `[1]`,
+		"[{\"a\": 1}\n {\"a\": \"}{\"}]":  "[{\"a\": 1},\n {\"a\": \"}{\"}]",
+		"":                                ``,
+	}
+	for in, expected := range cases {
+		if out := string(sanitize([]byte(in))); out != expected {
+			t.Errorf("sanitize(%q) = %q, expected %q", in, out, expected)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package occtl
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Control commands and replies of the ocserv occtl socket, from src/ctl.h of ocserv 1.2.4
+// (https://gitlab.com/openconnect/ocserv/-/blob/1.2.4/src/ctl.h).
+const (
+	ctlCmdStatus         = 1
+	ctlCmdReload         = 2
+	ctlCmdList           = 4
+	ctlCmdUserInfo       = 5
+	ctlCmdIDInfo         = 6
+	ctlCmdDisconnectName = 7
+	ctlCmdDisconnectID   = 8
+	ctlCmdListIPBans     = 9
+	ctlCmdUnbanIP        = 10
+
+	ctlCmdStatusRep         = 101
+	ctlCmdReloadRep         = 102
+	ctlCmdListRep           = 104
+	ctlCmdListBannedRep     = 106
+	ctlCmdUnbanIPRep        = 107
+	ctlCmdDisconnectNameRep = 108
+	ctlCmdDisconnectIDRep   = 109
+)
+
+// Field numbers of the messages exchanged over the socket, from src/ctl.proto of
+// ocserv 1.2.4. Only the fields the dashboard uses are listed; unknown fields are
+// skipped when decoding.
+const (
+	// bool_msg, username_req, id_req, unban_req
+	fieldBoolStatus   protowire.Number = 1
+	fieldUsernameReq  protowire.Number = 1
+	fieldIDReq        protowire.Number = 1
+	fieldUnbanReqIP   protowire.Number = 1
+	fieldUserListing  protowire.Number = 1
+	fieldBanListing   protowire.Number = 1
+	fieldBanInfoIP    protowire.Number = 1
+	fieldBanInfoScore protowire.Number = 2
+	fieldBanInfoUntil protowire.Number = 3
+
+	// status_rep
+	fieldStatusOnline          protowire.Number = 1
+	fieldStatusPID             protowire.Number = 2
+	fieldStatusSecModPID       protowire.Number = 4
+	fieldStatusActiveClients   protowire.Number = 5
+	fieldStatusStartTime       protowire.Number = 6
+	fieldStatusBannedIPs       protowire.Number = 8
+	fieldStatusTimeouts        protowire.Number = 10
+	fieldStatusIdleTimeouts    protowire.Number = 11
+	fieldStatusErrors          protowire.Number = 12
+	fieldStatusClosed          protowire.Number = 13
+	fieldStatusKBytesIn        protowire.Number = 14
+	fieldStatusKBytesOut       protowire.Number = 15
+	fieldStatusLastReset       protowire.Number = 18
+	fieldStatusAvgAuthTime     protowire.Number = 19
+	fieldStatusAvgSessionMins  protowire.Number = 20
+	fieldStatusMaxAuthTime     protowire.Number = 21
+	fieldStatusMaxSessionMins  protowire.Number = 22
+	fieldStatusAuthFailures    protowire.Number = 23
+	fieldStatusTotalAuthFails  protowire.Number = 24
+	fieldStatusTotalClosed     protowire.Number = 25
+	fieldStatusSecModInstances protowire.Number = 29
+
+	// user_info_rep
+	fieldUserID          protowire.Number = 1
+	fieldUserUsername    protowire.Number = 2
+	fieldUserGroupname   protowire.Number = 3
+	fieldUserIP          protowire.Number = 4
+	fieldUserTun         protowire.Number = 5
+	fieldUserRemoteIP    protowire.Number = 6
+	fieldUserLocalIP     protowire.Number = 7
+	fieldUserConnTime    protowire.Number = 8
+	fieldUserHostname    protowire.Number = 9
+	fieldUserAgent       protowire.Number = 10
+	fieldUserStatus      protowire.Number = 11
+	fieldUserTLSCipher   protowire.Number = 12
+	fieldUserDTLSCipher  protowire.Number = 13
+	fieldUserDNS         protowire.Number = 14
+	fieldUserNBNS        protowire.Number = 15
+	fieldUserRoutes      protowire.Number = 16
+	fieldUserNoRoutes    protowire.Number = 17
+	fieldUserIRoutes     protowire.Number = 18
+	fieldUserIPv6        protowire.Number = 19
+	fieldUserLocalIPv6   protowire.Number = 20
+	fieldUserBytesIn     protowire.Number = 21
+	fieldUserBytesOut    protowire.Number = 22
+	fieldUserMTU         protowire.Number = 23
+	fieldUserSafeID      protowire.Number = 24
+	fieldUserLocalDevIP  protowire.Number = 25
+	fieldUserRestricted  protowire.Number = 26
+	fieldUserVHost       protowire.Number = 27
+	fieldUserDPD         protowire.Number = 28
+	fieldUserKeepAlive   protowire.Number = 29
+	fieldUserSplitDNS    protowire.Number = 30
+	fieldUserFullSession protowire.Number = 31
+)
+
+var errMalformed = errors.New("occtl: malformed socket message")
+
+// protoField is a decoded field; varint and fixed values are in num, length delimited ones in raw.
+type protoField struct {
+	number protowire.Number
+	num    uint64
+	raw    []byte
+}
+
+func (f protoField) str() string { return string(f.raw) }
+
+// sint32 decodes a zigzag encoded varint.
+func (f protoField) sint32() int32 { return int32(protowire.DecodeZigZag(f.num)) }
+
+// decodeMessage calls fn for every field of the protobuf message b.
+func decodeMessage(b []byte, fn func(f protoField) error) error {
+	for len(b) > 0 {
+		number, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("%w: %v", errMalformed, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		f := protoField{number: number}
+		switch typ {
+		case protowire.VarintType:
+			f.num, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.num = uint64(v)
+		case protowire.Fixed64Type:
+			f.num, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.raw, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(number, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("%w: %v", errMalformed, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendString(b []byte, number protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, number protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, number, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendMessage(b []byte, number protowire.Number, m []byte) []byte {
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
+
+func encodeUsernameReq(username string) []byte {
+	return appendString(nil, fieldUsernameReq, username)
+}
+
+func encodeIDReq(id int32) []byte {
+	return appendVarint(nil, fieldIDReq, protowire.EncodeZigZag(int64(id)))
+}
+
+func encodeUnbanReq(ip string) []byte {
+	return appendString(nil, fieldUnbanReqIP, ip)
+}
+
+func decodeBoolMsg(b []byte) (bool, error) {
+	var status bool
+	err := decodeMessage(b, func(f protoField) error {
+		if f.number == fieldBoolStatus {
+			status = f.num != 0
+		}
+		return nil
+	})
+	return status, err
+}
+
+// userInfo is the user_info_rep message.
+type userInfo struct {
+	ID          int32
+	Username    string
+	Groupname   string
+	IP          string
+	Tun         string
+	RemoteIP    string
+	LocalIP     string
+	IPv6        string
+	LocalIPv6   string
+	LocalDevIP  string
+	ConnTime    int64
+	Hostname    string
+	UserAgent   string
+	Status      string
+	TLSCipher   string
+	DTLSCipher  string
+	DNS         []string
+	NBNS        []string
+	SplitDNS    []string
+	Routes      []string
+	NoRoutes    []string
+	IRoutes     []string
+	BytesIn     uint64
+	BytesOut    uint64
+	MTU         uint32
+	DPD         uint32
+	KeepAlive   uint32
+	SafeID      string
+	FullSession string
+	VHost       string
+	Restricted  bool
+}
+
+func decodeUserInfo(b []byte) (userInfo, error) {
+	var u userInfo
+	err := decodeMessage(b, func(f protoField) error {
+		switch f.number {
+		case fieldUserID:
+			u.ID = f.sint32()
+		case fieldUserUsername:
+			u.Username = f.str()
+		case fieldUserGroupname:
+			u.Groupname = f.str()
+		case fieldUserIP:
+			u.IP = f.str()
+		case fieldUserTun:
+			u.Tun = f.str()
+		case fieldUserRemoteIP:
+			u.RemoteIP = f.str()
+		case fieldUserLocalIP:
+			u.LocalIP = f.str()
+		case fieldUserIPv6:
+			u.IPv6 = f.str()
+		case fieldUserLocalIPv6:
+			u.LocalIPv6 = f.str()
+		case fieldUserLocalDevIP:
+			u.LocalDevIP = f.str()
+		case fieldUserConnTime:
+			u.ConnTime = int64(f.num)
+		case fieldUserHostname:
+			u.Hostname = f.str()
+		case fieldUserAgent:
+			u.UserAgent = f.str()
+		case fieldUserStatus:
+			u.Status = f.str()
+		case fieldUserTLSCipher:
+			u.TLSCipher = f.str()
+		case fieldUserDTLSCipher:
+			u.DTLSCipher = f.str()
+		case fieldUserDNS:
+			u.DNS = append(u.DNS, f.str())
+		case fieldUserNBNS:
+			u.NBNS = append(u.NBNS, f.str())
+		case fieldUserSplitDNS:
+			u.SplitDNS = append(u.SplitDNS, f.str())
+		case fieldUserRoutes:
+			u.Routes = append(u.Routes, f.str())
+		case fieldUserNoRoutes:
+			u.NoRoutes = append(u.NoRoutes, f.str())
+		case fieldUserIRoutes:
+			u.IRoutes = append(u.IRoutes, f.str())
+		case fieldUserBytesIn:
+			u.BytesIn = f.num
+		case fieldUserBytesOut:
+			u.BytesOut = f.num
+		case fieldUserMTU:
+			u.MTU = uint32(f.num)
+		case fieldUserDPD:
+			u.DPD = uint32(f.num)
+		case fieldUserKeepAlive:
+			u.KeepAlive = uint32(f.num)
+		case fieldUserSafeID:
+			u.SafeID = f.str()
+		case fieldUserFullSession:
+			u.FullSession = f.str()
+		case fieldUserVHost:
+			u.VHost = f.str()
+		case fieldUserRestricted:
+			u.Restricted = f.num != 0
+		}
+		return nil
+	})
+	return u, err
+}
+
+// decodeUserListing decodes user_listing_rep, the reply of list, user and id info commands.
+func decodeUserListing(b []byte) ([]userInfo, error) {
+	users := make([]userInfo, 0)
+	err := decodeMessage(b, func(f protoField) error {
+		if f.number != fieldUserListing {
+			return nil
+		}
+		u, err := decodeUserInfo(f.raw)
+		if err != nil {
+			return err
+		}
+		users = append(users, u)
+		return nil
+	})
+	return users, err
+}
+
+// statusInfo is the status_rep message.
+type statusInfo struct {
+	Online          bool
+	PID             uint32
+	SecModPID       uint32
+	SecModInstances uint32
+	ActiveClients   uint32
+	StartTime       int64
+	BannedIPs       uint32
+	Timeouts        uint32
+	IdleTimeouts    uint32
+	Errors          uint32
+	Closed          uint32
+	TotalClosed     uint32
+	KBytesIn        uint64
+	KBytesOut       uint64
+	LastReset       int64
+	AvgAuthTime     uint32
+	MaxAuthTime     uint32
+	AvgSessionMins  uint32
+	MaxSessionMins  uint32
+	AuthFailures    uint32
+	TotalAuthFails  uint32
+}
+
+func decodeStatus(b []byte) (statusInfo, error) {
+	var s statusInfo
+	err := decodeMessage(b, func(f protoField) error {
+		switch f.number {
+		case fieldStatusOnline:
+			s.Online = f.num != 0
+		case fieldStatusPID:
+			s.PID = uint32(f.num)
+		case fieldStatusSecModPID:
+			s.SecModPID = uint32(f.num)
+		case fieldStatusSecModInstances:
+			s.SecModInstances = uint32(f.num)
+		case fieldStatusActiveClients:
+			s.ActiveClients = uint32(f.num)
+		case fieldStatusStartTime:
+			s.StartTime = int64(f.num)
+		case fieldStatusBannedIPs:
+			s.BannedIPs = uint32(f.num)
+		case fieldStatusTimeouts:
+			s.Timeouts = uint32(f.num)
+		case fieldStatusIdleTimeouts:
+			s.IdleTimeouts = uint32(f.num)
+		case fieldStatusErrors:
+			s.Errors = uint32(f.num)
+		case fieldStatusClosed:
+			s.Closed = uint32(f.num)
+		case fieldStatusTotalClosed:
+			s.TotalClosed = uint32(f.num)
+		case fieldStatusKBytesIn:
+			s.KBytesIn = f.num
+		case fieldStatusKBytesOut:
+			s.KBytesOut = f.num
+		case fieldStatusLastReset:
+			s.LastReset = int64(f.num)
+		case fieldStatusAvgAuthTime:
+			s.AvgAuthTime = uint32(f.num)
+		case fieldStatusMaxAuthTime:
+			s.MaxAuthTime = uint32(f.num)
+		case fieldStatusAvgSessionMins:
+			s.AvgSessionMins = uint32(f.num)
+		case fieldStatusMaxSessionMins:
+			s.MaxSessionMins = uint32(f.num)
+		case fieldStatusAuthFailures:
+			s.AuthFailures = uint32(f.num)
+		case fieldStatusTotalAuthFails:
+			s.TotalAuthFails = uint32(f.num)
+		}
+		return nil
+	})
+	return s, err
+}
+
+// banInfo is the ban_info_rep message.
+type banInfo struct {
+	IP    string
+	Score uint32
+	Until int64
+}
+
+func decodeBanListing(b []byte) ([]banInfo, error) {
+	bans := make([]banInfo, 0)
+	err := decodeMessage(b, func(f protoField) error {
+		if f.number != fieldBanListing {
+			return nil
+		}
+
+		var ban banInfo
+		if err := decodeMessage(f.raw, func(f protoField) error {
+			switch f.number {
+			case fieldBanInfoIP:
+				ban.IP = f.str()
+			case fieldBanInfoScore:
+				ban.Score = uint32(f.num)
+			case fieldBanInfoUntil:
+				ban.Until = int64(f.num)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		bans = append(bans, ban)
+		return nil
+	})
+	return bans, err
+}
@@ -0,0 +1,456 @@
+package occtl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DriverExec   = "exec"
+	DriverSocket = "socket"
+
+	socketTimeout = 10 * time.Second
+	// maxSocketMessage bounds the reply size accepted from the socket.
+	maxSocketMessage = 16 << 20
+	// occtlTimeLayout matches the dates printed by the occtl binary.
+	occtlTimeLayout = "2006-01-02 15:04"
+)
+
+// errSocketUnavailable wraps connection errors; callers fall back to the occtl binary.
+var errSocketUnavailable = errors.New("occtl socket unavailable")
+
+// OcservOcctlSocket talks to the occtl unix socket of ocserv (use-occtl and
+// occtl-socket-file in ocserv.conf) instead of executing the occtl binary.
+// Commands without a socket counterpart, and every command while the socket
+// cannot be reached, are served by the embedded exec implementation.
+type OcservOcctlSocket struct {
+	*OcservOcctl
+	path     string
+	timeout  time.Duration
+	warnOnce sync.Once
+}
+
+func NewOcservOcctlSocket(path string) *OcservOcctlSocket {
+	return &OcservOcctlSocket{
+		OcservOcctl: NewOcservOcctl(),
+		path:        path,
+		timeout:     socketTimeout,
+	}
+}
+
+// New returns the occtl implementation selected by the OCCTL_DRIVER setting.
+func New() OcservOcctlInterface {
+	if cfg := config.Get(); cfg != nil && cfg.Occtl.Driver == DriverSocket {
+		return NewOcservOcctlSocket(cfg.Occtl.Socket)
+	}
+	return NewOcservOcctl()
+}
+
+// call sends a command with its protobuf payload and returns the payload of the reply.
+// Messages are framed as a command byte followed by the payload length as a native
+// endian uint32.
+func (s *OcservOcctlSocket) call(cmd, replyCmd uint8, payload []byte) ([]byte, error) {
+	conn, err := net.DialTimeout("unix", s.path, s.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errSocketUnavailable, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(s.timeout))
+
+	header := make([]byte, 5)
+	header[0] = cmd
+	binary.NativeEndian.PutUint32(header[1:], uint32(len(payload)))
+	if _, err = conn.Write(append(header, payload...)); err != nil {
+		return nil, err
+	}
+
+	if _, err = io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	if header[0] != replyCmd {
+		return nil, fmt.Errorf("occtl: unexpected reply %d to command %d", header[0], cmd)
+	}
+	length := binary.NativeEndian.Uint32(header[1:])
+	if length > maxSocketMessage {
+		return nil, fmt.Errorf("occtl: reply of %d bytes is too large", length)
+	}
+
+	reply := make([]byte, length)
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// fallback reports whether err means the socket cannot be reached and the exec
+// implementation should serve the call.
+func (s *OcservOcctlSocket) fallback(err error) bool {
+	if !errors.Is(err, errSocketUnavailable) {
+		return false
+	}
+	s.warnOnce.Do(func() {
+		logger.Warn("occtl socket %s is unavailable, falling back to %s: %v", s.path, occtlExec, err)
+	})
+	return true
+}
+
+func (s *OcservOcctlSocket) users(cmd uint8, payload []byte) ([]models.OnlineUserSession, error) {
+	reply, err := s.call(cmd, ctlCmdListRep, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := decodeUserListing(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]models.OnlineUserSession, 0, len(infos))
+	for _, info := range infos {
+		sessions = append(sessions, info.session())
+	}
+	return sessions, nil
+}
+
+func (s *OcservOcctlSocket) OnlineUsers() ([]string, error) {
+	sessions, err := s.OnlineSessions()
+	if err != nil {
+		return nil, err
+	}
+	return usernames(*sessions), nil
+}
+
+func (s *OcservOcctlSocket) OnlineSessions() (*[]models.OnlineUserSession, error) {
+	sessions, err := s.users(ctlCmdList, nil)
+	if s.fallback(err) {
+		return s.OcservOcctl.OnlineSessions()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sessions, nil
+}
+
+func (s *OcservOcctlSocket) ShowUser(username string) ([]models.OnlineUserSession, error) {
+	sessions, err := s.users(ctlCmdUserInfo, encodeUsernameReq(username))
+	if s.fallback(err) {
+		return s.OcservOcctl.ShowUser(username)
+	}
+	return sessions, err
+}
+
+func (s *OcservOcctlSocket) ShowUserByID(id string) (models.OnlineUserSession, error) {
+	n, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return models.OnlineUserSession{}, fmt.Errorf("invalid id: %s", id)
+	}
+
+	sessions, err := s.users(ctlCmdIDInfo, encodeIDReq(int32(n)))
+	if s.fallback(err) {
+		return s.OcservOcctl.ShowUserByID(id)
+	}
+	if err != nil {
+		return models.OnlineUserSession{}, err
+	}
+	if len(sessions) == 0 {
+		return models.OnlineUserSession{}, ErrNotFound
+	}
+	return sessions[0], nil
+}
+
+func (s *OcservOcctlSocket) DisconnectUser(username string) (string, error) {
+	reply, err := s.call(ctlCmdDisconnectName, ctlCmdDisconnectNameRep, encodeUsernameReq(username))
+	if s.fallback(err) {
+		return s.OcservOcctl.DisconnectUser(username)
+	}
+	if err != nil {
+		return "", err
+	}
+	if ok, err := decodeBoolMsg(reply); err != nil || !ok {
+		return "", fmt.Errorf("could not disconnect user '%s'", username)
+	}
+	return fmt.Sprintf("user '%s' was disconnected\n", username), nil
+}
+
+func (s *OcservOcctlSocket) DisconnectID(id string) (string, error) {
+	sid, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid session id: %s", id)
+	}
+
+	reply, err := s.call(ctlCmdDisconnectID, ctlCmdDisconnectIDRep, encodeIDReq(int32(sid)))
+	if s.fallback(err) {
+		return s.OcservOcctl.DisconnectID(id)
+	}
+	if err != nil {
+		return "", err
+	}
+	if ok, err := decodeBoolMsg(reply); err != nil || !ok {
+		return "", fmt.Errorf("could not disconnect ID %s", id)
+	}
+	return fmt.Sprintf("connection ID %s was disconnected\n", id), nil
+}
+
+func (s *OcservOcctlSocket) ReloadConfigs() (string, error) {
+	reply, err := s.call(ctlCmdReload, ctlCmdReloadRep, nil)
+	if s.fallback(err) {
+		return s.OcservOcctl.ReloadConfigs()
+	}
+	if err != nil {
+		return "", err
+	}
+	if ok, err := decodeBoolMsg(reply); err != nil || !ok {
+		return "", errors.New("could not reload server")
+	}
+	return "server was reloaded\n", nil
+}
+
+func (s *OcservOcctlSocket) ShowIPBans() (*[]models.IPBanPoints, error) {
+	reply, err := s.call(ctlCmdListIPBans, ctlCmdListBannedRep, nil)
+	if s.fallback(err) {
+		return s.OcservOcctl.ShowIPBans()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bans, err := decodeBanListing(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]models.IPBanPoints, 0, len(bans))
+	for _, ban := range bans {
+		point := models.IPBanPoints{IP: addressString(ban.IP), Score: models.OcctlInt(ban.Score)}
+		if ban.Until > 0 {
+			until := time.Unix(ban.Until, 0)
+			point.Since = until.Format(occtlTimeLayout)
+			point.Until = humanDuration(time.Until(until))
+		}
+		points = append(points, point)
+	}
+	return &points, nil
+}
+
+func (s *OcservOcctlSocket) UnbanIP(ip string) (string, error) {
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("invalid IP: %s", ip)
+	}
+
+	reply, err := s.call(ctlCmdUnbanIP, ctlCmdUnbanIPRep, encodeUnbanReq(ip))
+	if s.fallback(err) {
+		return s.OcservOcctl.UnbanIP(ip)
+	}
+	if err != nil {
+		return "", err
+	}
+	if ok, err := decodeBoolMsg(reply); err != nil || !ok {
+		return "", fmt.Errorf("could not unban IP '%s'", ip)
+	}
+	return fmt.Sprintf("IP '%s' was unbanned\n", ip), nil
+}
+
+func (s *OcservOcctlSocket) ShowStatus() (*models.ServerStatus, error) {
+	reply, err := s.call(ctlCmdStatus, ctlCmdStatusRep, nil)
+	if s.fallback(err) {
+		return s.OcservOcctl.ShowStatus()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := decodeStatus(reply)
+	if err != nil {
+		return nil, err
+	}
+	return info.status(time.Now()), nil
+}
+
+// ShowIRoutes derives the iroutes listing from the connected users.
+func (s *OcservOcctlSocket) ShowIRoutes() (*[]models.IRoute, error) {
+	sessions, err := s.users(ctlCmdList, nil)
+	if s.fallback(err) {
+		return s.OcservOcctl.ShowIRoutes()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]models.IRoute, 0)
+	for _, session := range sessions {
+		if len(session.IRoutes) == 0 {
+			continue
+		}
+		routes = append(routes, models.IRoute{
+			ID:       session.ID,
+			Username: session.Username,
+			Vhost:    session.VHost,
+			Device:   session.Device,
+			IP:       session.IPv4,
+			IRoutes:  session.IRoutes,
+		})
+	}
+	return &routes, nil
+}
+
+func (u userInfo) session() models.OnlineUserSession {
+	connectedAt := time.Unix(u.ConnTime, 0)
+	elapsed := time.Since(connectedAt).Seconds()
+	if elapsed < 1 {
+		elapsed = 1
+	}
+
+	state := u.Status
+	if state == "" {
+		state = "connected"
+	}
+
+	return models.OnlineUserSession{
+		ID:                 models.OcctlID(u.ID),
+		Username:           u.Username,
+		Group:              u.Groupname,
+		State:              state,
+		VHost:              u.VHost,
+		Device:             u.Tun,
+		MTU:                models.OcctlInt(u.MTU),
+		RemoteIP:           u.RemoteIP,
+		Location:           "unknown",
+		LocalDeviceIP:      u.LocalDevIP,
+		IPv4:               u.IP,
+		PtPIPv4:            u.LocalIP,
+		IPv6:               u.IPv6,
+		PtPIPv6:            u.LocalIPv6,
+		UserAgent:          u.UserAgent,
+		Hostname:           u.Hostname,
+		RX:                 models.OcctlInt(u.BytesIn),
+		TX:                 models.OcctlInt(u.BytesOut),
+		RXHuman:            humanBytes(float64(u.BytesIn)),
+		TXHuman:            humanBytes(float64(u.BytesOut)),
+		AverageRX:          humanBytes(float64(u.BytesIn)/elapsed) + "/sec",
+		AverageTX:          humanBytes(float64(u.BytesOut)/elapsed) + "/sec",
+		DPD:                models.OcctlInt(u.DPD),
+		KeepAlive:          models.OcctlInt(u.KeepAlive),
+		ConnectedSince:     humanDuration(time.Since(connectedAt)),
+		RawConnectedAt:     models.OcctlInt(u.ConnTime),
+		ConnectedAt:        connectedAt,
+		FullSession:        u.FullSession,
+		Session:            u.SafeID,
+		TLSCiphersuite:     u.TLSCipher,
+		DTLSCipher:         u.DTLSCipher,
+		DNS:                u.DNS,
+		NBNS:               u.NBNS,
+		SplitDNSDomains:    u.SplitDNS,
+		Routes:             u.Routes,
+		NoRoutes:           u.NoRoutes,
+		IRoutes:            u.IRoutes,
+		RestrictedToRoutes: models.OcctlBool(u.Restricted),
+	}
+}
+
+func (s statusInfo) status(now time.Time) *models.ServerStatus {
+	state := "offline"
+	if s.Online {
+		state = "online"
+	}
+
+	startedAt := time.Unix(s.StartTime, 0)
+	resetAt := time.Unix(s.LastReset, 0)
+	rx, tx := s.KBytesIn*1000, s.KBytesOut*1000
+
+	return &models.ServerStatus{
+		Status:                   state,
+		ServerPID:                models.OcctlInt(s.PID),
+		SecModPID:                models.OcctlInt(s.SecModPID),
+		SecModInstanceCount:      models.OcctlInt(s.SecModInstances),
+		UpSince:                  startedAt.Format(occtlTimeLayout),
+		UpSinceDuration:          humanDuration(now.Sub(startedAt)),
+		RawUpSince:               models.OcctlInt(s.StartTime),
+		Uptime:                   models.OcctlInt(now.Sub(startedAt).Seconds()),
+		ActiveSessions:           models.OcctlInt(s.ActiveClients),
+		TotalSessions:            models.OcctlInt(s.TotalClosed),
+		TotalAuthFailures:        models.OcctlInt(s.TotalAuthFails),
+		IPsInBanList:             models.OcctlInt(s.BannedIPs),
+		LastStatsReset:           resetAt.Format(occtlTimeLayout),
+		LastStatsResetDuration:   humanDuration(now.Sub(resetAt)),
+		RawLastStatsReset:        models.OcctlInt(s.LastReset),
+		SessionsHandled:          models.OcctlInt(s.Closed),
+		TimedOutSessions:         models.OcctlInt(s.Timeouts),
+		TimedOutIdleSessions:     models.OcctlInt(s.IdleTimeouts),
+		ClosedDueToErrorSessions: models.OcctlInt(s.Errors),
+		AuthenticationFailures:   models.OcctlInt(s.AuthFailures),
+		AverageAuthTime:          humanDuration(time.Duration(s.AvgAuthTime) * time.Second),
+		RawAvgAuthTime:           models.OcctlInt(s.AvgAuthTime),
+		MaxAuthTime:              humanDuration(time.Duration(s.MaxAuthTime) * time.Second),
+		RawMaxAuthTime:           models.OcctlInt(s.MaxAuthTime),
+		AverageSessionTime:       humanDuration(time.Duration(s.AvgSessionMins) * time.Minute),
+		RawAvgSessionTime:        models.OcctlInt(s.AvgSessionMins * 60),
+		MaxSessionTime:           humanDuration(time.Duration(s.MaxSessionMins) * time.Minute),
+		RawMaxSessionTime:        models.OcctlInt(s.MaxSessionMins * 60),
+		RX:                       humanBytes(float64(rx)),
+		TX:                       humanBytes(float64(tx)),
+		RawRX:                    models.OcctlInt(rx),
+		RawTX:                    models.OcctlInt(tx),
+	}
+}
+
+// addressString formats binary IPv4/IPv6 addresses; textual ones are returned as is.
+func addressString(ip string) string {
+	if len(ip) == net.IPv4len || len(ip) == net.IPv6len {
+		if net.ParseIP(ip) == nil {
+			return net.IP(ip).String()
+		}
+	}
+	return ip
+}
+
+// humanBytes formats a byte count the way occtl prints it, e.g. "1.3 MB".
+func humanBytes(b float64) string {
+	if b < 1000 {
+		return fmt.Sprintf("%.0f bytes", b)
+	}
+	for _, unit := range []string{"KB", "MB", "GB", "TB"} {
+		b /= 1000
+		if b < 1000 || unit == "TB" {
+			return fmt.Sprintf("%.1f %s", b, unit)
+		}
+	}
+	return ""
+}
+
+// humanDuration formats a duration the way occtl prints it, e.g. "2h:15m" or "42s".
+func humanDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	secs := int64(d.Seconds())
+	switch {
+	case secs >= 86400:
+		return fmt.Sprintf("%dd:%02dh", secs/86400, (secs%86400)/3600)
+	case secs >= 3600:
+		return fmt.Sprintf("%dh:%02dm", secs/3600, (secs%3600)/60)
+	case secs >= 60:
+		return fmt.Sprintf("%dm:%02ds", secs/60, secs%60)
+	default:
+		return fmt.Sprintf("%ds", secs)
+	}
+}
+
+// usernames lists the username of every session, so users with several sessions appear more than once.
+func usernames(sessions []models.OnlineUserSession) []string {
+	users := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		if session.Username != "" {
+			users = append(users, session.Username)
+		}
+	}
+	return users
+}
@@ -0,0 +1,371 @@
+package occtl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeReply struct {
+	cmd     uint8
+	payload []byte
+}
+
+// fakeServer serves the occtl socket protocol with canned replies and records the requests.
+type fakeServer struct {
+	path     string
+	replies  map[uint8]fakeReply
+	requests chan fakeReply
+}
+
+func newFakeServer(t *testing.T, replies map[uint8]fakeReply) *fakeServer {
+	t.Helper()
+
+	srv := &fakeServer{
+		path:     filepath.Join(t.TempDir(), "occtl.socket"),
+		replies:  replies,
+		requests: make(chan fakeReply, 16),
+	}
+	ln, err := net.Listen("unix", srv.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.handle(conn)
+		}
+	}()
+	return srv
+}
+
+func (f *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	payload := make([]byte, binary.NativeEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return
+	}
+	f.requests <- fakeReply{cmd: header[0], payload: payload}
+
+	reply := f.replies[header[0]]
+	binary.NativeEndian.PutUint32(header[1:], uint32(len(reply.payload)))
+	header[0] = reply.cmd
+	_, _ = conn.Write(append(header, reply.payload...))
+}
+
+func boolMsg(ok bool) []byte {
+	v := uint64(0)
+	if ok {
+		v = 1
+	}
+	return appendVarint(nil, fieldBoolStatus, v)
+}
+
+func userInfoMsg(id int32, username, tun string, connTime int64, bytesIn, bytesOut uint64) []byte {
+	var b []byte
+	b = appendVarint(b, fieldUserID, protowire.EncodeZigZag(int64(id)))
+	b = appendString(b, fieldUserUsername, username)
+	b = appendString(b, fieldUserGroupname, "staff")
+	b = appendString(b, fieldUserIP, "192.168.100.12")
+	b = appendString(b, fieldUserTun, tun)
+	b = appendString(b, fieldUserRemoteIP, "203.0.113.7")
+	b = appendString(b, fieldUserLocalIP, "192.168.100.1")
+	b = appendVarint(b, fieldUserConnTime, uint64(connTime))
+	b = appendString(b, fieldUserAgent, "AnyConnect Windows 4.10.07062")
+	b = appendString(b, fieldUserDNS, "1.1.1.1")
+	b = appendString(b, fieldUserDNS, "8.8.8.8")
+	b = appendString(b, fieldUserIRoutes, "10.10.0.0/255.255.0.0")
+	b = appendVarint(b, fieldUserBytesIn, bytesIn)
+	b = appendVarint(b, fieldUserBytesOut, bytesOut)
+	b = appendVarint(b, fieldUserMTU, 1434)
+	// unknown fields are skipped
+	b = appendVarint(b, 99, 1)
+	return b
+}
+
+func TestSocket_OnlineSessions(t *testing.T) {
+	connTime := time.Now().Add(-time.Hour).Unix()
+	listing := appendMessage(nil, fieldUserListing, userInfoMsg(1375, "john", "vpns0", connTime, 1342213, 7723744))
+	listing = appendMessage(listing, fieldUserListing, userInfoMsg(1380, "jane", "vpns1", connTime, 0, 1024))
+
+	srv := newFakeServer(t, map[uint8]fakeReply{
+		ctlCmdList: {cmd: ctlCmdListRep, payload: listing},
+	})
+	client := NewOcservOcctlSocket(srv.path)
+
+	sessions, err := client.OnlineSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(*sessions))
+	}
+
+	john := (*sessions)[0]
+	if john.ID != 1375 || john.Username != "john" || john.Group != "staff" || john.Device != "vpns0" ||
+		john.RX != 1342213 || john.TX != 7723744 || john.MTU != 1434 || john.PtPIPv4 != "192.168.100.1" {
+		t.Errorf("unexpected session: %+v", john)
+	}
+	if !john.ConnectedAt.Equal(time.Unix(connTime, 0)) || john.ConnectedSince != "1h:00m" {
+		t.Errorf("unexpected connected at: %v %q", john.ConnectedAt, john.ConnectedSince)
+	}
+	if !reflect.DeepEqual([]string(john.DNS), []string{"1.1.1.1", "8.8.8.8"}) || john.RXHuman != "1.3 MB" {
+		t.Errorf("unexpected details: %v %q", john.DNS, john.RXHuman)
+	}
+
+	users, err := client.OnlineUsers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(users, []string{"john", "jane"}) {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	routes, err := client.ShowIRoutes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*routes) != 2 || (*routes)[0].Device != "vpns0" || len((*routes)[0].IRoutes) != 1 {
+		t.Errorf("unexpected routes: %+v", *routes)
+	}
+}
+
+// exchange reads the frames of a socket exchange dump in testdata, the ones sent
+// by occtl and the ones sent by ocserv.
+func exchange(t *testing.T, name string) (sent, received []fakeReply) {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var (
+		direction string
+		frames    = map[string]*bytes.Buffer{">": {}, "<": {}}
+		scanner   = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case line == ">" || line == "<":
+			direction = line
+		default:
+			b, err := hex.DecodeString(line)
+			require.NoError(t, err)
+			require.NotEmpty(t, direction, "frame without direction")
+			frames[direction].Write(b)
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	split := func(b []byte) []fakeReply {
+		var list []fakeReply
+		for len(b) > 0 {
+			require.GreaterOrEqual(t, len(b), 5, "truncated header")
+			length := int(binary.LittleEndian.Uint32(b[1:5]))
+			require.GreaterOrEqual(t, len(b), 5+length, "truncated payload")
+			list = append(list, fakeReply{cmd: b[0], payload: b[5 : 5+length]})
+			b = b[5+length:]
+		}
+		return list
+	}
+	return split(frames[">"].Bytes()), split(frames["<"].Bytes())
+}
+
+func TestSocket_ListExchange(t *testing.T) {
+	sent, received := exchange(t, "socket_list.hex")
+	require.Len(t, sent, 1)
+	require.Len(t, received, 1)
+
+	srv := newFakeServer(t, map[uint8]fakeReply{sent[0].cmd: received[0]})
+	sessions, err := NewOcservOcctlSocket(srv.path).OnlineSessions()
+	require.NoError(t, err)
+
+	req := <-srv.requests
+	assert.Equal(t, uint8(ctlCmdList), req.cmd)
+	assert.Equal(t, sent[0].cmd, req.cmd)
+	assert.Empty(t, req.payload)
+
+	require.Len(t, *sessions, 2)
+	john, jane := (*sessions)[0], (*sessions)[1]
+
+	assert.Equal(t, models.OcctlID(1375), john.ID)
+	assert.Equal(t, "john", john.Username)
+	assert.Equal(t, "staff", john.Group)
+	assert.Equal(t, "connected", john.State)
+	assert.Equal(t, "default", john.VHost)
+	assert.Equal(t, "vpns0", john.Device)
+	assert.Equal(t, "192.168.100.12", john.IPv4)
+	assert.Equal(t, "192.168.100.1", john.PtPIPv4)
+	assert.Equal(t, "198.51.100.7", john.RemoteIP)
+	assert.Equal(t, "203.0.113.1", john.LocalDeviceIP)
+	assert.Equal(t, "john-laptop", john.Hostname)
+	assert.Equal(t, "AnyConnect Windows 4.10.07062", john.UserAgent)
+	assert.Equal(t, "(DTLS1.2)-(ECDHE-RSA)-(AES-256-GCM)", john.DTLSCipher)
+	assert.Equal(t, models.OcctlInt(1342213), john.RX)
+	assert.Equal(t, models.OcctlInt(7723744), john.TX)
+	assert.Equal(t, models.OcctlInt(1434), john.MTU)
+	assert.Equal(t, models.OcctlInt(90), john.DPD)
+	assert.Equal(t, models.OcctlInt(32400), john.KeepAlive)
+	assert.Equal(t, time.Unix(1760850000, 0), john.ConnectedAt)
+	assert.Equal(t, "c4eNlKXxnDNBi2rWcsaxYyW5dxw=", john.Session)
+	assert.Equal(t, "Eq3FHh3Vb3oqV3nyO1nzjdsGjCs=", john.FullSession)
+	assert.Equal(t, []string{"8.8.8.8"}, []string(john.DNS))
+	assert.Equal(t, []string{"default"}, []string(john.Routes))
+	assert.False(t, bool(john.RestrictedToRoutes))
+
+	assert.Equal(t, models.OcctlID(1380), jane.ID)
+	assert.Empty(t, jane.Group)
+	assert.Empty(t, jane.DTLSCipher)
+	assert.Equal(t, models.OcctlInt(0), jane.RX)
+	assert.Equal(t, models.OcctlInt(1024), jane.TX)
+}
+
+func TestSocket_ShowUserByID(t *testing.T) {
+	listing := appendMessage(nil, fieldUserListing, userInfoMsg(-1, "john", "vpns0", time.Now().Unix(), 0, 0))
+	srv := newFakeServer(t, map[uint8]fakeReply{
+		ctlCmdIDInfo: {cmd: ctlCmdListRep, payload: listing},
+	})
+
+	session, err := NewOcservOcctlSocket(srv.path).ShowUserByID("-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.ID != -1 || session.Username != "john" {
+		t.Errorf("unexpected session: %+v", session)
+	}
+
+	req := <-srv.requests
+	if !reflect.DeepEqual(req.payload, encodeIDReq(-1)) {
+		t.Errorf("unexpected request payload: %x", req.payload)
+	}
+}
+
+func TestSocket_Commands(t *testing.T) {
+	srv := newFakeServer(t, map[uint8]fakeReply{
+		ctlCmdDisconnectName: {cmd: ctlCmdDisconnectNameRep, payload: boolMsg(true)},
+		ctlCmdDisconnectID:   {cmd: ctlCmdDisconnectIDRep, payload: boolMsg(true)},
+		ctlCmdReload:         {cmd: ctlCmdReloadRep, payload: boolMsg(true)},
+		ctlCmdUnbanIP:        {cmd: ctlCmdUnbanIPRep, payload: boolMsg(false)},
+	})
+	client := NewOcservOcctlSocket(srv.path)
+
+	if _, err := client.DisconnectUser("john"); err != nil {
+		t.Errorf("disconnect: %v", err)
+	}
+	if req := <-srv.requests; req.cmd != ctlCmdDisconnectName || !reflect.DeepEqual(req.payload, encodeUsernameReq("john")) {
+		t.Errorf("unexpected request: %+v", req)
+	}
+
+	if _, err := client.DisconnectID("1375"); err != nil {
+		t.Errorf("disconnect id: %v", err)
+	}
+	if req := <-srv.requests; req.cmd != ctlCmdDisconnectID || !reflect.DeepEqual(req.payload, encodeIDReq(1375)) {
+		t.Errorf("unexpected request: %+v", req)
+	}
+
+	if _, err := client.ReloadConfigs(); err != nil {
+		t.Errorf("reload: %v", err)
+	}
+	<-srv.requests
+
+	if _, err := client.UnbanIP("192.0.2.44"); err == nil {
+		t.Error("expected unban failure")
+	}
+	<-srv.requests
+
+	if _, err := client.UnbanIP("not-an-ip"); err == nil {
+		t.Error("expected invalid IP error")
+	}
+}
+
+func TestSocket_ShowIPBans(t *testing.T) {
+	ban := appendString(nil, fieldBanInfoIP, string(net.ParseIP("192.0.2.44").To4()))
+	ban = appendVarint(ban, fieldBanInfoScore, 20)
+	listing := appendMessage(nil, fieldBanListing, ban)
+
+	srv := newFakeServer(t, map[uint8]fakeReply{
+		ctlCmdListIPBans: {cmd: ctlCmdListBannedRep, payload: listing},
+	})
+
+	bans, err := NewOcservOcctlSocket(srv.path).ShowIPBans()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*bans) != 1 || (*bans)[0].IP != "192.0.2.44" || (*bans)[0].Score != 20 {
+		t.Errorf("unexpected bans: %+v", *bans)
+	}
+}
+
+func TestSocket_ShowStatus(t *testing.T) {
+	start := time.Now().Add(-2 * time.Hour).Unix()
+	var status []byte
+	status = appendVarint(status, fieldStatusOnline, 1)
+	status = appendVarint(status, fieldStatusPID, 1)
+	status = appendVarint(status, fieldStatusSecModPID, 25)
+	status = appendVarint(status, fieldStatusActiveClients, 2)
+	status = appendVarint(status, fieldStatusStartTime, uint64(start))
+	status = appendVarint(status, fieldStatusKBytesIn, 1500)
+	status = appendVarint(status, fieldStatusAvgSessionMins, 42)
+
+	srv := newFakeServer(t, map[uint8]fakeReply{
+		ctlCmdStatus: {cmd: ctlCmdStatusRep, payload: status},
+	})
+
+	result, err := NewOcservOcctlSocket(srv.path).ShowStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "online" || result.SecModPID != 25 || result.ActiveSessions != 2 ||
+		int64(result.RawUpSince) != start || result.RawRX != 1500000 || result.RawAvgSessionTime != 2520 {
+		t.Errorf("unexpected status: %+v", result)
+	}
+}
+
+func TestSocket_Errors(t *testing.T) {
+	srv := newFakeServer(t, map[uint8]fakeReply{
+		ctlCmdReload: {cmd: ctlCmdStatusRep, payload: boolMsg(true)},
+	})
+
+	if _, err := NewOcservOcctlSocket(srv.path).call(ctlCmdReload, ctlCmdReloadRep, nil); err == nil ||
+		errors.Is(err, errSocketUnavailable) {
+		t.Errorf("expected unexpected reply error, got %v", err)
+	}
+
+	missing := NewOcservOcctlSocket(filepath.Join(t.TempDir(), "missing.socket"))
+	_, err := missing.call(ctlCmdStatus, ctlCmdStatusRep, nil)
+	if !errors.Is(err, errSocketUnavailable) || !missing.fallback(err) {
+		t.Errorf("expected socket unavailable error, got %v", err)
+	}
+}
+
+func TestDecodeMessage_Malformed(t *testing.T) {
+	if _, err := decodeUserListing([]byte{0x0a, 0x05, 0x01}); !errors.Is(err, errMalformed) {
+		t.Errorf("expected malformed error, got %v", err)
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -14,6 +17,96 @@ type Config struct {
 	JWTSecret    string
 	AllowOrigins []string
 	DB           PostgresConfig
+	Ocserv       OcservConfig
+	Metrics      MetricsConfig
+	Occtl        OcctlConfig
+	GeoIP        GeoIPConfig
+	Webhook      WebhookConfig
+	Driver       DriverConfig
+	Agent        AgentConfig
+}
+
+// OcservConfig holds the public address clients use to reach ocserv
+// and the path of its server certificate.
+type OcservConfig struct {
+	Host     string
+	Port     string
+	CertPath string
+}
+
+// MetricsConfig controls the server resource sampler. Raw samples are rolled up
+// hourly and each resolution is kept for its own retention.
+type MetricsConfig struct {
+	Interval        time.Duration
+	RawRetention    time.Duration
+	HourlyRetention time.Duration
+}
+
+// OcctlConfig selects how ocserv is controlled: "exec" runs the occtl binary,
+// "socket" talks to the occtl unix socket of ocserv directly.
+type OcctlConfig struct {
+	Driver string
+	Socket string
+}
+
+// GeoIPConfig holds the paths of local MaxMind DBs: a GeoLite2 Country or City
+// database and an optional GeoLite2 ASN database. Each lookup is disabled when
+// its path is empty.
+type GeoIPConfig struct {
+	Database    string
+	ASNDatabase string
+}
+
+// defaultSecretKey is the SECRET_KEY used when it is not set.
+const defaultSecretKey = "SECRET_KEY122456"
+
+// WebhookConfig holds the internal webhook service of the ocserv container: the
+// address it listens on, loopback unless WEBHOOK_LISTEN is set, the URL other
+// containers call it at and the shared secret requests are signed with. The
+// secret is WEBHOOK_SECRET only, see CheckSecret.
+type WebhookConfig struct {
+	Listen string
+	URL    string
+	Secret string
+}
+
+// DriverConfig selects the ocserv backend driver: "local" runs the ocserv tools of
+// this host, "docker" calls the webhook service of the ocserv container, "ssh" the
+// webhook service of a remote host through an SSH tunnel and "fake" keeps users,
+// groups and sessions in memory. Services fall back to their -docker-mode flag when
+// Name is empty.
+type DriverConfig struct {
+	Name            string
+	DockerContainer string
+	DockerSocket    string
+	SSH             SSHConfig
+}
+
+// SSHConfig holds the remote host of the ssh driver. The host key must be listed in
+// KnownHostsFile, WebhookAddress is the webhook service as seen from the remote host.
+type SSHConfig struct {
+	Address        string
+	User           string
+	KeyFile        string
+	KnownHostsFile string
+	WebhookAddress string
+}
+
+// AgentConfig holds the node agent of an ocserv host: the address its API listens
+// on and the TLS certificate and key it is served with, the name and secret of its
+// server in the dashboard, the dashboard URL events are pushed to with the CA file
+// of its certificate when self-signed, and the file buffering up to BufferSize
+// events while the dashboard is unreachable.
+type AgentConfig struct {
+	Listen     string
+	TLSCert    string
+	TLSKey     string
+	ServerName string
+	Secret     string
+	PanelURL   string
+	PanelCA    string
+	BufferFile string
+	BufferSize int
 }
 
 type PostgresConfig struct {
@@ -28,11 +121,6 @@ type PostgresConfig struct {
 var cfg *Config
 
 func Init(debug bool, host string, port int) {
-	secretKey := os.Getenv("SECRET_KEY")
-	if secretKey == "" {
-		secretKey = "SECRET_KEY122456"
-	}
-
 	allowOrigins := os.Getenv("ALLOW_ORIGINS")
 	if allowOrigins == "" {
 		logger.Warn("Warning: ALLOW_ORIGINS environment variable not set")
@@ -48,10 +136,88 @@ func Init(debug bool, host string, port int) {
 		Debug:        debug,
 		Host:         host,
 		Port:         port,
-		SecretKey:    secretKey,
+		SecretKey:    loadSecretKeyEnv(),
 		JWTSecret:    jwtSecret,
 		AllowOrigins: strings.Split(allowOrigins, ","),
 		DB:           loadDatabaseEnv(),
+		Ocserv:       loadOcservEnv(),
+		Metrics:      loadMetricsEnv(),
+		Occtl:        loadOcctlEnv(),
+		GeoIP:        loadGeoIPEnv(),
+		Webhook:      loadWebhookEnv(),
+		Driver:       loadDriverEnv(),
+		Agent:        loadAgentEnv(),
+	}
+}
+
+func loadSecretKeyEnv() string {
+	return getEnv("SECRET_KEY", defaultSecretKey)
+}
+
+func loadMetricsEnv() MetricsConfig {
+	return MetricsConfig{
+		Interval:        time.Duration(getEnvInt("METRICS_INTERVAL_SECONDS", 60)) * time.Second,
+		RawRetention:    time.Duration(getEnvInt("METRICS_RAW_RETENTION_HOURS", 48)) * time.Hour,
+		HourlyRetention: time.Duration(getEnvInt("METRICS_HOURLY_RETENTION_DAYS", 90)) * 24 * time.Hour,
+	}
+}
+
+func loadOcctlEnv() OcctlConfig {
+	return OcctlConfig{
+		Driver: getEnv("OCCTL_DRIVER", "exec"),
+		Socket: getEnv("OCCTL_SOCKET", "/var/run/occtl.socket"),
+	}
+}
+
+func loadGeoIPEnv() GeoIPConfig {
+	return GeoIPConfig{
+		Database:    getEnv("GEOIP_DATABASE", ""),
+		ASNDatabase: getEnv("GEOIP_ASN_DATABASE", ""),
+	}
+}
+
+func loadWebhookEnv() WebhookConfig {
+	return WebhookConfig{
+		Listen: getEnv("WEBHOOK_LISTEN", "127.0.0.1:8888"),
+		URL:    getEnv("WEBHOOK_URL", "http://ocserv:8888"),
+		Secret: os.Getenv("WEBHOOK_SECRET"),
+	}
+}
+
+func loadDriverEnv() DriverConfig {
+	return DriverConfig{
+		Name:            getEnv("OCSERV_DRIVER", ""),
+		DockerContainer: getEnv("OCSERV_DOCKER_CONTAINER", "ocserv"),
+		DockerSocket:    getEnv("OCSERV_DOCKER_SOCKET", "/var/run/docker.sock"),
+		SSH: SSHConfig{
+			Address:        getEnv("OCSERV_SSH_ADDRESS", ""),
+			User:           getEnv("OCSERV_SSH_USER", "root"),
+			KeyFile:        getEnv("OCSERV_SSH_KEY_FILE", ""),
+			KnownHostsFile: getEnv("OCSERV_SSH_KNOWN_HOSTS", ""),
+			WebhookAddress: getEnv("OCSERV_SSH_WEBHOOK_ADDRESS", "127.0.0.1:8888"),
+		},
+	}
+}
+
+func loadAgentEnv() AgentConfig {
+	return AgentConfig{
+		Listen:     getEnv("AGENT_LISTEN", "0.0.0.0:8890"),
+		TLSCert:    getEnv("AGENT_TLS_CERT", ""),
+		TLSKey:     getEnv("AGENT_TLS_KEY", ""),
+		ServerName: getEnv("AGENT_SERVER_NAME", ""),
+		Secret:     getEnv("AGENT_SECRET", ""),
+		PanelURL:   getEnv("AGENT_PANEL_URL", ""),
+		PanelCA:    getEnv("AGENT_PANEL_CA", ""),
+		BufferFile: getEnv("AGENT_BUFFER_FILE", "/var/lib/ocserv-agent/events.jsonl"),
+		BufferSize: getEnvInt("AGENT_BUFFER_SIZE", 100000),
+	}
+}
+
+func loadOcservEnv() OcservConfig {
+	return OcservConfig{
+		Host:     getEnv("HOST", "127.0.0.1"),
+		Port:     getEnv("OCSERV_PORT", "443"),
+		CertPath: getEnv("OCSERV_CERT_PATH", "/etc/ocserv/certs/cert.pem"),
 	}
 }
 
@@ -77,6 +243,68 @@ func Get() *Config {
 	return cfg
 }
 
+// CheckSecret returns why the secret cannot authenticate webhook calls: it is not
+// set, or it is the default or the actual SECRET_KEY, which a webhook caller would
+// learn along with the secret.
+func (w WebhookConfig) CheckSecret() error {
+	switch w.Secret {
+	case "":
+		return errors.New("WEBHOOK_SECRET is required to authenticate webhook calls")
+	case defaultSecretKey:
+		return errors.New("WEBHOOK_SECRET must not be the default secret key")
+	case os.Getenv("SECRET_KEY"):
+		return errors.New("WEBHOOK_SECRET must differ from SECRET_KEY")
+	}
+	return nil
+}
+
+// Webhook returns the webhook settings, read from the environment in services
+// like the webhook itself that do not Init the whole config.
+func Webhook() WebhookConfig {
+	if cfg != nil {
+		return cfg.Webhook
+	}
+	return loadWebhookEnv()
+}
+
+// SecretKey returns SECRET_KEY, the key the secrets stored by the dashboard are
+// encrypted with, read from the environment in services that do not Init the
+// whole config.
+func SecretKey() string {
+	if cfg != nil {
+		return cfg.SecretKey
+	}
+	return loadSecretKeyEnv()
+}
+
+// Driver returns the ocserv driver settings, read from the environment in services
+// that do not Init the whole config.
+func Driver() DriverConfig {
+	if cfg != nil {
+		return cfg.Driver
+	}
+	return loadDriverEnv()
+}
+
+// Agent returns the node agent settings, read from the environment in the agent
+// that does not Init the whole config.
+func Agent() AgentConfig {
+	if cfg != nil {
+		return cfg.Agent
+	}
+	return loadAgentEnv()
+}
+
+func getEnvInt(key string, fallback int) int {
+	if v := os.Getenv(key); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i > 0 {
+			return i
+		}
+		logger.Warn("Warning: invalid %s value %q, default value %d used", key, v, fallback)
+	}
+	return fallback
+}
+
 func getEnv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -0,0 +1,83 @@
+// Package dbtest opens the Postgres test database for the tests of the services.
+package dbtest
+
+import (
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"strings"
+	"testing"
+)
+
+// Open returns a connection to the Postgres database of the POSTGRES_* environment,
+// the one common/tests uses, on a schema of its own holding the tables of models.
+// The schema is dropped when the test ends. The connection is also returned by
+// database.GetConnection meanwhile, for the code under test using it.
+//
+// The test is skipped when the database is unreachable.
+func Open(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	if config.Get() == nil {
+		config.Init(false, "", 0)
+	}
+	cfg := config.Get().DB
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=UTC connect_timeout=3",
+		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode,
+	)
+
+	admin, err := open(dsn)
+	if err != nil {
+		t.Skipf("Postgres test database %s:%s/%s unreachable: %v", cfg.Host, cfg.Port, cfg.DBName, err)
+	}
+	schema := "test_" + strings.ToLower(ulid.Make().String())
+	if err = admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("creating schema %s: %v", schema, err)
+	}
+
+	db, err := open(dsn + " search_path=" + schema)
+	if err != nil {
+		t.Fatalf("connecting to schema %s: %v", schema, err)
+	}
+
+	previous := database.PostgresDB
+	database.PostgresDB = db
+	t.Cleanup(func() {
+		database.PostgresDB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("dropping schema %s: %v", schema, err)
+		}
+		if sqlDB, err := admin.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrating the test schema: %v", err)
+	}
+	return db
+}
+
+func open(dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err = sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
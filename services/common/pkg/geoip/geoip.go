@@ -0,0 +1,220 @@
+package geoip
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/oschwald/maxminddb-golang"
+	"math"
+	"net"
+	"sync"
+)
+
+// Record is the location and network of an IP address. Fields missing from the
+// database, e.g. City on a Country database or ASN on a City database, are left empty.
+type Record struct {
+	CountryCode     string  `json:"country_code"`
+	Country         string  `json:"country"`
+	City            string  `json:"city,omitempty"`
+	Latitude        float64 `json:"latitude,omitempty"`
+	Longitude       float64 `json:"longitude,omitempty"`
+	ASN             uint    `json:"asn,omitempty"`
+	ASNOrganization string  `json:"asn_organization,omitempty"`
+}
+
+// Reader looks up IP addresses in a MaxMind DB (.mmdb) file.
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+// Open opens a MaxMind DB file.
+func Open(path string) (*Reader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{db: db}, nil
+}
+
+// FromBytes opens a MaxMind DB held in memory.
+func FromBytes(buf []byte) (*Reader, error) {
+	db, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{db: db}, nil
+}
+
+// DatabaseType returns the database_type of the metadata, e.g. "GeoLite2-Country".
+func (r *Reader) DatabaseType() string {
+	return r.db.Metadata.DatabaseType
+}
+
+// Lookup returns the record of ip, or nil when it is not in the database.
+func (r *Reader) Lookup(ip net.IP) (*Record, error) {
+	if ip.To4() == nil && r.db.Metadata.IPVersion == 4 {
+		return nil, nil
+	}
+
+	var fields mmdbRecord
+	_, ok, err := r.db.LookupNetwork(ip, &fields)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	record := &Record{}
+	record.merge(&fields)
+	return record, nil
+}
+
+type mmdbName struct {
+	Names map[string]string `maxminddb:"names"`
+}
+
+type mmdbCountry struct {
+	ISOCode string            `maxminddb:"iso_code"`
+	Names   map[string]string `maxminddb:"names"`
+}
+
+// mmdbRecord holds the fields of the Country, City and ASN databases the dashboard uses.
+type mmdbRecord struct {
+	Country           mmdbCountry `maxminddb:"country"`
+	RegisteredCountry mmdbCountry `maxminddb:"registered_country"`
+	City              mmdbName    `maxminddb:"city"`
+	Location          struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+	ASN             uint   `maxminddb:"autonomous_system_number"`
+	ASNOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+func (r *Record) merge(fields *mmdbRecord) {
+	country := fields.Country
+	if country.ISOCode == "" {
+		// anonymous proxies and satellite providers only have a registered country
+		country = fields.RegisteredCountry
+	}
+	if country.ISOCode != "" {
+		r.CountryCode = country.ISOCode
+		r.Country = country.Names["en"]
+	}
+
+	if city := fields.City.Names["en"]; city != "" {
+		r.City = city
+	}
+	if fields.Location.Latitude != 0 || fields.Location.Longitude != 0 {
+		r.Latitude = fields.Location.Latitude
+		r.Longitude = fields.Location.Longitude
+	}
+
+	if fields.ASN > 0 {
+		r.ASN = fields.ASN
+		r.ASNOrganization = fields.ASNOrganization
+	}
+}
+
+// add fills the fields of r missing from other, e.g. the ASN of a City record.
+func (r *Record) add(other *Record) {
+	if r.CountryCode == "" {
+		r.CountryCode, r.Country = other.CountryCode, other.Country
+	}
+	if r.City == "" {
+		r.City = other.City
+	}
+	if !r.HasLocation() {
+		r.Latitude, r.Longitude = other.Latitude, other.Longitude
+	}
+	if r.ASN == 0 {
+		r.ASN, r.ASNOrganization = other.ASN, other.ASNOrganization
+	}
+}
+
+// HasLocation reports whether the record has coordinates, which only City databases provide.
+func (r *Record) HasLocation() bool {
+	return r.Latitude != 0 || r.Longitude != 0
+}
+
+// DistanceKm returns the great-circle distance between two records with coordinates.
+func DistanceKm(a, b *Record) float64 {
+	const earthRadiusKm = 6371.0
+
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+var (
+	defaultOnce sync.Once
+	locationDB  *Reader
+	asnDB       *Reader
+)
+
+func openDefault(path string) *Reader {
+	if path == "" {
+		return nil
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		logger.Warn("GeoIP database %s disabled: %v", path, err)
+		return nil
+	}
+	logger.Info("GeoIP database %s (%s) loaded", path, reader.DatabaseType())
+	return reader
+}
+
+// Enabled reports whether at least one of the configured databases could be opened.
+func Enabled() bool {
+	defaultOnce.Do(func() {
+		cfg := config.Get()
+		if cfg == nil {
+			return
+		}
+		locationDB = openDefault(cfg.GeoIP.Database)
+		asnDB = openDefault(cfg.GeoIP.ASNDatabase)
+	})
+	return locationDB != nil || asnDB != nil
+}
+
+// Lookup returns the location and network of ip from the configured databases,
+// or nil when it is unknown or GeoIP is disabled.
+func Lookup(ip string) *Record {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || !Enabled() {
+		return nil
+	}
+
+	var record *Record
+	for _, reader := range []*Reader{locationDB, asnDB} {
+		if reader == nil {
+			continue
+		}
+
+		found, err := reader.Lookup(parsed)
+		if err != nil {
+			logger.Warn("GeoIP lookup of %s failed: %v", ip, err)
+			continue
+		}
+		if found == nil {
+			continue
+		}
+		if record == nil {
+			record = found
+			continue
+		}
+		record.add(found)
+	}
+	return record
+}
+
+// Country returns the ISO country code of ip using the configured databases, or
+// an empty string when it is unknown or GeoIP is disabled.
+func Country(ip string) string {
+	if record := Lookup(ip); record != nil {
+		return record.CountryCode
+	}
+	return ""
+}
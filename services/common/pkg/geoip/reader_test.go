@@ -0,0 +1,271 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"sort"
+	"testing"
+)
+
+// metadataMarker precedes the metadata map at the end of a MaxMind DB file.
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSectionSeparator is the size of the zero block between the search tree and the data section.
+const dataSectionSeparator = 16
+
+// Data section types.
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// encode writes v in the MaxMind DB data section format.
+func encode(v any) []byte {
+	header := func(kind int, size int) []byte {
+		var extra []byte
+		if size >= 29 {
+			// sizes up to 284 use one extra byte
+			extra = []byte{byte(size - 29)}
+			size = 29
+		}
+		out := []byte{byte(size)}
+		if kind < 8 {
+			out[0] |= byte(kind << 5)
+		} else {
+			out = append(out, byte(kind-7))
+		}
+		return append(out, extra...)
+	}
+
+	switch val := v.(type) {
+	case string:
+		return append(header(typeString, len(val)), val...)
+	case uint32:
+		b := binary.BigEndian.AppendUint32(nil, val)
+		return append(header(typeUint32, 4), b...)
+	case float64:
+		b := binary.BigEndian.AppendUint64(nil, math.Float64bits(val))
+		return append(header(typeDouble, 8), b...)
+	case bool:
+		size := 0
+		if val {
+			size = 1
+		}
+		return header(typeBool, size)
+	case []any:
+		out := header(typeArray, len(val))
+		for _, item := range val {
+			out = append(out, encode(item)...)
+		}
+		return out
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		out := header(typeMap, len(val))
+		for _, key := range keys {
+			out = append(out, encode(key)...)
+			out = append(out, encode(val[key])...)
+		}
+		return out
+	}
+	panic("unsupported value")
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	data     int // offset + 1 in the data section when the node is a network
+}
+
+// buildDatabase writes an IPv4 database with 24 bit records mapping each network to its record.
+func buildDatabase(t *testing.T, networks map[string]map[string]any) []byte {
+	t.Helper()
+
+	var (
+		root = &trieNode{}
+		data []byte
+	)
+	for cidr, record := range networks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ones, _ := network.Mask.Size()
+
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := (network.IP.To4()[i/8] >> (7 - uint(i%8))) & 1
+			if node.children[bit] == nil {
+				node.children[bit] = &trieNode{}
+			}
+			node = node.children[bit]
+		}
+		node.data = len(data) + 1
+		data = append(data, encode(record)...)
+	}
+
+	// number the inner nodes breadth first
+	var nodes []*trieNode
+	index := map[*trieNode]int{}
+	queue := []*trieNode{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		index[node] = len(nodes)
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			if child != nil && child.data == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	nodeCount := len(nodes)
+	var tree []byte
+	for _, node := range nodes {
+		for _, child := range node.children {
+			record := nodeCount
+			switch {
+			case child == nil:
+			case child.data > 0:
+				record = nodeCount + dataSectionSeparator + child.data - 1
+			default:
+				record = index[child]
+			}
+			tree = append(tree, byte(record>>16), byte(record>>8), byte(record))
+		}
+	}
+
+	out := append(tree, make([]byte, dataSectionSeparator)...)
+	out = append(out, data...)
+	out = append(out, metadataMarker...)
+	return append(out, encode(map[string]any{
+		"binary_format_major_version": uint32(2),
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint32(24),
+		"ip_version":                  uint32(4),
+		"database_type":               "GeoLite2-City",
+	})...)
+}
+
+func TestReader_Lookup(t *testing.T) {
+	db := buildDatabase(t, map[string]map[string]any{
+		"203.0.113.0/24": {
+			"country":  map[string]any{"iso_code": "US", "names": map[string]any{"en": "United States", "de": "USA"}},
+			"city":     map[string]any{"names": map[string]any{"en": "Chicago"}},
+			"location": map[string]any{"latitude": 41.8, "longitude": -87.6},
+		},
+		"198.51.100.0/25": {
+			"registered_country": map[string]any{"iso_code": "DE", "names": map[string]any{"en": "Germany"}},
+			"traits":             map[string]any{"is_anonymous_proxy": true},
+			"subdivisions":       []any{map[string]any{"iso_code": "BE"}},
+		},
+	})
+
+	reader, err := FromBytes(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.DatabaseType() != "GeoLite2-City" {
+		t.Errorf("unexpected database type %q", reader.DatabaseType())
+	}
+
+	record, err := reader.Lookup(net.ParseIP("203.0.113.7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.CountryCode != "US" || record.Country != "United States" || record.City != "Chicago" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+	if !record.HasLocation() || record.Latitude != 41.8 || record.Longitude != -87.6 {
+		t.Errorf("unexpected location: %v %v", record.Latitude, record.Longitude)
+	}
+
+	record, err = reader.Lookup(net.ParseIP("198.51.100.100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.CountryCode != "DE" || record.City != "" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+
+	for _, ip := range []string{"198.51.100.200", "192.0.2.1", "2001:db8::1"} {
+		if record, err = reader.Lookup(net.ParseIP(ip)); err != nil || record != nil {
+			t.Errorf("expected no record for %s, got %+v %v", ip, record, err)
+		}
+	}
+}
+
+func TestReader_LookupASN(t *testing.T) {
+	db := buildDatabase(t, map[string]map[string]any{
+		"203.0.113.0/24": {
+			"autonomous_system_number":       uint32(64500),
+			"autonomous_system_organization": "Example Networks",
+		},
+	})
+
+	reader, err := FromBytes(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := reader.Lookup(net.ParseIP("203.0.113.7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.ASN != 64500 || record.ASNOrganization != "Example Networks" || record.CountryCode != "" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	chicago := &Record{Latitude: 41.88, Longitude: -87.63}
+	berlin := &Record{Latitude: 52.52, Longitude: 13.40}
+
+	if d := DistanceKm(chicago, berlin); d < 7000 || d > 7100 {
+		t.Errorf("unexpected distance Chicago-Berlin: %.0f km", d)
+	}
+	if d := DistanceKm(berlin, berlin); d != 0 {
+		t.Errorf("expected zero distance, got %f", d)
+	}
+}
+
+func TestReader_DeeplyNested(t *testing.T) {
+	nested := map[string]any{"iso_code": "US"}
+	for i := 0; i < 10000; i++ {
+		nested = map[string]any{"country": nested}
+	}
+	reader, err := FromBytes(buildDatabase(t, map[string]map[string]any{"203.0.113.0/24": nested}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the nested maps are skipped, not decoded recursively
+	record, err := reader.Lookup(net.ParseIP("203.0.113.7"))
+	if err != nil || record == nil || record.CountryCode != "" {
+		t.Errorf("unexpected record: %+v %v", record, err)
+	}
+}
+
+func TestFromBytes_Invalid(t *testing.T) {
+	if _, err := FromBytes([]byte("not a database")); err == nil {
+		t.Error("expected invalid database error")
+	}
+}
@@ -0,0 +1,180 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
+	"gorm.io/gorm"
+	"os"
+	"sync"
+	"time"
+)
+
+// LeaseTTL is how long a lease outlives its last renewal, the leader renews it
+// every LeaseTTL/3.
+const LeaseTTL = 15 * time.Second
+
+// acquireQuery takes the lease when it is free, expired or already held by the
+// holder. Expiry is checked against the clock of Postgres, so the clocks of the
+// replicas do not need to agree.
+const acquireQuery = `
+	INSERT INTO leader_leases (name, holder, expires_at, updated_at)
+	VALUES (?, ?, NOW() + ? * INTERVAL '1 millisecond', NOW())
+	ON CONFLICT (name) DO UPDATE
+	SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at, updated_at = EXCLUDED.updated_at
+	WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at < NOW()`
+
+// Elector campaigns for a named lease with the other replicas of a service, the
+// replica holding it is the leader.
+type Elector struct {
+	db     *gorm.DB
+	name   string
+	holder string
+
+	mu      sync.Mutex
+	until   time.Time
+	term    context.Context
+	endTerm context.CancelFunc
+	expiry  *time.Timer
+}
+
+// New returns an elector for the lease name, identified by the host name and a
+// unique suffix in the lease table.
+func New(db *gorm.DB, name string) *Elector {
+	host, _ := os.Hostname()
+	if len(host) > 64 {
+		host = host[:64]
+	}
+	return &Elector{
+		db:     db,
+		name:   name,
+		holder: fmt.Sprintf("%s/%s", host, requestid.New()),
+	}
+}
+
+// IsLeader reports whether the lease is held. A leader that cannot renew it steps
+// down when its last renewal expires, before another replica can take over.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return time.Now().Before(e.until)
+}
+
+// Term returns the context of the current term of this replica as the leader, it
+// is canceled when the replica steps down. ok is false when it is not the leader.
+func (e *Elector) Term() (ctx context.Context, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.term == nil || !time.Now().Before(e.until) {
+		return nil, false
+	}
+	return e.term, true
+}
+
+// Run campaigns for the lease until ctx is done, then releases it. onElected runs
+// in its own goroutine every time this replica becomes the leader, with the
+// context of the term.
+func (e *Elector) Run(ctx context.Context, onElected func(ctx context.Context)) {
+	ticker := time.NewTicker(LeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx, onElected)
+
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign acquires or renews the lease once.
+func (e *Elector) campaign(ctx context.Context, onElected func(ctx context.Context)) {
+	start := time.Now()
+
+	result := e.db.WithContext(ctx).Exec(acquireQuery, e.name, e.holder, LeaseTTL.Milliseconds())
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			logger.Warn("Failed to renew %s leader lease: %v", e.name, result.Error)
+		}
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// the term ends when the lease expires, even while a renewal is in flight
+	wasLeader := e.term != nil && e.term.Err() == nil
+
+	switch {
+	case result.RowsAffected == 1 && !wasLeader:
+		e.until = start.Add(LeaseTTL)
+		e.startTerm(ctx)
+		logger.Info("Elected %s leader as %s", e.name, e.holder)
+		if onElected != nil {
+			go onElected(e.term)
+		}
+	case result.RowsAffected == 1:
+		e.until = start.Add(LeaseTTL)
+		e.expiry.Reset(time.Until(e.until))
+	default:
+		e.until = time.Time{}
+		e.stopTerm()
+		if wasLeader {
+			logger.Warn("Lost %s leadership, another replica holds the lease", e.name)
+		}
+	}
+}
+
+// startTerm starts a term ending when the lease expires without renewal, the
+// caller holds e.mu.
+func (e *Elector) startTerm(ctx context.Context) {
+	e.stopTerm()
+
+	term, endTerm := context.WithCancel(ctx)
+	e.term, e.endTerm = term, endTerm
+	e.expiry = time.AfterFunc(time.Until(e.until), func() {
+		if term.Err() == nil {
+			logger.Warn("Stepping down as %s leader, the lease expired without renewal", e.name)
+		}
+		endTerm()
+	})
+}
+
+// stopTerm cancels the context of the current term, the caller holds e.mu.
+func (e *Elector) stopTerm() {
+	if e.term == nil {
+		return
+	}
+	e.expiry.Stop()
+	e.endTerm()
+	e.term, e.endTerm, e.expiry = nil, nil, nil
+}
+
+// release gives up the lease so another replica takes over without waiting for
+// it to expire.
+func (e *Elector) release() {
+	if !e.IsLeader() {
+		return
+	}
+
+	e.mu.Lock()
+	e.until = time.Time{}
+	e.stopTerm()
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := e.db.WithContext(ctx).
+		Exec("DELETE FROM leader_leases WHERE name = ? AND holder = ?", e.name, e.holder).Error
+	if err != nil {
+		logger.Warn("Failed to release %s leader lease: %v", e.name, err)
+		return
+	}
+	logger.Info("Released %s leader lease", e.name)
+}
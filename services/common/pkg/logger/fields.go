@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Entry is a logger with fields attached to every message, e.g.
+//
+//	logger.With("username", username, "ip", ip).Warn("user locked")
+type Entry struct {
+	fields Fields
+}
+
+var root = &Entry{}
+
+// With returns an entry with the given key/value pairs. A key without a value is
+// kept with a nil value.
+func With(keyvals ...interface{}) *Entry {
+	return root.With(keyvals...)
+}
+
+// With returns a copy of the entry with the given key/value pairs added.
+func (e *Entry) With(keyvals ...interface{}) *Entry {
+	fields := make(Fields, len(e.fields)+len(keyvals)/2)
+	for key, value := range e.fields {
+		fields[key] = value
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 < len(keyvals) {
+			fields[key] = keyvals[i+1]
+		} else {
+			fields[key] = nil
+		}
+	}
+	return &Entry{fields: fields}
+}
+
+// WithFields returns a copy of the entry with fields added.
+func (e *Entry) WithFields(fields Fields) *Entry {
+	keyvals := make([]interface{}, 0, len(fields)*2)
+	for key, value := range fields {
+		keyvals = append(keyvals, key, value)
+	}
+	return e.With(keyvals...)
+}
+
+// Fields returns the fields of the entry.
+func (e *Entry) Fields() Fields {
+	return e.fields
+}
+
+func (e *Entry) Debug(format string, args ...interface{}) {
+	// skip formatting, debug is disabled by default
+	if Log == nil || !Log.enabled(DebugLevel) {
+		return
+	}
+	send(DebugLevel, SafeSprintf(format, args...), e.fields)
+}
+
+func (e *Entry) Info(format string, args ...interface{}) {
+	send(InfoLevel, SafeSprintf(format, args...), e.fields)
+}
+
+func (e *Entry) Warn(format string, args ...interface{}) {
+	send(WarnLevel, SafeSprintf(format, args...), e.fields)
+}
+
+func (e *Entry) Error(format string, args ...interface{}) {
+	send(ErrorLevel, SafeSprintf(format, args...), e.fields)
+}
+
+// Fatal writes the message synchronously, bypassing the buffer, and exits.
+func (e *Entry) Fatal(format string, args ...interface{}) {
+	msg := LogMessage{
+		Level:   FatalLevel,
+		Message: SafeSprintf(format, args...),
+		Time:    time.Now(),
+		Fields:  e.fields,
+	}
+
+	if Log == nil {
+		_, _ = os.Stderr.Write(encode(FormatText, "", msg, false))
+	} else {
+		Log.write(os.Stderr, msg, false)
+		if Log.file != nil {
+			Log.write(Log.file, msg, false)
+		}
+	}
+	os.Exit(1)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the fields of its entry plus keyvals,
+// for FromContext to log them, e.g. the request ID of an API request.
+func NewContext(ctx context.Context, keyvals ...interface{}) context.Context {
+	return context.WithValue(ctx, contextKey{}, FromContext(ctx).With(keyvals...))
+}
+
+// FromContext returns the entry carried by ctx, or one without fields.
+func FromContext(ctx context.Context) *Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(contextKey{}).(*Entry); ok {
+			return entry
+		}
+	}
+	return root
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encode renders msg as one line of format. Fields are sorted by key so that
+// lines of the same message are identical apart from their values.
+func encode(format, service string, msg LogMessage, color bool) []byte {
+	var buf bytes.Buffer
+
+	switch format {
+	case FormatJSON:
+		buf.WriteString(`{"time":`)
+		writeJSON(&buf, msg.Time.Format(time.RFC3339Nano))
+		buf.WriteString(`,"level":`)
+		writeJSON(&buf, levelName(msg.Level))
+		if service != "" {
+			buf.WriteString(`,"service":`)
+			writeJSON(&buf, service)
+		}
+		buf.WriteString(`,"msg":`)
+		writeJSON(&buf, msg.Message)
+		for _, key := range sortedKeys(msg.Fields) {
+			buf.WriteByte(',')
+			writeJSON(&buf, key)
+			buf.WriteByte(':')
+			writeJSON(&buf, fieldValue(msg.Fields[key]))
+		}
+		buf.WriteString("}\n")
+
+	case FormatLogfmt:
+		buf.WriteString("time=")
+		buf.WriteString(msg.Time.Format(time.RFC3339Nano))
+		buf.WriteString(" level=")
+		buf.WriteString(levelName(msg.Level))
+		if service != "" {
+			buf.WriteString(" service=")
+			writeLogfmt(&buf, service)
+		}
+		buf.WriteString(" msg=")
+		writeLogfmt(&buf, msg.Message)
+		writeLogfmtFields(&buf, msg.Fields)
+		buf.WriteByte('\n')
+
+	default:
+		if color {
+			buf.WriteString(LevelColors[msg.Level])
+		}
+		fmt.Fprintf(&buf, "[%s] [%s] %s", msg.Time.Format("2006-01-02 15:04:05"), msg.Level, msg.Message)
+		writeLogfmtFields(&buf, msg.Fields)
+		if color {
+			buf.WriteString(ColorReset)
+		}
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+func levelName(level LogLevel) string {
+	if level == WarnLevel {
+		return "warn"
+	}
+	return strings.ToLower(string(level))
+}
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldValue keeps errors and stringers readable instead of encoding their struct fields.
+func fieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case time.Duration:
+		return v.String()
+	default:
+		return value
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, value interface{}) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(b)
+}
+
+func writeLogfmtFields(buf *bytes.Buffer, fields Fields) {
+	for _, key := range sortedKeys(fields) {
+		buf.WriteByte(' ')
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		writeLogfmt(buf, fmt.Sprint(fieldValue(fields[key])))
+	}
+}
+
+func writeLogfmt(buf *bytes.Buffer, value string) {
+	if value == "" || strings.ContainsAny(value, " =\"\t\r\n") {
+		buf.WriteString(strconv.Quote(value))
+		return
+	}
+	buf.WriteString(value)
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMessage = LogMessage{
+	Level:   WarnLevel,
+	Message: "user locked",
+	Time:    time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC),
+	Fields:  Fields{"username": "john", "reason": "traffic limit", "err": errors.New("boom")},
+}
+
+func TestEncode_JSON(t *testing.T) {
+	line := encode(FormatJSON, "api", testMessage, false)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatalf("invalid json %q: %v", line, err)
+	}
+	want := map[string]interface{}{
+		"time":     "2025-01-31T10:00:00Z",
+		"level":    "warn",
+		"service":  "api",
+		"msg":      "user locked",
+		"username": "john",
+		"reason":   "traffic limit",
+		"err":      "boom",
+	}
+	for key, value := range want {
+		if decoded[key] != value {
+			t.Errorf("%s: got %v, want %v", key, decoded[key], value)
+		}
+	}
+}
+
+func TestEncode_Logfmt(t *testing.T) {
+	got := string(encode(FormatLogfmt, "api", testMessage, false))
+	want := `time=2025-01-31T10:00:00Z level=warn service=api msg="user locked" err=boom reason="traffic limit" username=john` + "\n"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestEncode_Text(t *testing.T) {
+	got := string(encode(FormatText, "api", testMessage, false))
+	want := `[2025-01-31 10:00:00] [WARNING] user locked err=boom reason="traffic limit" username=john` + "\n"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestEntry_With(t *testing.T) {
+	base := With("request_id", "abc")
+	entry := base.With("username", "john", "dangling")
+
+	if len(base.Fields()) != 1 {
+		t.Errorf("With modified its receiver: %v", base.Fields())
+	}
+	fields := entry.Fields()
+	if fields["request_id"] != "abc" || fields["username"] != "john" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if value, ok := fields["dangling"]; !ok || value != nil {
+		t.Errorf("expected dangling key with nil value: %v", fields)
+	}
+
+	ctx := NewContext(NewContext(t.Context(), "request_id", "abc"), "username", "john")
+	if fields = FromContext(ctx).Fields(); fields["request_id"] != "abc" || fields["username"] != "john" {
+		t.Errorf("unexpected context fields: %v", fields)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, want := range map[string]LogLevel{"debug": DebugLevel, "INFO": InfoLevel, "warn": WarnLevel, "Warning": WarnLevel, "error": ErrorLevel} {
+		if got, err := ParseLevel(input); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v", input, got, err)
+		}
+	}
+	if _, err := ParseLevel("fatal"); err == nil {
+		t.Error("expected error for fatal")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	file, err := openRotatingFile(path, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	line := []byte(strings.Repeat("x", 1<<19-1) + "\n") // half a MiB
+	for i := 0; i < 7; i++ {
+		if _, err = file.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		if info.Size() > 1<<20 {
+			t.Errorf("%s exceeds the max size: %d", name, info.Size())
+		}
+	}
+	if _, err = os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 backups, got %v", err)
+	}
+}
@@ -3,31 +3,168 @@ package logger
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"sync/atomic"
+	"strconv"
+	"strings"
 	"time"
 )
 
-var inSend int32
+// dropReportInterval is how often a warning about messages dropped on a full buffer is written.
+const dropReportInterval = 10 * time.Second
 
-func Init(ctx context.Context, bufferSize int) {
+// Init starts the logger of service with the options of OptionsFromEnv. Messages
+// are buffered up to bufferSize and written by a single goroutine until ctx is done.
+func Init(ctx context.Context, service string, bufferSize int) {
+	InitWithOptions(ctx, service, bufferSize, OptionsFromEnv())
+}
+
+func InitWithOptions(ctx context.Context, service string, bufferSize int, opts Options) {
 	l := &Logger{
 		logChan: make(chan LogMessage, bufferSize),
+		done:    make(chan struct{}),
+		service: service,
+		format:  opts.Format,
+		stdout:  os.Stdout,
+	}
+	if l.format != FormatJSON && l.format != FormatLogfmt {
+		l.format = FormatText
+	}
+	l.level.Store(levelSeverity[InfoLevel])
+	if severity, ok := levelSeverity[opts.Level]; ok {
+		l.level.Store(severity)
+	}
+
+	var fileErr error
+	if opts.File != "" {
+		l.file, fileErr = openRotatingFile(opts.File, opts.MaxSizeMB, opts.MaxBackups)
 	}
 
 	Log = l
 	go l.worker(ctx)
+
+	if fileErr != nil {
+		Error("Log file %s disabled: %v", opts.File, fileErr)
+	}
+}
+
+// OptionsFromEnv reads LOG_FORMAT (text, json or logfmt), LOG_LEVEL (debug, info,
+// warning or error), LOG_FILE, LOG_FILE_MAX_SIZE_MB and LOG_FILE_MAX_BACKUPS.
+func OptionsFromEnv() Options {
+	opts := Options{
+		Format:     strings.ToLower(os.Getenv("LOG_FORMAT")),
+		Level:      InfoLevel,
+		File:       os.Getenv("LOG_FILE"),
+		MaxSizeMB:  100,
+		MaxBackups: 5,
+	}
+	if level, err := ParseLevel(os.Getenv("LOG_LEVEL")); err == nil {
+		opts.Level = level
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOG_FILE_MAX_SIZE_MB")); err == nil && v > 0 {
+		opts.MaxSizeMB = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOG_FILE_MAX_BACKUPS")); err == nil && v >= 0 {
+		opts.MaxBackups = v
+	}
+	return opts
 }
 
 func GetLogger() *Logger {
 	return Log
 }
 
+// ParseLevel parses a level name case-insensitively, "warn" is accepted for WarnLevel.
+func ParseLevel(level string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "DEBUG":
+		return DebugLevel, nil
+	case "INFO":
+		return InfoLevel, nil
+	case "WARN", "WARNING":
+		return WarnLevel, nil
+	case "ERROR":
+		return ErrorLevel, nil
+	default:
+		return "", fmt.Errorf("invalid log level %q", level)
+	}
+}
+
+// SetLevel changes the minimum level written at runtime.
+func SetLevel(level LogLevel) {
+	if severity, ok := levelSeverity[level]; ok && Log != nil {
+		Log.level.Store(severity)
+	}
+}
+
+// GetLevel returns the minimum level written.
+func GetLevel() LogLevel {
+	if Log == nil {
+		return InfoLevel
+	}
+	severity := Log.level.Load()
+	for level, s := range levelSeverity {
+		if s == severity {
+			return level
+		}
+	}
+	return InfoLevel
+}
+
+// GetFormat returns the output format of the logger.
+func GetFormat() string {
+	if Log == nil {
+		return FormatText
+	}
+	return Log.format
+}
+
+// GetStats returns the number of written and dropped messages.
+func GetStats() Stats {
+	if Log == nil {
+		return Stats{}
+	}
+	return Stats{
+		Written: Log.written.Load(),
+		Dropped: Log.dropped.Load(),
+	}
+}
+
+// Done is closed once the logger has drained its buffer after its context was canceled.
+func Done() <-chan struct{} {
+	if Log == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return Log.done
+}
+
+func (l *Logger) enabled(level LogLevel) bool {
+	return levelSeverity[level] >= l.level.Load()
+}
+
 func (l *Logger) worker(ctx context.Context) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(dropReportInterval)
+	defer ticker.Stop()
+
+	var reported uint64
 	for {
 		select {
 		case msg := <-l.logChan:
 			l.print(msg)
+		case <-ticker.C:
+			if dropped := l.dropped.Load(); dropped > reported {
+				l.print(LogMessage{
+					Level:   WarnLevel,
+					Message: "log messages dropped, buffer full",
+					Time:    time.Now(),
+					Fields:  Fields{"dropped": dropped - reported, "dropped_total": dropped},
+				})
+				reported = dropped
+			}
 		case <-ctx.Done():
 			for {
 				select {
@@ -35,6 +172,9 @@ func (l *Logger) worker(ctx context.Context) {
 					l.print(msg)
 				default:
 					// channel empty, stop draining
+					if l.file != nil {
+						_ = l.file.Close()
+					}
 					return
 				}
 			}
@@ -43,16 +183,15 @@ func (l *Logger) worker(ctx context.Context) {
 }
 
 func (l *Logger) print(msg LogMessage) {
-	color := LevelColors[msg.Level]
+	l.write(l.stdout, msg, l.format == FormatText)
+	if l.file != nil {
+		l.write(l.file, msg, false)
+	}
+	l.written.Add(1)
+}
 
-	fmt.Printf(
-		"%s[%s] [%s] %s%s\n",
-		color,
-		msg.Time.Format("2006-01-02 15:04:05"),
-		msg.Level,
-		msg.Message,
-		ColorReset,
-	)
+func (l *Logger) write(w io.Writer, msg LogMessage, color bool) {
+	_, _ = w.Write(encode(l.format, l.service, msg, color))
 }
 
 func SafeSprintf(format string, args ...interface{}) (result string) {
@@ -66,40 +205,47 @@ func SafeSprintf(format string, args ...interface{}) (result string) {
 	return
 }
 
-func send(level LogLevel, message string) {
-	if !atomic.CompareAndSwapInt32(&inSend, 0, 1) {
-		// already inside send, drop message to prevent recursion
+func send(level LogLevel, message string, fields Fields) {
+	msg := LogMessage{
+		Level:   level,
+		Message: message,
+		Time:    time.Now(),
+		Fields:  fields,
+	}
+
+	if Log == nil || Log.logChan == nil {
+		// not initialized yet, write directly instead of losing the message
+		_, _ = os.Stderr.Write(encode(FormatText, "", msg, false))
+		return
+	}
+	if !Log.enabled(level) {
 		return
 	}
-	defer atomic.StoreInt32(&inSend, 0)
 
-	if Log != nil && Log.logChan != nil {
-		select {
-		case Log.logChan <- LogMessage{
-			Level:   level,
-			Message: message,
-			Time:    time.Now(),
-		}:
-		default:
-			// drop if buffer full
-		}
+	select {
+	case Log.logChan <- msg:
+	default:
+		// never block the caller, the drop is reported by the worker
+		Log.dropped.Add(1)
 	}
 }
 
+func Debug(format string, args ...interface{}) {
+	root.Debug(format, args...)
+}
+
 func Info(format string, args ...interface{}) {
-	send(InfoLevel, SafeSprintf(format, args...))
+	root.Info(format, args...)
 }
 
 func Warn(format string, args ...interface{}) {
-	send(WarnLevel, SafeSprintf(format, args...))
+	root.Warn(format, args...)
 }
 
 func Error(format string, args ...interface{}) {
-	send(ErrorLevel, SafeSprintf(format, args...))
+	root.Error(format, args...)
 }
 
 func Fatal(format string, args ...interface{}) {
-	msg := SafeSprintf(format, args...)
-	fmt.Fprintf(os.Stderr, "[FATAL] %s\n", msg) // direct write
-	os.Exit(1)
+	root.Fatal(format, args...)
 }
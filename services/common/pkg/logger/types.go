@@ -1,30 +1,75 @@
 package logger
 
-import "time"
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Fields are the key/value pairs attached to a structured log message.
+type Fields map[string]interface{}
 
 type LogMessage struct {
 	Level   LogLevel
 	Message string
 	Time    time.Time
+	Fields  Fields
 }
 
 type Logger struct {
 	logChan chan LogMessage
+	done    chan struct{}
+	service string
+	format  string
+	stdout  io.Writer
+	file    io.WriteCloser
+	level   atomic.Int32
+	dropped atomic.Uint64
+	written atomic.Uint64
+}
+
+// Options configure the logger, see OptionsFromEnv for their environment variables.
+type Options struct {
+	// Format is one of FormatText, FormatJSON or FormatLogfmt.
+	Format string
+	// Level is the minimum level written, it can be changed at runtime with SetLevel.
+	Level LogLevel
+	// File is an optional path every message is also written to, rotated at
+	// MaxSizeMB keeping MaxBackups old files.
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+}
+
+// Stats are the counters of the logger. Dropped counts messages lost because the
+// buffer was full.
+type Stats struct {
+	Written uint64 `json:"written"`
+	Dropped uint64 `json:"dropped"`
 }
 
 type LogLevel string
 
 // Log levels
 const (
+	DebugLevel LogLevel = "DEBUG"
 	InfoLevel  LogLevel = "INFO"
 	WarnLevel  LogLevel = "WARNING"
 	ErrorLevel LogLevel = "ERROR"
 	FatalLevel LogLevel = "FATAL"
 )
 
+// Output formats
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
 // ANSI color codes for terminal output
 const (
 	ColorReset   = "\033[0m"
+	ColorGray    = "\033[90m"   // Debug
 	ColorBlue    = "\033[34m"   // Info
 	ColorYellow  = "\033[33m"   // Warning
 	ColorRed     = "\033[31m"   // Error
@@ -34,8 +79,17 @@ const (
 var Log *Logger
 
 var LevelColors = map[LogLevel]string{
+	DebugLevel: ColorGray,
 	InfoLevel:  ColorBlue,
 	WarnLevel:  ColorYellow,
 	ErrorLevel: ColorRed,
 	FatalLevel: ColorBoldRed,
 }
+
+var levelSeverity = map[LogLevel]int32{
+	DebugLevel: 0,
+	InfoLevel:  1,
+	WarnLevel:  2,
+	ErrorLevel: 3,
+	FatalLevel: 4,
+}
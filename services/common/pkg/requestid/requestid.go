@@ -0,0 +1,52 @@
+// Package requestid correlates one operator action across the api, webhook and
+// cron services. The ID travels in the X-Request-ID header between services and
+// in the context within a service, where it is also a logger field.
+package requestid
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/oklog/ulid/v2"
+	"regexp"
+)
+
+const Header = "X-Request-ID"
+
+// pattern limits IDs received from clients to safe log and header values.
+var pattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type contextKey struct{}
+
+// New returns a new request ID.
+func New() string {
+	return ulid.Make().String()
+}
+
+// Valid reports whether id may be reused as a request ID.
+func Valid(id string) bool {
+	return pattern.MatchString(id)
+}
+
+// NewContext returns a copy of ctx carrying id, added to its logger fields as request_id.
+func NewContext(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, contextKey{}, id)
+	return logger.NewContext(ctx, "request_id", id)
+}
+
+// FromContext returns the request ID of ctx, or an empty string.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Ensure returns ctx with its request ID, generating one when it has none.
+func Ensure(ctx context.Context) (context.Context, string) {
+	if id := FromContext(ctx); id != "" {
+		return ctx, id
+	}
+	id := New()
+	return NewContext(ctx, id), id
+}
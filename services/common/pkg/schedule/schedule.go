@@ -0,0 +1,20 @@
+package schedule
+
+import (
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// parser reads the schedules of the jobs: cron specs with a leading seconds field,
+// e.g. "0 1 0 * * *" for 00:01:00 every day, or descriptors like "@daily".
+var parser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Parse returns the schedule of spec.
+func Parse(spec string) (cron.Schedule, error) {
+	return parser.Parse(spec)
+}
+
+// Missed reports whether a run of s was due after lastRun and up to now.
+func Missed(s cron.Schedule, lastRun, now time.Time) bool {
+	return !s.Next(lastRun).After(now)
+}
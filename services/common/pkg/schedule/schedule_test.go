@@ -0,0 +1,36 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMissed(t *testing.T) {
+	monthly, err := Parse("0 1 0 1,2 * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lastRun := time.Date(2026, 3, 2, 0, 1, 0, 0, time.Local)
+	cases := []struct {
+		now    time.Time
+		missed bool
+	}{
+		{time.Date(2026, 3, 20, 12, 0, 0, 0, time.Local), false},
+		{time.Date(2026, 4, 1, 0, 0, 59, 0, time.Local), false},
+		{time.Date(2026, 4, 1, 0, 1, 0, 0, time.Local), true},
+		{time.Date(2026, 4, 15, 8, 0, 0, 0, time.Local), true},
+	}
+	for _, c := range cases {
+		if got := Missed(monthly, lastRun, c.now); got != c.missed {
+			t.Errorf("Missed(%s) = %v, want %v", c.now, got, c.missed)
+		}
+	}
+
+	if _, err = Parse("0 1 0 * *"); err == nil {
+		t.Error("five field spec accepted, schedules start with seconds")
+	}
+	if _, err = Parse("@daily"); err != nil {
+		t.Errorf("descriptor rejected: %v", err)
+	}
+}
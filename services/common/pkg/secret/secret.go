@@ -0,0 +1,75 @@
+// Package secret encrypts the secrets the dashboard stores in the database, like
+// the ones the servers sign their requests with, so that a database dump does not
+// give access to the servers. They are sealed with AES-256-GCM under a key derived
+// from SECRET_KEY.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"strings"
+)
+
+// prefix marks the encrypted values, the version selects the cipher and the key.
+const prefix = "enc:v1:"
+
+// Encrypt returns plaintext sealed with the key of SECRET_KEY, encrypting the
+// same plaintext twice gives different values.
+func Encrypt(plaintext string) (string, error) {
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of a value returned by Encrypt. It fails on
+// values that are not encrypted or were encrypted with another SECRET_KEY.
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", errors.New("secret is not encrypted")
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("secret is truncated")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("secret cannot be decrypted with SECRET_KEY")
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value was returned by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func newAEAD() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(config.SecretKey()))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
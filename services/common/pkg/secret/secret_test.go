@@ -0,0 +1,58 @@
+package secret
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+	t.Setenv("SECRET_KEY", "0123456789abcdef0123456789abcdef")
+
+	value, err := Encrypt("node-secret-0123456789")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(value))
+	assert.NotContains(t, value, "node-secret")
+
+	again, err := Encrypt("node-secret-0123456789")
+	require.NoError(t, err)
+	assert.NotEqual(t, value, again, "nonce reused")
+
+	plaintext, err := Decrypt(value)
+	require.NoError(t, err)
+	assert.Equal(t, "node-secret-0123456789", plaintext)
+}
+
+func TestDecrypt_Invalid(t *testing.T) {
+	t.Setenv("SECRET_KEY", "0123456789abcdef0123456789abcdef")
+	value, err := Encrypt("node-secret-0123456789")
+	require.NoError(t, err)
+	tampered := []byte(value)
+	if tampered[len(prefix)+20] == 'A' {
+		tampered[len(prefix)+20] = 'B'
+	} else {
+		tampered[len(prefix)+20] = 'A'
+	}
+
+	tests := []struct {
+		name   string
+		value  string
+		setKey string
+	}{
+		{name: "plaintext", value: "node-secret-0123456789"},
+		{name: "not base64", value: prefix + "!!"},
+		{name: "truncated", value: prefix + "AAAA"},
+		{name: "tampered", value: string(tampered)},
+		{name: "other key", value: value, setKey: "fedcba9876543210fedcba9876543210"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setKey != "" {
+				t.Setenv("SECRET_KEY", tt.setKey)
+			}
+			_, err := Decrypt(tt.value)
+			assert.Error(t, err)
+		})
+	}
+}
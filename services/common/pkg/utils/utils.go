@@ -235,7 +235,7 @@ func GetOcservVersion() string {
 		logger.Error("Command error: %v", err)
 		return ""
 	}
-	
+
 	// Combine stdout and stderr for pattern matching
 	fullOutput := out.String() + stderr.String()
 
@@ -0,0 +1,73 @@
+// go test ./common/tests -run TestLeaderElection -v
+
+package tests
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/leader"
+	"testing"
+	"time"
+)
+
+func waitLeader(e *leader.Elector, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if e.IsLeader() {
+			return true
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return false
+}
+
+func TestLeaderElection(t *testing.T) {
+	config.Init(false, "", 0)
+	database.Connect()
+	db := database.GetConnection()
+
+	if err := db.AutoMigrate(&models.LeaderLease{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Where("name = ?", "test").Delete(&models.LeaderLease{})
+
+	first, second := leader.New(db, "test"), leader.New(db, "test")
+
+	ctxFirst, stopFirst := context.WithCancel(context.Background())
+	defer stopFirst()
+	terms := make(chan context.Context, 1)
+	go first.Run(ctxFirst, func(ctx context.Context) { terms <- ctx })
+	if !waitLeader(first, 2*time.Second) {
+		t.Fatal("first replica was not elected")
+	}
+	term := <-terms
+	if current, ok := first.Term(); !ok || current != term {
+		t.Fatal("expected the term of the election to be the current term")
+	}
+
+	ctxSecond, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	go second.Run(ctxSecond, nil)
+	if waitLeader(second, time.Second) {
+		t.Fatal("second replica elected while the first holds the lease")
+	}
+
+	// the released lease is taken at the next renewal of the second replica
+	stopFirst()
+	if !waitLeader(second, leader.LeaseTTL) {
+		t.Fatal("second replica did not take over the released lease")
+	}
+	if first.IsLeader() {
+		t.Fatal("stopped replica still reports leadership")
+	}
+	select {
+	case <-term.Done():
+	default:
+		t.Fatal("term of the stopped replica was not canceled")
+	}
+	if _, ok := first.Term(); ok {
+		t.Fatal("stopped replica still has a term")
+	}
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/secret"
+	"github.com/mmtaee/ocserv-dashboard/log_stream/internal/stats"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// eventRetention is how long the IDs of the handled events are kept to drop
+	// events pushed twice.
+	eventRetention = 30 * 24 * time.Hour
+	pruneInterval  = time.Hour
+)
+
+// Ingest receives the occtl events pushed by the node agents. Requests are signed
+// with the secret of the server named in the path, and the events are handled by
+// the stat service of that server.
+type Ingest struct {
+	db    *gorm.DB
+	stats *stats.StatService
+
+	mu       sync.Mutex
+	servers  map[string]*serverState
+	prunedAt time.Time
+}
+
+// serverState is kept per server so the nonces of its requests are remembered, it
+// is replaced when the secret or endpoint of the server changes.
+type serverState struct {
+	id       uint
+	secret   string
+	endpoint string
+	verifier *occtlDocker.Verifier
+	stats    *stats.StatService
+}
+
+func NewIngest(statService *stats.StatService) *Ingest {
+	return &Ingest{
+		db:      database.GetConnection(),
+		stats:   statService,
+		servers: map[string]*serverState{},
+	}
+}
+
+func (i *Ingest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, occtlDocker.EventsPath)
+
+	state, err := i.server(r, name)
+	if err != nil {
+		requestID := r.Header.Get(requestid.Header)
+		if !requestid.Valid(requestID) {
+			requestID = requestid.New()
+		}
+		log := logger.FromContext(requestid.NewContext(r.Context(), requestID)).With("remote", r.RemoteAddr, "server", name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			occtlDocker.WriteError(w, log, requestID, http.StatusUnauthorized, occtlDocker.ErrCodeUnauthorized, "unknown server")
+			return
+		}
+		occtlDocker.WriteError(w, log, requestID, http.StatusServiceUnavailable, occtlDocker.ErrCodeActionFailed, err.Error())
+		return
+	}
+
+	body, requestID, log, ok := occtlDocker.ReadRequest(w, r, state.verifier)
+	if !ok {
+		return
+	}
+	log = log.With("server", name)
+
+	var payload occtlDocker.AgentEventsPayload
+	if err = json.Unmarshal(body, &payload); err != nil {
+		occtlDocker.WriteError(w, log, requestID, http.StatusBadRequest, occtlDocker.ErrCodeInvalidPayload, "Invalid payload: "+err.Error())
+		return
+	}
+
+	handled := 0
+	for _, event := range payload.Events {
+		if event.ID == "" {
+			continue
+		}
+		// the event ID is recorded in the transaction saving the event, so a failed
+		// event is not taken for a duplicate when the agent pushes it again
+		ok, err := state.stats.HandleEvent(r.Context(), event.Event, func(tx *gorm.DB) (bool, error) {
+			return claim(tx, state.id, event.ID)
+		})
+		if err != nil {
+			// the agent keeps the batch spooled and pushes it again
+			occtlDocker.WriteError(w, log, requestID, http.StatusServiceUnavailable, occtlDocker.ErrCodeActionFailed, err.Error())
+			return
+		}
+		if ok {
+			handled++
+		}
+	}
+	log.Info("Handled %d of %d pushed events", handled, len(payload.Events))
+	i.prune(r.Context())
+
+	occtlDocker.WriteResponse(w, http.StatusOK, occtlDocker.WebhookResponse{
+		OK:        true,
+		Message:   fmt.Sprintf("%d events handled", handled),
+		RequestID: requestID,
+	})
+}
+
+// server returns the state of the server named name.
+func (i *Ingest) server(r *http.Request, name string) (*serverState, error) {
+	var server models.Server
+	if err := i.db.WithContext(r.Context()).Where("name = ?", name).First(&server).Error; err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	state, ok := i.servers[name]
+	if !ok || state.secret != server.Secret || state.endpoint != server.Endpoint {
+		key, err := secret.Decrypt(server.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("secret of server %s: %w", name, err)
+		}
+		state = &serverState{
+			id:       server.ID,
+			secret:   server.Secret,
+			endpoint: server.Endpoint,
+			verifier: occtlDocker.NewVerifier([]byte(key)),
+			stats:    i.stats.ForServer(server),
+		}
+		i.servers[name] = state
+	}
+	return state, nil
+}
+
+// claim records the event id of the server serverID on db and reports whether it
+// is new. An event already recorded was handled by an earlier push, possibly on
+// another replica.
+func claim(db *gorm.DB, serverID uint, id string) (bool, error) {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.IngestedEvent{ID: id, ServerID: serverID})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// prune forgets the event IDs older than eventRetention, at most once per
+// pruneInterval.
+func (i *Ingest) prune(ctx context.Context) {
+	i.mu.Lock()
+	if time.Since(i.prunedAt) < pruneInterval {
+		i.mu.Unlock()
+		return
+	}
+	i.prunedAt = time.Now()
+	i.mu.Unlock()
+
+	err := i.db.WithContext(ctx).
+		Where("created_at < ?", time.Now().Add(-eventRetention)).
+		Delete(&models.IngestedEvent{}).Error
+	if err != nil {
+		logger.Warn("Failed to prune ingested event IDs: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database/dbtest"
+	localModels "github.com/mmtaee/ocserv-dashboard/log_stream/internal/models"
+	"github.com/mmtaee/ocserv-dashboard/log_stream/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestIngestClaim(t *testing.T) {
+	db := dbtest.Open(t, &models.IngestedEvent{})
+
+	tests := []struct {
+		name     string
+		serverID uint
+		id       string
+		want     bool
+	}{
+		{name: "new event", serverID: 1, id: "01HZX0000000000000000000A1", want: true},
+		{name: "pushed twice", serverID: 1, id: "01HZX0000000000000000000A1", want: false},
+		{name: "next event", serverID: 1, id: "01HZX0000000000000000000A2", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := claim(db, tt.serverID, tt.id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIngestPrune(t *testing.T) {
+	db := dbtest.Open(t, &models.IngestedEvent{})
+	i := &Ingest{db: db}
+	ctx := context.Background()
+
+	old := models.IngestedEvent{ID: "old", ServerID: 1, CreatedAt: time.Now().Add(-eventRetention - time.Hour)}
+	require.NoError(t, db.Create(&old).Error)
+	_, err := claim(db, 1, "recent")
+	require.NoError(t, err)
+
+	i.prune(ctx)
+
+	var ids []string
+	require.NoError(t, db.Model(&models.IngestedEvent{}).Order("id").Pluck("id", &ids).Error)
+	assert.Equal(t, []string{"recent"}, ids, "expected only the recent event to be kept")
+
+	// pruned again within pruneInterval, nothing is deleted
+	require.NoError(t, db.Create(&models.IngestedEvent{ID: "old", ServerID: 1, CreatedAt: old.CreatedAt}).Error)
+	i.prune(ctx)
+	var count int64
+	require.NoError(t, db.Model(&models.IngestedEvent{}).Count(&count).Error)
+	assert.EqualValues(t, 2, count, "expected the prune to wait for pruneInterval")
+}
+
+func TestIngestHandleEvent(t *testing.T) {
+	db := dbtest.Open(t,
+		&models.IngestedEvent{},
+		&models.OcservUser{},
+		&models.OcservUserSessionLog{},
+		&models.OcservUserTrafficStatistics{},
+		&models.OcservUserAlert{},
+		&models.OcservUserDevice{},
+		&localModels.System{},
+	)
+	t.Setenv("OCSERV_DRIVER", driver.Fake)
+	d, err := driver.Init(false)
+	require.NoError(t, err)
+	ctx := context.Background()
+	svc := stats.NewStatService(ctx, nil, d)
+
+	event := models.OcctlEvent{Type: models.OcctlEventDisconnect, ID: 7, Username: "john", RemoteIP: "198.51.100.1", RX: 100, TX: 200}
+	handle := func() (bool, error) {
+		return svc.HandleEvent(ctx, event, func(tx *gorm.DB) (bool, error) {
+			return claim(tx, 1, "01HZX0000000000000000000A1")
+		})
+	}
+	count := func(model interface{}) int64 {
+		var n int64
+		require.NoError(t, db.Model(model).Count(&n).Error)
+		return n
+	}
+
+	// the traffic of an unknown user fails, the event is not recorded as handled
+	_, err = handle()
+	require.Error(t, err)
+	assert.Zero(t, count(&models.IngestedEvent{}), "expected the claim to be rolled back")
+	assert.Zero(t, count(&models.OcservUserSessionLog{}))
+
+	// pushed again once the user exists
+	require.NoError(t, db.Create(&models.OcservUser{Username: "john", Password: "secret", TrafficType: models.Free}).Error)
+	handled, err := handle()
+	require.NoError(t, err)
+	assert.True(t, handled)
+
+	// pushed twice
+	handled, err = handle()
+	require.NoError(t, err)
+	assert.False(t, handled)
+
+	assert.EqualValues(t, 1, count(&models.IngestedEvent{}))
+	assert.EqualValues(t, 1, count(&models.OcservUserSessionLog{}))
+	assert.EqualValues(t, 1, count(&models.OcservUserTrafficStatistics{}))
+	var user models.OcservUser
+	require.NoError(t, db.Where("username = ?", "john").First(&user).Error)
+	assert.Equal(t, 100, user.Rx)
+	assert.Equal(t, 200, user.Tx)
+}
@@ -0,0 +1,10 @@
+package models
+
+type System struct {
+	ID                      uint `json:"_" gorm:"primaryKey"`
+	AnomalyDetection        bool `json:"anomaly_detection" gorm:"type:boolean;default:true"`
+	AnomalyAutoLock         bool `json:"anomaly_auto_lock" gorm:"type:boolean;default:false"`
+	AnomalyMaxConcurrentIPs int  `json:"anomaly_max_concurrent_ips" gorm:"column:anomaly_max_concurrent_ips;default:1"`
+	AnomalyMaxTravelKmh     int  `json:"anomaly_max_travel_kmh" gorm:"default:900"`
+	AnomalyMaxUserAgents    int  `json:"anomaly_max_user_agents" gorm:"default:3"`
+}
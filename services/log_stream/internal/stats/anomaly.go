@@ -0,0 +1,295 @@
+package stats
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/geoip"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	localModels "github.com/mmtaee/ocserv-dashboard/log_stream/internal/models"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	anomalyPolicyTTL = time.Minute
+	// anomalyCooldown keeps a user from being alerted twice for the same kind in a row.
+	anomalyCooldown = time.Hour
+	// minTravelKm ignores jumps within the accuracy of GeoIP city locations.
+	minTravelKm = 100
+)
+
+type anomalyState struct {
+	mu       sync.Mutex
+	policy   localModels.System
+	policyAt time.Time
+	raised   map[string]time.Time
+	// now replaces time.Now in tests
+	now func() time.Time
+}
+
+func (a *anomalyState) clock() time.Time {
+	if a.now != nil {
+		return a.now()
+	}
+	return time.Now()
+}
+
+// anomalyPolicy returns the detection policy of the system settings, reloaded once a minute.
+func (s *StatService) anomalyPolicy(db *gorm.DB) localModels.System {
+	s.anomaly.mu.Lock()
+	defer s.anomaly.mu.Unlock()
+
+	if time.Since(s.anomaly.policyAt) < anomalyPolicyTTL {
+		return s.anomaly.policy
+	}
+
+	policy := localModels.System{
+		AnomalyDetection:        true,
+		AnomalyMaxConcurrentIPs: 1,
+		AnomalyMaxTravelKmh:     900,
+		AnomalyMaxUserAgents:    3,
+	}
+	if err := db.First(&policy).Error; err != nil {
+		logger.Warn("Failed to get anomaly detection policy, using defaults: %v", err)
+	}
+	s.anomaly.policy = policy
+	s.anomaly.policyAt = time.Now()
+	return policy
+}
+
+// detectAnomalies checks a saved session log for signs of account sharing: more
+// concurrent sessions from different IPs than allowed, an impossible travel since
+// the previous session start or too many distinct user agents today.
+func (s *StatService) detectAnomalies(db *gorm.DB, log *models.OcservUserSessionLog) {
+	if log.Username == "" || log.IP == "" {
+		return
+	}
+
+	policy := s.anomalyPolicy(db)
+	if !policy.AnomalyDetection {
+		return
+	}
+
+	switch log.Event {
+	case models.EventConnect, models.EventHandshake:
+		s.checkConcurrentSessions(db, policy, log)
+		s.checkImpossibleTravel(db, policy, log)
+	}
+
+	switch log.Event {
+	case models.EventConnect, models.EventUseragent:
+		s.checkUserAgents(db, policy, log)
+	}
+}
+
+func (s *StatService) checkConcurrentSessions(db *gorm.DB, policy localModels.System, log *models.OcservUserSessionLog) {
+	if policy.AnomalyMaxConcurrentIPs < 1 {
+		return
+	}
+
+	var ocUser models.OcservUser
+	if err := db.Preload("Servers").Where("username = ?", log.Username).First(&ocUser).Error; err != nil {
+		logger.Warn("Failed to get servers of %s: %v", log.Username, err)
+		return
+	}
+
+	// the sessions of the user on all its servers count, the failed ones are skipped
+	var (
+		mu       sync.Mutex
+		sessions []models.OnlineUserSession
+	)
+	err := driver.FanOut(db.Statement.Context, driver.Nodes(ocUser.Servers), func(_ driver.Node, d driver.Driver) error {
+		found, err := d.Occtl().ShowUser(log.Username)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		sessions = append(sessions, found...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Failed to get sessions of %s: %v", log.Username, err)
+	}
+
+	ips := map[string]struct{}{}
+	countries := map[string]struct{}{}
+	for _, session := range sessions {
+		if session.RemoteIP == "" {
+			continue
+		}
+		ips[session.RemoteIP] = struct{}{}
+		if record := s.geoLookup(session.RemoteIP); record != nil && record.CountryCode != "" {
+			countries[record.CountryCode] = struct{}{}
+		}
+	}
+	if len(ips) <= policy.AnomalyMaxConcurrentIPs {
+		return
+	}
+
+	s.raiseAlert(db, policy, log.Username, models.AlertConcurrentSessions, models.AlertEvidence{
+		"ips":       sortedKeys(ips),
+		"countries": sortedKeys(countries),
+		"limit":     policy.AnomalyMaxConcurrentIPs,
+	})
+}
+
+func (s *StatService) checkImpossibleTravel(db *gorm.DB, policy localModels.System, log *models.OcservUserSessionLog) {
+	if policy.AnomalyMaxTravelKmh < 1 {
+		return
+	}
+
+	current := s.geoLookup(log.IP)
+	if current == nil || !current.HasLocation() {
+		return
+	}
+
+	var previous models.OcservUserSessionLog
+	err := db.Where("username = ? AND event IN ? AND ip <> '' AND ip <> ? AND id <> ?",
+		log.Username, []string{models.EventConnect, models.EventHandshake}, log.IP, log.ID).
+		Order("created_at DESC").
+		First(&previous).Error
+	if err != nil {
+		return
+	}
+
+	last := s.geoLookup(previous.IP)
+	if last == nil || !last.HasLocation() {
+		return
+	}
+
+	distance := geoip.DistanceKm(last, current)
+	if distance < minTravelKm {
+		return
+	}
+
+	// a zero interval would divide by zero, a minute is the resolution that matters
+	hours := log.CreatedAt.Sub(previous.CreatedAt).Hours()
+	if hours < 1.0/60 {
+		hours = 1.0 / 60
+	}
+	speed := distance / hours
+	if speed <= float64(policy.AnomalyMaxTravelKmh) {
+		return
+	}
+
+	s.raiseAlert(db, policy, log.Username, models.AlertImpossibleTravel, models.AlertEvidence{
+		"from_ip":      previous.IP,
+		"from_country": last.CountryCode,
+		"from_city":    last.City,
+		"from_time":    previous.CreatedAt,
+		"to_ip":        log.IP,
+		"to_country":   current.CountryCode,
+		"to_city":      current.City,
+		"to_time":      log.CreatedAt,
+		"distance_km":  int(distance),
+		"speed_kmh":    int(speed),
+		"limit_kmh":    policy.AnomalyMaxTravelKmh,
+	})
+}
+
+func (s *StatService) checkUserAgents(db *gorm.DB, policy localModels.System, log *models.OcservUserSessionLog) {
+	if policy.AnomalyMaxUserAgents < 1 {
+		return
+	}
+
+	now := s.anomaly.clock()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var messages []string
+	err := db.Model(&models.OcservUserSessionLog{}).
+		Where("username = ? AND event IN ? AND created_at >= ?",
+			log.Username, []string{models.EventConnect, models.EventUseragent}, startOfDay).
+		Pluck("message", &messages).Error
+	if err != nil {
+		logger.Warn("Failed to get user agents of %s: %v", log.Username, err)
+		return
+	}
+
+	agents := map[string]struct{}{}
+	for _, message := range messages {
+		if agent := userAgent(message); agent != "" {
+			agents[agent] = struct{}{}
+		}
+	}
+	if len(agents) <= policy.AnomalyMaxUserAgents {
+		return
+	}
+
+	s.raiseAlert(db, policy, log.Username, models.AlertUserAgents, models.AlertEvidence{
+		"user_agents": sortedKeys(agents),
+		"limit":       policy.AnomalyMaxUserAgents,
+	})
+}
+
+// raiseAlert records the alert unless the same one was raised within the cooldown,
+// and locks and disconnects the user when the policy asks for it.
+func (s *StatService) raiseAlert(db *gorm.DB, policy localModels.System, username, kind string, evidence models.AlertEvidence) {
+	key := username + "/" + kind
+
+	s.anomaly.mu.Lock()
+	if s.anomaly.raised == nil {
+		s.anomaly.raised = map[string]time.Time{}
+	}
+	now := s.anomaly.clock()
+	if last, ok := s.anomaly.raised[key]; ok && now.Sub(last) < anomalyCooldown {
+		s.anomaly.mu.Unlock()
+		return
+	}
+	s.anomaly.raised[key] = now
+	s.anomaly.mu.Unlock()
+
+	alert := models.OcservUserAlert{
+		Username: username,
+		Kind:     kind,
+		Evidence: evidence,
+	}
+	logger.With("username", username, "kind", kind, "evidence", evidence).Warn("Anomaly detected")
+
+	if policy.AnomalyAutoLock {
+		var ocUser models.OcservUser
+		if err := db.Preload("Servers").Where("username = ?", username).First(&ocUser).Error; err != nil {
+			logger.Error("Error finding oc user: %v", err)
+		} else if !ocUser.IsLocked {
+			s.lockUser(db.Statement.Context, &ocUser, models.LockReasonAnomaly)
+			if err = db.Omit("Servers").Save(&ocUser).Error; err != nil {
+				logger.Error("Error locking user %s: %v", username, err)
+			} else {
+				alert.Locked = true
+				s.disconnectUser(username)
+			}
+		}
+	}
+
+	if err := db.Create(&alert).Error; err != nil {
+		logger.Error("Error saving %s alert of %s: %v", kind, username, err)
+	}
+}
+
+func (s *StatService) disconnectUser(username string) {
+	if _, err := s.ocservOcctlRepo.DisconnectUser(username); err != nil {
+		logger.Error("Error disconnecting user %s: %v", username, err)
+	}
+}
+
+// userAgent extracts the client from a session log message, e.g.
+// "User-agent: 'AnyConnect Linux_64 4.10'".
+func userAgent(message string) string {
+	_, agent, found := strings.Cut(message, "User-agent:")
+	if !found {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(agent), `'"`)
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,222 @@
+package stats
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	localModels "github.com/mmtaee/ocserv-dashboard/log_stream/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func defaultPolicy() localModels.System {
+	return localModels.System{
+		AnomalyDetection:        true,
+		AnomalyMaxConcurrentIPs: 1,
+		AnomalyMaxTravelKmh:     900,
+		AnomalyMaxUserAgents:    3,
+	}
+}
+
+func TestDetectAnomalies_ConcurrentSessions(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   func(*localModels.System)
+		ips      []string
+		wantIPs  []string
+		wantGeos []string
+	}{
+		{
+			name:     "two IPs over a limit of one",
+			ips:      []string{"198.51.100.1", "203.0.113.1"},
+			wantIPs:  []string{"198.51.100.1", "203.0.113.1"},
+			wantGeos: []string{"DE", "JP"},
+		},
+		{
+			name: "sessions from the same IP",
+			ips:  []string{"198.51.100.1", "198.51.100.1"},
+		},
+		{
+			name:   "two IPs within a limit of two",
+			policy: func(p *localModels.System) { p.AnomalyMaxConcurrentIPs = 2 },
+			ips:    []string{"198.51.100.1", "203.0.113.1"},
+		},
+		{
+			name:   "check disabled",
+			policy: func(p *localModels.System) { p.AnomalyMaxConcurrentIPs = 0 },
+			ips:    []string{"198.51.100.1", "203.0.113.1"},
+		},
+		{
+			name:   "detection disabled",
+			policy: func(p *localModels.System) { p.AnomalyDetection = false },
+			ips:    []string{"198.51.100.1", "203.0.113.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := defaultPolicy()
+			if tt.policy != nil {
+				tt.policy(&policy)
+			}
+			f := newStatsFixture(t, policy)
+
+			for _, ip := range tt.ips {
+				f.fake.Connect(models.OnlineUserSession{Username: "john", RemoteIP: ip})
+			}
+			log := f.sessionLog(t, models.EventHandshake, tt.ips[len(tt.ips)-1], "", f.now)
+			f.svc.detectAnomalies(f.db, log)
+
+			alerts := f.alerts(t)
+			if tt.wantIPs == nil {
+				assert.Empty(t, alerts)
+				return
+			}
+			require.Len(t, alerts, 1)
+			require.Equal(t, models.AlertConcurrentSessions, alerts[0].Kind)
+			assert.Equal(t, tt.wantIPs, evidenceList(alerts[0].Evidence["ips"]))
+			assert.Equal(t, tt.wantGeos, evidenceList(alerts[0].Evidence["countries"]))
+		})
+	}
+}
+
+func TestDetectAnomalies_ImpossibleTravel(t *testing.T) {
+	tests := []struct {
+		name    string
+		fromIP  string
+		toIP    string
+		elapsed time.Duration
+		want    bool
+	}{
+		{name: "Berlin to Tokyo in an hour", fromIP: "198.51.100.1", toIP: "203.0.113.1", elapsed: time.Hour, want: true},
+		{name: "Berlin to Tokyo at the same minute", fromIP: "198.51.100.1", toIP: "203.0.113.1", want: true},
+		{name: "Berlin to Tokyo in a day", fromIP: "198.51.100.1", toIP: "203.0.113.1", elapsed: 24 * time.Hour},
+		{name: "Berlin to Potsdam within GeoIP accuracy", fromIP: "198.51.100.1", toIP: "198.51.100.2", elapsed: time.Minute},
+		{name: "previous location unknown", fromIP: "192.0.2.1", toIP: "203.0.113.1", elapsed: time.Minute},
+		{name: "current location unknown", fromIP: "198.51.100.1", toIP: "192.0.2.99", elapsed: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newStatsFixture(t, defaultPolicy())
+
+			f.sessionLog(t, models.EventConnect, tt.fromIP, "", f.now.Add(-tt.elapsed))
+			log := f.sessionLog(t, models.EventConnect, tt.toIP, "", f.now)
+			f.svc.detectAnomalies(f.db, log)
+
+			alerts := f.alerts(t)
+			if !tt.want {
+				assert.Empty(t, alerts)
+				return
+			}
+			require.Len(t, alerts, 1)
+			require.Equal(t, models.AlertImpossibleTravel, alerts[0].Kind)
+			evidence := alerts[0].Evidence
+			assert.Equal(t, "Berlin", evidence["from_city"])
+			assert.Equal(t, "Tokyo", evidence["to_city"])
+			assert.InDelta(t, 8900, evidence["distance_km"], 100)
+		})
+	}
+}
+
+func TestDetectAnomalies_UserAgents(t *testing.T) {
+	tests := []struct {
+		name   string
+		agents []string
+		// yesterday is the number of the first agents seen the day before
+		yesterday int
+		want      bool
+	}{
+		{name: "three clients within the limit", agents: []string{"AnyConnect Windows 4.10", "AnyConnect Linux_64 4.10", "OpenConnect v9.12"}},
+		{name: "a client reconnecting", agents: []string{"AnyConnect Windows 4.10", "AnyConnect Windows 4.10", "AnyConnect Windows 4.10", "AnyConnect Windows 4.10"}},
+		{name: "four clients over the limit", agents: []string{"AnyConnect Windows 4.10", "AnyConnect Linux_64 4.10", "OpenConnect v9.12", "AnyConnect Android 4.10"}, want: true},
+		{name: "four clients, one of them yesterday", agents: []string{"AnyConnect Windows 4.10", "AnyConnect Linux_64 4.10", "OpenConnect v9.12", "AnyConnect Android 4.10"}, yesterday: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newStatsFixture(t, defaultPolicy())
+			// a day in the past, the day of the check is the one of the service clock
+			f.now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+
+			var log *models.OcservUserSessionLog
+			for i, agent := range tt.agents {
+				at := f.now
+				if i < tt.yesterday {
+					at = at.AddDate(0, 0, -1)
+				}
+				log = f.sessionLog(t, models.EventUseragent, "198.51.100.1", "User-agent: '"+agent+"'", at)
+			}
+			f.svc.detectAnomalies(f.db, log)
+
+			alerts := f.alerts(t)
+			if !tt.want {
+				assert.Empty(t, alerts)
+				return
+			}
+			require.Len(t, alerts, 1)
+			require.Equal(t, models.AlertUserAgents, alerts[0].Kind)
+			assert.Len(t, evidenceList(alerts[0].Evidence["user_agents"]), 4)
+		})
+	}
+}
+
+func TestRaiseAlert_Cooldown(t *testing.T) {
+	f := newStatsFixture(t, defaultPolicy())
+	policy := defaultPolicy()
+	raise := func(kind string) {
+		f.svc.raiseAlert(f.db, policy, "john", kind, models.AlertEvidence{})
+	}
+
+	raise(models.AlertUserAgents)
+	raise(models.AlertUserAgents)
+	require.Len(t, f.alerts(t), 1, "repeated alert within the cooldown")
+
+	// other kinds have their own cooldown
+	raise(models.AlertConcurrentSessions)
+	require.Len(t, f.alerts(t), 2)
+
+	f.now = f.now.Add(anomalyCooldown - time.Minute)
+	raise(models.AlertUserAgents)
+	require.Len(t, f.alerts(t), 2, "alert raised again before the cooldown ended")
+
+	f.now = f.now.Add(time.Minute)
+	raise(models.AlertUserAgents)
+	require.Len(t, f.alerts(t), 3, "alert not raised again after the cooldown")
+}
+
+func TestRaiseAlert_AutoLock(t *testing.T) {
+	policy := defaultPolicy()
+	policy.AnomalyAutoLock = true
+	f := newStatsFixture(t, policy)
+
+	f.fake.Connect(models.OnlineUserSession{Username: "john", RemoteIP: "198.51.100.1"})
+	f.fake.Connect(models.OnlineUserSession{Username: "john", RemoteIP: "203.0.113.1"})
+	log := f.sessionLog(t, models.EventConnect, "203.0.113.1", "", f.now)
+	f.svc.detectAnomalies(f.db, log)
+
+	alerts := f.alerts(t)
+	require.NotEmpty(t, alerts)
+	require.True(t, alerts[0].Locked, "expected a locking alert")
+
+	var user models.OcservUser
+	require.NoError(t, f.db.Where("username = ?", "john").First(&user).Error)
+	assert.True(t, user.IsLocked, "user not locked in the database")
+	assert.NotNil(t, user.DeactivatedAt)
+	assert.Equal(t, models.LockReasonAnomaly, user.LockReason)
+
+	fakeUser, _ := f.fake.User("john")
+	assert.True(t, fakeUser.Locked, "user not locked on ocserv")
+	sessions, _ := f.fake.Occtl().ShowUser("john")
+	assert.Empty(t, sessions, "sessions of the locked user left connected")
+}
+
+// evidenceList converts a decoded JSON list of the evidence.
+func evidenceList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		out = append(out, item.(string))
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"errors"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+	"time"
+)
+
+const (
+	deviceDisconnectAttempts = 3
+	deviceDisconnectRetry    = 2 * time.Second
+)
+
+// trackDevice records the client of a session start as a device of the user. A
+// device beyond the MaxDevices of the user is blocked on sight, and blocked
+// devices, revoked ones included, are disconnected whenever they connect.
+func (s *StatService) trackDevice(db *gorm.DB, log *models.OcservUserSessionLog) {
+	if log.Event != models.EventConnect && log.Event != models.EventUseragent {
+		return
+	}
+
+	agent := userAgent(log.Message)
+	if agent == "" {
+		return
+	}
+	agent = models.DeviceUserAgent(agent)
+
+	var ocUser models.OcservUser
+	if err := db.Where("username = ?", log.Username).First(&ocUser).Error; err != nil {
+		logger.Error("Error finding oc user: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var device models.OcservUserDevice
+	err := db.Where("oc_user_id = ? AND user_agent = ?", ocUser.ID, agent).First(&device).Error
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		device = models.OcservUserDevice{
+			OcUserID:    ocUser.ID,
+			UserAgent:   agent,
+			Platform:    models.DevicePlatform(agent),
+			LastIP:      log.IP,
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		}
+		if err = createDevice(db, &ocUser, &device); err != nil {
+			logger.Error("Error saving device of %s: %v", ocUser.Username, err)
+			return
+		}
+		logger.Info("New device %q (%s) of user %s", agent, device.Platform, ocUser.Username)
+
+	case err != nil:
+		logger.Error("Error finding device of %s: %v", ocUser.Username, err)
+		return
+
+	default:
+		update := map[string]interface{}{"last_seen_at": now}
+		if log.IP != "" {
+			update["last_ip"] = log.IP
+		}
+		if err = db.Model(&device).Updates(update).Error; err != nil {
+			logger.Error("Error updating device of %s: %v", ocUser.Username, err)
+		}
+	}
+
+	if device.Blocked {
+		logger.With("username", ocUser.Username, "user_agent", agent, "reason", device.BlockReason).Warn("Disconnecting blocked device")
+		s.disconnectDevice(ocUser.Username, agent)
+	}
+}
+
+// createDevice saves a new device of ocUser, blocked when the user already has
+// MaxDevices active ones. The row of the user is locked meanwhile, so that devices
+// connecting at once are counted one after the other. A device saved by such a
+// concurrent connection is loaded instead.
+func createDevice(db *gorm.DB, ocUser *models.OcservUser, device *models.OcservUserDevice) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&models.OcservUser{}, ocUser.ID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("oc_user_id = ? AND user_agent = ?", device.OcUserID, device.UserAgent).First(device).Error
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if ocUser.MaxDevices > 0 {
+			var active int64
+			err = tx.Model(&models.OcservUserDevice{}).
+				Where("oc_user_id = ? AND NOT blocked", ocUser.ID).
+				Count(&active).Error
+			if err != nil {
+				return err
+			}
+			if active >= int64(ocUser.MaxDevices) {
+				device.Blocked = true
+				device.BlockReason = models.DeviceBlockLimit
+			}
+		}
+		return tx.Create(device).Error
+	})
+}
+
+// disconnectDevice disconnects the sessions of username opened by the userAgent
+// client, in its stored DeviceUserAgent form. The user agent is logged before the
+// session is listed by occtl, so the lookup is retried a few times.
+func (s *StatService) disconnectDevice(username, userAgent string) {
+	go func() {
+		for attempt := 0; attempt < deviceDisconnectAttempts; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-s.ctx.Done():
+					return
+				case <-time.After(deviceDisconnectRetry):
+				}
+			}
+
+			sessions, err := s.ocservOcctlRepo.ShowUser(username)
+			if err != nil {
+				logger.Error("Error getting sessions of %s: %v", username, err)
+				continue
+			}
+
+			disconnected := false
+			for _, session := range sessions {
+				if models.DeviceUserAgent(session.UserAgent) != userAgent {
+					continue
+				}
+				if _, err = s.ocservOcctlRepo.DisconnectID(strconv.Itoa(int(session.ID))); err != nil {
+					logger.Error("Error disconnecting session %d of %s: %v", session.ID, username, err)
+					continue
+				}
+				disconnected = true
+			}
+			if disconnected {
+				return
+			}
+		}
+	}()
+}
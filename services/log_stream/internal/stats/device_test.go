@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	windowsAgent = "AnyConnect Windows 4.10.07061"
+	linuxAgent   = "Open AnyConnect VPN Agent v9.12-linux"
+	androidAgent = "AnyConnect Android 4.10.05085"
+)
+
+// longAgent is longer than the user_agent column of the devices.
+var longAgent = "AnyConnect Windows 4.10.07061 " + strings.Repeat("x", 300)
+
+func userAgentMessage(agent string) string {
+	return "worker: User-agent: '" + agent + "'"
+}
+
+func (f *statsFixture) devices(t *testing.T) []models.OcservUserDevice {
+	t.Helper()
+
+	var devices []models.OcservUserDevice
+	require.NoError(t, f.db.Order("id").Find(&devices).Error)
+	return devices
+}
+
+func TestTrackDevice(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxDevices  int
+		known       []string
+		agent       string
+		wantDevices int
+		wantBlocked bool
+	}{
+		{
+			name:        "new device",
+			agent:       windowsAgent,
+			wantDevices: 1,
+		},
+		{
+			name:        "known device",
+			known:       []string{windowsAgent},
+			agent:       windowsAgent,
+			wantDevices: 1,
+		},
+		{
+			name:        "unlimited devices",
+			known:       []string{windowsAgent, linuxAgent},
+			agent:       androidAgent,
+			wantDevices: 3,
+		},
+		{
+			name:        "within the limit",
+			maxDevices:  2,
+			known:       []string{windowsAgent},
+			agent:       linuxAgent,
+			wantDevices: 2,
+		},
+		{
+			name:        "over the limit",
+			maxDevices:  2,
+			known:       []string{windowsAgent, linuxAgent},
+			agent:       androidAgent,
+			wantDevices: 3,
+			wantBlocked: true,
+		},
+		{
+			name:        "over the limit with a user agent longer than its column",
+			maxDevices:  2,
+			known:       []string{linuxAgent, androidAgent},
+			agent:       longAgent,
+			wantDevices: 3,
+			wantBlocked: true,
+		},
+		{
+			name:        "known device at the limit",
+			maxDevices:  2,
+			known:       []string{windowsAgent, linuxAgent},
+			agent:       linuxAgent,
+			wantDevices: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newStatsFixture(t, defaultPolicy())
+			require.NoError(t, f.db.Model(&models.OcservUser{}).Where("username = ?", "john").
+				Update("max_devices", tt.maxDevices).Error)
+
+			for _, agent := range tt.known {
+				f.svc.trackDevice(f.db, f.sessionLog(t, models.EventUseragent, "198.51.100.1", userAgentMessage(agent), f.now))
+			}
+			f.fake.Connect(models.OnlineUserSession{Username: "john", RemoteIP: "198.51.100.2", UserAgent: tt.agent})
+			f.svc.trackDevice(f.db, f.sessionLog(t, models.EventUseragent, "198.51.100.2", userAgentMessage(tt.agent), f.now))
+
+			devices := f.devices(t)
+			require.Len(t, devices, tt.wantDevices)
+
+			var device models.OcservUserDevice
+			for _, d := range devices {
+				if d.UserAgent == models.DeviceUserAgent(tt.agent) {
+					device = d
+				}
+			}
+			assert.Equal(t, "198.51.100.2", device.LastIP)
+			assert.Equal(t, models.DevicePlatform(tt.agent), device.Platform)
+			require.Equal(t, tt.wantBlocked, device.Blocked)
+
+			if !tt.wantBlocked {
+				sessions, _ := f.fake.Occtl().ShowUser("john")
+				assert.Len(t, sessions, 1, "expected the session to stay")
+				return
+			}
+			assert.Equal(t, models.DeviceBlockLimit, device.BlockReason)
+			assert.Eventually(t, func() bool {
+				sessions, _ := f.fake.Occtl().ShowUser("john")
+				return len(sessions) == 0
+			}, time.Second, 10*time.Millisecond, "expected the blocked device to be disconnected")
+		})
+	}
+}
+
+func TestTrackDevice_ConcurrentNewDevices(t *testing.T) {
+	f := newStatsFixture(t, defaultPolicy())
+	require.NoError(t, f.db.Model(&models.OcservUser{}).Where("username = ?", "john").
+		Update("max_devices", 2).Error)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		log := f.sessionLog(t, models.EventUseragent, "198.51.100.1", userAgentMessage(windowsAgent+strings.Repeat("+", i)), f.now)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.svc.trackDevice(f.db, log)
+		}()
+	}
+	wg.Wait()
+
+	devices := f.devices(t)
+	require.Len(t, devices, 8)
+	active := 0
+	for _, device := range devices {
+		if !device.Blocked {
+			active++
+		}
+	}
+	assert.Equal(t, 2, active, "devices beyond the limit left unblocked")
+}
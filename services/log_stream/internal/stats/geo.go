@@ -0,0 +1,44 @@
+package stats
+
+import (
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"gorm.io/gorm"
+)
+
+type countrySightings struct {
+	Seen int64
+	Same int64
+}
+
+// enrichSessionLog tags the log with the GeoIP location and network of its IP. A
+// session start from a country the user has not been seen in before is flagged
+// as NewCountry; the very first located session of a user is not.
+func (s *StatService) enrichSessionLog(db *gorm.DB, log *models.OcservUserSessionLog) error {
+	if log.IP == "" {
+		return nil
+	}
+	record := s.geoLookup(log.IP)
+	if record == nil {
+		return nil
+	}
+
+	log.Country = record.CountryCode
+	log.City = record.City
+	log.ASN = record.ASN
+	log.ASNOrganization = record.ASNOrganization
+
+	if log.Country == "" || (log.Event != models.EventConnect && log.Event != models.EventUseragent) {
+		return nil
+	}
+
+	var sightings countrySightings
+	err := db.Model(&models.OcservUserSessionLog{}).
+		Select("COUNT(*) AS seen, COUNT(*) FILTER (WHERE country = ?) AS same", log.Country).
+		Where("username = ? AND country <> ''", log.Username).
+		Scan(&sightings).Error
+	if err != nil {
+		return err
+	}
+	log.NewCountry = sightings.Seen > 0 && sightings.Same == 0
+	return nil
+}
@@ -2,11 +2,12 @@ package stats
 
 import (
 	"context"
+	"fmt"
 	"github.com/mmtaee/ocserv-dashboard/common/models"
-	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
-	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/geoip"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
 	"gorm.io/gorm"
 	"os"
@@ -20,27 +21,30 @@ import (
 type StatService struct {
 	ctx             context.Context
 	stream          <-chan string
-	ocservUserRepo  user.OcservUserInterface
 	ocservOcctlRepo occtl.OcservOcctlInterface
-	occtlDockerRepo occtlDocker.OcservOcctlUsersDocker
-	dockerMode      bool
+	anomaly         *anomalyState
+	geoLookup       func(ip string) *geoip.Record
 }
 
-func NewStatService(ctx context.Context, stream chan string, dockerMode bool) *StatService {
-	s := &StatService{
-		ctx:        ctx,
-		stream:     stream,
-		dockerMode: dockerMode,
+func NewStatService(ctx context.Context, stream chan string, ocservDriver driver.Driver) *StatService {
+	return &StatService{
+		ctx:             ctx,
+		stream:          stream,
+		ocservOcctlRepo: ocservDriver.Occtl(),
+		anomaly:         &anomalyState{},
+		geoLookup:       geoip.Lookup,
 	}
+}
 
-	if dockerMode {
-		s.occtlDockerRepo = occtlDocker.NewOcservOcctlDocker()
-	} else {
-		s.ocservUserRepo = user.NewOcservUser()
-		s.ocservOcctlRepo = occtl.NewOcservOcctl()
+// ForServer returns a service handling the events of server, sessions flagged by
+// the device and anomaly checks are disconnected on its ocserv.
+func (s *StatService) ForServer(server models.Server) *StatService {
+	return &StatService{
+		ctx:             s.ctx,
+		ocservOcctlRepo: driver.ServerNode(server).Driver.WithContext(s.ctx).Occtl(),
+		anomaly:         s.anomaly,
+		geoLookup:       s.geoLookup,
 	}
-
-	return s
 }
 
 func (s *StatService) CalculateUserStats() {
@@ -75,13 +79,14 @@ func (s *StatService) CalculateUserStats() {
 					continue
 				}
 
-				err = s.saveRxTx(s.ctx, stats)
+				locked, err := s.saveRxTx(database.GetConnection().WithContext(s.ctx), stats)
 				if err != nil {
 					logger.Error("Failed to save RxTx stats: %v", err)
+				} else {
+					logger.Info("Saved RxTx stats: %v", stats)
+					s.lockOnNodes(s.ctx, locked)
 				}
 
-				logger.Info("Saved RxTx stats: %v", stats)
-
 				// replace main word with worker to extract user session log
 				cleanLine = strings.Replace(cleanLine, "main[", "worker[", 1)
 			}
@@ -93,15 +98,106 @@ func (s *StatService) CalculateUserStats() {
 				continue
 			}
 
-			if err := s.saveSessionLog(s.ctx, sessionLog); err != nil {
+			db := database.GetConnection().WithContext(s.ctx)
+			s.locateSessionLog(db, sessionLog)
+			if err := db.Save(sessionLog).Error; err != nil {
 				logger.Error("Error saving session msg (%v): %v", sessionLog.Username, err)
 				continue
 			}
+			s.inspectSessionLog(db, sessionLog)
 			//logger.Info("Processed user: %v successfully", sessionLog.Username)
 		}
 	}
 }
 
+// CalculateUserStatsFromEvents is the occtl events alternative to CalculateUserStats.
+// Disconnects carry the session totals, so no log line parsing is needed.
+func (s *StatService) CalculateUserStatsFromEvents(events <-chan models.OcctlEvent) {
+	for {
+		select {
+		case <-s.ctx.Done():
+			logger.Warn("stopping: context cancelled")
+			return
+
+		case event, ok := <-events:
+			if !ok {
+				logger.Warn("events closed, exiting ...")
+				return
+			}
+			if _, err := s.HandleEvent(s.ctx, event, nil); err != nil {
+				logger.Error("Failed to handle %s event of %s: %v", event.Type, event.Username, err)
+			}
+		}
+	}
+}
+
+// HandleEvent saves the session log of event, with the session totals of
+// disconnects, in one transaction. claim, when not nil, runs first in that
+// transaction and the event is skipped when it reports false, so an event is
+// recorded as handled only along with its changes. It reports whether the event
+// was handled; on error nothing is saved and the event can be handled again.
+func (s *StatService) HandleEvent(ctx context.Context, event models.OcctlEvent, claim func(tx *gorm.DB) (bool, error)) (bool, error) {
+	sessionLog := &models.OcservUserSessionLog{
+		Username: event.Username,
+		IP:       event.RemoteIP,
+	}
+
+	var stats *UserStats
+	switch event.Type {
+	case models.OcctlEventConnect:
+		sessionLog.Event = models.EventConnect
+		sessionLog.Message = fmt.Sprintf("user connected (id %d) on %s, User-agent: %s", event.ID, event.Device, event.UserAgent)
+
+	case models.OcctlEventDisconnect:
+		// exclude rx/tx 0 from stats
+		if event.RX > 0 || event.TX > 0 {
+			stats = &UserStats{Username: event.Username, RX: int(event.RX), TX: int(event.TX)}
+		}
+		sessionLog.Event = models.EventDisconnect
+		sessionLog.Message = fmt.Sprintf("user disconnected (id %d), rx: %d, tx: %d, reason: %s", event.ID, event.RX, event.TX, event.Reason)
+
+	default:
+		return false, nil
+	}
+
+	db := database.GetConnection().WithContext(ctx)
+	// located before the transaction, a failed lookup would abort it
+	s.locateSessionLog(db, sessionLog)
+
+	handled := false
+	var locked *models.OcservUser
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if claim != nil {
+			claimed, err := claim(tx)
+			if err != nil || !claimed {
+				return err
+			}
+		}
+		if stats != nil {
+			var err error
+			if locked, err = s.saveRxTx(tx, stats); err != nil {
+				return fmt.Errorf("save rx/tx of %s: %w", stats.Username, err)
+			}
+		}
+		if err := tx.Save(sessionLog).Error; err != nil {
+			return fmt.Errorf("save session log of %s: %w", sessionLog.Username, err)
+		}
+		handled = true
+		return nil
+	})
+	if err != nil || !handled {
+		return false, err
+	}
+	if stats != nil {
+		logger.Info("Saved RxTx stats: %v", stats)
+	}
+
+	// the user is locked on ocserv once the lock is saved, even if the caller is gone
+	s.lockOnNodes(context.WithoutCancel(ctx), locked)
+	s.inspectSessionLog(db, sessionLog)
+	return true, nil
+}
+
 func (s *StatService) getUserSessionLog(cleanLine string) *models.OcservUserSessionLog {
 	workerRe := regexp.MustCompile(`worker\[(?P<user>[^\]]+)\]:\s*(?P<rest>.*)`)
 	ipRe := regexp.MustCompile(`^(?P<ip>\d+\.\d+\.\d+\.\d+)(?::\d+)?\s+(?P<rest>.*)$`)
@@ -165,18 +261,18 @@ func (s *StatService) getDisconnectStat(cleanLine string) (*UserStats, error) {
 	return nil, nil
 }
 
-func (s *StatService) saveRxTx(ctx context.Context, u *UserStats) error {
+// saveRxTx adds the session totals u to the traffic of the user and marks it
+// locked when it went over its quota. The user to lock on ocserv is returned,
+// the caller locks it with lockOnNodes once the change is committed.
+func (s *StatService) saveRxTx(db *gorm.DB, u *UserStats) (*models.OcservUser, error) {
 	logger.Info("saveRxTx called for user=%s RX=%d TX=%d", u.Username, u.RX, u.TX)
 
-	db := database.GetConnection()
-	db = db.WithContext(ctx)
-
 	var ocUser models.OcservUser
 
-	err := db.Where("username = ? ", u.Username).First(&ocUser).Error
+	err := db.Preload("Servers").Where("username = ? ", u.Username).First(&ocUser).Error
 	if err != nil {
 		logger.Error("Error finding oc user: %v", err)
-		return err
+		return nil, err
 	}
 
 	traffic := models.OcservUserTrafficStatistics{
@@ -188,7 +284,7 @@ func (s *StatService) saveRxTx(ctx context.Context, u *UserStats) error {
 	err = db.Create(&traffic).Error
 	if err != nil {
 		logger.Error("Error creating traffic stats: %v", err)
-		return err
+		return nil, err
 	}
 
 	ocUser.Rx += u.RX
@@ -196,24 +292,25 @@ func (s *StatService) saveRxTx(ctx context.Context, u *UserStats) error {
 
 	var trafficSizeBytes = ocUser.TrafficSize * (1 << 30)
 
-	totalMonthStats, err := s.getCurrentMonthTotals(db, ocUser.ID)
+	totalCycleStats, err := s.getCurrentCycleTotals(db, &ocUser)
 	if err != nil {
-		logger.Error("Error getting current month stats: %v", err)
-		return err
+		logger.Error("Error getting current billing cycle stats: %v", err)
+		return nil, err
 	}
 
+	var overQuota bool
 	switch ocUser.TrafficType {
 	case models.TotallyTransmit:
-		ocUser.IsLocked = ocUser.Tx >= trafficSizeBytes
+		overQuota = ocUser.Tx >= trafficSizeBytes
 
 	case models.TotallyReceive:
-		ocUser.IsLocked = ocUser.Rx >= trafficSizeBytes
+		overQuota = ocUser.Rx >= trafficSizeBytes
 
 	case models.MonthlyTransmit:
-		ocUser.IsLocked = totalMonthStats.TotalTx >= trafficSizeBytes
+		overQuota = totalCycleStats.TotalTx >= trafficSizeBytes
 
 	case models.MonthlyReceive:
-		ocUser.IsLocked = totalMonthStats.TotalRx >= trafficSizeBytes
+		overQuota = totalCycleStats.TotalRx >= trafficSizeBytes
 
 	case models.Free:
 
@@ -221,50 +318,76 @@ func (s *StatService) saveRxTx(ctx context.Context, u *UserStats) error {
 		logger.Error("Unknown traffic type: %v", ocUser.TrafficType)
 	}
 
-	now := time.Now()
-	if ocUser.IsLocked {
-		var lockFunc func(username string) (string, error)
-		if s.dockerMode {
-			lockFunc = s.occtlDockerRepo.Lock
-		} else {
-			lockFunc = s.ocservUserRepo.Lock
-		}
-		_, err = lockFunc(ocUser.Username)
-		if err != nil {
-			logger.Error("Error locking user: %v", err)
-		}
-		ocUser.DeactivatedAt = &now
+	// users locked for another reason keep it, they are not unlocked on the next cycle
+	var locked *models.OcservUser
+	if overQuota && !ocUser.IsLocked {
+		markLocked(&ocUser, models.LockReasonQuota)
+		locked = &ocUser
 	}
-	err = db.Save(&ocUser).Error
+	err = db.Omit("Servers").Save(&ocUser).Error
 	if err != nil {
 		logger.Error("Error updating user stats: %v", err)
-		return err
+		return nil, err
 	}
-	return nil
+	return locked, nil
 }
 
-func (s *StatService) saveSessionLog(ctx context.Context, log *models.OcservUserSessionLog) error {
-	db := database.GetConnection()
-	db = db.WithContext(ctx)
+// lockUser locks the user on the ocserv of every server it is deployed to and marks
+// it deactivated for reason, the caller saves it.
+func (s *StatService) lockUser(ctx context.Context, ocUser *models.OcservUser, reason string) {
+	s.lockOnNodes(ctx, ocUser)
+	markLocked(ocUser, reason)
+}
 
-	err := db.Save(log).Error
-	if err != nil {
-		logger.Error("Error updating user stats: %v", err)
+// lockOnNodes locks the user on the ocserv of every server it is deployed to, it
+// does nothing when ocUser is nil.
+func (s *StatService) lockOnNodes(ctx context.Context, ocUser *models.OcservUser) {
+	if ocUser == nil {
+		return
+	}
+	err := driver.FanOut(ctx, driver.Nodes(ocUser.Servers), func(_ driver.Node, d driver.Driver) error {
+		_, err := d.Users().Lock(ocUser.Username)
 		return err
+	})
+	if err != nil {
+		logger.Error("Error locking user: %v", err)
 	}
-
-	return nil
 }
 
-func (s *StatService) getCurrentMonthTotals(db *gorm.DB, userID uint) (Totals, error) {
+// markLocked marks the user deactivated for reason.
+func markLocked(ocUser *models.OcservUser, reason string) {
 	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, 0)
+	ocUser.IsLocked = true
+	ocUser.DeactivatedAt = &now
+	ocUser.LockReason = reason
+}
+
+// locateSessionLog tags the log with its GeoIP location before it is saved, a
+// failed lookup leaves it untagged.
+func (s *StatService) locateSessionLog(db *gorm.DB, log *models.OcservUserSessionLog) {
+	if err := s.enrichSessionLog(db, log); err != nil {
+		logger.Warn("Error enriching session log of %s: %v", log.Username, err)
+	}
+	if log.NewCountry {
+		logger.Warn("User %s connected from a new country %s (%s)", log.Username, log.Country, log.IP)
+	}
+}
+
+// inspectSessionLog tracks the device and checks the anomalies of a saved log.
+func (s *StatService) inspectSessionLog(db *gorm.DB, log *models.OcservUserSessionLog) {
+	s.trackDevice(db, log)
+	s.detectAnomalies(db, log)
+}
+
+// getCurrentCycleTotals sums the traffic of the user in its current billing
+// cycle, which starts on the day of month of its creation or renewal.
+func (s *StatService) getCurrentCycleTotals(db *gorm.DB, ocUser *models.OcservUser) (Totals, error) {
+	cycleStart, cycleEnd := ocUser.BillingCycle(time.Now())
 
 	var result Totals
 	err := db.Model(&models.OcservUserTrafficStatistics{}).
 		Select("SUM(rx) as total_rx, SUM(tx) as total_tx").
-		Where("oc_user_id = ? AND created_at >= ? AND created_at < ?", userID, startOfMonth, endOfMonth).
+		Where("oc_user_id = ? AND created_at >= ? AND created_at < ?", ocUser.ID, cycleStart, cycleEnd).
 		Scan(&result).Error
 
 	return result, err
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database/dbtest"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/geoip"
+	localModels "github.com/mmtaee/ocserv-dashboard/log_stream/internal/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+// Locations of the stubbed GeoIP database.
+var testLocations = map[string]*geoip.Record{
+	"198.51.100.1": {CountryCode: "DE", City: "Berlin", Latitude: 52.52, Longitude: 13.405},
+	"198.51.100.2": {CountryCode: "DE", City: "Potsdam", Latitude: 52.39, Longitude: 13.06},
+	"203.0.113.1":  {CountryCode: "JP", City: "Tokyo", Latitude: 35.68, Longitude: 139.69},
+	"192.0.2.1":    {CountryCode: "FR"},
+}
+
+type statsFixture struct {
+	svc  *StatService
+	fake *driver.FakeDriver
+	db   *gorm.DB
+	now  time.Time
+}
+
+// newStatsFixture returns a service on the fake ocserv driver, a test database
+// holding policy and the user john, and a clock the test can move.
+func newStatsFixture(t *testing.T, policy localModels.System) *statsFixture {
+	t.Helper()
+
+	db := dbtest.Open(t,
+		&models.OcservUser{},
+		&models.OcservUserSessionLog{},
+		&models.OcservUserTrafficStatistics{},
+		&models.OcservUserAlert{},
+		&models.OcservUserDevice{},
+		&localModels.System{},
+	)
+	// creating fills the zero values with the column defaults, the policy is saved over them
+	row := policy
+	require.NoError(t, db.Create(&row).Error)
+	require.NoError(t, db.Model(&row).Updates(map[string]interface{}{
+		"anomaly_detection":          policy.AnomalyDetection,
+		"anomaly_auto_lock":          policy.AnomalyAutoLock,
+		"anomaly_max_concurrent_ips": policy.AnomalyMaxConcurrentIPs,
+		"anomaly_max_travel_kmh":     policy.AnomalyMaxTravelKmh,
+		"anomaly_max_user_agents":    policy.AnomalyMaxUserAgents,
+	}).Error)
+
+	t.Setenv("OCSERV_DRIVER", driver.Fake)
+	d, err := driver.Init(false)
+	require.NoError(t, err)
+	fake := d.(*driver.FakeDriver)
+
+	user := models.OcservUser{Username: "john", Password: "secret", TrafficType: models.Free}
+	require.NoError(t, db.Create(&user).Error)
+	require.NoError(t, fake.Users().Create("defaults", "john", "secret", nil))
+
+	f := &statsFixture{fake: fake, db: db, now: time.Now()}
+	f.svc = &StatService{
+		ctx:             context.Background(),
+		ocservOcctlRepo: fake.Occtl(),
+		anomaly:         &anomalyState{now: func() time.Time { return f.now }},
+		geoLookup: func(ip string) *geoip.Record {
+			return testLocations[ip]
+		},
+	}
+	return f
+}
+
+// sessionLog saves a session log of john at the given time.
+func (f *statsFixture) sessionLog(t *testing.T, event, ip, message string, at time.Time) *models.OcservUserSessionLog {
+	t.Helper()
+
+	log := &models.OcservUserSessionLog{
+		Username:  "john",
+		IP:        ip,
+		Event:     event,
+		Message:   message,
+		CreatedAt: at,
+	}
+	require.NoError(t, f.db.Create(log).Error)
+	return log
+}
+
+func (f *statsFixture) alerts(t *testing.T) []models.OcservUserAlert {
+	t.Helper()
+
+	var alerts []models.OcservUserAlert
+	require.NoError(t, f.db.Order("id").Find(&alerts).Error)
+	return alerts
+}
@@ -5,10 +5,15 @@ import (
 	"flag"
 	"fmt"
 	"github.com/joho/godotenv"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/leader"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
-	"github.com/mmtaee/ocserv-dashboard/log_stream/internal/readers"
+	"github.com/mmtaee/ocserv-dashboard/log_stream/internal/agent"
 	"github.com/mmtaee/ocserv-dashboard/log_stream/internal/sse"
 	"github.com/mmtaee/ocserv-dashboard/log_stream/internal/stats"
 	"net/http"
@@ -18,10 +23,16 @@ import (
 )
 
 var (
-	debug      bool
-	host       string
-	port       int
-	dockerMode bool
+	debug       bool
+	host        string
+	port        int
+	dockerMode  bool
+	eventSource string
+)
+
+const (
+	eventSourceLogs  = "logs"
+	eventSourceOcctl = "occtl"
 )
 
 func main() {
@@ -29,12 +40,12 @@ func main() {
 	flag.StringVar(&host, "h", "0.0.0.0", "Server Host")
 	flag.IntVar(&port, "p", 8080, "Server Port")
 	flag.BoolVar(&dockerMode, "docker-mode", false, "Docker Mode")
+	flag.StringVar(&eventSource, "event-source", eventSourceLogs, "User stats event source (logs|occtl)")
 	flag.Parse()
 
 	ctx, cancel := context.WithCancel(context.Background())
-	service := "ocserv"
 
-	logger.Init(ctx, 100)
+	logger.Init(ctx, "log_stream", 100)
 
 	if err := godotenv.Load(); err != nil {
 		logger.Warn("Error loading .env file, using system environment")
@@ -45,37 +56,59 @@ func main() {
 
 	database.Connect()
 
+	// every replica streams the logs, only the leader counts the user stats
+	elector := leader.New(database.GetConnection(), "log_stream")
+	electorDone := make(chan struct{})
+	go func() {
+		defer close(electorDone)
+		elector.Run(ctx, nil)
+	}()
+
 	streamChan := make(chan string, 1000)
 	lineLogChan := make(chan string, 1000)
 	broadcastChan := make(chan string, 1000)
 
-	if !dockerMode {
-		logger.Info("Systemd Mode")
+	ocservDriver, err := driver.Init(dockerMode)
+	if err != nil {
+		logger.Fatal("Failed to create ocserv driver: %v", err)
+	}
+
+	go func() {
+		if err := ocservDriver.Logs(ctx, streamChan); err != nil {
+			logger.Error("Stream Logs Error: %v", err)
+		}
+	}()
+
+	if eventSource == eventSourceOcctl && !ocservDriver.SupportsEvents() {
+		logger.Warn("occtl event source is not available with the %s driver, using logs", ocservDriver.Name())
+		eventSource = eventSourceLogs
+	}
+
+	statService := stats.NewStatService(ctx, lineLogChan, ocservDriver)
+	if eventSource == eventSourceOcctl {
+		logger.Info("User stats from occtl events")
+		// log lines are still streamed to /logs but not parsed for stats
+		lineLogChan = nil
+
+		events := make(chan models.OcctlEvent, 1000)
+		go occtl.WatchEvents(ctx, ocservDriver.Occtl(), events)
 		go func() {
-			if err := readers.SystemdStreamLogs(ctx, service, streamChan); err != nil {
-				logger.Error("Systemd Stream Logs Error: %v", err)
-			}
+			statService.CalculateUserStatsFromEvents(leaderEvents(ctx, events, elector.IsLeader))
 		}()
 	} else {
-		logger.Info("Docker Mode")
 		go func() {
-			if err := readers.DockerStreamLogs(ctx, service, streamChan); err != nil {
-				logger.Error("Docker Stream Logs Error: %v", err)
-			}
+			statService.CalculateUserStats()
 		}()
 	}
 
-	statService := stats.NewStatService(ctx, lineLogChan, dockerMode)
-	go func() {
-		statService.CalculateUserStats()
-	}()
-
 	sseServer := sse.NewSSEServer()
 	sseServer.StartBroadcast(broadcastChan)
 
 	go func() {
 		server := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 		http.HandleFunc("/logs", sseServer.SSEHandler())
+		// session events pushed by the node agents of the servers
+		http.Handle(occtlDocker.EventsPath, agent.NewIngest(statService))
 
 		logger.Info("Starting server on %s", server)
 		if err := http.ListenAndServe(server, nil); err != nil {
@@ -84,7 +117,7 @@ func main() {
 	}()
 
 	go func() {
-		start(ctx, streamChan, broadcastChan, lineLogChan)
+		start(ctx, streamChan, broadcastChan, lineLogChan, elector.IsLeader)
 	}()
 
 	sigChan := make(chan os.Signal, 1)
@@ -97,10 +130,11 @@ func main() {
 	}()
 
 	<-ctx.Done()
+	<-electorDone
 	logger.Info("Log stream service shutting down successfully")
 }
 
-func start(ctx context.Context, streamText <-chan string, broadcaster, lineLogChan chan<- string) {
+func start(ctx context.Context, streamText <-chan string, broadcaster, lineLogChan chan<- string, isLeader func() bool) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -120,7 +154,10 @@ func start(ctx context.Context, streamText <-chan string, broadcaster, lineLogCh
 				}
 			}(line)
 
-			// Send to lineLogChan
+			// Send to lineLogChan, on the leader only so traffic is counted once
+			if lineLogChan == nil || !isLeader() {
+				continue
+			}
 			go func(l string) {
 				select {
 				case lineLogChan <- l:
@@ -130,3 +167,31 @@ func start(ctx context.Context, streamText <-chan string, broadcaster, lineLogCh
 		}
 	}
 }
+
+// leaderEvents forwards the events of in while this replica is the leader and
+// drops them otherwise, so traffic is counted once.
+func leaderEvents(ctx context.Context, in <-chan models.OcctlEvent, isLeader func() bool) <-chan models.OcctlEvent {
+	out := make(chan models.OcctlEvent, cap(in))
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-in:
+				if !ok {
+					return
+				}
+				if !isLeader() {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,311 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/schedule"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sync"
+	"time"
+)
+
+// pollInterval is how often the jobs table is read for schedule changes, pauses
+// and manual triggers.
+const pollInterval = 5 * time.Second
+
+// Job is a job of the scheduler. Run returns the number of users it affected.
+type Job struct {
+	Name            string
+	DefaultSchedule string
+	Run             func(ctx context.Context, db *gorm.DB) (int, error)
+}
+
+// Scheduler runs the registered jobs on the schedules of the jobs table and
+// records every run in job_runs. Every replica follows the table, only the leader
+// runs the jobs, under the context of its term.
+type Scheduler struct {
+	db    *gorm.DB
+	term  func() (context.Context, bool)
+	cron  *cron.Cron
+	jobs  map[string]Job
+	names []string
+	// now replaces time.Now in tests
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry
+	running map[string]bool
+}
+
+// entry is the cron entry of a job and the schedule it was added with.
+type entry struct {
+	id       cron.EntryID
+	schedule string
+}
+
+// New returns a scheduler running the jobs while term reports this replica as the
+// leader, see leader.Elector.Term.
+func New(db *gorm.DB, term func() (context.Context, bool)) *Scheduler {
+	return &Scheduler{
+		db:      db,
+		term:    term,
+		cron:    cron.New(cron.WithSeconds()),
+		jobs:    map[string]Job{},
+		entries: map[string]entry{},
+		running: map[string]bool{},
+		now:     time.Now,
+	}
+}
+
+// Register adds job to the scheduler, jobs are registered before Start. Missed
+// runs are caught up in the order the jobs are registered.
+func (s *Scheduler) Register(job Job) {
+	s.jobs[job.Name] = job
+	s.names = append(s.names, job.Name)
+}
+
+// Start adds the registered jobs missing from the jobs table with their default
+// schedule, schedules them and follows the table until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) error {
+	for _, name := range s.names {
+		err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.Job{Name: name, Schedule: s.jobs[name].DefaultSchedule}).Error
+		if err != nil {
+			return fmt.Errorf("add job %s: %w", name, err)
+		}
+	}
+
+	s.sync(ctx)
+	s.cron.Start()
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				<-s.cron.Stop().Done()
+				logger.Info("Job scheduler stopped")
+				return
+			case <-ticker.C:
+				s.sync(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// sync applies the schedule changes of the jobs table and runs the triggered jobs.
+func (s *Scheduler) sync(ctx context.Context) {
+	var rows []models.Job
+	if err := s.db.WithContext(ctx).Where("name IN ?", s.names).Find(&rows).Error; err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to read jobs: %v", err)
+		}
+		return
+	}
+
+	for _, row := range rows {
+		s.schedule(ctx, row)
+
+		if _, leader := s.term(); row.TriggerRequestedAt == nil || !leader {
+			continue
+		}
+		// the trigger is cleared first so only one replica takes it
+		result := s.db.WithContext(ctx).Model(&models.Job{}).
+			Where("name = ? AND trigger_requested_at = ?", row.Name, *row.TriggerRequestedAt).
+			Update("trigger_requested_at", nil)
+		if result.Error != nil {
+			logger.Error("Failed to take trigger of job %s: %v", row.Name, result.Error)
+			continue
+		}
+		if result.RowsAffected == 1 {
+			go s.run(ctx, row.Name, models.JobTriggerManual)
+		}
+	}
+}
+
+// schedule adds the cron entry of row, replacing the current one when the
+// schedule changed. Invalid schedules keep the current entry.
+func (s *Scheduler) schedule(ctx context.Context, row models.Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.entries[row.Name]
+	if ok && current.schedule == row.Schedule {
+		return
+	}
+
+	spec, err := schedule.Parse(row.Schedule)
+	if err != nil {
+		logger.Error("Invalid schedule %q of job %s: %v", row.Schedule, row.Name, err)
+		return
+	}
+
+	name := row.Name
+	id := s.cron.Schedule(spec, cron.FuncJob(func() {
+		s.run(ctx, name, models.JobTriggerSchedule)
+	}))
+	if ok {
+		s.cron.Remove(current.id)
+		logger.Info("Job %s rescheduled to %s", name, row.Schedule)
+	} else {
+		logger.Info("Job %s scheduled at %s", name, row.Schedule)
+	}
+	s.entries[name] = entry{id: id, schedule: row.Schedule}
+}
+
+// RunMissed runs the jobs with a scheduled run since their last run, or since
+// they were added when they never ran, once each. Runs left running by a previous
+// leader are marked failed first. It runs every time this replica is elected.
+func (s *Scheduler) RunMissed(ctx context.Context) {
+	s.interruptRuns(ctx)
+
+	var rows []models.Job
+	if err := s.db.WithContext(ctx).Where("name IN ?", s.names).Find(&rows).Error; err != nil {
+		logger.Error("Failed to read jobs: %v", err)
+		return
+	}
+	byName := make(map[string]models.Job, len(rows))
+	for _, row := range rows {
+		byName[row.Name] = row
+	}
+
+	now := s.now()
+	for _, name := range s.names {
+		row, ok := byName[name]
+		if !ok || row.Paused {
+			continue
+		}
+
+		spec, err := schedule.Parse(row.Schedule)
+		if err != nil {
+			logger.Error("Invalid schedule %q of job %s: %v", row.Schedule, name, err)
+			continue
+		}
+
+		since := row.CreatedAt
+		if row.LastRunAt != nil {
+			since = *row.LastRunAt
+		}
+		if !schedule.Missed(spec, since, now) {
+			continue
+		}
+
+		logger.Info("Running missed job %s, last run %s", name, since.Format(time.RFC3339))
+		s.run(ctx, name, models.JobTriggerMissed)
+	}
+}
+
+// interruptRuns marks the runs left running by a previous leader as failed.
+func (s *Scheduler) interruptRuns(ctx context.Context) {
+	s.mu.Lock()
+	var active []string
+	for name := range s.running {
+		active = append(active, name)
+	}
+	s.mu.Unlock()
+
+	query := s.db.WithContext(ctx).Model(&models.JobRun{}).Where("status = ?", models.JobRunRunning)
+	if len(active) > 0 {
+		query = query.Where("job_name NOT IN ?", active)
+	}
+	err := query.Updates(map[string]interface{}{
+		"status":      models.JobRunFailed,
+		"finished_at": s.now(),
+		"error":       "interrupted, the replica running it stopped",
+	}).Error
+	if err != nil {
+		logger.Error("Failed to mark interrupted job runs: %v", err)
+	}
+}
+
+// run runs the job name on the leader and records the run. The run is canceled
+// when the replica steps down. Scheduled runs of paused jobs are skipped, a job
+// never runs twice at the same time.
+func (s *Scheduler) run(ctx context.Context, name, trigger string) {
+	term, leader := s.term()
+	if !leader {
+		logger.Info("Skipping %s job, this replica is not the leader", name)
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(term, cancel)
+	defer stop()
+
+	if trigger == models.JobTriggerSchedule {
+		var row models.Job
+		if err := s.db.WithContext(ctx).Where("name = ?", name).First(&row).Error; err != nil {
+			logger.Error("Failed to read job %s: %v", name, err)
+			return
+		}
+		if row.Paused {
+			logger.Info("Skipping paused %s job", name)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	if s.running[name] {
+		s.mu.Unlock()
+		logger.Warn("Job %s is already running, %s run skipped", name, trigger)
+		return
+	}
+	s.running[name] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, name)
+		s.mu.Unlock()
+	}()
+
+	// one request ID per run correlates the webhook calls of all its users
+	requestID := requestid.New()
+	ctx = requestid.NewContext(ctx, requestID)
+	log := logger.FromContext(ctx).With("job", name, "trigger", trigger)
+	db := s.db.WithContext(ctx)
+
+	jobRun := models.JobRun{
+		JobName:   name,
+		Trigger:   trigger,
+		Status:    models.JobRunRunning,
+		StartedAt: s.now(),
+		RequestID: requestID,
+	}
+	if err := db.Create(&jobRun).Error; err != nil {
+		log.Error("Failed to record job run: %v", err)
+		return
+	}
+
+	log.Info("Job started")
+	affected, err := s.jobs[name].Run(ctx, db)
+	finishedAt := s.now()
+
+	updates := map[string]interface{}{
+		"status":      models.JobRunSucceeded,
+		"finished_at": finishedAt,
+		"affected":    affected,
+	}
+	if err != nil {
+		updates["status"] = models.JobRunFailed
+		updates["error"] = err.Error()
+		log.Error("Job failed after %s, %d users affected: %v", finishedAt.Sub(jobRun.StartedAt), affected, err)
+	} else {
+		log.Info("Job completed in %s, %d users affected", finishedAt.Sub(jobRun.StartedAt), affected)
+	}
+
+	// the run is recorded even when ctx was canceled during the job
+	db = s.db.WithContext(context.WithoutCancel(ctx))
+	if err = db.Model(&jobRun).Updates(updates).Error; err != nil {
+		log.Error("Failed to record job run: %v", err)
+	}
+	if err = db.Model(&models.Job{}).Where("name = ?", name).Update("last_run_at", jobRun.StartedAt).Error; err != nil {
+		log.Error("Failed to save last run of job: %v", err)
+	}
+}
@@ -0,0 +1,365 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"sync"
+	"testing"
+	"time"
+)
+
+const daily = "0 1 0 * * *"
+
+// testNow is the time of the fake clock, noon after the daily run of 00:01.
+var testNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+
+// replica is a scheduler with a fake clock and a leadership the test hands over.
+type replica struct {
+	*Scheduler
+
+	mu      sync.Mutex
+	current context.Context
+	endTerm context.CancelFunc
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	return dbtest.Open(t, &models.Job{}, &models.JobRun{})
+}
+
+func newReplica(db *gorm.DB, jobs ...Job) *replica {
+	r := &replica{}
+	r.Scheduler = New(db, r.currentTerm)
+	r.Scheduler.now = func() time.Time { return testNow }
+	for _, job := range jobs {
+		r.Register(job)
+	}
+	return r
+}
+
+func (r *replica) currentTerm() (context.Context, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current == nil || r.current.Err() != nil {
+		return nil, false
+	}
+	return r.current, true
+}
+
+func (r *replica) elect() context.Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current, r.endTerm = context.WithCancel(context.Background())
+	return r.current
+}
+
+func (r *replica) stepDown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.endTerm != nil {
+		r.endTerm()
+	}
+}
+
+// countingJob returns a job sending the context of each of its runs to runs.
+func countingJob(name string, runs chan<- context.Context) Job {
+	return Job{
+		Name:            name,
+		DefaultSchedule: daily,
+		Run: func(ctx context.Context, _ *gorm.DB) (int, error) {
+			runs <- ctx
+			return 1, nil
+		},
+	}
+}
+
+func addJob(t *testing.T, db *gorm.DB, job models.Job) {
+	t.Helper()
+
+	if job.Schedule == "" {
+		job.Schedule = daily
+	}
+	require.NoError(t, db.Create(&job).Error)
+	// creating fills the zero values with the column defaults
+	require.NoError(t, db.Model(&job).Update("paused", job.Paused).Error)
+}
+
+func jobRuns(t *testing.T, db *gorm.DB) []models.JobRun {
+	t.Helper()
+
+	var runs []models.JobRun
+	require.NoError(t, db.Order("id").Find(&runs).Error)
+	return runs
+}
+
+func waitRun(t *testing.T, runs <-chan context.Context) {
+	t.Helper()
+
+	select {
+	case <-runs:
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "expected the job to run")
+	}
+}
+
+func noRun(t *testing.T, runs <-chan context.Context) {
+	t.Helper()
+
+	select {
+	case <-runs:
+		require.FailNow(t, "expected the job not to run")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func requestTrigger(t *testing.T, db *gorm.DB, name string) {
+	t.Helper()
+
+	at := testNow
+	require.NoError(t, db.Model(&models.Job{}).Where("name = ?", name).Update("trigger_requested_at", &at).Error)
+}
+
+func TestSync_TriggerHandOff(t *testing.T) {
+	db := newTestDB(t)
+	addJob(t, db, models.Job{Name: "expire"})
+
+	runs := make(chan context.Context, 10)
+	a, b := newReplica(db, countingJob("expire", runs)), newReplica(db, countingJob("expire", runs))
+	a.elect()
+	ctx := context.Background()
+
+	requestTrigger(t, db, "expire")
+	b.sync(ctx)
+	noRun(t, runs)
+
+	a.sync(ctx)
+	waitRun(t, runs)
+
+	var job models.Job
+	require.NoError(t, db.First(&job, "name = ?", "expire").Error)
+	require.Nil(t, job.TriggerRequestedAt, "expected the trigger to be taken")
+
+	// the leadership moves to b, the next trigger is taken by b only
+	a.stepDown()
+	b.elect()
+	requestTrigger(t, db, "expire")
+	a.sync(ctx)
+	noRun(t, runs)
+	b.sync(ctx)
+	waitRun(t, runs)
+
+	got := jobRuns(t, db)
+	require.Len(t, got, 2)
+	assert.Equal(t, models.JobTriggerManual, got[0].Trigger)
+	assert.Equal(t, models.JobTriggerManual, got[1].Trigger)
+}
+
+func TestRun_Pause(t *testing.T) {
+	tests := []struct {
+		name    string
+		paused  bool
+		trigger string
+		wantRun bool
+	}{
+		{name: "scheduled run", trigger: models.JobTriggerSchedule, wantRun: true},
+		{name: "scheduled run of a paused job", paused: true, trigger: models.JobTriggerSchedule},
+		{name: "manual run of a paused job", paused: true, trigger: models.JobTriggerManual, wantRun: true},
+		{name: "missed run of a paused job", paused: true, trigger: models.JobTriggerMissed, wantRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			addJob(t, db, models.Job{Name: "expire", Paused: tt.paused})
+
+			runs := make(chan context.Context, 1)
+			r := newReplica(db, countingJob("expire", runs))
+			r.elect()
+
+			r.run(context.Background(), "expire", tt.trigger)
+
+			got := jobRuns(t, db)
+			if !tt.wantRun {
+				assert.Empty(t, got)
+				return
+			}
+			waitRun(t, runs)
+			require.Len(t, got, 1)
+			assert.Equal(t, models.JobRunSucceeded, got[0].Status)
+			assert.Equal(t, 1, got[0].Affected)
+			assert.WithinDuration(t, testNow, got[0].StartedAt, 0)
+		})
+	}
+}
+
+func TestRun_NotLeader(t *testing.T) {
+	db := newTestDB(t)
+	addJob(t, db, models.Job{Name: "expire"})
+
+	runs := make(chan context.Context, 1)
+	r := newReplica(db, countingJob("expire", runs))
+
+	r.run(context.Background(), "expire", models.JobTriggerManual)
+
+	noRun(t, runs)
+	assert.Empty(t, jobRuns(t, db), "expected no run on a follower")
+}
+
+func TestRun_CanceledOnStepDown(t *testing.T) {
+	db := newTestDB(t)
+	addJob(t, db, models.Job{Name: "expire"})
+
+	started := make(chan struct{})
+	r := newReplica(db, Job{
+		Name:            "expire",
+		DefaultSchedule: daily,
+		Run: func(ctx context.Context, _ *gorm.DB) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		},
+	})
+	r.elect()
+
+	done := make(chan struct{})
+	go func() {
+		r.run(context.Background(), "expire", models.JobTriggerManual)
+		close(done)
+	}()
+	<-started
+	r.stepDown()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "expected the run to stop when the replica steps down")
+	}
+	got := jobRuns(t, db)
+	require.Len(t, got, 1)
+	assert.Equal(t, models.JobRunFailed, got[0].Status)
+	assert.Equal(t, context.Canceled.Error(), got[0].Error)
+}
+
+func TestSchedule_Reschedule(t *testing.T) {
+	// scheduling does not touch the database
+	r := newReplica(nil)
+	ctx := context.Background()
+
+	r.schedule(ctx, models.Job{Name: "expire", Schedule: daily})
+	first := r.entries["expire"]
+	require.Equal(t, daily, first.schedule)
+	require.NotZero(t, r.cron.Entry(first.id).ID, "expected the job to be scheduled")
+
+	r.schedule(ctx, models.Job{Name: "expire", Schedule: daily})
+	assert.Equal(t, first, r.entries["expire"], "expected an unchanged schedule to keep the entry")
+
+	hourly := "0 0 * * * *"
+	r.schedule(ctx, models.Job{Name: "expire", Schedule: hourly})
+	second := r.entries["expire"]
+	require.Equal(t, hourly, second.schedule)
+	require.NotEqual(t, first.id, second.id, "expected the job to be rescheduled")
+	assert.Zero(t, r.cron.Entry(first.id).ID, "expected the old entry to be removed")
+	next := r.cron.Entry(second.id).Schedule.Next(testNow)
+	assert.Equal(t, testNow.Add(time.Hour), next)
+
+	r.schedule(ctx, models.Job{Name: "expire", Schedule: "every full moon"})
+	assert.Equal(t, second, r.entries["expire"], "expected an invalid schedule to keep the current entry")
+	assert.Len(t, r.cron.Entries(), 1)
+}
+
+func TestRunMissed(t *testing.T) {
+	yesterday := testNow.Add(-24 * time.Hour)
+	today := time.Date(2026, 10, 19, 0, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		job     models.Job
+		wantRun bool
+	}{
+		{name: "run missed since the last run", job: models.Job{LastRunAt: &yesterday}, wantRun: true},
+		{name: "ran after the last scheduled run", job: models.Job{LastRunAt: &today}},
+		{name: "never ran, added before the last scheduled run", job: models.Job{CreatedAt: yesterday}, wantRun: true},
+		{name: "never ran, added after the last scheduled run", job: models.Job{CreatedAt: today}},
+		{name: "paused", job: models.Job{LastRunAt: &yesterday, Paused: true}},
+		{name: "invalid schedule", job: models.Job{LastRunAt: &yesterday, Schedule: "every full moon"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			tt.job.Name = "expire"
+			addJob(t, db, tt.job)
+
+			runs := make(chan context.Context, 1)
+			r := newReplica(db, countingJob("expire", runs))
+			r.RunMissed(r.elect())
+
+			got := jobRuns(t, db)
+			if !tt.wantRun {
+				assert.Empty(t, got)
+				return
+			}
+			waitRun(t, runs)
+			require.Len(t, got, 1)
+			assert.Equal(t, models.JobTriggerMissed, got[0].Trigger)
+
+			var job models.Job
+			require.NoError(t, db.First(&job, "name = ?", "expire").Error)
+			require.NotNil(t, job.LastRunAt)
+			assert.WithinDuration(t, testNow, *job.LastRunAt, 0)
+		})
+	}
+}
+
+func TestInterruptRuns(t *testing.T) {
+	db := newTestDB(t)
+	started := testNow.Add(-time.Hour)
+	for _, run := range []models.JobRun{
+		{JobName: "expire", Trigger: models.JobTriggerSchedule, Status: models.JobRunRunning, StartedAt: started},
+		{JobName: "reset", Trigger: models.JobTriggerSchedule, Status: models.JobRunRunning, StartedAt: started},
+		{JobName: "delete", Trigger: models.JobTriggerSchedule, Status: models.JobRunSucceeded, StartedAt: started},
+	} {
+		require.NoError(t, db.Create(&run).Error)
+	}
+
+	r := newReplica(db)
+	// reset is still running on this replica
+	r.running["reset"] = true
+	r.interruptRuns(context.Background())
+
+	got := jobRuns(t, db)
+	require.Len(t, got, 3)
+	assert.Equal(t, models.JobRunFailed, got[0].Status, "expected the run left by the previous leader to fail")
+	if assert.NotNil(t, got[0].FinishedAt) {
+		assert.WithinDuration(t, testNow, *got[0].FinishedAt, 0)
+	}
+	assert.Equal(t, models.JobRunRunning, got[1].Status, "expected the run of this replica to keep running")
+	assert.Equal(t, models.JobRunSucceeded, got[2].Status, "expected the finished run to be kept")
+}
+
+func TestStart_AddsJobs(t *testing.T) {
+	db := newTestDB(t)
+	addJob(t, db, models.Job{Name: "expire", Schedule: "0 0 * * * *"})
+
+	runs := make(chan context.Context, 1)
+	r := newReplica(db, countingJob("expire", runs), countingJob("reset", runs))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, r.Start(ctx))
+
+	var jobs []models.Job
+	require.NoError(t, db.Order("name").Find(&jobs).Error)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "0 0 * * * *", jobs[0].Schedule)
+	assert.Equal(t, daily, jobs[1].Schedule, "expected the missing job added with its default schedule")
+	assert.Equal(t, "0 0 * * * *", r.entries["expire"].schedule)
+	assert.Equal(t, daily, r.entries["reset"].schedule)
+}
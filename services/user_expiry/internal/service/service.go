@@ -2,92 +2,41 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	commonModels "github.com/mmtaee/ocserv-dashboard/common/models"
-	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
-	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
-	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
-	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/requestid"
 	"github.com/mmtaee/ocserv-dashboard/user_expiry/internal/models"
-	stateManager "github.com/mmtaee/ocserv-dashboard/user_expiry/pkg/state"
-	"github.com/robfig/cron/v3"
+	"github.com/mmtaee/ocserv-dashboard/user_expiry/internal/scheduler"
 	"gorm.io/gorm"
 	"sync"
 	"time"
 )
 
+// Names of the jobs of the service.
+const (
+	JobExpireUsers        = "expire_users"
+	JobActiveMonthlyUsers = "active_monthly_users"
+	JobDeleteExpiredUsers = "delete_expired_users"
+)
+
 // CornService handles all scheduled background jobs related to
 // user expiration, monthly reactivation and auto-deletion.
 //
-// It supports both docker-mode and native ocserv mode.
+// ocserv is controlled through the configured ocserv driver, users assigned
+// to servers are locked and unlocked on each of their servers.
 type CornService struct {
-	occtlHandler      occtl.OcservOcctlInterface
-	ocservUserHandler user.OcservUserInterface
-	occtlDockerRepo   occtlDocker.OcservOcctlUsersDocker
-	dockerMode        bool
+	ocservDriver driver.Driver
 }
 
-// NewCornService initializes cron service.
-// If dockerMode is true, docker-based occtl commands will be used.
-// Otherwise, native ocserv handlers are used.
-func NewCornService(dockerMode bool) *CornService {
-	s := &CornService{
-		dockerMode: dockerMode,
-	}
-
-	if dockerMode {
-		s.occtlDockerRepo = occtlDocker.NewOcservOcctlDocker()
-	} else {
-		s.occtlHandler = occtl.NewOcservOcctl()
-		s.ocservUserHandler = user.NewOcservUser()
-	}
-	return s
+// NewCornService initializes cron service with the ocserv driver.
+func NewCornService(ocservDriver driver.Driver) *CornService {
+	return &CornService{ocservDriver: ocservDriver}
 }
 
-// MissedCron checks whether daily or monthly cron jobs were missed
-// (for example if the service was down) and executes them manually.
-//
-// It ensures:
-// - ExpireUsers runs once per day
-// - ActiveMonthlyUsers runs once per month (on first day)
-func (c *CornService) MissedCron() {
-	db := database.GetConnection()
-
-	state := stateManager.NewCronState()
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	lastRun := state.DailyLastRun.Truncate(24 * time.Hour)
-
-	// daily missed job
-	logger.Info("Start checking missing daily cron jobs")
-	if state.DailyLastRun.IsZero() || lastRun.Before(today) {
-		logger.Info("Running missed DAILY cron...")
-		c.ExpireUsers(context.Background(), db)
-		c.DeleteExpiredUsers(context.Background(), db)
-		state.DailyLastRun = today
-	} else {
-		logger.Info("Daily cron already ran today, skipping.")
-	}
-	logger.Info("Checking missing daily cron jobs completed")
-
-	// monthly missed job
-	logger.Info("start checking missing monthly cron jobs completed")
-	firstDay := today.Day() == 1
-	newMonth := state.MonthlyLastRun.IsZero() || state.MonthlyLastRun.Month() != today.Month()
-
-	if firstDay && newMonth {
-		logger.Info("Running missed MONTHLY cron...")
-		c.ActiveMonthlyUsers(context.Background(), db)
-		state.MonthlyLastRun = today
-	}
-	logger.Info("Checking missing monthly cron jobs completed")
-
-	if err := state.Save(); err != nil {
-		logger.Fatal("Failed to save state: %v", err)
-	}
-	logger.Info("Saving missing cron jobs completed")
-}
-
-// UserExpiryCron registers and starts all cron jobs:
+// Jobs returns the jobs of the service with their default schedules:
 //
 // Daily (00:01:00):
 //   - ExpireUsers
@@ -95,70 +44,23 @@ func (c *CornService) MissedCron() {
 // Daily (00:02:00):
 //   - DeleteExpiredUsers
 //
-// Monthly (1st & 2nd day at 00:01:00):
+// Daily (00:01:00), for the users whose billing cycle rolled over:
 //   - ActiveMonthlyUsers
-//
-// The cron stops when context is canceled.
-func (c *CornService) UserExpiryCron(ctx context.Context) {
-	cronJob := cron.New(cron.WithSeconds())
-	db := database.GetConnection()
-
-	state := stateManager.NewCronState()
-
-	// Every day at 00:01:00 — expire users
-	_, err := cronJob.AddFunc("0 1 0 * * *", func() {
-		c.ExpireUsers(ctx, db)
-
-		state.DailyLastRun = time.Now().Truncate(24 * time.Hour)
-		if err := state.Save(); err != nil {
-			logger.Error("Failed to save state: %v", err)
-		}
-	})
-	if err != nil {
-		logger.Fatal("Failed to add cron job: %v", err)
-	}
-	logger.Info("Running user expiry cron...")
-
-	// First and second day of each month at 00:01:00 — activate monthly users
-	_, err = cronJob.AddFunc("0 1 0 1,2 * *", func() {
-		c.ActiveMonthlyUsers(ctx, db)
-
-		state.MonthlyLastRun = time.Now().Truncate(24 * time.Hour)
-		if err = state.Save(); err != nil {
-			logger.Error("Failed to update state: %v", err)
-		}
-	})
-	if err != nil {
-		logger.Fatal("Failed to add cron job: %v", err)
+func (c *CornService) Jobs() []scheduler.Job {
+	return []scheduler.Job{
+		{Name: JobExpireUsers, DefaultSchedule: "0 1 0 * * *", Run: c.ExpireUsers},
+		{Name: JobDeleteExpiredUsers, DefaultSchedule: "0 2 0 * * *", Run: c.DeleteExpiredUsers},
+		{Name: JobActiveMonthlyUsers, DefaultSchedule: "0 1 0 * * *", Run: c.ActiveMonthlyUsers},
 	}
+}
 
-	logger.Info("User activating Cron starting...")
-
-	// Every day at 00:02:00 — delete expired users
-	_, err3 := cronJob.AddFunc("0 2 0 * * *", func() {
-		c.DeleteExpiredUsers(ctx, db)
-
-		state.DailyLastRun = time.Now().Truncate(24 * time.Hour)
-		if errSave := state.Save(); errSave != nil {
-			logger.Error("Failed to save state: %v", errSave)
-		}
-	})
-	if err3 != nil {
-		logger.Fatal("Failed to add cron job: %v", err3)
+// nodes returns the nodes of servers, the ocserv of the configured driver when
+// servers is empty.
+func (c *CornService) nodes(servers []commonModels.Server) []driver.Node {
+	if len(servers) == 0 {
+		return []driver.Node{{Name: driver.DefaultNode, Driver: c.ocservDriver}}
 	}
-	logger.Info("Running delete expired users cron...")
-
-	//// Test: run every minute at second 0
-	//_, err = cronJob.AddFunc("0 * * * * *", func() {
-	//	c.DeleteExpiredUsers(ctx, db)
-	//})
-
-	cronJob.Start()
-
-	<-ctx.Done()
-	logger.Warn("Received context cancel, shutting down...")
-	cronJob.Stop()
-	logger.Info("User activating Cron stopped...")
+	return driver.Nodes(servers)
 }
 
 // ExpireUsers finds users whose expire_at has passed
@@ -166,27 +68,35 @@ func (c *CornService) UserExpiryCron(ctx context.Context) {
 //
 // Actions performed per user:
 //   - Set deactivated_at = now
-//   - Set is_locked = true
+//   - Set is_locked = true, locked for expiry
 //   - Disconnect active session
 //   - Lock user in ocserv
 //
-// Runs concurrently with max 10 workers.
-func (c *CornService) ExpireUsers(ctx context.Context, db *gorm.DB) {
+// Runs concurrently with max 10 workers. It returns the number of deactivated
+// users and the failures of the others.
+func (c *CornService) ExpireUsers(ctx context.Context, db *gorm.DB) (int, error) {
+	// one request ID per run correlates the webhook calls of all its users
+	ctx, _ = requestid.Ensure(ctx)
+	log := logger.FromContext(ctx).With("job", "expire_users")
+
 	var users []commonModels.OcservUser
 
 	pastDay := time.Now().UTC().AddDate(0, 0, -1)
 	err := db.WithContext(ctx).
 		Select("id", "username", "expire_at").
+		Preload("Servers").
 		Where("expire_at IS NOT NULL").
 		Where("deactivated_at IS NULL").
 		Where("expire_at < ?", pastDay).
 		Find(&users).Error
 	if err != nil {
-		logger.Error("Failed to get users: %v", err)
+		log.Error("Failed to get users: %v", err)
+		return 0, fmt.Errorf("get users: %w", err)
 	}
 
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, 10)
+	result := &runResult{}
 
 	for _, u := range users {
 		wg.Add(1)
@@ -200,72 +110,84 @@ func (c *CornService) ExpireUsers(ctx context.Context, db *gorm.DB) {
 			if err2 := db.Model(&u).Updates(map[string]interface{}{ // CHANGED: using &u (copied)
 				"deactivated_at": time.Now(),
 				"is_locked":      true,
+				"lock_reason":    commonModels.LockReasonExpired,
 			}).Error; err2 != nil {
-				logger.Error("Failed to update user: %v", err2)
+				log.Error("Failed to update user: %v", err2)
+				result.fail(fmt.Errorf("update user %s: %w", u.Username, err2))
 				return
 			}
-
-			var (
-				disconnect func(string) (string, error)
-				lock       func(string) (string, error)
-			)
-
-			if c.dockerMode {
-				disconnect = c.occtlDockerRepo.DisconnectUser
-				lock = c.occtlDockerRepo.Lock
-			} else {
-				disconnect = c.occtlHandler.DisconnectUser
-				lock = c.ocservUserHandler.Lock
-			}
-
-			if _, err3 := disconnect(u.Username); err3 != nil {
-				logger.Error("Failed to disconnect user %s: %v", u.Username, err3)
-			}
-			if _, err4 := lock(u.Username); err4 != nil {
-				logger.Error("Failed to lock user %s: %v", u.Username, err4)
-			}
+			result.done()
+
+			_ = driver.FanOut(ctx, c.nodes(u.Servers), func(node driver.Node, d driver.Driver) error {
+				if _, err3 := d.Occtl().DisconnectUser(u.Username); err3 != nil {
+					log.Error("Failed to disconnect user %s on %s: %v", u.Username, node.Name, err3)
+				}
+				if _, err4 := d.Users().Lock(u.Username); err4 != nil {
+					log.Error("Failed to lock user %s on %s: %v", u.Username, node.Name, err4)
+					result.fail(fmt.Errorf("lock user %s on %s: %w", u.Username, node.Name, err4))
+				}
+				return nil
+			})
 			return
 		}(u)
 	}
 
 	wg.Wait()
+	return result.affected, result.err()
 }
 
 // ActiveMonthlyUsers reactivates monthly traffic users
-// at the beginning of a new month.
+// whose billing cycle rolled over since they were deactivated.
+//
+// Cycles start on the day of month of the user's cycle anchor, its creation
+// or renewal date, so the job runs daily and picks whichever users' cycles
+// started since their deactivation, missed days included.
 //
 // Conditions:
-//   - User is currently deactivated
+//   - User is currently deactivated, locked for exceeding its quota
 //   - Traffic type is MonthlyReceive or MonthlyTransmit
 //   - User is not expired
+//   - The current cycle of the user started after its deactivation day
 //
 // Actions:
 //   - Reset rx and tx counters
-//   - Remove deactivated_at
+//   - Remove deactivated_at and the lock reason
 //   - Unlock user
 //
-// Runs concurrently with max 10 workers.
-func (c *CornService) ActiveMonthlyUsers(ctx context.Context, db *gorm.DB) {
+// Runs concurrently with max 10 workers. It returns the number of reactivated
+// users and the failures of the others.
+func (c *CornService) ActiveMonthlyUsers(ctx context.Context, db *gorm.DB) (int, error) {
+	ctx, _ = requestid.Ensure(ctx)
+	log := logger.FromContext(ctx).With("job", "active_monthly_users")
+
 	var users []commonModels.OcservUser
-	today := time.Now().Truncate(24 * time.Hour)
+	now := time.Now()
+	today := startOfDay(now, now.Location())
 
 	err := db.WithContext(ctx).
+		Preload("Servers").
 		Where("(expire_at IS NULL OR expire_at > ?)", today).
 		Where("deactivated_at IS NOT NULL").
+		Where("lock_reason = ?", commonModels.LockReasonQuota).
 		Where("traffic_type IN ?", []string{
 			commonModels.MonthlyReceive,
 			commonModels.MonthlyTransmit,
 		}).
 		Find(&users).Error
 	if err != nil {
-		logger.Error("Failed to get users: %v", err)
-		return
+		log.Error("Failed to get users: %v", err)
+		return 0, fmt.Errorf("get users: %w", err)
 	}
 
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, 10)
+	result := &runResult{}
 
 	for _, u := range users {
+		if !cycleRolledOver(&u, now) {
+			continue
+		}
+
 		wg.Add(1)
 		sem <- struct{}{}
 
@@ -278,26 +200,40 @@ func (c *CornService) ActiveMonthlyUsers(ctx context.Context, db *gorm.DB) {
 				"tx":             0,
 				"deactivated_at": nil,
 				"is_locked":      false,
+				"lock_reason":    "",
 			}).Error; err2 != nil {
-				logger.Error("Failed to update user %s: %v", u.Username, err2)
+				log.Error("Failed to update user %s: %v", u.Username, err2)
+				result.fail(fmt.Errorf("update user %s: %w", u.Username, err2))
 				return
 			}
-
-			var unlock func(string) (string, error)
-
-			if c.dockerMode {
-				unlock = c.occtlDockerRepo.Unlock
-			} else {
-				unlock = c.ocservUserHandler.UnLock
-			}
-			if _, err2 := unlock(u.Username); err2 != nil {
-				logger.Error("Failed to unlock user %s: %v", u.Username, err2)
+			result.done()
+
+			if err2 := driver.FanOut(ctx, c.nodes(u.Servers), func(_ driver.Node, d driver.Driver) error {
+				_, err3 := d.Users().UnLock(u.Username)
+				return err3
+			}); err2 != nil {
+				log.Error("Failed to unlock user %s: %v", u.Username, err2)
+				result.fail(fmt.Errorf("unlock user %s: %w", u.Username, err2))
 			}
 
 		}(u)
 	}
 
 	wg.Wait()
+	return result.affected, result.err()
+}
+
+// cycleRolledOver reports whether the current billing cycle of the deactivated
+// user u started after the day it was deactivated. deactivated_at is a date, it
+// is compared as a day of the location of now.
+func cycleRolledOver(u *commonModels.OcservUser, now time.Time) bool {
+	cycleStart, _ := u.BillingCycle(now)
+	return cycleStart.After(startOfDay(*u.DeactivatedAt, now.Location()))
+}
+
+// startOfDay returns the midnight starting the calendar day of t in loc.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 }
 
 // DeleteExpiredUsers permanently deletes users who:
@@ -306,8 +242,8 @@ func (c *CornService) ActiveMonthlyUsers(ctx context.Context, db *gorm.DB) {
 //   - Have been inactive longer than system.KeepInactiveUserDays
 //   - AutoDeleteInactiveUsers setting is enabled
 //
-// Uses bulk delete for performance and logs number of deleted rows.
-func (c *CornService) DeleteExpiredUsers(ctx context.Context, db *gorm.DB) {
+// Uses bulk delete for performance and returns the number of deleted rows.
+func (c *CornService) DeleteExpiredUsers(ctx context.Context, db *gorm.DB) (int, error) {
 	var system models.System
 	err := db.WithContext(ctx).First(&system).Error
 	if err != nil {
@@ -319,12 +255,12 @@ func (c *CornService) DeleteExpiredUsers(ctx context.Context, db *gorm.DB) {
 
 	if !system.AutoDeleteInactiveUsers {
 		logger.Warn("User auto-delete is disabled")
-		return
+		return 0, nil
 	}
 
 	if system.KeepInactiveUserDays < 1 {
 		logger.Warn("User keep inactive days is lower than 1 day")
-		return
+		return 0, nil
 	}
 
 	cutoffDate := time.Now().AddDate(0, 0, -system.KeepInactiveUserDays).UTC()
@@ -334,13 +270,37 @@ func (c *CornService) DeleteExpiredUsers(ctx context.Context, db *gorm.DB) {
 
 	if result.Error != nil {
 		logger.Error("Failed to delete inactive users: %v", result.Error)
-		return
+		return 0, result.Error
 	}
 
 	if result.RowsAffected == 0 {
 		logger.Info("No inactive users found for deletion")
-		return
+		return 0, nil
 	}
 
 	logger.Info("Deleted %d inactive users", result.RowsAffected)
+	return int(result.RowsAffected), nil
+}
+
+// runResult collects the outcome of the concurrent user updates of a job.
+type runResult struct {
+	mu       sync.Mutex
+	affected int
+	errs     []error
+}
+
+func (r *runResult) done() {
+	r.mu.Lock()
+	r.affected++
+	r.mu.Unlock()
+}
+
+func (r *runResult) fail(err error) {
+	r.mu.Lock()
+	r.errs = append(r.errs, err)
+	r.mu.Unlock()
+}
+
+func (r *runResult) err() error {
+	return errors.Join(r.errs...)
 }
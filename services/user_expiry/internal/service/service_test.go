@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	commonModels "github.com/mmtaee/ocserv-dashboard/common/models"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/database/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestActiveMonthlyUsers(t *testing.T) {
+	db := dbtest.Open(t, &commonModels.Server{}, &commonModels.OcservUser{})
+
+	fake := driver.NewFake()
+	now := time.Now()
+	anchor := now.AddDate(0, -2, 0)
+	deactivated := now.AddDate(0, 0, -40)
+
+	reasons := map[string]string{
+		"john": commonModels.LockReasonQuota,
+		"jane": commonModels.LockReasonAnomaly,
+		"joe":  commonModels.LockReasonManual,
+	}
+	for username, reason := range reasons {
+		user := commonModels.OcservUser{
+			UID:           username,
+			Username:      username,
+			Password:      "secret",
+			IsLocked:      true,
+			DeactivatedAt: &deactivated,
+			LockReason:    reason,
+			CycleAnchor:   anchor,
+			TrafficType:   commonModels.MonthlyTransmit,
+			TrafficSize:   1,
+			Tx:            1 << 30,
+		}
+		require.NoError(t, db.Create(&user).Error)
+		require.NoError(t, fake.Users().Create("defaults", username, "secret", nil))
+		_, err := fake.Users().Lock(username)
+		require.NoError(t, err)
+	}
+
+	affected, err := NewCornService(fake).ActiveMonthlyUsers(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, 1, affected, "expected only the user locked for its quota to be reactivated")
+
+	for username, reason := range reasons {
+		var user commonModels.OcservUser
+		require.NoError(t, db.Where("username = ?", username).First(&user).Error)
+		ocservUser, _ := fake.User(username)
+
+		if reason != commonModels.LockReasonQuota {
+			assert.True(t, user.IsLocked, "%s locked for %s unlocked", username, reason)
+			assert.True(t, ocservUser.Locked, "%s locked for %s unlocked on ocserv", username, reason)
+			assert.NotNil(t, user.DeactivatedAt, username)
+			assert.Equal(t, reason, user.LockReason, username)
+			continue
+		}
+		assert.False(t, user.IsLocked, username)
+		assert.False(t, ocservUser.Locked, username)
+		assert.Nil(t, user.DeactivatedAt, username)
+		assert.Empty(t, user.LockReason, username)
+		assert.Zero(t, user.Tx, username)
+	}
+}
+
+func TestCycleRolledOver(t *testing.T) {
+	tehran := time.FixedZone("IRST", 3*3600+1800)
+	// deactivated_at and the cycle anchor are dates, read back at midnight UTC
+	deactivated := time.Date(2026, 9, 20, 0, 0, 0, 0, time.UTC)
+	user := commonModels.OcservUser{
+		CycleAnchor:   time.Date(2026, 1, 19, 0, 0, 0, 0, time.UTC),
+		DeactivatedAt: &deactivated,
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{name: "before the cycle day", now: time.Date(2026, 10, 18, 23, 0, 0, 0, tehran)},
+		// still October 18 in UTC
+		{name: "just after midnight of the cycle day", now: time.Date(2026, 10, 19, 0, 30, 0, 0, tehran), want: true},
+		{name: "on the day of the deactivation", now: time.Date(2026, 9, 20, 12, 0, 0, 0, tehran)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cycleRolledOver(&user, tt.now))
+		})
+	}
+}
+
+func TestStartOfDay(t *testing.T) {
+	tehran := time.FixedZone("IRST", 3*3600+1800)
+	now := time.Date(2026, 10, 19, 0, 30, 0, 0, tehran)
+
+	today := startOfDay(now, now.Location())
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, tehran), today)
+	// truncating would have gone back to midnight UTC, October 18 at 03:30 here
+	assert.True(t, today.After(now.Truncate(24*time.Hour)))
+}
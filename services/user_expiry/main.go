@@ -3,10 +3,14 @@ package main
 import (
 	"context"
 	"flag"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/driver"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/database"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/leader"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
+	"github.com/mmtaee/ocserv-dashboard/user_expiry/internal/scheduler"
 	"github.com/mmtaee/ocserv-dashboard/user_expiry/internal/service"
+	"github.com/mmtaee/ocserv-dashboard/user_expiry/pkg/state"
 	"os"
 	"os/signal"
 	"syscall"
@@ -24,19 +28,46 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	logger.Init(ctx, 100)
+	logger.Init(ctx, "user_expiry", 100)
 
 	config.Init(debug, "", 8888)
 	database.Connect()
 
-	cronService := service.NewCornService(dockerMode)
+	ocservDriver, err := driver.Init(dockerMode)
+	if err != nil {
+		logger.Fatal("Failed to create ocserv driver: %v", err)
+	}
 
-	logger.Info("Start checking missing cron jobs")
-	cronService.MissedCron()
-	logger.Info("Checking missing cron jobs completed")
+	// replicas share the jobs table, only the leader runs the jobs
+	db := database.GetConnection()
+	elector := leader.New(db, "user_expiry")
+	cronService := service.NewCornService(ocservDriver)
 
+	jobScheduler := scheduler.New(db, elector.Term)
+	for _, job := range cronService.Jobs() {
+		jobScheduler.Register(job)
+	}
+	if err = jobScheduler.Start(ctx); err != nil {
+		logger.Fatal("Failed to start job scheduler: %v", err)
+	}
+
+	err = state.ImportLegacyFile(db, map[string]string{
+		"daily_last_run":                service.JobExpireUsers,
+		"monthly_last_run":              service.JobActiveMonthlyUsers,
+		"delete_inactive_user_last_run": service.JobDeleteExpiredUsers,
+	})
+	if err != nil {
+		logger.Error("Failed to import the legacy cron state: %v", err)
+	}
+
+	electorDone := make(chan struct{})
 	go func() {
-		cronService.UserExpiryCron(ctx)
+		defer close(electorDone)
+		elector.Run(ctx, func(ctx context.Context) {
+			logger.Info("Start checking missed jobs")
+			jobScheduler.RunMissed(ctx)
+			logger.Info("Checking missed jobs completed")
+		})
 	}()
 
 	sigChan := make(chan os.Signal, 1)
@@ -45,6 +76,7 @@ func main() {
 	sig := <-sigChan
 	logger.Warn("Received signal: %s ", sig)
 	cancel()
+	<-electorDone
 
 	logger.Info("User expiry service shutting down completed")
 }
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	occtlDocker "github.com/mmtaee/ocserv-dashboard/common/occtl_docker"
+	"github.com/mmtaee/ocserv-dashboard/common/ocserv/group"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/occtl"
 	"github.com/mmtaee/ocserv-dashboard/common/ocserv/user"
+	"github.com/mmtaee/ocserv-dashboard/common/pkg/config"
 	"github.com/mmtaee/ocserv-dashboard/common/pkg/logger"
 	"net/http"
 	"os"
@@ -23,22 +25,44 @@ type Webhook struct {
 }
 
 var (
-	occtlHandler      occtl.OcservOcctlInterface
-	ocservUserHandler user.OcservUserInterface
+	occtlHandler       occtl.OcservOcctlInterface
+	ocservUserHandler  user.OcservUserInterface
+	ocservGroupHandler group.OcservGroupInterface
+	verifier           *occtlDocker.Verifier
 )
 
 func init() {
-	occtlHandler = occtl.NewOcservOcctl()
+	occtlHandler = occtl.New()
 	ocservUserHandler = user.NewOcservUser()
+	ocservGroupHandler = group.NewOcservGroup()
 }
 
 func main() {
+	logCtx, stopLogger := context.WithCancel(context.Background())
+	defer func() {
+		stopLogger()
+		<-logger.Done()
+	}()
+	logger.Init(logCtx, "webhook", 100)
+
+	cfg := config.Webhook()
+	if err := cfg.CheckSecret(); err != nil {
+		logger.Fatal("Refusing to start the webhook: %v", err)
+	}
+	verifier = occtlDocker.NewVerifier([]byte(cfg.Secret))
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/webhook/", webhookHandler)
+	mux.Handle(occtlDocker.RPCPrefix, occtlDocker.NewRPCServer(verifier, ocservUserHandler, ocservGroupHandler, occtlHandler))
 
 	server := &http.Server{
-		Addr:    "0.0.0.0:8888",
-		Handler: mux,
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    8 << 10,
 	}
 
 	stop := make(chan os.Signal, 1)
@@ -64,62 +88,66 @@ func main() {
 	logger.Info("Webhook server shutdown successfully")
 }
 
-// webhookHandler extracts action from path and handles requests
+// webhookHandler verifies the signature of the request, extracts action from path
+// and handles it. The disconnect, lock and unlock actions predate the RPC API and
+// are kept for clients of older releases.
 func webhookHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	body, requestID, log, ok := occtlDocker.ReadRequest(w, r, verifier)
+	if !ok {
 		return
 	}
 
 	payload := occtlDocker.WebhookPayload{}
-	defer r.Body.Close()
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&payload); err != nil {
-		http.Error(w, "Invalid payload: "+err.Error(), http.StatusBadRequest)
+	if err := json.Unmarshal(body, &payload); err != nil {
+		occtlDocker.WriteError(w, log, requestID, http.StatusBadRequest, occtlDocker.ErrCodeInvalidPayload, "Invalid payload: "+err.Error())
 		return
 	}
 
-	if payload.Username == "" {
-		http.Error(w, "Username is required", http.StatusBadRequest)
+	if !occtlDocker.ValidName(payload.Username) {
+		occtlDocker.WriteError(w, log, requestID, http.StatusBadRequest, occtlDocker.ErrCodeInvalidPayload, "Invalid username")
 		return
 	}
 
 	// Extract action from path: /webhook/<action>
 	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(parts) < 2 {
-		http.Error(w, "Action not specified in URL path", http.StatusBadRequest)
+	if len(parts) != 2 {
+		occtlDocker.WriteError(w, log, requestID, http.StatusNotFound, occtlDocker.ErrCodeUnknownAction, "Action not specified in URL path")
 		return
 	}
 	action := strings.ToLower(parts[1])
 
-	logger.Info("Received webhook action: %s for username %s", action, payload.Username)
+	log = log.With("action", action, "username", payload.Username)
+	log.Info("Received webhook action")
 
+	var (
+		msg  string
+		verb string
+		err  error
+	)
 	switch action {
 	case "disconnect":
-		msg, err := occtlHandler.DisconnectUser(payload.Username)
-		if err != nil {
-			http.Error(w, "Failed to disconnect user: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-		_, _ = fmt.Fprintf(w, "User %s disconnected successfully. message: %s", payload.Username, msg)
-
+		verb = "disconnected"
+		msg, err = occtlHandler.DisconnectUser(payload.Username)
 	case "lock":
-		msg, err := ocservUserHandler.Lock(payload.Username)
-		if err != nil {
-			http.Error(w, "Failed to lock user: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-		_, _ = fmt.Fprintf(w, "User %s locked successfully. message: %s", payload.Username, msg)
-
+		verb = "locked"
+		msg, err = ocservUserHandler.Lock(payload.Username)
 	case "unlock":
-		msg, err := ocservUserHandler.UnLock(payload.Username)
-		if err != nil {
-			http.Error(w, "Failed to unlock user: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-		_, _ = fmt.Fprintf(w, "User %s unlocked successfully. message: %s", payload.Username, msg)
-
+		verb = "unlocked"
+		msg, err = ocservUserHandler.UnLock(payload.Username)
 	default:
-		http.Error(w, "Unknown action: "+action, http.StatusBadRequest)
+		occtlDocker.WriteError(w, log, requestID, http.StatusNotFound, occtlDocker.ErrCodeUnknownAction, "Unknown action: "+action)
+		return
 	}
+
+	if err != nil {
+		occtlDocker.WriteError(w, log, requestID, http.StatusBadRequest, occtlDocker.ErrCodeActionFailed,
+			fmt.Sprintf("Failed to %s user: %v", action, err))
+		return
+	}
+
+	occtlDocker.WriteResponse(w, http.StatusOK, occtlDocker.WebhookResponse{
+		OK:        true,
+		Message:   fmt.Sprintf("User %s %s successfully. message: %s", payload.Username, verb, msg),
+		RequestID: requestID,
+	})
 }